@@ -0,0 +1,618 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reviewer-service/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+type runFunc func(e *env, args []string) error
+
+// command описывает подкоманду; setup регистрирует её флаги и возвращает
+// обработчик, замыкающий значения этих флагов.
+type command struct {
+	name  string
+	words int
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+var commandTable = []command{
+	{name: "team create", words: 2, setup: teamCreate},
+	{name: "team get", words: 2, setup: teamGet},
+	{name: "team deactivate-members", words: 2, setup: noFlags(teamDeactivateMembers)},
+	{name: "team add-member", words: 2, setup: teamAddMember},
+	{name: "team remove-member", words: 2, setup: noFlags(teamRemoveMember)},
+	{name: "team archive", words: 2, setup: noFlags(teamArchive)},
+	{name: "team sync", words: 2, setup: teamSync},
+	{name: "user activate", words: 2, setup: noFlags(userSetActive(true))},
+	{name: "user deactivate", words: 2, setup: noFlags(userSetActive(false))},
+	{name: "user reviews", words: 2, setup: userReviews},
+	{name: "user move", words: 2, setup: noFlags(userMove)},
+	{name: "user archive", words: 2, setup: noFlags(userArchive)},
+	{name: "pr create", words: 2, setup: prCreate},
+	{name: "pr merge", words: 2, setup: noFlags(prMerge)},
+	{name: "pr reassign", words: 2, setup: noFlags(prReassign)},
+	{name: "pr history", words: 2, setup: noFlags(prHistory)},
+	{name: "pr get", words: 2, setup: noFlags(prGet)},
+	{name: "pr list", words: 2, setup: prList},
+	{name: "stats", words: 1, setup: noFlags(stats)},
+}
+
+func noFlags(run runFunc) func(fs *flag.FlagSet) runFunc {
+	return func(fs *flag.FlagSet) runFunc { return run }
+}
+
+func lookupCommand(args []string) (command, bool) {
+	for _, cmd := range commandTable {
+		if len(args) >= cmd.words && strings.Join(args[:cmd.words], " ") == cmd.name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func expectArgs(args []string, n int, names string) error {
+	if len(args) != n {
+		return fmt.Errorf("%w: expected %s", errUsage, names)
+	}
+	return nil
+}
+
+type memberFlags []client.TeamMember
+
+func (m *memberFlags) String() string {
+	return fmt.Sprint(*m)
+}
+
+func (m *memberFlags) Set(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("member must be ID:USERNAME[:inactive], got %q", value)
+	}
+	member := client.TeamMember{UserID: parts[0], Username: parts[1], IsActive: true}
+	if len(parts) == 3 {
+		if parts[2] != "inactive" {
+			return fmt.Errorf("unknown member flag %q, only \"inactive\" is supported", parts[2])
+		}
+		member.IsActive = false
+	}
+	*m = append(*m, member)
+	return nil
+}
+
+func teamCreate(fs *flag.FlagSet) runFunc {
+	var members memberFlags
+	fs.Var(&members, "member", "team member as ID:USERNAME[:inactive] (repeatable)")
+	file := fs.String("file", "", "JSON file with team definition")
+
+	return func(e *env, args []string) error {
+		var team client.Team
+		if *file != "" {
+			if len(args) != 0 || len(members) != 0 {
+				return fmt.Errorf("%w: --file cannot be combined with a team name or --member", errUsage)
+			}
+			raw, err := os.ReadFile(*file)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(raw, &team); err != nil {
+				return fmt.Errorf("parse %s: %w", *file, err)
+			}
+		} else {
+			if err := expectArgs(args, 1, "<team>"); err != nil {
+				return err
+			}
+			team = client.Team{TeamName: args[0], Members: members}
+		}
+
+		created, err := e.client.CreateTeam(e.ctx, team)
+		if err != nil {
+			return err
+		}
+		return e.render(created, teamTable(created))
+	}
+}
+
+func teamGet(fs *flag.FlagSet) runFunc {
+	includeArchived := fs.Bool("include-archived", false, "show archived team and members")
+
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 1, "<team>"); err != nil {
+			return err
+		}
+		if !*includeArchived {
+			team, err := e.client.GetTeam(e.ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return e.render(team, teamTable(team))
+		}
+
+		team, err := e.client.GetTeamWithArchived(e.ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return e.render(team, archivedTeamTable(team))
+	}
+}
+
+func teamArchive(e *env, args []string) error {
+	if err := expectArgs(args, 1, "<team>"); err != nil {
+		return err
+	}
+	result, err := e.client.ArchiveTeam(e.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if err := e.render(result, archivedTeamTable(result.Team)); err != nil {
+		return err
+	}
+	if e.output == formatTable {
+		fmt.Fprintf(e.stdout, "\nreassigned PRs: %d\n", result.ReassignedPRs)
+	}
+	return nil
+}
+
+// syncManifest — файл для team sync в YAML или JSON; is_active по умолчанию true.
+type syncManifest struct {
+	Teams []struct {
+		TeamName string `yaml:"team_name"`
+		Members  []struct {
+			UserID   string `yaml:"user_id"`
+			Username string `yaml:"username"`
+			IsActive *bool  `yaml:"is_active"`
+		} `yaml:"members"`
+	} `yaml:"teams"`
+}
+
+func readSyncManifest(path string) ([]client.Team, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest syncManifest
+	if err := yaml.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	teams := make([]client.Team, 0, len(manifest.Teams))
+	for _, t := range manifest.Teams {
+		team := client.Team{TeamName: t.TeamName, Members: make([]client.TeamMember, 0, len(t.Members))}
+		for _, m := range t.Members {
+			team.Members = append(team.Members, client.TeamMember{
+				UserID:   m.UserID,
+				Username: m.Username,
+				IsActive: m.IsActive == nil || *m.IsActive,
+			})
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func teamSync(fs *flag.FlagSet) runFunc {
+	file := fs.String("file", "", "YAML or JSON manifest with all teams and members")
+	fs.StringVar(file, "f", "", "manifest file (shorthand)")
+	dryRun := fs.Bool("dry-run", false, "only show the planned changes")
+
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+		if *file == "" {
+			return fmt.Errorf("%w: --file is required", errUsage)
+		}
+		teams, err := readSyncManifest(*file)
+		if err != nil {
+			return err
+		}
+
+		result, err := e.client.SyncTeams(e.ctx, teams, *dryRun)
+		if err != nil {
+			return err
+		}
+		t := table{header: []string{"ACTION", "TEAM", "USER ID", "PREVIOUS TEAM"}}
+		for _, c := range result.Changes {
+			t.rows = append(t.rows, []string{c.Action, dash(c.TeamName), dash(c.UserID), dash(c.PreviousTeam)})
+		}
+		if err := e.render(result, t); err != nil {
+			return err
+		}
+		if e.output == formatTable {
+			if result.DryRun {
+				fmt.Fprintf(e.stdout, "\ndry run: %d changes, ~%d PRs would be reassigned\n", len(result.Changes), result.ReassignedPRs)
+			} else {
+				fmt.Fprintf(e.stdout, "\napplied %d changes, reassigned PRs: %d\n", len(result.Changes), result.ReassignedPRs)
+			}
+		}
+		return nil
+	}
+}
+
+func teamDeactivateMembers(e *env, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: expected <team> <user_id>...", errUsage)
+	}
+	result, err := e.client.DeactivateTeamMembers(e.ctx, args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	return e.render(result, table{
+		header: []string{"DEACTIVATED USERS", "REASSIGNED PRS"},
+		rows:   [][]string{{strings.Join(result.DeactivatedUsers, ","), fmt.Sprint(result.ReassignedPRs)}},
+	})
+}
+
+func teamAddMember(fs *flag.FlagSet) runFunc {
+	active := fs.Bool("active", false, "mark the member as active")
+	inactive := fs.Bool("inactive", false, "mark the member as inactive")
+
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 3, "<team> <user_id> <username>"); err != nil {
+			return err
+		}
+		if *active && *inactive {
+			return fmt.Errorf("%w: --active and --inactive are mutually exclusive", errUsage)
+		}
+		// Без флагов активность существующего пользователя не меняется
+		var isActive *bool
+		if *active || *inactive {
+			isActive = active
+		}
+		result, err := e.client.AddTeamMember(e.ctx, args[0], args[1], args[2], isActive)
+		if err != nil {
+			return err
+		}
+		return e.renderMembership(result)
+	}
+}
+
+func teamRemoveMember(e *env, args []string) error {
+	if err := expectArgs(args, 2, "<team> <user_id>"); err != nil {
+		return err
+	}
+	result, err := e.client.RemoveTeamMember(e.ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return e.renderMembership(result)
+}
+
+func (e *env) renderMembership(result *client.MembershipResult) error {
+	if err := e.render(result, teamTable(result.Team)); err != nil {
+		return err
+	}
+	if e.output == formatTable {
+		fmt.Fprintf(e.stdout, "\nreassigned PRs: %d\n", result.ReassignedPRs)
+	}
+	return nil
+}
+
+func userMove(e *env, args []string) error {
+	if err := expectArgs(args, 2, "<user_id> <team>"); err != nil {
+		return err
+	}
+	result, err := e.client.MoveUser(e.ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return e.render(result, userChangeTable(result))
+}
+
+func userArchive(e *env, args []string) error {
+	if err := expectArgs(args, 1, "<user_id>"); err != nil {
+		return err
+	}
+	result, err := e.client.ArchiveUser(e.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	t := userChangeTable(result)
+	t.header = append(t.header, "ARCHIVED AT")
+	t.rows[0] = append(t.rows[0], archivedAt(result.User.ArchivedAt))
+	return e.render(result, t)
+}
+
+func userChangeTable(result *client.UserChangeResult) table {
+	user := result.User
+	return table{
+		header: []string{"USER ID", "USERNAME", "TEAM", "ACTIVE", "REASSIGNED PRS"},
+		rows:   [][]string{{user.UserID, user.Username, user.TeamName, fmt.Sprint(user.IsActive), fmt.Sprint(result.ReassignedPRs)}},
+	}
+}
+
+func userSetActive(isActive bool) func(e *env, args []string) error {
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 1, "<user_id>"); err != nil {
+			return err
+		}
+		user, err := e.client.SetUserActive(e.ctx, args[0], isActive)
+		if err != nil {
+			return err
+		}
+		return e.render(user, table{
+			header: []string{"USER ID", "USERNAME", "TEAM", "ACTIVE"},
+			rows:   [][]string{{user.UserID, user.Username, user.TeamName, fmt.Sprint(user.IsActive)}},
+		})
+	}
+}
+
+func userReviews(fs *flag.FlagSet) runFunc {
+	var opts client.ReviewsOptions
+	fs.StringVar(&opts.Status, "status", "", "OPEN (default), MERGED or ALL")
+	fs.BoolVar(&opts.IncludeAuthored, "include-authored", false, "also list the user's own PRs")
+	fs.StringVar(&opts.Sort, "sort", "", "pull_request_id or assigned_at (oldest waiting first)")
+	fs.IntVar(&opts.Limit, "limit", 0, "page size (1-100)")
+	fs.StringVar(&opts.Cursor, "cursor", "", "cursor from the previous page")
+	all := fs.Bool("all", false, "follow cursors and print every page")
+
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 1, "<user_id>"); err != nil {
+			return err
+		}
+
+		prs := make([]client.PullRequestShort, 0)
+		for {
+			page, next, err := e.client.ListUserReviews(e.ctx, args[0], opts)
+			if err != nil {
+				return err
+			}
+			prs = append(prs, page...)
+			opts.Cursor = next
+			if next == "" || !*all {
+				break
+			}
+		}
+
+		t := table{header: []string{"PR ID", "NAME", "AUTHOR", "STATUS", "ROLE", "WAITING"}}
+		for _, pr := range prs {
+			waiting := (time.Duration(pr.ReviewAgeSeconds) * time.Second).String()
+			t.rows = append(t.rows, []string{pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.Role, waiting})
+		}
+		var next interface{}
+		if opts.Cursor != "" {
+			next = opts.Cursor
+		}
+		if err := e.render(map[string]interface{}{"user_id": args[0], "pull_requests": prs, "next_cursor": next}, t); err != nil {
+			return err
+		}
+		if opts.Cursor != "" && e.output == formatTable {
+			fmt.Fprintf(e.stdout, "\nmore results: --cursor %s\n", opts.Cursor)
+		}
+		return nil
+	}
+}
+
+func prCreate(fs *flag.FlagSet) runFunc {
+	id := fs.String("id", "", "pull request ID")
+	name := fs.String("name", "", "pull request name")
+	author := fs.String("author", "", "author user ID")
+
+	return func(e *env, args []string) error {
+		if len(args) != 0 || *id == "" || *name == "" || *author == "" {
+			return fmt.Errorf("%w: pr create requires --id, --name and --author", errUsage)
+		}
+		pr, err := e.client.CreatePullRequest(e.ctx, *id, *name, *author)
+		if err != nil {
+			return err
+		}
+		return e.render(pr, prTable(pr))
+	}
+}
+
+func prMerge(e *env, args []string) error {
+	if err := expectArgs(args, 1, "<pr_id>"); err != nil {
+		return err
+	}
+	pr, err := e.client.MergePullRequest(e.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.render(pr, prTable(pr))
+}
+
+func prReassign(e *env, args []string) error {
+	if err := expectArgs(args, 2, "<pr_id> <old_user_id>"); err != nil {
+		return err
+	}
+	pr, replacedBy, err := e.client.ReassignReviewer(e.ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	t := prTable(pr)
+	t.header = append(t.header, "REPLACED BY")
+	t.rows[0] = append(t.rows[0], replacedBy)
+	return e.render(map[string]interface{}{"pr": pr, "replaced_by": replacedBy}, t)
+}
+
+func prHistory(e *env, args []string) error {
+	if err := expectArgs(args, 1, "<pr_id>"); err != nil {
+		return err
+	}
+	events, err := e.client.GetPullRequestHistory(e.ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "TIME", "EVENT", "USER", "PREVIOUS USER"}}
+	for _, ev := range events {
+		t.rows = append(t.rows, []string{
+			fmt.Sprint(ev.ID),
+			ev.CreatedAt.UTC().Format(timeFormat),
+			ev.Type,
+			dash(ev.UserID),
+			dash(ev.PreviousUserID),
+		})
+	}
+	return e.render(map[string]interface{}{"pull_request_id": args[0], "events": events}, t)
+}
+
+func prGet(e *env, args []string) error {
+	if err := expectArgs(args, 1, "<pr_id>"); err != nil {
+		return err
+	}
+	pr, err := e.client.GetPullRequest(e.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.render(pr, prTable(pr))
+}
+
+// timeFlag принимает дату в RFC 3339 или просто YYYY-MM-DD (полночь UTC).
+type timeFlag struct{ t *time.Time }
+
+func (f timeFlag) String() string {
+	if f.t == nil || f.t.IsZero() {
+		return ""
+	}
+	return f.t.Format(time.RFC3339)
+}
+
+func (f timeFlag) Set(value string) error {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			*f.t = t
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q: use RFC 3339 or YYYY-MM-DD", value)
+}
+
+func prList(fs *flag.FlagSet) runFunc {
+	var opts client.ListOptions
+	fs.StringVar(&opts.Status, "status", "", "OPEN or MERGED")
+	fs.StringVar(&opts.AuthorID, "author", "", "author user ID")
+	fs.StringVar(&opts.ReviewerID, "reviewer", "", "assigned reviewer user ID")
+	fs.StringVar(&opts.TeamName, "team", "", "author's team")
+	fs.Var(timeFlag{&opts.CreatedFrom}, "created-from", "created at or after")
+	fs.Var(timeFlag{&opts.CreatedTo}, "created-to", "created before")
+	fs.Var(timeFlag{&opts.MergedFrom}, "merged-from", "merged at or after")
+	fs.Var(timeFlag{&opts.MergedTo}, "merged-to", "merged before")
+	fs.BoolVar(&opts.NeedsReviewers, "needs-reviewers", false, "only open PRs with fewer than 2 reviewers")
+	fs.StringVar(&opts.Sort, "sort", "", "created_at or pull_request_id")
+	fs.StringVar(&opts.Order, "order", "", "asc or desc")
+	fs.IntVar(&opts.Limit, "limit", 0, "page size (1-100)")
+	fs.StringVar(&opts.Cursor, "cursor", "", "cursor from the previous page")
+	all := fs.Bool("all", false, "follow cursors and print every page")
+
+	return func(e *env, args []string) error {
+		if err := expectArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+
+		prs := make([]client.PullRequest, 0)
+		for {
+			page, next, err := e.client.ListPullRequests(e.ctx, opts)
+			if err != nil {
+				return err
+			}
+			prs = append(prs, page...)
+			opts.Cursor = next
+			if next == "" || !*all {
+				break
+			}
+		}
+
+		t := table{header: prHeader}
+		for i := range prs {
+			t.rows = append(t.rows, prRow(&prs[i]))
+		}
+		var next interface{}
+		if opts.Cursor != "" {
+			next = opts.Cursor
+		}
+		if err := e.render(map[string]interface{}{"pull_requests": prs, "next_cursor": next}, t); err != nil {
+			return err
+		}
+		if opts.Cursor != "" && e.output == formatTable {
+			fmt.Fprintf(e.stdout, "\nmore results: --cursor %s\n", opts.Cursor)
+		}
+		return nil
+	}
+}
+
+func stats(e *env, args []string) error {
+	if err := expectArgs(args, 0, "no arguments"); err != nil {
+		return err
+	}
+	s, err := e.client.GetStatistics(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{
+		header: []string{"METRIC", "VALUE"},
+		rows: [][]string{
+			{"teams", fmt.Sprint(s.Teams.Total)},
+			{"users", fmt.Sprint(s.Users.Total)},
+			{"users active", fmt.Sprint(s.Users.Active)},
+			{"users inactive", fmt.Sprint(s.Users.Inactive)},
+			{"pull requests", fmt.Sprint(s.PullRequests.Total)},
+			{"pull requests open", fmt.Sprint(s.PullRequests.Open)},
+			{"pull requests merged", fmt.Sprint(s.PullRequests.Merged)},
+			{"review assignments", fmt.Sprint(s.ReviewAssignments.Total)},
+		},
+	}
+	for _, r := range s.ReviewAssignments.ByReviewer {
+		t.rows = append(t.rows, []string{"assignments " + r.UserID, fmt.Sprint(r.Count)})
+	}
+	return e.render(s, t)
+}
+
+func teamTable(team *client.Team) table {
+	t := table{header: []string{"TEAM", "USER ID", "USERNAME", "ACTIVE"}}
+	for _, m := range team.Members {
+		t.rows = append(t.rows, []string{team.TeamName, m.UserID, m.Username, fmt.Sprint(m.IsActive)})
+	}
+	return t
+}
+
+func archivedTeamTable(team *client.Team) table {
+	t := table{header: []string{"TEAM", "USER ID", "USERNAME", "ACTIVE", "ARCHIVED AT"}}
+	for _, m := range team.Members {
+		t.rows = append(t.rows, []string{team.TeamName, m.UserID, m.Username, fmt.Sprint(m.IsActive), archivedAt(m.ArchivedAt)})
+	}
+	return t
+}
+
+func archivedAt(at *time.Time) string {
+	if at == nil {
+		return "-"
+	}
+	return at.UTC().Format(timeFormat)
+}
+
+var prHeader = []string{"PR ID", "NAME", "AUTHOR", "STATUS", "REVIEWERS", "MERGED AT"}
+
+func prTable(pr *client.PullRequest) table {
+	return table{header: append([]string(nil), prHeader...), rows: [][]string{prRow(pr)}}
+}
+
+func prRow(pr *client.PullRequest) []string {
+	merged := "-"
+	if pr.MergedAt != nil {
+		merged = pr.MergedAt.UTC().Format(timeFormat)
+	}
+	return []string{
+		pr.PullRequestID,
+		pr.PullRequestName,
+		pr.AuthorID,
+		pr.Status,
+		dash(strings.Join(pr.AssignedReviewers, ",")),
+		merged,
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+// reviewerctl — административная CLI для сервиса назначения ревьюверов.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reviewer-service/pkg/client"
+)
+
+const usage = `usage: reviewerctl <command> <subcommand> [args] [flags]
+
+commands:
+  team create <team> --member ID:USERNAME[:inactive]...   create a team
+  team create --file team.json                             create a team from JSON
+  team get <team> [--include-archived]                     show team members
+  team deactivate-members <team> <user_id>...              deactivate members and reassign their PRs
+  team add-member <team> <user_id> <username> [--active|--inactive]
+                                                           add or update a member, moving them from another team
+  team remove-member <team> <user_id>                      remove a member and reassign their reviews
+  team archive <team>                                      archive a team together with its members
+  team sync --file manifest.yaml [--dry-run]               sync all teams and members with a YAML/JSON manifest
+  user activate <user_id>                                  mark user as active
+  user deactivate <user_id>                                mark user as inactive
+  user reviews <user_id> [--status OPEN|MERGED|ALL] [--include-authored]
+          [--sort pull_request_id|assigned_at] [--limit N] [--cursor C] [--all]
+                                                           list user's review inbox
+  user move <user_id> <team>                               move user to another team
+  user archive <user_id>                                   hand over user's open work and archive them
+  pr create --id ID --name NAME --author USER_ID           create PR with auto-assigned reviewers
+  pr merge <pr_id>                                         merge PR
+  pr reassign <pr_id> <old_user_id>                        replace a reviewer
+  pr history <pr_id>                                       show PR change log
+  pr get <pr_id>                                           show PR
+  pr list [--status S] [--author U] [--reviewer U] [--team T]
+          [--created-from D] [--created-to D] [--merged-from D] [--merged-to D]
+          [--needs-reviewers] [--sort F] [--order asc|desc] [--limit N] [--cursor C] [--all]
+                                                           list PRs page by page
+  stats                                                    show service statistics
+
+flags (accepted by every command):
+  --server URL        service URL (env REVIEWERCTL_SERVER, default http://localhost:8080)
+  --token TOKEN       bearer token (env REVIEWERCTL_TOKEN)
+  -o, --output FMT    table | json | yaml (env REVIEWERCTL_OUTPUT, default table)
+  --timeout DURATION  request timeout (default 10s)
+`
+
+// errUsage означает неверные аргументы: печатается usage, код выхода 2.
+var errUsage = errors.New("invalid usage")
+
+type globalFlags struct {
+	server  string
+	token   string
+	output  string
+	timeout time.Duration
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.server, "server", getEnv("REVIEWERCTL_SERVER", "http://localhost:8080"), "service URL")
+	fs.StringVar(&g.token, "token", os.Getenv("REVIEWERCTL_TOKEN"), "bearer token")
+	fs.StringVar(&g.output, "output", getEnv("REVIEWERCTL_OUTPUT", formatTable), "output format")
+	fs.StringVar(&g.output, "o", getEnv("REVIEWERCTL_OUTPUT", formatTable), "output format (shorthand)")
+	fs.DurationVar(&g.timeout, "timeout", client.DefaultTimeout, "request timeout")
+}
+
+// env — общее окружение команды: клиент, формат вывода и потоки.
+type env struct {
+	ctx    context.Context
+	client *client.Client
+	output string
+	stdout io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := lookupCommand(args)
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", joinArgs(args, 2), usage)
+		return 2
+	}
+
+	var g globalFlags
+	fs := flag.NewFlagSet("reviewerctl "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	g.register(fs)
+	runCmd := cmd.setup(fs)
+
+	positional, err := parseInterspersed(fs, args[cmd.words:])
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n\n%s", err, usage)
+		return 2
+	}
+	if err := validateFormat(g.output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	c, err := client.New(g.server,
+		client.WithToken(g.token),
+		client.WithUserAgent("reviewerctl"),
+		client.WithHTTPClient(newHTTPClient(g.timeout)),
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	e := &env{
+		ctx:    context.Background(),
+		client: c,
+		output: g.output,
+		stdout: stdout,
+	}
+	if err := runCmd(e, positional); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "%v\n\n%s", err, usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed разрешает флаги после позиционных аргументов:
+// стандартный flag останавливается на первом не-флаге.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+func joinArgs(args []string, n int) string {
+	if len(args) < n {
+		n = len(args)
+	}
+	return strings.Join(args[:n], " ")
+}
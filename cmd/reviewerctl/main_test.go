@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/server"
+	"github.com/reviewer-service/internal/storage"
+)
+
+func setupTestServer(t *testing.T) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{Idempotency: config.IdempotencyConfig{TTL: time.Hour}}
+	router, err := server.NewRouter(storage.NewMemory(), cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func runCtl(t *testing.T, srv *httptest.Server, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(append(args, "--server", srv.URL), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestReviewerctl_Flow(t *testing.T) {
+	srv := setupTestServer(t)
+
+	code, out, errOut := runCtl(t, srv, "team", "create", "backend",
+		"--member", "u1:Alice", "--member", "u2:Bob", "--member", "u3:Charlie", "--member", "u4:Dave:inactive")
+	if code != 0 {
+		t.Fatalf("team create exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "TEAM") || !strings.Contains(out, "Charlie") {
+		t.Errorf("unexpected table output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "pr", "create", "--id", "pr-1", "--name", "Add search", "--author", "u1", "-o", "json")
+	if code != 0 {
+		t.Fatalf("pr create exit code %d, stderr: %s", code, errOut)
+	}
+	var pr struct {
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	}
+	if err := json.Unmarshal([]byte(out), &pr); err != nil {
+		t.Fatalf("pr create output is not JSON: %v\n%s", err, out)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", pr.AssignedReviewers)
+	}
+
+	code, out, errOut = runCtl(t, srv, "user", "reviews", pr.AssignedReviewers[0], "-o", "yaml")
+	if code != 0 {
+		t.Fatalf("user reviews exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "user_id: "+pr.AssignedReviewers[0]) || !strings.Contains(out, "pull_request_id: pr-1") {
+		t.Errorf("unexpected yaml output:\n%s", out)
+	}
+
+	if code, _, errOut = runCtl(t, srv, "pr", "merge", "pr-1"); code != 0 {
+		t.Fatalf("pr merge exit code %d, stderr: %s", code, errOut)
+	}
+
+	code, out, errOut = runCtl(t, srv, "user", "reviews", pr.AssignedReviewers[0], "--status", "MERGED", "--sort", "assigned_at")
+	if code != 0 {
+		t.Fatalf("user reviews --status exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "pr-1") || !strings.Contains(out, "REVIEWER") {
+		t.Errorf("unexpected merged reviews output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "pr", "history", "pr-1")
+	if code != 0 {
+		t.Fatalf("pr history exit code %d, stderr: %s", code, errOut)
+	}
+	for _, event := range []string{"PR_CREATED", "REVIEWER_ASSIGNED", "PR_MERGED"} {
+		if !strings.Contains(out, event) {
+			t.Errorf("history misses %s:\n%s", event, out)
+		}
+	}
+
+	code, out, errOut = runCtl(t, srv, "pr", "get", "pr-1")
+	if code != 0 {
+		t.Fatalf("pr get exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "MERGED") {
+		t.Errorf("unexpected pr get output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "pr", "list", "--status", "MERGED", "--limit", "1", "--all", "-o", "json")
+	if code != 0 {
+		t.Fatalf("pr list exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"pull_request_id": "pr-1"`) || !strings.Contains(out, `"next_cursor": null`) {
+		t.Errorf("unexpected pr list output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "team", "add-member", "backend", "u5", "Eve", "--inactive")
+	if code != 0 {
+		t.Fatalf("team add-member exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Eve") || !strings.Contains(out, "reassigned PRs: 0") {
+		t.Errorf("unexpected team add-member output:\n%s", out)
+	}
+
+	if code, _, errOut = runCtl(t, srv, "team", "create", "frontend"); code != 0 {
+		t.Fatalf("team create exit code %d, stderr: %s", code, errOut)
+	}
+	code, out, errOut = runCtl(t, srv, "user", "move", "u5", "frontend", "-o", "json")
+	if code != 0 {
+		t.Fatalf("user move exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"team_name": "frontend"`) || !strings.Contains(out, `"reassigned_prs": 0`) {
+		t.Errorf("unexpected user move output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "team", "remove-member", "frontend", "u5")
+	if code != 0 {
+		t.Fatalf("team remove-member exit code %d, stderr: %s", code, errOut)
+	}
+	if strings.Contains(out, "Eve") {
+		t.Errorf("removed member is still listed:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "user", "archive", "u4")
+	if code != 0 {
+		t.Fatalf("user archive exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "ARCHIVED AT") {
+		t.Errorf("unexpected user archive output:\n%s", out)
+	}
+	code, out, errOut = runCtl(t, srv, "team", "get", "backend", "--include-archived")
+	if code != 0 {
+		t.Fatalf("team get --include-archived exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Dave") {
+		t.Errorf("archived member is not listed:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "team", "archive", "frontend", "-o", "json")
+	if code != 0 {
+		t.Fatalf("team archive exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"archived_users": []`) {
+		t.Errorf("unexpected team archive output:\n%s", out)
+	}
+
+	code, out, errOut = runCtl(t, srv, "stats", "-o", "json")
+	if code != 0 {
+		t.Fatalf("stats exit code %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"merged": 1`) {
+		t.Errorf("unexpected stats output:\n%s", out)
+	}
+}
+
+func TestReviewerctl_TeamSync(t *testing.T) {
+	srv := setupTestServer(t)
+
+	if code, _, errOut := runCtl(t, srv, "team", "create", "backend", "--member", "u1:Alice", "--member", "u2:Bob"); code != 0 {
+		t.Fatalf("team create exit code %d, stderr: %s", code, errOut)
+	}
+
+	manifest := filepath.Join(t.TempDir(), "teams.yaml")
+	err := os.WriteFile(manifest, []byte(`teams:
+  - team_name: backend
+    members:
+      - user_id: u1
+        username: Alice
+  - team_name: frontend
+    members:
+      - user_id: u3
+        username: Charlie
+        is_active: false
+`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	code, out, errOut := runCtl(t, srv, "team", "sync", "-f", manifest, "--dry-run")
+	if code != 0 {
+		t.Fatalf("team sync --dry-run exit code %d, stderr: %s", code, errOut)
+	}
+	for _, want := range []string{"CREATE_TEAM", "CREATE_USER", "DEACTIVATE_USER", "dry run: 3 changes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in dry run output:\n%s", want, out)
+		}
+	}
+	if code, _, _ := runCtl(t, srv, "team", "get", "frontend"); code != 1 {
+		t.Errorf("dry run must not create teams, exit code %d", code)
+	}
+
+	code, out, errOut = runCtl(t, srv, "team", "sync", "--file", manifest, "-o", "json")
+	if code != 0 {
+		t.Fatalf("team sync exit code %d, stderr: %s", code, errOut)
+	}
+	var result struct {
+		DryRun  bool `json:"dry_run"`
+		Changes []struct {
+			Action string `json:"action"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("team sync output is not JSON: %v\n%s", err, out)
+	}
+	if result.DryRun || len(result.Changes) != 3 {
+		t.Errorf("unexpected sync result: %+v", result)
+	}
+
+	code, out, _ = runCtl(t, srv, "team", "get", "frontend")
+	if code != 0 || !strings.Contains(out, "Charlie") || !strings.Contains(out, "false") {
+		t.Errorf("expected inactive Charlie in frontend, got exit code %d:\n%s", code, out)
+	}
+}
+
+func TestReviewerctl_Errors(t *testing.T) {
+	srv := setupTestServer(t)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expectedErr  string
+	}{
+		{name: "unknown command", args: []string{"team", "rename"}, expectedCode: 2, expectedErr: "unknown command"},
+		{name: "missing argument", args: []string{"team", "get"}, expectedCode: 2, expectedErr: "expected <team>"},
+		{name: "bad output format", args: []string{"stats", "-o", "xml"}, expectedCode: 2, expectedErr: "unknown output format"},
+		{name: "bad member spec", args: []string{"team", "create", "x", "--member", "u1"}, expectedCode: 2, expectedErr: "ID:USERNAME"},
+		{name: "api error", args: []string{"team", "get", "missing"}, expectedCode: 1, expectedErr: "NOT_FOUND"},
+		{name: "conflicting activity flags", args: []string{"team", "add-member", "t", "u1", "A", "--active", "--inactive"}, expectedCode: 2, expectedErr: "mutually exclusive"},
+		{name: "sync without manifest", args: []string{"team", "sync", "--dry-run"}, expectedCode: 2, expectedErr: "--file is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runCtl(t, srv, tt.args...)
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d", tt.expectedCode, code)
+			}
+			if !strings.Contains(errOut, tt.expectedErr) {
+				t.Errorf("expected stderr to contain %q, got %q", tt.expectedErr, errOut)
+			}
+		})
+	}
+}
@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
@@ -12,13 +10,11 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
 	"github.com/reviewer-service/internal/config"
-	"github.com/reviewer-service/internal/handlers"
-	"github.com/reviewer-service/internal/middleware"
 	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/server"
 	"github.com/reviewer-service/internal/service"
+	"github.com/reviewer-service/internal/storage"
 )
 
 func main() {
@@ -27,48 +23,41 @@ func main() {
 	logger := setupLogger(cfg.Logger.Level)
 	slog.SetDefault(logger)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:], logger))
+	}
+
 	logger.Info("starting PR reviewer assignment service")
 
-	db, err := connectDB(cfg.Database, logger)
+	st, err := storage.Open(cfg, logger)
+	if err != nil {
+		log.Fatalf("Storage initialization failed: %v", err)
+	}
+	defer st.Close()
+
+	if cfg.Migrations.OnStart {
+		if err := st.Migrate(context.Background(), logger); err != nil {
+			logger.Error("failed to apply migrations", "error", err)
+			st.Close()
+			os.Exit(1)
+		}
+	}
+
+	notifications, err := server.NewNotificationService(st, cfg, logger)
+	if err != nil {
+		logger.Error("failed to set up notifications", "error", err)
+		st.Close()
+		os.Exit(1)
+	}
+
+	events := service.NewEventStream(st.Events, cfg.Events.PollInterval, logger)
+	statsCache := service.NewStatisticsCache(cfg.Statistics.CacheTTL)
+	r, err := server.NewRouterWithDeps(st, cfg, server.Deps{Notifications: notifications, Events: events, StatsCache: statsCache}, logger)
 	if err != nil {
-		log.Fatalf("Database connection failed: %v", err)
+		logger.Error("failed to create router", "error", err)
+		st.Close()
+		os.Exit(1)
 	}
-	defer db.Close()
-
-	teamRepo := repository.NewTeamRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	prRepo := repository.NewPullRequestRepository(db)
-	statsRepo := repository.NewStatisticsRepository(db)
-
-	teamService := service.NewTeamService(teamRepo, userRepo, prRepo, db, logger)
-	userService := service.NewUserService(userRepo, prRepo, logger)
-	prService := service.NewPullRequestService(prRepo, userRepo, logger)
-	statsService := service.NewStatisticsService(statsRepo, logger)
-
-	teamHandler := handlers.NewTeamHandler(teamService, logger)
-	userHandler := handlers.NewUserHandler(userService, logger)
-	prHandler := handlers.NewPullRequestHandler(prService, logger)
-	statsHandler := handlers.NewStatisticsHandler(statsService, logger)
-
-	r := mux.NewRouter()
-	r.Use(middleware.LoggingMiddleware(logger))
-
-	// API endpoints
-	r.HandleFunc("/team/add", teamHandler.AddTeam).Methods("POST")
-	r.HandleFunc("/team/get", teamHandler.GetTeam).Methods("GET")
-	r.HandleFunc("/team/deactivateMembers", teamHandler.DeactivateTeamMembers).Methods("POST")
-	r.HandleFunc("/users/setIsActive", userHandler.SetUserActive).Methods("POST")
-	r.HandleFunc("/users/getReview", userHandler.GetUserReviews).Methods("GET")
-	r.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods("POST")
-	r.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods("POST")
-	r.HandleFunc("/pullRequest/reassign", prHandler.ReassignReviewer).Methods("POST")
-
-	// Statistics endpoint
-	r.HandleFunc("/statistics", statsHandler.GetStatistics).Methods("GET")
-
-	// Documentation endpoints
-	r.HandleFunc("/docs", handlers.ServeDocs).Methods("GET")
-	r.HandleFunc("/api/openapi.yaml", handlers.ServeOpenAPISpec).Methods("GET")
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -77,6 +66,20 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Потоки /events/stream не завершаются сами и задержали бы Shutdown
+	srv.RegisterOnShutdown(events.Close)
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go purgeExpiredIdempotencyKeys(bgCtx, st.Idempotency, time.Hour, logger)
+	go returnAwayUsers(bgCtx, st.Users, statsCache, time.Minute, logger)
+	if cfg.SLA.CheckInterval > 0 {
+		slaService := server.NewSLAService(st, cfg, notifications, logger)
+		go checkReviewSLAs(bgCtx, slaService, statsCache, cfg.SLA.CheckInterval, logger)
+	}
+	if notifications.Enabled() && cfg.Notifications.DigestCheckInterval > 0 {
+		go sendReviewDigests(bgCtx, notifications, cfg.Notifications.DigestCheckInterval, logger)
+	}
 
 	go func() {
 		logger.Info("server starting", "port", cfg.Server.Port)
@@ -87,6 +90,8 @@ func main() {
 	}()
 
 	gracefulShutdown(srv, cfg.Server.ShutdownTimeout, logger)
+	stopBackground()
+	notifications.Wait()
 }
 
 func setupLogger(level string) *slog.Logger {
@@ -109,23 +114,99 @@ func setupLogger(level string) *slog.Logger {
 	}))
 }
 
-func connectDB(cfg config.DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database)
+func purgeExpiredIdempotencyKeys(ctx context.Context, repo repository.IdempotencyRepository, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repo.DeleteExpired(time.Now().UTC())
+			if err != nil {
+				logger.Error("failed to purge expired idempotency keys", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				logger.Info("expired idempotency keys purged", "count", deleted)
+			}
+		}
+	}
+}
 
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		logger.Error("failed to connect to database", "error", err)
-		return nil, err
+// returnAwayUsers возвращает в ротацию ревьюверов, чьё отсутствие
+// (/review ooo) закончилось, и сбрасывает кэш статистики.
+func returnAwayUsers(ctx context.Context, repo repository.UserRepository, stats *service.StatisticsCache, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			returned, err := repo.ReturnFromAway(time.Now().UTC())
+			if err != nil {
+				logger.Error("failed to return away users", "error", err)
+				continue
+			}
+			if returned > 0 {
+				stats.Invalidate()
+				logger.Info("away users returned to rotation", "count", returned)
+			}
+		}
 	}
+}
 
-	if err := db.Ping(); err != nil {
-		logger.Error("failed to ping database", "error", err)
-		return nil, err
+// checkReviewSLAs периодически напоминает о просроченных ревью и заменяет
+// ревьюверов, у которых истёк срок автоматической замены.
+func checkReviewSLAs(ctx context.Context, slaService *service.SLAService, stats *service.StatisticsCache, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := slaService.CheckOverdue(ctx)
+			// Часть замен могла пройти и при ошибке
+			if result.Reassigned > 0 {
+				stats.Invalidate()
+			}
+			if err != nil {
+				logger.Error("failed to check review SLAs", "error", err)
+				continue
+			}
+			if result.Reminded > 0 || result.Reassigned > 0 {
+				logger.Info("review SLAs checked", "reminded", result.Reminded, "reassigned", result.Reassigned)
+			}
+		}
 	}
+}
 
-	logger.Info("database connection established")
-	return db, nil
+// sendReviewDigests периодически отправляет утренние сводки тем, у кого
+// по их часовому поясу наступил DIGEST_HOUR.
+func sendReviewDigests(ctx context.Context, notifications *service.NotificationService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := notifications.SendDigests(ctx)
+			if err != nil {
+				logger.Error("failed to send review digests", "error", err)
+				continue
+			}
+			if sent > 0 {
+				logger.Info("review digests sent", "count", sent)
+			}
+		}
+	}
 }
 
 func gracefulShutdown(srv *http.Server, timeout time.Duration, logger *slog.Logger) {
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/migrate"
+	"github.com/reviewer-service/internal/storage"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    revert the last N applied migrations (default 1)
+  status      list migrations and whether they are applied
+`
+
+// runMigrate обслуживает подкоманду "server migrate" и возвращает код выхода.
+func runMigrate(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+	case "down":
+		if len(args) > 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "invalid number of steps %q\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	st, err := storage.Open(cfg, logger)
+	if err != nil {
+		return 1
+	}
+	defer st.Close()
+
+	if st.DB == nil {
+		fmt.Fprintf(os.Stderr, "storage driver %q has no schema to migrate\n", st.Driver)
+		return 1
+	}
+
+	migrator, err := migrate.New(st.DB, st.Driver, logger)
+	if err != nil {
+		logger.Error("failed to load migrations", "error", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return 1
+		}
+		printMigrations(os.Stdout, "applied", applied)
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Error("migration rollback failed", "error", err)
+			return 1
+		}
+		printMigrations(os.Stdout, "reverted", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("failed to read migration status", "error", err)
+			return 1
+		}
+		printStatus(os.Stdout, statuses)
+	}
+	return 0
+}
+
+func printMigrations(w io.Writer, action string, list []migrate.Migration) {
+	if len(list) == 0 {
+		fmt.Fprintf(w, "nothing %s\n", action)
+		return
+	}
+	for _, m := range list {
+		fmt.Fprintf(w, "%s %03d_%s\n", action, m.Version, m.Name)
+	}
+}
+
+func printStatus(w io.Writer, statuses []migrate.Status) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", "-"
+		if s.Applied {
+			state = "applied"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+		}
+		fmt.Fprintf(tw, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	tw.Flush()
+}
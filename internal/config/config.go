@@ -2,13 +2,24 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
+	Server        ServerConfig
+	Storage       StorageConfig
+	Database      DatabaseConfig
+	Logger        LoggerConfig
+	Idempotency   IdempotencyConfig
+	Migrations    MigrationsConfig
+	SCIM          SCIMConfig
+	Statistics    StatisticsConfig
+	SLA           SLAConfig
+	Assignment    AssignmentConfig
+	Notifications NotificationsConfig
+	Slack         SlackConfig
+	Events        EventsConfig
 }
 
 type ServerConfig struct {
@@ -16,6 +27,12 @@ type ServerConfig struct {
 	ShutdownTimeout time.Duration
 }
 
+// StorageConfig выбирает бэкенд хранилища: "postgres" (по умолчанию), "sqlite" или "memory".
+type StorageConfig struct {
+	Driver     string
+	SQLitePath string
+}
+
 type DatabaseConfig struct {
 	Host     string
 	Port     string
@@ -28,12 +45,77 @@ type LoggerConfig struct {
 	Level string
 }
 
+type IdempotencyConfig struct {
+	TTL time.Duration
+}
+
+// MigrationsConfig.OnStart включает применение миграций при старте сервера.
+type MigrationsConfig struct {
+	OnStart bool
+}
+
+// SCIMConfig.Token — отдельный bearer-токен для /scim/v2; пустой отключает SCIM.
+type SCIMConfig struct {
+	Token string
+}
+
+// SlackConfig — интеграция с чатом в формате Slack. SigningSecret проверяет
+// подпись запросов, пустой отключает интеграцию; ответы на нажатия кнопок
+// отправляются только на response_url с префиксом ResponseURLPrefix.
+type SlackConfig struct {
+	SigningSecret     string
+	ResponseURLPrefix string
+}
+
+// EventsConfig.PollInterval — период чтения журнала событий для
+// /events/stream, то есть задержка доставки события подписчикам.
+type EventsConfig struct {
+	PollInterval time.Duration
+}
+
+// StatisticsConfig.CacheTTL — время жизни снимка /statistics; 0 выключает кэш.
+type StatisticsConfig struct {
+	CacheTTL time.Duration
+}
+
+// SLAConfig.CheckInterval — период проверки сроков ревью; 0 выключает
+// напоминания и автоматическую замену ревьюверов.
+type SLAConfig struct {
+	CheckInterval time.Duration
+}
+
+// AssignmentConfig.PreferWorking — при выборе ревьюверов сначала брать тех,
+// у кого по расписанию сейчас рабочее время.
+type AssignmentConfig struct {
+	PreferWorking bool
+}
+
+// NotificationsConfig — SMTP-сервер для писем ревьюверам; пустой SMTPHost
+// выключает отправку. SMTPTLS: starttls, tls или none. Утренняя сводка
+// уходит после DigestHour по часовому поясу пользователя; DigestCheckInterval 0
+// выключает сводки. TemplatesDir — каталог с заменами встроенных шаблонов.
+type NotificationsConfig struct {
+	SMTPHost            string
+	SMTPPort            string
+	SMTPUsername        string
+	SMTPPassword        string
+	SMTPFrom            string
+	SMTPTLS             string
+	TemplatesDir        string
+	DigestHour          int
+	DigestCheckInterval time.Duration
+}
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
 			Port:            getEnv("PORT", "8080"),
 			ShutdownTimeout: 10 * time.Second,
 		},
+		Storage: StorageConfig{
+			Driver:     getEnv("STORAGE", "postgres"),
+			SQLitePath: getEnv("SQLITE_PATH", "reviewers.db"),
+		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
@@ -44,6 +126,42 @@ func Load() *Config {
 		Logger: LoggerConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
+		Idempotency: IdempotencyConfig{
+			TTL: getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		},
+		Migrations: MigrationsConfig{
+			OnStart: getEnvBool("MIGRATE_ON_START", true),
+		},
+		SCIM: SCIMConfig{
+			Token: os.Getenv("SCIM_TOKEN"),
+		},
+		Statistics: StatisticsConfig{
+			CacheTTL: getEnvDuration("STATS_CACHE_TTL", 0),
+		},
+		SLA: SLAConfig{
+			CheckInterval: getEnvDuration("SLA_CHECK_INTERVAL", 5*time.Minute),
+		},
+		Assignment: AssignmentConfig{
+			PreferWorking: getEnvBool("PREFER_WORKING_REVIEWERS", false),
+		},
+		Notifications: NotificationsConfig{
+			SMTPHost:            os.Getenv("SMTP_HOST"),
+			SMTPPort:            getEnv("SMTP_PORT", "587"),
+			SMTPUsername:        os.Getenv("SMTP_USERNAME"),
+			SMTPPassword:        os.Getenv("SMTP_PASSWORD"),
+			SMTPFrom:            getEnv("SMTP_FROM", "reviewer-service@localhost"),
+			SMTPTLS:             getEnv("SMTP_TLS", "starttls"),
+			TemplatesDir:        os.Getenv("NOTIFICATION_TEMPLATES_DIR"),
+			DigestHour:          getEnvInt("DIGEST_HOUR", 9),
+			DigestCheckInterval: getEnvDuration("DIGEST_CHECK_INTERVAL", 5*time.Minute),
+		},
+		Slack: SlackConfig{
+			SigningSecret:     os.Getenv("SLACK_SIGNING_SECRET"),
+			ResponseURLPrefix: getEnv("SLACK_RESPONSE_URL_PREFIX", "https://hooks.slack.com/"),
+		},
+		Events: EventsConfig{
+			PollInterval: getEnvDuration("EVENTS_POLL_INTERVAL", time.Second),
+		},
 	}
 }
 
@@ -53,3 +171,30 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
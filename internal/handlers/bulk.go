@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/service"
+)
+
+// Форматы массового импорта и экспорта
+const (
+	ContentTypeCSV    = "text/csv"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+const (
+	// maxImportBytes ограничивает тело импорта
+	maxImportBytes = 64 << 20
+	// maxNDJSONLine — максимальная длина одной строки NDJSON
+	maxNDJSONLine = 1 << 20
+	// exportFlushRows — через сколько строк выгрузка сбрасывается клиенту
+	exportFlushRows = 500
+	// reviewerSeparator разделяет ревьюверов в колонке assigned_reviewers CSV
+	reviewerSeparator = ";"
+)
+
+// Колонки CSV. Имена совпадают с полями JSON, порядок — порядок выгрузки.
+var (
+	teamColumns = []string{"team_name"}
+	userColumns = []string{"user_id", "username", "team_name", "is_active"}
+	prColumns   = []string{"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers", "createdAt", "mergedAt"}
+)
+
+// teamRecord — строка выгрузки команд: только имя, участники выгружаются
+// вместе с пользователями
+type teamRecord struct {
+	TeamName string `json:"team_name"`
+}
+
+type userRecord struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive *bool  `json:"is_active,omitempty"`
+}
+
+type BulkHandler struct {
+	service *service.BulkService
+	logger  *slog.Logger
+}
+
+func NewBulkHandler(service *service.BulkService, logger *slog.Logger) *BulkHandler {
+	return &BulkHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *BulkHandler) ExportTeams(w http.ResponseWriter, r *http.Request) {
+	out := h.newExportWriter(w, r, teamColumns)
+	err := h.service.ExportTeams(r.Context(), func(team *models.Team) error {
+		return out.write(teamRecord{TeamName: team.TeamName}, []string{team.TeamName})
+	})
+	out.finish(err)
+}
+
+func (h *BulkHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	out := h.newExportWriter(w, r, userColumns)
+	err := h.service.ExportUsers(r.Context(), func(user *models.User) error {
+		isActive := user.IsActive
+		record := userRecord{UserID: user.UserID, Username: user.Username, TeamName: user.TeamName, IsActive: &isActive}
+		return out.write(record, []string{user.UserID, user.Username, user.TeamName, strconv.FormatBool(user.IsActive)})
+	})
+	out.finish(err)
+}
+
+func (h *BulkHandler) ExportPullRequests(w http.ResponseWriter, r *http.Request) {
+	out := h.newExportWriter(w, r, prColumns)
+	err := h.service.ExportPullRequests(r.Context(), func(pr *models.PullRequest) error {
+		row := []string{
+			pr.PullRequestID,
+			pr.PullRequestName,
+			pr.AuthorID,
+			pr.Status,
+			strings.Join(pr.AssignedReviewers, reviewerSeparator),
+			formatTime(pr.CreatedAt),
+			formatTime(pr.MergedAt),
+		}
+		return out.write(pr, row)
+	})
+	out.finish(err)
+}
+
+func (h *BulkHandler) ImportTeams(w http.ResponseWriter, r *http.Request) {
+	var rows []service.TeamRow
+	ok := h.readImport(w, r, teamColumns, func(line int, fields map[string]string, rejected *service.ImportError) {
+		rows = append(rows, service.TeamRow{Line: line, TeamName: fields["team_name"]})
+	}, func(line int, data []byte, rejected *service.ImportError) {
+		var record teamRecord
+		if decodeRecord(line, data, &record, rejected) {
+			rows = append(rows, service.TeamRow{Line: line, TeamName: record.TeamName})
+		}
+	})
+	if !ok {
+		return
+	}
+
+	imported, err := h.service.ImportTeams(r.Context(), rows)
+	h.respondImport(w, r, "teams", imported, err)
+}
+
+func (h *BulkHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	var rows []service.UserRow
+	ok := h.readImport(w, r, userColumns, func(line int, fields map[string]string, rejected *service.ImportError) {
+		isActive := true
+		if v := fields["is_active"]; v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				rejected.Add(line, "is_active", "must be a boolean")
+				return
+			}
+			isActive = parsed
+		}
+		rows = append(rows, service.UserRow{Line: line, User: models.User{
+			UserID:   fields["user_id"],
+			Username: fields["username"],
+			TeamName: fields["team_name"],
+			IsActive: isActive,
+		}})
+	}, func(line int, data []byte, rejected *service.ImportError) {
+		var record userRecord
+		if !decodeRecord(line, data, &record, rejected) {
+			return
+		}
+		user := models.User{UserID: record.UserID, Username: record.Username, TeamName: record.TeamName, IsActive: true}
+		if record.IsActive != nil {
+			user.IsActive = *record.IsActive
+		}
+		rows = append(rows, service.UserRow{Line: line, User: user})
+	})
+	if !ok {
+		return
+	}
+
+	imported, err := h.service.ImportUsers(r.Context(), rows)
+	h.respondImport(w, r, "users", imported, err)
+}
+
+func (h *BulkHandler) ImportPullRequests(w http.ResponseWriter, r *http.Request) {
+	var rows []service.PullRequestRow
+	ok := h.readImport(w, r, prColumns, func(line int, fields map[string]string, rejected *service.ImportError) {
+		pr := models.PullRequest{
+			PullRequestID:     fields["pull_request_id"],
+			PullRequestName:   fields["pull_request_name"],
+			AuthorID:          fields["author_id"],
+			Status:            fields["status"],
+			AssignedReviewers: splitReviewers(fields["assigned_reviewers"]),
+		}
+		var valid bool
+		if pr.CreatedAt, valid = parseTime(line, "createdAt", fields["createdAt"], rejected); !valid {
+			return
+		}
+		if pr.MergedAt, valid = parseTime(line, "mergedAt", fields["mergedAt"], rejected); !valid {
+			return
+		}
+		rows = append(rows, service.PullRequestRow{Line: line, PR: pr})
+	}, func(line int, data []byte, rejected *service.ImportError) {
+		var pr models.PullRequest
+		if decodeRecord(line, data, &pr, rejected) {
+			rows = append(rows, service.PullRequestRow{Line: line, PR: pr})
+		}
+	})
+	if !ok {
+		return
+	}
+
+	imported, err := h.service.ImportPullRequests(r.Context(), rows)
+	h.respondImport(w, r, "pull_requests", imported, err)
+}
+
+func (h *BulkHandler) respondImport(w http.ResponseWriter, r *http.Request, entity string, imported int, err error) {
+	var rejected *service.ImportError
+	switch {
+	case errors.As(err, &rejected):
+		respondImportRejected(w, rejected)
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to import", "error", err, "entity", entity)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	default:
+		// OpenAPI: 200 OK с { "entity": "...", "imported": N }
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"entity":   entity,
+			"imported": imported,
+		})
+	}
+}
+
+func respondImportRejected(w http.ResponseWriter, rejected *service.ImportError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: models.ErrorDetail{
+			Code:    "INVALID_REQUEST",
+			Message: fmt.Sprintf("Import rejected: %d errors, nothing was imported", rejected.Total),
+			Details: rejected.Violations,
+		},
+	}); err != nil {
+		slog.Error("failed to encode error response", "error", err)
+	}
+}
+
+// readImport читает тело построчно в формате из Content-Type и передаёт
+// строки в csvRow или jsonRow. Ошибки разбора копятся по всем строкам; если
+// они есть, ответ отправляется здесь и возвращается false.
+func (h *BulkHandler) readImport(w http.ResponseWriter, r *http.Request, columns []string,
+	csvRow func(line int, fields map[string]string, rejected *service.ImportError),
+	jsonRow func(line int, data []byte, rejected *service.ImportError)) bool {
+	ctx := r.Context()
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rejected := &service.ImportError{}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var err error
+	if mediaType == ContentTypeCSV {
+		err = readCSV(body, columns, csvRow, rejected)
+	} else {
+		err = readNDJSON(body, jsonRow, rejected)
+	}
+
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", fmt.Sprintf("Import body exceeds %d bytes", maxImportBytes))
+		return false
+	case err != nil:
+		h.logger.WarnContext(ctx, "failed to read import body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return false
+	case rejected.Total > 0:
+		h.logger.WarnContext(ctx, "import body has malformed rows", "errors", rejected.Total)
+		respondImportRejected(w, rejected)
+		return false
+	}
+	return true
+}
+
+// readCSV читает CSV с заголовком. Колонки из columns можно опускать и
+// переставлять, неизвестные запрещены. Нумерация строк учитывает заголовок.
+func readCSV(body io.Reader, columns []string, row func(int, map[string]string, *service.ImportError), rejected *service.ImportError) error {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	allowed := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		allowed[c] = true
+	}
+	seen := make(map[string]bool, len(header))
+	for _, c := range header {
+		switch {
+		case !allowed[c]:
+			rejected.Add(1, c, "unknown column")
+		case seen[c]:
+			rejected.Add(1, c, "duplicate column")
+		}
+		seen[c] = true
+	}
+	if rejected.Total > 0 {
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rejected.Add(line, "", fmt.Sprintf("expected %d columns, got %d", len(header), len(record)))
+			continue
+		}
+		fields := make(map[string]string, len(header))
+		for i, c := range header {
+			fields[c] = strings.TrimSpace(record[i])
+		}
+		row(line, fields, rejected)
+	}
+}
+
+// readNDJSON читает по объекту JSON на строку, пустые строки пропускаются.
+func readNDJSON(body io.Reader, row func(int, []byte, *service.ImportError), rejected *service.ImportError) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		row(line, data, rejected)
+	}
+	return scanner.Err()
+}
+
+// decodeRecord разбирает строку NDJSON строго: неизвестные поля — ошибка,
+// как и в остальном API.
+func decodeRecord(line int, data []byte, v interface{}, rejected *service.ImportError) bool {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		rejected.Add(line, "", "invalid JSON: "+err.Error())
+		return false
+	}
+	if decoder.More() {
+		rejected.Add(line, "", "expected one JSON object per line")
+		return false
+	}
+	return true
+}
+
+func splitReviewers(value string) []string {
+	reviewers := make([]string, 0)
+	for _, id := range strings.Split(value, reviewerSeparator) {
+		if id = strings.TrimSpace(id); id != "" {
+			reviewers = append(reviewers, id)
+		}
+	}
+	return reviewers
+}
+
+func parseTime(line int, field, value string, rejected *service.ImportError) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		rejected.Add(line, field, "must be an RFC 3339 date-time")
+		return nil, false
+	}
+	return &t, true
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// exportWriter пишет выгрузку построчно и периодически сбрасывает её
+// клиенту. Заголовки отправляются с первой строкой, поэтому ошибка до неё
+// ещё превращается в обычный ответ 500.
+type exportWriter struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	logger  *slog.Logger
+	columns []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	rows    int
+	started bool
+}
+
+func (h *BulkHandler) newExportWriter(w http.ResponseWriter, r *http.Request, columns []string) *exportWriter {
+	out := &exportWriter{w: w, r: r, logger: h.logger, columns: columns}
+	if r.URL.Query().Get("format") == "csv" {
+		out.csv = csv.NewWriter(w)
+	} else {
+		out.json = json.NewEncoder(w)
+	}
+	return out
+}
+
+func (e *exportWriter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	if e.csv != nil {
+		e.w.Header().Set("Content-Type", ContentTypeCSV+"; charset=utf-8")
+		e.w.WriteHeader(http.StatusOK)
+		return e.csv.Write(e.columns)
+	}
+	e.w.Header().Set("Content-Type", ContentTypeNDJSON)
+	e.w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (e *exportWriter) write(record interface{}, row []string) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	var err error
+	if e.csv != nil {
+		err = e.csv.Write(row)
+	} else {
+		err = e.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := http.NewResponseController(e.w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (e *exportWriter) finish(err error) {
+	ctx := e.r.Context()
+	if err == nil {
+		err = e.start()
+	}
+	if err == nil {
+		err = e.flush()
+	}
+	if err == nil {
+		e.logger.InfoContext(ctx, "export completed", "path", e.r.URL.Path, "rows", e.rows)
+		return
+	}
+
+	e.logger.ErrorContext(ctx, "failed to export", "error", err, "path", e.r.URL.Path, "rows", e.rows)
+	if !e.started {
+		respondError(e.w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		return
+	}
+	// Статус уже отправлен: обрываем соединение, чтобы клиент не принял
+	// неполную выгрузку за полную
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/service"
+)
+
+const (
+	slackSignatureHeader = "X-Slack-Signature"
+	slackTimestampHeader = "X-Slack-Request-Timestamp"
+	// slackMaxSkew — насколько время подписи может расходиться с часами
+	// сервера; старые запросы отклоняются, чтобы их нельзя было повторить
+	slackMaxSkew     = 5 * time.Minute
+	slackMaxBodySize = 64 << 10
+
+	// slackMaxReviews ограничивает ответ /review mine: в сообщении Slack не больше 50 блоков
+	slackMaxReviews = 20
+	slackMaxAway    = 90 * 24 * time.Hour
+
+	slackActionReassign = "reassign"
+)
+
+// ChatLinks — привязка пользователей чата к пользователям сервиса
+type ChatLinks interface {
+	LinkUser(ctx context.Context, chatUserID, userID string) error
+	ResolveUser(ctx context.Context, chatUserID string) (string, error)
+}
+
+// ChatUserService — операции над пользователями, доступные из чата
+type ChatUserService interface {
+	GetUserReviews(ctx context.Context, userID string, q service.UserReviewsQuery) ([]*models.PullRequestShort, string, error)
+	SetAway(ctx context.Context, userID string, until time.Time) error
+}
+
+// ChatHandler принимает slash-команды и нажатия кнопок в формате Slack и
+// выполняет их от имени привязанного пользователя.
+type ChatHandler struct {
+	links             ChatLinks
+	users             ChatUserService
+	prs               PRService
+	signingSecret     string
+	responseURLPrefix string
+	client            *http.Client
+	logger            *slog.Logger
+	now               func() time.Time
+}
+
+// NewChatHandler создаёт обработчик чата. Пустой signingSecret отключает
+// команды Slack; привязка пользователей при этом работает.
+func NewChatHandler(chat *service.ChatService, users *service.UserService, prs *service.PullRequestService,
+	signingSecret, responseURLPrefix string, logger *slog.Logger) *ChatHandler {
+	return &ChatHandler{
+		links:             chat,
+		users:             users,
+		prs:               prs,
+		signingSecret:     signingSecret,
+		responseURLPrefix: responseURLPrefix,
+		client:            &http.Client{Timeout: 2 * time.Second},
+		logger:            logger,
+		now:               time.Now,
+	}
+}
+
+func (h *ChatHandler) SetChatUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserID     string `json:"user_id"`
+		ChatUserID string `json:"chat_user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := h.links.LinkUser(ctx, req.ChatUserID, req.UserID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to link chat user", "error", err, "user_id", req.UserID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"user_id": req.UserID, "chat_user_id": req.ChatUserID})
+}
+
+// SlackCommand обрабатывает slash-команду /review. Ошибки команды
+// возвращаются сообщением со статусом 200, иначе Slack покажет общую ошибку.
+func (h *ChatHandler) SlackCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, ok := h.verifySlackRequest(w, r)
+	if !ok {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	userID, err := h.links.ResolveUser(ctx, form.Get("user_id"))
+	if err != nil {
+		respondJSON(w, http.StatusOK, h.resolveErrorMessage(ctx, err, form.Get("user_id")))
+		return
+	}
+
+	args := strings.Fields(form.Get("text"))
+	var msg slackMessage
+	switch {
+	case len(args) == 0 || args[0] == "mine" && len(args) == 1:
+		msg = h.myReviews(ctx, userID, "")
+	case args[0] == "reassign" && len(args) == 2:
+		msg = h.reassign(ctx, userID, args[1])
+	case args[0] == "ooo" && len(args) == 2:
+		msg = h.setAway(ctx, userID, args[1])
+	default:
+		msg = slackText(slackUsage)
+	}
+	msg.ResponseType = "ephemeral"
+	respondJSON(w, http.StatusOK, msg)
+}
+
+// SlackInteraction обрабатывает нажатия кнопок в сообщениях /review mine.
+// Slack не показывает тело ответа на block_actions, поэтому результат
+// отправляется на response_url, а сам запрос только подтверждается.
+func (h *ChatHandler) SlackInteraction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, ok := h.verifySlackRequest(w, r)
+	if !ok {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+	var payload slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid slack interaction payload", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid interaction payload")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if payload.Type != "block_actions" {
+		return
+	}
+
+	var msg slackMessage
+	userID, err := h.links.ResolveUser(ctx, payload.User.ID)
+	if err != nil {
+		msg = h.resolveErrorMessage(ctx, err, payload.User.ID)
+	} else {
+		for _, action := range payload.Actions {
+			if action.ActionID != slackActionReassign {
+				continue
+			}
+			// Результат показываем над обновлённым списком ревью
+			result := h.reassign(ctx, userID, action.Value)
+			msg = h.myReviews(ctx, userID, result.Text)
+			msg.ReplaceOriginal = true
+		}
+	}
+	if msg.Text != "" {
+		h.respondToURL(ctx, payload.ResponseURL, msg)
+	}
+}
+
+// verifySlackRequest проверяет подпись v0 из заголовков Slack и возвращает
+// тело запроса; при ошибке ответ уже записан.
+func (h *ChatHandler) verifySlackRequest(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	ctx := r.Context()
+	if h.signingSecret == "" {
+		respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Slack integration is disabled")
+		return nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxBodySize))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return nil, false
+	}
+
+	timestamp := r.Header.Get(slackTimestampHeader)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid Slack request timestamp")
+		return nil, false
+	}
+	if skew := h.now().Sub(time.Unix(ts, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		h.logger.WarnContext(ctx, "stale slack request", "timestamp", ts)
+		respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Slack request is too old")
+		return nil, false
+	}
+
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(slackSignatureHeader))) {
+		h.logger.WarnContext(ctx, "invalid slack request signature")
+		respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid Slack request signature")
+		return nil, false
+	}
+	return body, true
+}
+
+func (h *ChatHandler) resolveErrorMessage(ctx context.Context, err error, chatUserID string) slackMessage {
+	if errors.Is(err, service.ErrChatUserNotLinked) {
+		return slackText(fmt.Sprintf("Ваш аккаунт `%s` не привязан к пользователю сервиса ревью. Попросите администратора вызвать /users/setChatUser.", slackEscape(chatUserID)))
+	}
+	h.logger.ErrorContext(ctx, "failed to resolve chat user", "error", err, "chat_user_id", chatUserID)
+	return slackText(slackInternalError)
+}
+
+// myReviews показывает открытые ревью, дольше ждущие первыми, с кнопкой
+// передачи каждого; notice выводится над списком.
+func (h *ChatHandler) myReviews(ctx context.Context, userID, notice string) slackMessage {
+	reviews, next, err := h.users.GetUserReviews(ctx, userID, service.UserReviewsQuery{
+		SortBy: repository.ReviewSortAssignedAt,
+		Limit:  slackMaxReviews,
+	})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get reviews for chat", "error", err, "user_id", userID)
+		return slackText(slackInternalError)
+	}
+
+	var msg slackMessage
+	if notice != "" {
+		msg.Blocks = append(msg.Blocks, slackSection(notice))
+	}
+	if len(reviews) == 0 {
+		msg.Text = "У вас нет открытых ревью."
+		msg.Blocks = append(msg.Blocks, slackSection(msg.Text))
+		return msg
+	}
+
+	msg.Text = fmt.Sprintf("Открытые ревью: %d", len(reviews))
+	if next != "" {
+		msg.Text = fmt.Sprintf("Открытые ревью: больше %d", len(reviews))
+	}
+	msg.Blocks = append(msg.Blocks, slackBlock{Type: "header", Text: &slackTextObject{Type: "plain_text", Text: msg.Text}})
+	for _, pr := range reviews {
+		block := slackSection(fmt.Sprintf("*%s* `%s`\nАвтор %s, ждёт %s",
+			slackEscape(pr.PullRequestName), slackEscape(pr.PullRequestID), slackEscape(pr.AuthorID), formatAge(pr.ReviewAgeSeconds)))
+		block.Accessory = &slackButton{
+			Type:     "button",
+			Text:     slackTextObject{Type: "plain_text", Text: "Передать"},
+			ActionID: slackActionReassign,
+			Value:    pr.PullRequestID,
+		}
+		msg.Blocks = append(msg.Blocks, block)
+	}
+	if next != "" {
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "context", Elements: []slackTextObject{
+			{Type: "mrkdwn", Text: fmt.Sprintf("Показаны %d дольше всех ждущих, остальные — в /users/getReview", len(reviews))},
+		}})
+	}
+	return msg
+}
+
+func (h *ChatHandler) reassign(ctx context.Context, userID, prID string) slackMessage {
+	_, replacedBy, err := h.prs.ReassignReviewer(ctx, prID, userID)
+	if err != nil {
+		var text string
+		switch {
+		case errors.Is(err, service.ErrPRNotFound):
+			text = fmt.Sprintf("PR `%s` не найден.", slackEscape(prID))
+		case errors.Is(err, service.ErrPRMerged):
+			text = fmt.Sprintf("PR `%s` уже смержен.", slackEscape(prID))
+		case errors.Is(err, service.ErrNotAssigned):
+			text = fmt.Sprintf("Вы не ревьювер PR `%s`.", slackEscape(prID))
+		case errors.Is(err, service.ErrNoCandidate):
+			text = fmt.Sprintf("В команде нет свободного ревьювера для PR `%s`.", slackEscape(prID))
+		default:
+			h.logger.ErrorContext(ctx, "failed to reassign from chat", "error", err, "pr_id", prID, "user_id", userID)
+			text = slackInternalError
+		}
+		return slackText(text)
+	}
+	return slackText(fmt.Sprintf("PR `%s` передан ревьюверу `%s`.", slackEscape(prID), slackEscape(replacedBy)))
+}
+
+func (h *ChatHandler) setAway(ctx context.Context, userID, period string) slackMessage {
+	d, ok := parseAwayPeriod(period)
+	if !ok {
+		return slackText(fmt.Sprintf("Не понял срок `%s`: укажите число с h, d или w, например 3d.\n\n%s", slackEscape(period), slackUsage))
+	}
+	if d > slackMaxAway {
+		return slackText(fmt.Sprintf("Отсутствие не может быть дольше %d дней.", slackMaxAway/(24*time.Hour)))
+	}
+	until := h.now().UTC().Add(d).Truncate(time.Minute)
+	if err := h.users.SetAway(ctx, userID, until); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return slackText("Пользователь сервиса ревью не найден или архивирован.")
+		}
+		h.logger.ErrorContext(ctx, "failed to set away from chat", "error", err, "user_id", userID)
+		return slackText(slackInternalError)
+	}
+	// Slack покажет дату в часовом поясе читателя, текст после | — запасной
+	return slackText(fmt.Sprintf("Новые ревью не назначаются вам до <!date^%d^{date_short_pretty} {time}|%s>. Уже назначенные остаются за вами.",
+		until.Unix(), until.Format("2006-01-02 15:04 UTC")))
+}
+
+func (h *ChatHandler) respondToURL(ctx context.Context, responseURL string, msg slackMessage) {
+	if h.responseURLPrefix == "" || !strings.HasPrefix(responseURL, h.responseURLPrefix) {
+		h.logger.WarnContext(ctx, "slack response_url is not allowed", "response_url", responseURL)
+		return
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode slack response", "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		h.logger.WarnContext(ctx, "invalid slack response_url", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to send slack response", "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		h.logger.ErrorContext(ctx, "slack rejected response", "status", resp.StatusCode)
+	}
+}
+
+// parseAwayPeriod разбирает срок отсутствия вида 4h, 3d или 2w
+func parseAwayPeriod(s string) (time.Duration, bool) {
+	if len(s) < 2 {
+		return 0, false
+	}
+	// Не больше четырёх цифр, чтобы длительность не переполнилась
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 || n > 9999 {
+		return 0, false
+	}
+	switch s[len(s)-1] {
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// formatAge выводит длительность в самых крупных двух единицах
+func formatAge(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%d д %d ч", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%d ч %d мин", d/time.Hour, d%time.Hour/time.Minute)
+	default:
+		return fmt.Sprintf("%d мин", d/time.Minute)
+	}
+}
+
+const (
+	slackUsage = "Команды:\n" +
+		"• `/review mine` — ваши открытые ревью\n" +
+		"• `/review reassign PR-123` — передать ревью другому участнику команды\n" +
+		"• `/review ooo 3d` — не назначать вам новые ревью 3 дня (h — часы, d — дни, w — недели), уже назначенные остаются за вами"
+	slackInternalError = "Не удалось выполнить команду, попробуйте позже."
+)
+
+// slackMessage — ответ в формате Block Kit; Text показывается в уведомлениях
+// и клиентах без поддержки блоков.
+type slackMessage struct {
+	ResponseType    string       `json:"response_type,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original,omitempty"`
+	Text            string       `json:"text"`
+	Blocks          []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlock struct {
+	Type      string            `json:"type"`
+	Text      *slackTextObject  `json:"text,omitempty"`
+	Elements  []slackTextObject `json:"elements,omitempty"`
+	Accessory *slackButton      `json:"accessory,omitempty"`
+}
+
+type slackTextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackButton struct {
+	Type     string          `json:"type"`
+	Text     slackTextObject `json:"text"`
+	ActionID string          `json:"action_id"`
+	Value    string          `json:"value"`
+}
+
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+func slackText(text string) slackMessage {
+	return slackMessage{Text: text, Blocks: []slackBlock{slackSection(text)}}
+}
+
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackTextObject{Type: "mrkdwn", Text: text}}
+}
+
+// slackEscape экранирует управляющие символы разметки Slack
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/service"
+)
+
+const testSlackSecret = "test-signing-secret"
+
+type mockChatLinks struct {
+	users map[string]string
+}
+
+func (m *mockChatLinks) LinkUser(ctx context.Context, chatUserID, userID string) error {
+	m.users[chatUserID] = userID
+	return nil
+}
+
+func (m *mockChatLinks) ResolveUser(ctx context.Context, chatUserID string) (string, error) {
+	userID, ok := m.users[chatUserID]
+	if !ok {
+		return "", service.ErrChatUserNotLinked
+	}
+	return userID, nil
+}
+
+type mockChatUsers struct {
+	reviews   []*models.PullRequestShort
+	lastQuery service.UserReviewsQuery
+	awayUntil map[string]time.Time
+}
+
+func (m *mockChatUsers) GetUserReviews(ctx context.Context, userID string, q service.UserReviewsQuery) ([]*models.PullRequestShort, string, error) {
+	m.lastQuery = q
+	return m.reviews, "", nil
+}
+
+func (m *mockChatUsers) SetAway(ctx context.Context, userID string, until time.Time) error {
+	m.awayUntil[userID] = until
+	return nil
+}
+
+func loadTestdata(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func signedSlackRequest(t *testing.T, target, body string, at time.Time) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSlackSecret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(slackTimestampHeader, timestamp)
+	req.Header.Set(slackSignatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func newTestChatHandler(now time.Time, prs PRService, users *mockChatUsers) *ChatHandler {
+	return &ChatHandler{
+		links:         &mockChatLinks{users: map[string]string{"U2147483697": "u2"}},
+		users:         users,
+		prs:           prs,
+		signingSecret: testSlackSecret,
+		client:        http.DefaultClient,
+		logger:        setupTestLogger(),
+		now:           func() time.Time { return now },
+	}
+}
+
+func decodeSlackMessage(t *testing.T, rec *httptest.ResponseRecorder) slackMessage {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var msg slackMessage
+	if err := json.NewDecoder(rec.Body).Decode(&msg); err != nil {
+		t.Fatalf("failed to decode slack message: %v", err)
+	}
+	return msg
+}
+
+func TestChatHandler_VerifySlackSignature(t *testing.T) {
+	// Пример подписи из документации Slack
+	body := loadTestdata(t, "slack_signed_command.txt")
+	signedAt := time.Unix(1531420618, 0)
+	request := func(signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+		req.Header.Set(slackTimestampHeader, "1531420618")
+		req.Header.Set(slackSignatureHeader, signature)
+		return req
+	}
+	const valid = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+
+	tests := []struct {
+		name   string
+		secret string
+		now    time.Time
+		req    *http.Request
+		want   bool
+	}{
+		{"valid", "8f742231b10e8888abcd99yyyzzz85a5", signedAt.Add(time.Minute), request(valid), true},
+		{"wrong signature", "8f742231b10e8888abcd99yyyzzz85a5", signedAt, request("v0=" + strings.Repeat("0", 64)), false},
+		{"wrong secret", "other-secret", signedAt, request(valid), false},
+		{"replayed later", "8f742231b10e8888abcd99yyyzzz85a5", signedAt.Add(10 * time.Minute), request(valid), false},
+		{"disabled", "", signedAt, request(valid), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &ChatHandler{signingSecret: tt.secret, logger: setupTestLogger(), now: func() time.Time { return tt.now }}
+			rec := httptest.NewRecorder()
+			got, ok := h.verifySlackRequest(rec, tt.req)
+			if ok != tt.want {
+				t.Fatalf("verifySlackRequest() = %v, want %v (status %d)", ok, tt.want, rec.Code)
+			}
+			if ok && string(got) != body {
+				t.Error("expected the original body to be returned")
+			}
+			if !ok && rec.Code != http.StatusUnauthorized {
+				t.Errorf("expected status 401, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestChatHandler_SlackCommand(t *testing.T) {
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	assignedAt := now.Add(-26 * time.Hour)
+
+	t.Run("mine", func(t *testing.T) {
+		users := &mockChatUsers{reviews: []*models.PullRequestShort{
+			{PullRequestID: "PR-123", PullRequestName: "Fix <login>", AuthorID: "u1", Status: "OPEN", AssignedAt: &assignedAt, ReviewAgeSeconds: 26 * 3600},
+		}}
+		h := newTestChatHandler(now, &mockPRService{}, users)
+		rec := httptest.NewRecorder()
+		h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", loadTestdata(t, "slack_command_mine.txt"), now))
+
+		msg := decodeSlackMessage(t, rec)
+		if msg.ResponseType != "ephemeral" || len(msg.Blocks) != 2 {
+			t.Fatalf("expected ephemeral header and one review, got %+v", msg)
+		}
+		if users.lastQuery.SortBy != repository.ReviewSortAssignedAt {
+			t.Errorf("expected longest-waiting reviews first, got %+v", users.lastQuery)
+		}
+		review := msg.Blocks[1]
+		if review.Accessory == nil || review.Accessory.ActionID != slackActionReassign || review.Accessory.Value != "PR-123" {
+			t.Errorf("expected reassign button for PR-123, got %+v", review.Accessory)
+		}
+		if !strings.Contains(review.Text.Text, "Fix &lt;login&gt;") || !strings.Contains(review.Text.Text, "1 д 2 ч") {
+			t.Errorf("unexpected review text %q", review.Text.Text)
+		}
+	})
+
+	t.Run("reassign", func(t *testing.T) {
+		var gotPR, gotUser string
+		prs := &mockPRService{reassignReviewerFunc: func(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+			gotPR, gotUser = prID, oldUserID
+			return &models.PullRequest{PullRequestID: prID}, "u3", nil
+		}}
+		h := newTestChatHandler(now, prs, &mockChatUsers{})
+		rec := httptest.NewRecorder()
+		h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", loadTestdata(t, "slack_command_reassign.txt"), now))
+
+		msg := decodeSlackMessage(t, rec)
+		if gotPR != "PR-123" || gotUser != "u2" {
+			t.Errorf("expected u2 to be replaced on PR-123, got %s on %s", gotUser, gotPR)
+		}
+		if !strings.Contains(msg.Text, "`u3`") {
+			t.Errorf("expected new reviewer in reply, got %q", msg.Text)
+		}
+	})
+
+	t.Run("reassign without assignment", func(t *testing.T) {
+		prs := &mockPRService{reassignReviewerFunc: func(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+			return nil, "", service.ErrNotAssigned
+		}}
+		h := newTestChatHandler(now, prs, &mockChatUsers{})
+		rec := httptest.NewRecorder()
+		h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", loadTestdata(t, "slack_command_reassign.txt"), now))
+
+		if msg := decodeSlackMessage(t, rec); !strings.Contains(msg.Text, "не ревьювер") {
+			t.Errorf("expected not-assigned reply, got %q", msg.Text)
+		}
+	})
+
+	t.Run("ooo", func(t *testing.T) {
+		users := &mockChatUsers{awayUntil: map[string]time.Time{}}
+		h := newTestChatHandler(now, &mockPRService{}, users)
+		rec := httptest.NewRecorder()
+		h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", loadTestdata(t, "slack_command_ooo.txt"), now))
+
+		msg := decodeSlackMessage(t, rec)
+		want := now.Add(72 * time.Hour)
+		if got := users.awayUntil["u2"]; !got.Equal(want) {
+			t.Errorf("expected away until %v, got %v", want, got)
+		}
+		if !strings.Contains(msg.Text, "<!date^"+strconv.FormatInt(want.Unix(), 10)) {
+			t.Errorf("expected localized date in reply, got %q", msg.Text)
+		}
+		if !strings.Contains(msg.Text, "Уже назначенные остаются за вами") {
+			t.Errorf("expected reply to say current assignments are kept, got %q", msg.Text)
+		}
+	})
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"unknown command", "merge+PR-123", "Команды:"},
+		{"invalid period", "ooo+soon", "Не понял срок"},
+		{"too long period", "ooo+20w", "не может быть дольше 90 дней"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.Replace(loadTestdata(t, "slack_command_mine.txt"), "text=mine", "text="+tt.text, 1)
+			h := newTestChatHandler(now, &mockPRService{}, &mockChatUsers{awayUntil: map[string]time.Time{}})
+			rec := httptest.NewRecorder()
+			h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", body, now))
+
+			if msg := decodeSlackMessage(t, rec); !strings.Contains(msg.Text, tt.want) {
+				t.Errorf("expected %q in reply, got %q", tt.want, msg.Text)
+			}
+		})
+	}
+
+	t.Run("unlinked user", func(t *testing.T) {
+		body := strings.Replace(loadTestdata(t, "slack_command_mine.txt"), "user_id=U2147483697", "user_id=U0000000", 1)
+		h := newTestChatHandler(now, &mockPRService{}, &mockChatUsers{})
+		rec := httptest.NewRecorder()
+		h.SlackCommand(rec, signedSlackRequest(t, "/slack/commands", body, now))
+
+		if msg := decodeSlackMessage(t, rec); !strings.Contains(msg.Text, "не привязан") {
+			t.Errorf("expected unlinked reply, got %q", msg.Text)
+		}
+	})
+}
+
+func TestChatHandler_SlackInteraction(t *testing.T) {
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	responses := make(chan slackMessage, 1)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode response_url message: %v", err)
+		}
+		responses <- msg
+	}))
+	defer slack.Close()
+
+	var reassigned string
+	prs := &mockPRService{reassignReviewerFunc: func(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+		reassigned = prID + "/" + oldUserID
+		return &models.PullRequest{PullRequestID: prID}, "u3", nil
+	}}
+	h := newTestChatHandler(now, prs, &mockChatUsers{})
+	h.responseURLPrefix = slack.URL + "/actions/"
+
+	payload := strings.Replace(loadTestdata(t, "slack_interaction_reassign.json"), "https://hooks.slack.com", slack.URL, 1)
+	rec := httptest.NewRecorder()
+	h.SlackInteraction(rec, signedSlackRequest(t, "/slack/interactions", "payload="+url.QueryEscape(payload), now))
+
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Fatalf("expected empty acknowledgement, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reassigned != "PR-123/u2" {
+		t.Errorf("expected u2 to be replaced on PR-123, got %q", reassigned)
+	}
+	select {
+	case msg := <-responses:
+		if !msg.ReplaceOriginal || len(msg.Blocks) < 2 || !strings.Contains(msg.Blocks[0].Text.Text, "передан") {
+			t.Errorf("expected updated list with result on top, got %+v", msg)
+		}
+	default:
+		t.Fatal("expected a message on response_url")
+	}
+
+	// response_url вне разрешённого префикса не вызывается
+	h.responseURLPrefix = "https://hooks.slack.com/"
+	rec = httptest.NewRecorder()
+	h.SlackInteraction(rec, signedSlackRequest(t, "/slack/interactions", "payload="+url.QueryEscape(payload), now))
+	select {
+	case msg := <-responses:
+		t.Errorf("unexpected message to foreign response_url: %+v", msg)
+	default:
+	}
+}
@@ -1,67 +1,146 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-func ServeOpenAPISpec(w http.ResponseWriter, r *http.Request) {
-	// Try multiple possible paths
-	paths := []string{
-		"openapi.yaml",
-		"./openapi.yaml",
-		"../openapi.yaml",
-		"../../openapi.yaml",
-		"internal/static/openapi.yaml",
-	}
+const (
+	// Спецификацию и страницу документации браузер всегда перепроверяет по ETag,
+	// статику Swagger UI можно брать из кэша без запроса
+	revalidateCacheControl = "no-cache"
+	assetsCacheControl     = "public, max-age=3600"
+)
 
-	var data []byte
-	var err error
-	for _, path := range paths {
-		data, err = os.ReadFile(path)
-		if err == nil {
-			break
-		}
+// Типы для расширений статики фиксированы: mime.TypeByExtension зависит
+// от системных mime.types и в контейнере может вернуть другое значение
+var assetContentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".png":  "image/png",
+}
+
+// embeddedFile — ресурс из embed.FS с заранее посчитанным ETag
+type embeddedFile struct {
+	name         string
+	contentType  string
+	cacheControl string
+	etag         string
+	body         []byte
+}
+
+func newEmbeddedFile(name, contentType, cacheControl string, body []byte) embeddedFile {
+	sum := sha256.Sum256(body)
+	return embeddedFile{
+		name:         name,
+		contentType:  contentType,
+		cacheControl: cacheControl,
+		etag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		body:         body,
 	}
+}
 
+// DocsHandler отдаёт встроенную в бинарник спецификацию (YAML и JSON)
+// и страницу Swagger UI, не обращаясь к диску и внешним CDN.
+type DocsHandler struct {
+	specYAML embeddedFile
+	specJSON embeddedFile
+	index    embeddedFile
+	assets   map[string]embeddedFile
+}
+
+func NewDocsHandler(spec []byte, assets fs.FS) (*DocsHandler, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	specJSON, err := json.Marshal(doc)
 	if err != nil {
-		// Try to find file in current working directory
-		wd, _ := os.Getwd()
-		possiblePath := filepath.Join(wd, "openapi.yaml")
-		data, err = os.ReadFile(possiblePath)
+		return nil, fmt.Errorf("failed to convert OpenAPI spec to JSON: %w", err)
+	}
+
+	h := &DocsHandler{
+		specYAML: newEmbeddedFile("openapi.yaml", "application/yaml", revalidateCacheControl, spec),
+		specJSON: newEmbeddedFile("openapi.json", "application/json", revalidateCacheControl, specJSON),
+		assets:   make(map[string]embeddedFile),
+	}
+
+	err = fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(assets, name)
 		if err != nil {
-			http.Error(w, "OpenAPI spec not found", http.StatusNotFound)
-			return
+			return err
 		}
+		contentType, ok := assetContentTypes[path.Ext(name)]
+		if !ok {
+			contentType = mime.TypeByExtension(path.Ext(name))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h.assets[name] = newEmbeddedFile(name, contentType, assetsCacheControl, body)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load docs assets: %w", err)
+	}
+
+	index, ok := h.assets["index.html"]
+	if !ok {
+		return nil, fmt.Errorf("docs assets have no index.html")
 	}
+	index.cacheControl = revalidateCacheControl
+	h.index = index
 
-	w.Header().Set("Content-Type", "application/yaml")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	return h, nil
 }
 
-func ServeDocs(w http.ResponseWriter, r *http.Request) {
-	html := `<!DOCTYPE html>
-<html>
-<head>
-	<title>API Documentation</title>
-	<meta charset="utf-8"/>
-	<meta name="viewport" content="width=device-width, initial-scale=1">
-	<style>
-		body {
-			margin: 0;
-			padding: 0;
-		}
-	</style>
-</head>
-<body>
-	<script id="api-reference" data-configuration='{"url":"/api/openapi.yaml","theme":"default","darkMode":true}'></script>
-	<script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference@latest/dist/browser/standalone.js"></script>
-</body>
-</html>`
-
-	w.Header().Set("Content-Type", "text/html")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(html))
+func (h *DocsHandler) ServeSpecYAML(w http.ResponseWriter, r *http.Request) {
+	serveEmbedded(w, r, h.specYAML)
+}
+
+func (h *DocsHandler) ServeSpecJSON(w http.ResponseWriter, r *http.Request) {
+	serveEmbedded(w, r, h.specJSON)
+}
+
+func (h *DocsHandler) ServeDocs(w http.ResponseWriter, r *http.Request) {
+	serveEmbedded(w, r, h.index)
+}
+
+// ServeAsset отдаёт файлы Swagger UI по пути /docs/<файл>
+func (h *DocsHandler) ServeAsset(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/docs/")
+	if name == "" || name == "index.html" {
+		h.ServeDocs(w, r)
+		return
+	}
+	asset, ok := h.assets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	serveEmbedded(w, r, asset)
+}
+
+// serveEmbedded выставляет ETag и Cache-Control, а условные запросы
+// (If-None-Match → 304), HEAD и Range обрабатывает http.ServeContent
+func serveEmbedded(w http.ResponseWriter, r *http.Request, f embeddedFile) {
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Cache-Control", f.cacheControl)
+	w.Header().Set("ETag", f.etag)
+	http.ServeContent(w, r, f.name, time.Time{}, bytes.NewReader(f.body))
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	reviewerservice "github.com/reviewer-service"
+	"github.com/reviewer-service/internal/static"
+)
+
+const testSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1.0.0"
+paths:
+  /ping:
+    get:
+      responses:
+        '200':
+          description: OK
+`
+
+func newTestDocsHandler(t *testing.T) *DocsHandler {
+	t.Helper()
+	h, err := NewDocsHandler([]byte(testSpec), fstest.MapFS{
+		"index.html": {Data: []byte("<html></html>")},
+		"app.js":     {Data: []byte("console.log(1)")},
+	})
+	if err != nil {
+		t.Fatalf("NewDocsHandler() error = %v", err)
+	}
+	return h
+}
+
+func serveDocs(handler http.HandlerFunc, method, target, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestDocsHandler(t *testing.T) {
+	h := newTestDocsHandler(t)
+
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		target       string
+		status       int
+		contentType  string
+		cacheControl string
+	}{
+		{"spec yaml", h.ServeSpecYAML, "/api/openapi.yaml", http.StatusOK, "application/yaml", "no-cache"},
+		{"spec json", h.ServeSpecJSON, "/api/openapi.json", http.StatusOK, "application/json", "no-cache"},
+		{"docs page", h.ServeDocs, "/docs", http.StatusOK, "text/html; charset=utf-8", "no-cache"},
+		{"index via assets", h.ServeAsset, "/docs/", http.StatusOK, "text/html; charset=utf-8", "no-cache"},
+		{"asset", h.ServeAsset, "/docs/app.js", http.StatusOK, "text/javascript; charset=utf-8", "public, max-age=3600"},
+		{"missing asset", h.ServeAsset, "/docs/missing.js", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveDocs(tt.handler, http.MethodGet, tt.target, "")
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.contentType, got)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", tt.cacheControl, got)
+			}
+
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				t.Fatal("expected ETag header")
+			}
+			cached := serveDocs(tt.handler, http.MethodGet, tt.target, etag)
+			if cached.Code != http.StatusNotModified || cached.Body.Len() != 0 {
+				t.Errorf("expected empty 304 for matching ETag, got %d with %d bytes", cached.Code, cached.Body.Len())
+			}
+			stale := serveDocs(tt.handler, http.MethodGet, tt.target, `"stale"`)
+			if stale.Code != http.StatusOK {
+				t.Errorf("expected 200 for stale ETag, got %d", stale.Code)
+			}
+		})
+	}
+}
+
+func TestDocsHandler_SpecJSONMatchesYAML(t *testing.T) {
+	h := newTestDocsHandler(t)
+
+	yamlResp := serveDocs(h.ServeSpecYAML, http.MethodGet, "/api/openapi.yaml", "")
+	if yamlResp.Body.String() != testSpec {
+		t.Errorf("YAML spec must be served as is, got %q", yamlResp.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Get struct {
+				Responses map[string]struct {
+					Description string `json:"description"`
+				} `json:"responses"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	jsonResp := serveDocs(h.ServeSpecJSON, http.MethodGet, "/api/openapi.json", "")
+	if err := json.Unmarshal(jsonResp.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode JSON spec: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Paths["/ping"].Get.Responses["200"].Description != "OK" {
+		t.Errorf("unexpected JSON spec: %s", jsonResp.Body.String())
+	}
+	if jsonResp.Header().Get("ETag") == yamlResp.Header().Get("ETag") {
+		t.Error("YAML and JSON representations must have different ETags")
+	}
+}
+
+func TestDocsHandler_Errors(t *testing.T) {
+	if _, err := NewDocsHandler([]byte("openapi: [\n"), fstest.MapFS{"index.html": {}}); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+	if _, err := NewDocsHandler([]byte(testSpec), fstest.MapFS{"app.js": {}}); err == nil {
+		t.Error("expected error for assets without index.html")
+	}
+}
+
+// Страница документации не должна ссылаться на внешние ресурсы
+// или на файлы, которых нет в embed.FS.
+func TestDocsHandler_EmbeddedAssets(t *testing.T) {
+	assets, err := fs.Sub(static.Docs, "docs")
+	if err != nil {
+		t.Fatalf("fs.Sub() error = %v", err)
+	}
+	h, err := NewDocsHandler(reviewerservice.OpenAPISpec, assets)
+	if err != nil {
+		t.Fatalf("NewDocsHandler() error = %v", err)
+	}
+
+	page := serveDocs(h.ServeDocs, http.MethodGet, "/docs", "").Body.String()
+	if regexp.MustCompile(`(src|href)="(https?:)?//`).MatchString(page) {
+		t.Error("docs page must not load resources from external hosts")
+	}
+
+	refs := regexp.MustCompile(`(?:src|href)="(/docs/[^"]+)"`).FindAllStringSubmatch(page, -1)
+	if len(refs) == 0 {
+		t.Fatal("docs page references no assets")
+	}
+	for _, ref := range refs {
+		if w := serveDocs(h.ServeAsset, http.MethodGet, ref[1], ""); w.Code != http.StatusOK {
+			t.Errorf("asset %s referenced by docs page returned %d", ref[1], w.Code)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/service"
+)
+
+const (
+	// eventKeepAlive — период комментариев, которые не дают прокси закрыть
+	// молчащее соединение.
+	eventKeepAlive = 15 * time.Second
+	// eventRetryMillis — через сколько браузер переподключается после обрыва
+	eventRetryMillis = 3000
+)
+
+// EventSource — поток событий журнала для /events/stream.
+type EventSource interface {
+	Subscribe(ctx context.Context, filter repository.EventFilter) (*service.Subscription, error)
+	Unsubscribe(sub *service.Subscription)
+	ListAfter(ctx context.Context, filter repository.EventFilter, afterID int64) ([]*models.StreamEvent, error)
+}
+
+type EventHandler struct {
+	events    EventSource
+	keepAlive time.Duration
+	logger    *slog.Logger
+}
+
+func NewEventHandler(events *service.EventStream, logger *slog.Logger) *EventHandler {
+	return &EventHandler{
+		events:    events,
+		keepAlive: eventKeepAlive,
+		logger:    logger,
+	}
+}
+
+// Stream отдаёт события в формате Server-Sent Events, пока клиент не
+// отключится. С Last-Event-ID сначала дочитывается журнал после него, и
+// только потом открывается подписка: иначе живые события копились бы в её
+// буфере, пока медленный клиент читает длинный журнал, и подписку отключили бы.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	filter := repository.EventFilter{
+		Types:         service.StreamEventTypes,
+		TeamName:      query.Get("team_name"),
+		UserID:        query.Get("user_id"),
+		PullRequestID: query.Get("pull_request_id"),
+	}
+
+	var lastID int64
+	resume := false
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Last-Event-ID must be a non-negative integer")
+			return
+		}
+		lastID, resume = id, true
+	}
+
+	// Без Last-Event-ID подписываемся до ответа, чтобы ошибка стала 500
+	var sub *service.Subscription
+	if !resume {
+		var err error
+		if sub, err = h.events.Subscribe(ctx, filter); err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			return
+		}
+		defer h.events.Unsubscribe(sub)
+	}
+
+	rc := http.NewResponseController(w)
+	// Поток живёт дольше WriteTimeout сервера
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(ctx, "failed to lift write deadline for event stream", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryMillis)
+
+	// Прочитанные из журнала события новее Settled придут и из подписки
+	replayed := make(map[int64]bool)
+	if resume {
+		var backlog []int64
+		var ok bool
+		if lastID, ok = h.replay(ctx, w, rc, filter, lastID, &backlog); !ok {
+			return
+		}
+
+		var err error
+		if sub, err = h.events.Subscribe(ctx, filter); err != nil {
+			// Клиент переподключится с Last-Event-ID
+			return
+		}
+		defer h.events.Unsubscribe(sub)
+
+		// Журнал идёт по возрастанию id: из подписки могут прийти только
+		// события после Settled
+		for i := sort.Search(len(backlog), func(i int) bool { return backlog[i] > sub.Settled }); i < len(backlog); i++ {
+			replayed[backlog[i]] = true
+		}
+		// События, записанные между дочитыванием и подпиской
+		var caughtUp []int64
+		if _, ok = h.replay(ctx, w, rc, filter, lastID, &caughtUp); !ok {
+			return
+		}
+		for _, id := range caughtUp {
+			if id > sub.Settled {
+				replayed[id] = true
+			}
+		}
+	}
+	if !h.flush(ctx, rc) {
+		return
+	}
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.Events:
+			if !ok {
+				// Отстали или сервер останавливается: клиент переподключится
+				// с Last-Event-ID
+				return
+			}
+			if replayed[e.ID] {
+				delete(replayed, e.ID)
+				continue
+			}
+			if err := writeStreamEvent(w, e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if !h.flush(ctx, rc) {
+			return
+		}
+	}
+}
+
+// replay дочитывает журнал после afterID страницами, пока не догонит его,
+// и добавляет id отправленных событий в ids. Возвращает id последнего
+// события и false, если поток пора закрыть.
+func (h *EventHandler) replay(ctx context.Context, w io.Writer, rc *http.ResponseController, filter repository.EventFilter, afterID int64, ids *[]int64) (int64, bool) {
+	for {
+		events, err := h.events.ListAfter(ctx, filter, afterID)
+		if err != nil {
+			return afterID, false
+		}
+		if len(events) == 0 {
+			return afterID, true
+		}
+		for _, e := range events {
+			if err := writeStreamEvent(w, e); err != nil {
+				return afterID, false
+			}
+			afterID = e.ID
+			*ids = append(*ids, e.ID)
+		}
+		if !h.flush(ctx, rc) {
+			return afterID, false
+		}
+	}
+}
+
+func (h *EventHandler) flush(ctx context.Context, rc *http.ResponseController) bool {
+	if err := rc.Flush(); err != nil {
+		h.logger.WarnContext(ctx, "failed to flush event stream", "error", err)
+		return false
+	}
+	return true
+}
+
+func writeStreamEvent(w io.Writer, e *models.StreamEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
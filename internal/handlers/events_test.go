@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/service"
+)
+
+type mockEventSource struct {
+	sub          *service.Subscription
+	log          [][]*models.StreamEvent
+	filter       repository.EventFilter
+	afterIDs     []int64
+	unsubscribed bool
+	// readsBeforeSubscribe — сколько раз журнал прочитан до подписки
+	readsBeforeSubscribe int
+}
+
+func (m *mockEventSource) Subscribe(ctx context.Context, filter repository.EventFilter) (*service.Subscription, error) {
+	m.filter = filter
+	m.readsBeforeSubscribe = len(m.afterIDs)
+	return m.sub, nil
+}
+
+func (m *mockEventSource) Unsubscribe(sub *service.Subscription) {
+	m.unsubscribed = true
+}
+
+func (m *mockEventSource) ListAfter(ctx context.Context, filter repository.EventFilter, afterID int64) ([]*models.StreamEvent, error) {
+	m.afterIDs = append(m.afterIDs, afterID)
+	if len(m.log) == 0 {
+		return nil, nil
+	}
+	page := m.log[0]
+	m.log = m.log[1:]
+	return page, nil
+}
+
+func streamEvent(id int64, eventType string) *models.StreamEvent {
+	return &models.StreamEvent{PREvent: models.PREvent{ID: id, PullRequestID: "pr-1", Type: eventType, UserID: "u2"}, AuthorID: "u1", TeamName: "backend"}
+}
+
+func TestEventHandler_Stream(t *testing.T) {
+	// Событие 6 прочитано из журнала и пришло из подписки
+	live := make(chan *models.StreamEvent, 3)
+	live <- streamEvent(6, models.EventReviewerRemoved)
+	live <- streamEvent(7, models.EventPRMerged)
+	close(live)
+	source := &mockEventSource{
+		sub: &service.Subscription{Events: live, Settled: 5},
+		log: [][]*models.StreamEvent{{streamEvent(4, models.EventPRCreated), streamEvent(6, models.EventReviewerRemoved)}},
+	}
+	h := &EventHandler{events: source, keepAlive: time.Hour, logger: setupTestLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/events/stream?team_name=backend&user_id=u2", nil)
+	req.Header.Set("Last-Event-ID", "3")
+	rec := httptest.NewRecorder()
+	h.Stream(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if source.filter.TeamName != "backend" || source.filter.UserID != "u2" || len(source.filter.Types) != len(service.StreamEventTypes) {
+		t.Errorf("unexpected filter %+v", source.filter)
+	}
+	if len(source.afterIDs) != 3 || source.afterIDs[0] != 3 || source.afterIDs[1] != 6 || source.afterIDs[2] != 6 {
+		t.Errorf("expected log to be read after 3 and then twice after 6, got %v", source.afterIDs)
+	}
+	if source.readsBeforeSubscribe != 2 {
+		t.Errorf("expected subscription after the log is caught up, got %d reads before it", source.readsBeforeSubscribe)
+	}
+	if !source.unsubscribed {
+		t.Error("expected subscription to be released")
+	}
+
+	want := "retry: 3000\n\n" +
+		"id: 4\nevent: PR_CREATED\ndata: {\"id\":4,\"pull_request_id\":\"pr-1\",\"type\":\"PR_CREATED\",\"user_id\":\"u2\",\"created_at\":\"0001-01-01T00:00:00Z\",\"author_id\":\"u1\",\"team_name\":\"backend\"}\n\n"
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, want) {
+		t.Errorf("unexpected stream start:\n%s", body)
+	}
+	for _, id := range []string{"id: 4\n", "id: 6\n", "id: 7\n"} {
+		if strings.Count(body, id) != 1 {
+			t.Errorf("expected %q exactly once in stream:\n%s", id, body)
+		}
+	}
+}
+
+func TestEventHandler_Stream_LargeBacklog(t *testing.T) {
+	// Журнал намного длиннее буфера подписки: подписка открывается только
+	// после него, а событие, записанное между дочитыванием и подпиской,
+	// отдаётся один раз
+	var log [][]*models.StreamEvent
+	id := int64(10)
+	for page := 0; page < 4; page++ {
+		events := make([]*models.StreamEvent, 500)
+		for i := range events {
+			id++
+			events[i] = streamEvent(id, models.EventReviewerAssigned)
+		}
+		log = append(log, events)
+	}
+	log = append(log, nil, []*models.StreamEvent{streamEvent(id+1, models.EventPRMerged)})
+
+	live := make(chan *models.StreamEvent, 2)
+	live <- streamEvent(id+1, models.EventPRMerged)
+	live <- streamEvent(id+2, models.EventPRMerged)
+	close(live)
+	source := &mockEventSource{sub: &service.Subscription{Events: live, Settled: id}, log: log}
+	h := &EventHandler{events: source, keepAlive: time.Hour, logger: setupTestLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+	req.Header.Set("Last-Event-ID", "10")
+	rec := httptest.NewRecorder()
+	h.Stream(rec, req)
+
+	if source.readsBeforeSubscribe != 5 {
+		t.Errorf("expected the whole backlog to be read before subscribing, got %d reads", source.readsBeforeSubscribe)
+	}
+	body := rec.Body.String()
+	if n := strings.Count(body, "event: REVIEWER_ASSIGNED\n"); n != 2000 {
+		t.Errorf("expected 2000 replayed events, got %d", n)
+	}
+	for _, want := range []string{fmt.Sprintf("id: %d\n", id+1), fmt.Sprintf("id: %d\n", id+2)} {
+		if strings.Count(body, want) != 1 {
+			t.Errorf("expected %q exactly once in stream", want)
+		}
+	}
+}
+
+func TestEventHandler_Stream_KeepAlive(t *testing.T) {
+	source := &mockEventSource{sub: &service.Subscription{Events: make(chan *models.StreamEvent)}}
+	h := &EventHandler{events: source, keepAlive: 5 * time.Millisecond, logger: setupTestLogger()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	h.Stream(rec, httptest.NewRequest(http.MethodGet, "/events/stream", nil).WithContext(ctx))
+
+	if len(source.afterIDs) != 0 {
+		t.Errorf("expected no log reads without Last-Event-ID, got %v", source.afterIDs)
+	}
+	if !strings.Contains(rec.Body.String(), ": keep-alive\n\n") {
+		t.Errorf("expected keep-alive comments, got %q", rec.Body.String())
+	}
+}
+
+func TestEventHandler_Stream_InvalidLastEventID(t *testing.T) {
+	source := &mockEventSource{}
+	h := &EventHandler{events: source, keepAlive: time.Hour, logger: setupTestLogger()}
+
+	req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+	req.Header.Set("Last-Event-ID", "-1")
+	rec := httptest.NewRecorder()
+	h.Stream(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	if source.filter.Types != nil || source.unsubscribed {
+		t.Error("expected no subscription for invalid request")
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/service"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+	logger  *slog.Logger
+}
+
+func NewNotificationHandler(service *service.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SetPreferences задаёт адрес пользователя; не переданные подписки включены
+func (h *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserID            string `json:"user_id"`
+		Email             string `json:"email"`
+		DailyDigest       *bool  `json:"daily_digest"`
+		AssignmentNotices *bool  `json:"assignment_notices"`
+		OverdueReminders  *bool  `json:"overdue_reminders"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	enabled := func(v *bool) bool { return v == nil || *v }
+	prefs, err := h.service.SetPreferences(ctx, &models.NotificationPreferences{
+		UserID:            req.UserID,
+		Email:             req.Email,
+		DailyDigest:       enabled(req.DailyDigest),
+		AssignmentNotices: enabled(req.AssignmentNotices),
+		OverdueReminders:  enabled(req.OverdueReminders),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEmail) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to set notification preferences", "error", err, "user_id", req.UserID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"preferences": prefs})
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		h.logger.WarnContext(ctx, "user_id parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(ctx, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrPreferencesNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found or has no notification preferences")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get notification preferences", "error", err, "user_id", userID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, prefs)
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
 	"github.com/reviewer-service/internal/service"
 )
 
@@ -15,6 +20,10 @@ type PRService interface {
 	CreatePR(ctx context.Context, prID, prName, authorID string) (*models.PullRequest, error)
 	MergePR(ctx context.Context, prID string) (*models.PullRequest, error)
 	ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error)
+	SubmitReview(ctx context.Context, prID, userID string) (*models.PullRequest, time.Time, error)
+	GetHistory(ctx context.Context, prID string) ([]*models.PREvent, error)
+	GetPR(ctx context.Context, prID string) (*models.PullRequest, error)
+	ListPRs(ctx context.Context, q service.ListPullRequestsQuery) ([]*models.PullRequest, string, error)
 }
 
 type PullRequestHandler struct {
@@ -132,3 +141,192 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 		"replaced_by": replacedBy,
 	})
 }
+
+func (h *PullRequestHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	pr, reviewedAt, err := h.service.SubmitReview(ctx, req.PullRequestID, req.UserID)
+	if err != nil {
+		// OpenAPI:
+		// - 404 Not Found: PR не найден
+		// - 409 Conflict с кодами: PR_MERGED, NOT_ASSIGNED
+		if errors.Is(err, service.ErrPRNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "PR not found")
+		} else if errors.Is(err, service.ErrPRMerged) {
+			respondError(w, http.StatusConflict, "PR_MERGED", "cannot review merged PR")
+		} else if errors.Is(err, service.ErrNotAssigned) {
+			respondError(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+		} else {
+			h.logger.ErrorContext(ctx, "internal server error", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	// OpenAPI: 200 OK с { "pr": {...}, "reviewed_at": "..." } (идемпотентно)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pr":          pr,
+		"reviewed_at": reviewedAt,
+	})
+}
+
+func (h *PullRequestHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		h.logger.WarnContext(ctx, "pull_request_id parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	events, err := h.service.GetHistory(ctx, prID)
+	if err != nil {
+		if errors.Is(err, service.ErrPRNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "PR not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get PR history", "error", err, "pr_id", prID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	// OpenAPI: 200 OK с { "pull_request_id": "...", "events": [...] }
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+func (h *PullRequestHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		h.logger.WarnContext(ctx, "pull_request_id parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.service.GetPR(ctx, prID)
+	if err != nil {
+		if errors.Is(err, service.ErrPRNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "PR not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get PR", "error", err, "pr_id", prID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	// OpenAPI: 200 OK с { "pr": {...} }
+	respondJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *PullRequestHandler) ListPRs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		h.logger.WarnContext(ctx, "invalid PR list parameters", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	prs, next, err := h.service.ListPRs(ctx, q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidSort) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else {
+			h.logger.ErrorContext(ctx, "failed to list PRs", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	// OpenAPI: 200 OK с { "pull_requests": [...], "next_cursor": "..." | null }
+	var nextCursor interface{}
+	if next != "" {
+		nextCursor = next
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pull_requests": prs,
+		"next_cursor":   nextCursor,
+	})
+}
+
+func parseListQuery(values url.Values) (service.ListPullRequestsQuery, error) {
+	q := service.ListPullRequestsQuery{
+		Filter: repository.PullRequestFilter{
+			Status:     values.Get("status"),
+			AuthorID:   values.Get("author_id"),
+			ReviewerID: values.Get("reviewer_id"),
+			TeamName:   values.Get("team_name"),
+		},
+		SortBy: values.Get("sort"),
+		Desc:   true,
+		Cursor: values.Get("cursor"),
+	}
+
+	if q.Filter.Status != "" && q.Filter.Status != "OPEN" && q.Filter.Status != "MERGED" {
+		return q, fmt.Errorf("status must be OPEN or MERGED")
+	}
+
+	switch values.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Desc = false
+	default:
+		return q, fmt.Errorf("order must be asc or desc")
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > service.MaxListLimit {
+			return q, fmt.Errorf("limit must be an integer from 1 to %d", service.MaxListLimit)
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("needs_reviewers"); v != "" {
+		needs, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("needs_reviewers must be a boolean")
+		}
+		q.NeedsReviewers = needs
+	}
+
+	dates := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &q.Filter.CreatedFrom},
+		{"created_to", &q.Filter.CreatedTo},
+		{"merged_from", &q.Filter.MergedFrom},
+		{"merged_to", &q.Filter.MergedTo},
+	}
+	for _, d := range dates {
+		name, dst := d.name, d.dst
+		v := values.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("%s must be an RFC 3339 date-time", name)
+		}
+		*dst = &t
+	}
+
+	return q, nil
+}
@@ -9,9 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
 	"github.com/reviewer-service/internal/service"
 )
 
@@ -20,9 +23,13 @@ func setupTestLogger() *slog.Logger {
 }
 
 type mockPRService struct {
-	createPRFunc          func(ctx context.Context, prID, prName, authorID string) (*models.PullRequest, error)
-	mergePRFunc           func(ctx context.Context, prID string) (*models.PullRequest, error)
-	reassignReviewerFunc  func(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error)
+	createPRFunc         func(ctx context.Context, prID, prName, authorID string) (*models.PullRequest, error)
+	mergePRFunc          func(ctx context.Context, prID string) (*models.PullRequest, error)
+	reassignReviewerFunc func(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error)
+	submitReviewFunc     func(ctx context.Context, prID, userID string) (*models.PullRequest, time.Time, error)
+	getHistoryFunc       func(ctx context.Context, prID string) ([]*models.PREvent, error)
+	getPRFunc            func(ctx context.Context, prID string) (*models.PullRequest, error)
+	listPRsFunc          func(ctx context.Context, q service.ListPullRequestsQuery) ([]*models.PullRequest, string, error)
 }
 
 func (m *mockPRService) CreatePR(ctx context.Context, prID, prName, authorID string) (*models.PullRequest, error) {
@@ -46,6 +53,34 @@ func (m *mockPRService) ReassignReviewer(ctx context.Context, prID, oldUserID st
 	return nil, "", errors.New("not implemented")
 }
 
+func (m *mockPRService) SubmitReview(ctx context.Context, prID, userID string) (*models.PullRequest, time.Time, error) {
+	if m.submitReviewFunc != nil {
+		return m.submitReviewFunc(ctx, prID, userID)
+	}
+	return nil, time.Time{}, errors.New("not implemented")
+}
+
+func (m *mockPRService) GetHistory(ctx context.Context, prID string) ([]*models.PREvent, error) {
+	if m.getHistoryFunc != nil {
+		return m.getHistoryFunc(ctx, prID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPRService) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	if m.getPRFunc != nil {
+		return m.getPRFunc(ctx, prID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPRService) ListPRs(ctx context.Context, q service.ListPullRequestsQuery) ([]*models.PullRequest, string, error) {
+	if m.listPRsFunc != nil {
+		return m.listPRsFunc(ctx, q)
+	}
+	return nil, "", errors.New("not implemented")
+}
+
 func TestPullRequestHandler_CreatePR(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -383,3 +418,296 @@ func TestPullRequestHandler_ReassignReviewer(t *testing.T) {
 	}
 }
 
+func TestPullRequestHandler_SubmitReview(t *testing.T) {
+	reviewedAt := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		serviceErr     error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "successful review", requestBody: `{"pull_request_id":"pr-1","user_id":"u2"}`, expectedStatus: http.StatusOK},
+		{name: "PR not found", requestBody: `{"pull_request_id":"pr-x","user_id":"u2"}`, serviceErr: service.ErrPRNotFound, expectedStatus: http.StatusNotFound, expectedError: "NOT_FOUND"},
+		{name: "PR merged", requestBody: `{"pull_request_id":"pr-1","user_id":"u2"}`, serviceErr: service.ErrPRMerged, expectedStatus: http.StatusConflict, expectedError: "PR_MERGED"},
+		{name: "not assigned", requestBody: `{"pull_request_id":"pr-1","user_id":"u9"}`, serviceErr: service.ErrNotAssigned, expectedStatus: http.StatusConflict, expectedError: "NOT_ASSIGNED"},
+		{name: "internal error", requestBody: `{"pull_request_id":"pr-1","user_id":"u2"}`, serviceErr: errors.New("db down"), expectedStatus: http.StatusInternalServerError, expectedError: "INTERNAL_ERROR"},
+		{name: "invalid request body", requestBody: "invalid json", expectedStatus: http.StatusBadRequest, expectedError: "INVALID_REQUEST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &PullRequestHandler{
+				service: &mockPRService{
+					submitReviewFunc: func(ctx context.Context, prID, userID string) (*models.PullRequest, time.Time, error) {
+						if tt.serviceErr != nil {
+							return nil, time.Time{}, tt.serviceErr
+						}
+						return &models.PullRequest{PullRequestID: prID, Status: "OPEN", AssignedReviewers: []string{userID}}, reviewedAt, nil
+					},
+				},
+				logger: setupTestLogger(),
+			}
+
+			w := httptest.NewRecorder()
+			handler.SubmitReview(w, httptest.NewRequest("POST", "/pullRequest/submitReview", bytes.NewBufferString(tt.requestBody)))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var response models.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if response.Error.Code != tt.expectedError {
+					t.Errorf("expected error code %s, got %s", tt.expectedError, response.Error.Code)
+				}
+				return
+			}
+
+			var response struct {
+				PR         models.PullRequest `json:"pr"`
+				ReviewedAt time.Time          `json:"reviewed_at"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.PR.PullRequestID != "pr-1" || !response.ReviewedAt.Equal(reviewedAt) {
+				t.Errorf("unexpected response: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPullRequestHandler_GetHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockService    *mockPRService
+		expectedStatus int
+		expectedError  string
+		expectedEvents int
+	}{
+		{
+			name:  "successful history",
+			query: "?pull_request_id=pr-1",
+			mockService: &mockPRService{
+				getHistoryFunc: func(ctx context.Context, prID string) ([]*models.PREvent, error) {
+					return []*models.PREvent{
+						{ID: 1, PullRequestID: prID, Type: models.EventPRCreated, UserID: "u1"},
+						{ID: 2, PullRequestID: prID, Type: models.EventReviewerAssigned, UserID: "u2"},
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedEvents: 2,
+		},
+		{
+			name:  "PR not found",
+			query: "?pull_request_id=pr-missing",
+			mockService: &mockPRService{
+				getHistoryFunc: func(ctx context.Context, prID string) ([]*models.PREvent, error) {
+					return nil, service.ErrPRNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "NOT_FOUND",
+		},
+		{
+			name:           "missing pull_request_id",
+			query:          "",
+			mockService:    &mockPRService{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_REQUEST",
+		},
+		{
+			name:  "internal server error",
+			query: "?pull_request_id=pr-1",
+			mockService: &mockPRService{
+				getHistoryFunc: func(ctx context.Context, prID string) ([]*models.PREvent, error) {
+					return nil, errors.New("database connection failed")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "INTERNAL_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/pullRequest/history"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler := &PullRequestHandler{
+				service: tt.mockService,
+				logger:  setupTestLogger(),
+			}
+
+			handler.GetHistory(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedError != "" {
+				var response models.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if response.Error.Code != tt.expectedError {
+					t.Errorf("expected error code %s, got %s", tt.expectedError, response.Error.Code)
+				}
+				return
+			}
+
+			var response struct {
+				Events []models.PREvent `json:"events"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(response.Events) != tt.expectedEvents {
+				t.Errorf("expected %d events, got %d", tt.expectedEvents, len(response.Events))
+			}
+		})
+	}
+}
+
+func TestPullRequestHandler_GetPR(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		err            error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "found", query: "?pull_request_id=pr-1", expectedStatus: http.StatusOK},
+		{name: "not found", query: "?pull_request_id=pr-1", err: service.ErrPRNotFound, expectedStatus: http.StatusNotFound, expectedError: "NOT_FOUND"},
+		{name: "missing pull_request_id", expectedStatus: http.StatusBadRequest, expectedError: "INVALID_REQUEST"},
+		{name: "internal error", query: "?pull_request_id=pr-1", err: errors.New("db down"), expectedStatus: http.StatusInternalServerError, expectedError: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &PullRequestHandler{
+				service: &mockPRService{
+					getPRFunc: func(ctx context.Context, prID string) (*models.PullRequest, error) {
+						if tt.err != nil {
+							return nil, tt.err
+						}
+						return &models.PullRequest{PullRequestID: prID, Status: "OPEN", AssignedReviewers: []string{"u2"}}, nil
+					},
+				},
+				logger: setupTestLogger(),
+			}
+
+			w := httptest.NewRecorder()
+			handler.GetPR(w, httptest.NewRequest("GET", "/pullRequest/get"+tt.query, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var response struct {
+				PR    models.PullRequest `json:"pr"`
+				Error models.ErrorDetail `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Error.Code != tt.expectedError {
+				t.Errorf("expected error code %q, got %q", tt.expectedError, response.Error.Code)
+			}
+			if tt.expectedStatus == http.StatusOK && response.PR.PullRequestID != "pr-1" {
+				t.Errorf("expected pr-1, got %+v", response.PR)
+			}
+		})
+	}
+}
+
+func TestPullRequestHandler_ListPRs(t *testing.T) {
+	createdFrom := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		next           string
+		expectedStatus int
+		expectedQuery  *service.ListPullRequestsQuery
+		expectedNext   interface{}
+	}{
+		{
+			name:           "defaults",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  &service.ListPullRequestsQuery{Desc: true},
+			expectedNext:   nil,
+		},
+		{
+			name:           "all parameters",
+			query:          "?status=OPEN&author_id=u1&reviewer_id=u2&team_name=backend&created_from=2025-10-01T00:00:00Z&needs_reviewers=true&sort=pull_request_id&order=asc&limit=10&cursor=abc",
+			next:           "next-page",
+			expectedStatus: http.StatusOK,
+			expectedQuery: &service.ListPullRequestsQuery{
+				Filter: repository.PullRequestFilter{
+					Status: "OPEN", AuthorID: "u1", ReviewerID: "u2", TeamName: "backend", CreatedFrom: &createdFrom,
+				},
+				NeedsReviewers: true,
+				SortBy:         "pull_request_id",
+				Cursor:         "abc",
+				Limit:          10,
+			},
+			expectedNext: "next-page",
+		},
+		{name: "invalid status", query: "?status=CLOSED", expectedStatus: http.StatusBadRequest},
+		{name: "invalid order", query: "?order=up", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", query: "?merged_to=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid boolean", query: "?needs_reviewers=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "invalid cursor", query: "?cursor=abc", serviceErr: service.ErrInvalidCursor, expectedStatus: http.StatusBadRequest},
+		{name: "internal error", serviceErr: errors.New("db down"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *service.ListPullRequestsQuery
+			handler := &PullRequestHandler{
+				service: &mockPRService{
+					listPRsFunc: func(ctx context.Context, q service.ListPullRequestsQuery) ([]*models.PullRequest, string, error) {
+						got = &q
+						if tt.serviceErr != nil {
+							return nil, "", tt.serviceErr
+						}
+						return []*models.PullRequest{{PullRequestID: "pr-1", AssignedReviewers: []string{}}}, tt.next, nil
+					},
+				},
+				logger: setupTestLogger(),
+			}
+
+			w := httptest.NewRecorder()
+			handler.ListPRs(w, httptest.NewRequest("GET", "/pullRequest/list"+tt.query, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedQuery != nil && !reflect.DeepEqual(got, tt.expectedQuery) {
+				t.Errorf("unexpected service query:\n got  %+v\n want %+v", got, tt.expectedQuery)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if next, ok := response["next_cursor"]; !ok || next != tt.expectedNext {
+				t.Errorf("expected next_cursor %v, got %v", tt.expectedNext, response["next_cursor"])
+			}
+			if prs, _ := response["pull_requests"].([]interface{}); len(prs) != 1 {
+				t.Errorf("expected 1 PR, got %v", response["pull_requests"])
+			}
+		})
+	}
+}
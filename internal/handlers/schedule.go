@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/service"
+)
+
+type ScheduleHandler struct {
+	service *service.ScheduleService
+	logger  *slog.Logger
+}
+
+func NewScheduleHandler(service *service.ScheduleService, logger *slog.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *ScheduleHandler) SetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserID string `json:"user_id"`
+		models.WorkingHours
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	wh, err := h.service.SetWorkingHours(ctx, req.UserID, &req.WorkingHours)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to set working hours", "error", err, "user_id", req.UserID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"user_id": req.UserID, "working_hours": wh})
+}
+
+func (h *ScheduleHandler) GetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		h.logger.WarnContext(ctx, "user_id parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	wh, err := h.service.GetWorkingHours(ctx, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrScheduleNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found or has no working hours")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get working hours", "error", err, "user_id", userID)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"user_id": userID, "working_hours": wh})
+}
+
+func (h *ScheduleHandler) SetHolidays(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		TeamName string           `json:"team_name"`
+		Holidays []models.Holiday `json:"holidays"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	holidays, err := h.service.SetHolidays(ctx, req.TeamName, req.Holidays)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else if errors.Is(err, service.ErrTeamNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to set team holidays", "error", err, "team_name", req.TeamName)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"team_name": req.TeamName, "holidays": holidays})
+}
+
+func (h *ScheduleHandler) GetHolidays(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get("team_name")
+
+	if teamName == "" {
+		h.logger.WarnContext(ctx, "team_name parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "team_name is required")
+		return
+	}
+
+	holidays, err := h.service.GetHolidays(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, service.ErrTeamNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get team holidays", "error", err, "team_name", teamName)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"team_name": teamName, "holidays": holidays})
+}
@@ -0,0 +1,690 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/service"
+)
+
+// SCIM 2.0 (RFC 7643, RFC 7644): пользователи отображаются на users
+// (id и userName — user_id, displayName — username), группы — на teams
+// (id и displayName — team_name).
+const (
+	SCIMContentType = "application/scim+json"
+	SCIMBasePath    = "/scim/v2"
+
+	scimSchemaUser  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaList  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaError = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Значения scimType из RFC 7644, раздел 3.12
+const (
+	scimInvalidFilter = "invalidFilter"
+	scimInvalidValue  = "invalidValue"
+	scimInvalidPath   = "invalidPath"
+	scimInvalidSyntax = "invalidSyntax"
+	scimMutability    = "mutability"
+	scimUniqueness    = "uniqueness"
+)
+
+type scimRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimUser struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	UserName    string    `json:"userName"`
+	DisplayName string    `json:"displayName"`
+	Active      bool      `json:"active"`
+	Groups      []scimRef `json:"groups"`
+	Meta        scimMeta  `json:"meta"`
+}
+
+type scimGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	DisplayName string    `json:"displayName"`
+	Members     []scimRef `json:"members"`
+	Meta        scimMeta  `json:"meta"`
+}
+
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+type scimPatchOp struct {
+	Operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"Operations"`
+}
+
+type SCIMHandler struct {
+	teams  *service.TeamService
+	users  *service.UserService
+	token  string
+	logger *slog.Logger
+}
+
+func NewSCIMHandler(teams *service.TeamService, users *service.UserService, token string, logger *slog.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		teams:  teams,
+		users:  users,
+		token:  token,
+		logger: logger,
+	}
+}
+
+// RequireToken пропускает только запросы с SCIM-токеном. Без настроенного
+// токена SCIM отключён и все запросы получают 401.
+func (h *SCIMHandler) RequireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.token == "" {
+			respondSCIMError(w, http.StatusUnauthorized, "", "SCIM provisioning is disabled")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			respondSCIMError(w, http.StatusUnauthorized, "", "Invalid SCIM token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+		return
+	}
+
+	users, err := h.users.ListUsers(ctx)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to list users")
+		return
+	}
+
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		match, err := filter.matchUser(u)
+		if err != nil {
+			respondSCIMError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+			return
+		}
+		if match {
+			resources = append(resources, toSCIMUser(u))
+		}
+	}
+	respondSCIMList(w, r, resources)
+}
+
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := h.users.GetUser(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to get user")
+		return
+	}
+	respondSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// CreateUser создаёт пользователя без команды: состав команд задаётся через Groups.
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserName    string `json:"userName"`
+		DisplayName string `json:"displayName"`
+		Name        *struct {
+			Formatted string `json:"formatted"`
+		} `json:"name"`
+		Active *bool `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidSyntax, "Invalid request body")
+		return
+	}
+	if req.UserName == "" {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidValue, "userName is required")
+		return
+	}
+
+	user := &models.User{UserID: req.UserName, Username: req.DisplayName, IsActive: req.Active == nil || *req.Active}
+	if user.Username == "" && req.Name != nil {
+		user.Username = req.Name.Formatted
+	}
+	if user.Username == "" {
+		user.Username = req.UserName
+	}
+
+	created, err := h.teams.CreateUser(ctx, user)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to create user")
+		return
+	}
+	resource := toSCIMUser(created)
+	w.Header().Set("Location", resource.Meta.Location)
+	respondSCIM(w, http.StatusCreated, resource)
+}
+
+// PatchUser поддерживает active, displayName и неизменяемый userName;
+// остальные атрибуты, которые присылают провайдеры (name, emails и т.п.),
+// не хранятся и пропускаются.
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mux.Vars(r)["id"]
+	var patch scimPatchOp
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidSyntax, "Invalid request body")
+		return
+	}
+
+	var username *string
+	var active *bool
+	apply := func(attr string, value json.RawMessage) error {
+		switch strings.ToLower(attr) {
+		case "active":
+			b, err := scimBool(value)
+			if err != nil {
+				return err
+			}
+			active = &b
+		case "displayname":
+			var name string
+			if err := json.Unmarshal(value, &name); err != nil || name == "" {
+				return fmt.Errorf("displayName must be a non-empty string")
+			}
+			username = &name
+		case "username":
+			var name string
+			if err := json.Unmarshal(value, &name); err != nil || name != userID {
+				return errSCIMMutability
+			}
+		}
+		return nil
+	}
+
+	for _, op := range patch.Operations {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				err = apply(op.Path, op.Value)
+				break
+			}
+			var attrs map[string]json.RawMessage
+			if err = json.Unmarshal(op.Value, &attrs); err != nil {
+				err = fmt.Errorf("value must be an object when path is omitted")
+				break
+			}
+			for attr, value := range attrs {
+				if err = apply(attr, value); err != nil {
+					break
+				}
+			}
+		case "remove":
+			switch strings.ToLower(op.Path) {
+			case "active", "displayname", "username":
+				err = errSCIMMutability
+			}
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			respondSCIMPatchError(w, err)
+			return
+		}
+	}
+
+	user, _, err := h.teams.UpdateUser(ctx, userID, username, active)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to patch user")
+		return
+	}
+	respondSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// DeleteUser архивирует пользователя с передачей его открытой работы, как /users/archive.
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mux.Vars(r)["id"]
+	if _, err := h.users.GetUser(ctx, userID); err != nil {
+		h.respondServiceError(ctx, w, err, "failed to get user")
+		return
+	}
+	if _, _, err := h.teams.ArchiveUser(ctx, userID); err != nil {
+		h.respondServiceError(ctx, w, err, "failed to archive user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SCIMHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+		return
+	}
+
+	teams, err := h.teams.ListTeams(ctx)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to list teams")
+		return
+	}
+
+	resources := make([]interface{}, 0, len(teams))
+	for _, team := range teams {
+		match, err := filter.matchGroup(team)
+		if err != nil {
+			respondSCIMError(w, http.StatusBadRequest, scimInvalidFilter, err.Error())
+			return
+		}
+		if match {
+			resources = append(resources, toSCIMGroup(team))
+		}
+	}
+	respondSCIMList(w, r, resources)
+}
+
+func (h *SCIMHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, err := h.teams.GetTeam(ctx, mux.Vars(r)["id"], false)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to get team")
+		return
+	}
+	respondSCIM(w, http.StatusOK, toSCIMGroup(team))
+}
+
+// CreateGroup создаёт команду из существующих пользователей: они переходят
+// в неё так же, как при /users/moveTeam.
+func (h *SCIMHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		DisplayName string    `json:"displayName"`
+		Members     []scimRef `json:"members"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidSyntax, "Invalid request body")
+		return
+	}
+	if req.DisplayName == "" {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidValue, "displayName is required")
+		return
+	}
+
+	userIDs := make([]string, 0, len(req.Members))
+	for _, m := range req.Members {
+		userIDs = append(userIDs, m.Value)
+	}
+	team, _, err := h.teams.ProvisionTeam(ctx, req.DisplayName, userIDs)
+	if err != nil {
+		h.respondMemberError(ctx, w, err, "failed to create team")
+		return
+	}
+	resource := toSCIMGroup(team)
+	w.Header().Set("Location", resource.Meta.Location)
+	respondSCIM(w, http.StatusCreated, resource)
+}
+
+var scimMemberPath = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// PatchGroup меняет состав команды; операции применяются к составу по
+// очереди, а итоговая разница сохраняется одной транзакцией. Переименование
+// команды не поддерживается.
+func (h *SCIMHandler) PatchGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := mux.Vars(r)["id"]
+	var patch scimPatchOp
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidSyntax, "Invalid request body")
+		return
+	}
+
+	team, err := h.teams.GetTeam(ctx, teamName, false)
+	if err != nil {
+		h.respondServiceError(ctx, w, err, "failed to get team")
+		return
+	}
+	current := make(map[string]bool, len(team.Members))
+	for _, m := range team.Members {
+		current[m.UserID] = true
+	}
+	desired := make(map[string]bool, len(current))
+	for id := range current {
+		desired[id] = true
+	}
+
+	members := func(value json.RawMessage) ([]string, error) {
+		var refs []scimRef
+		if err := json.Unmarshal(value, &refs); err != nil {
+			return nil, fmt.Errorf("members must be a list of {\"value\": user id}")
+		}
+		ids := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			ids = append(ids, ref.Value)
+		}
+		return ids, nil
+	}
+	apply := func(op, attr string, value json.RawMessage) error {
+		switch strings.ToLower(attr) {
+		case "members":
+			ids, err := members(value)
+			if err != nil {
+				return err
+			}
+			if op == "replace" {
+				desired = make(map[string]bool, len(ids))
+			}
+			for _, id := range ids {
+				desired[id] = true
+			}
+		case "displayname":
+			var name string
+			if err := json.Unmarshal(value, &name); err != nil || name != teamName {
+				return errSCIMMutability
+			}
+		}
+		return nil
+	}
+
+	for _, op := range patch.Operations {
+		var err error
+		kind := strings.ToLower(op.Op)
+		switch kind {
+		case "add", "replace":
+			if op.Path != "" {
+				err = apply(kind, op.Path, op.Value)
+				break
+			}
+			var attrs map[string]json.RawMessage
+			if err = json.Unmarshal(op.Value, &attrs); err != nil {
+				err = fmt.Errorf("value must be an object when path is omitted")
+				break
+			}
+			for attr, value := range attrs {
+				if err = apply(kind, attr, value); err != nil {
+					break
+				}
+			}
+		case "remove":
+			if m := scimMemberPath.FindStringSubmatch(op.Path); m != nil {
+				delete(desired, m[1])
+				break
+			}
+			if !strings.EqualFold(op.Path, "members") {
+				err = errSCIMInvalidPath
+				break
+			}
+			if len(op.Value) == 0 || string(op.Value) == "null" {
+				desired = make(map[string]bool)
+				break
+			}
+			var ids []string
+			if ids, err = members(op.Value); err == nil {
+				for _, id := range ids {
+					delete(desired, id)
+				}
+			}
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			respondSCIMPatchError(w, err)
+			return
+		}
+	}
+
+	var add, remove []string
+	for id := range desired {
+		if !current[id] {
+			add = append(add, id)
+		}
+	}
+	for id := range current {
+		if !desired[id] {
+			remove = append(remove, id)
+		}
+	}
+
+	team, _, err = h.teams.UpdateMembers(ctx, teamName, add, remove)
+	if err != nil {
+		h.respondMemberError(ctx, w, err, "failed to patch team")
+		return
+	}
+	respondSCIM(w, http.StatusOK, toSCIMGroup(team))
+}
+
+// DeleteGroup архивирует команду вместе с участниками, как /team/archive.
+func (h *SCIMHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := mux.Vars(r)["id"]
+	if _, err := h.teams.GetTeam(ctx, teamName, false); err != nil {
+		h.respondServiceError(ctx, w, err, "failed to get team")
+		return
+	}
+	if _, _, _, err := h.teams.ArchiveTeam(ctx, teamName); err != nil {
+		h.respondServiceError(ctx, w, err, "failed to archive team")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var (
+	errSCIMMutability  = errors.New("attribute is immutable")
+	errSCIMInvalidPath = errors.New("unsupported path")
+)
+
+func respondSCIMPatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errSCIMMutability):
+		respondSCIMError(w, http.StatusBadRequest, scimMutability, err.Error())
+	case errors.Is(err, errSCIMInvalidPath):
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidPath, err.Error())
+	default:
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidValue, err.Error())
+	}
+}
+
+// respondMemberError — для групп неизвестный участник означает неверное
+// значение в запросе, а не отсутствие самой группы.
+func (h *SCIMHandler) respondMemberError(ctx context.Context, w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, service.ErrUserNotFound) {
+		respondSCIMError(w, http.StatusBadRequest, scimInvalidValue, "Unknown member user")
+		return
+	}
+	h.respondServiceError(ctx, w, err, msg)
+}
+
+func (h *SCIMHandler) respondServiceError(ctx context.Context, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		respondSCIMError(w, http.StatusNotFound, "", "User not found")
+	case errors.Is(err, service.ErrTeamNotFound):
+		respondSCIMError(w, http.StatusNotFound, "", "Group not found")
+	case errors.Is(err, service.ErrUserExists):
+		respondSCIMError(w, http.StatusConflict, scimUniqueness, "User already exists")
+	case errors.Is(err, service.ErrTeamExists):
+		respondSCIMError(w, http.StatusConflict, scimUniqueness, "Group already exists")
+	default:
+		h.logger.ErrorContext(ctx, msg, "error", err)
+		respondSCIMError(w, http.StatusInternalServerError, "", "Internal server error")
+	}
+}
+
+func toSCIMUser(u *models.User) scimUser {
+	groups := []scimRef{}
+	if u.TeamName != "" {
+		groups = append(groups, scimRef{Value: u.TeamName, Display: u.TeamName, Ref: SCIMBasePath + "/Groups/" + u.TeamName})
+	}
+	return scimUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          u.UserID,
+		UserName:    u.UserID,
+		DisplayName: u.Username,
+		Active:      u.IsActive,
+		Groups:      groups,
+		Meta:        scimMeta{ResourceType: "User", Location: SCIMBasePath + "/Users/" + u.UserID},
+	}
+}
+
+func toSCIMGroup(team *models.Team) scimGroup {
+	members := make([]scimRef, 0, len(team.Members))
+	for _, m := range team.Members {
+		members = append(members, scimRef{Value: m.UserID, Display: m.Username, Ref: SCIMBasePath + "/Users/" + m.UserID})
+	}
+	return scimGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          team.TeamName,
+		DisplayName: team.TeamName,
+		Members:     members,
+		Meta:        scimMeta{ResourceType: "Group", Location: SCIMBasePath + "/Groups/" + team.TeamName},
+	}
+}
+
+// scimFilter — поддерживается одно сравнение `атрибут eq значение`,
+// которым провайдеры ищут ресурс перед созданием.
+type scimFilter struct {
+	attr  string
+	value string
+}
+
+var scimFilterExpr = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+("(?:[^"\\]|\\.)*"|true|false)\s*$`)
+
+func parseSCIMFilter(filter string) (*scimFilter, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	m := scimFilterExpr.FindStringSubmatch(filter)
+	if m == nil {
+		return nil, fmt.Errorf("only `attribute eq value` filters are supported")
+	}
+	value := strings.ToLower(m[2])
+	if strings.HasPrefix(m[2], `"`) {
+		if err := json.Unmarshal([]byte(m[2]), &value); err != nil {
+			return nil, fmt.Errorf("invalid filter value %s", m[2])
+		}
+	}
+	return &scimFilter{attr: strings.ToLower(m[1]), value: value}, nil
+}
+
+func (f *scimFilter) matchUser(u *models.User) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	switch f.attr {
+	case "id":
+		return u.UserID == f.value, nil
+	case "username":
+		return strings.EqualFold(u.UserID, f.value), nil
+	case "displayname":
+		return strings.EqualFold(u.Username, f.value), nil
+	case "active":
+		return strconv.FormatBool(u.IsActive) == f.value, nil
+	}
+	return false, fmt.Errorf("filtering by %s is not supported", f.attr)
+}
+
+func (f *scimFilter) matchGroup(team *models.Team) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	switch f.attr {
+	case "id":
+		return team.TeamName == f.value, nil
+	case "displayname":
+		return strings.EqualFold(team.TeamName, f.value), nil
+	}
+	return false, fmt.Errorf("filtering by %s is not supported", f.attr)
+}
+
+// scimBool разбирает булево значение: часть провайдеров присылает его строкой ("False").
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("active must be a boolean")
+}
+
+// respondSCIMList отдаёт страницу по startIndex (с 1) и count.
+func respondSCIMList(w http.ResponseWriter, r *http.Request, resources []interface{}) {
+	startIndex := 1
+	if v, err := strconv.Atoi(r.URL.Query().Get("startIndex")); err == nil && v > 1 {
+		startIndex = v
+	}
+	count := service.MaxListLimit
+	if v, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && v < count {
+		count = max(v, 0)
+	}
+
+	total := len(resources)
+	from := min(startIndex-1, total)
+	to := min(from+count, total)
+	respondSCIM(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimSchemaList},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: to - from,
+		Resources:    resources[from:to],
+	})
+}
+
+func respondSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", SCIMContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("failed to encode SCIM response", "error", err)
+	}
+}
+
+func respondSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	respondSCIM(w, status, scimError{
+		Schemas:  []string{scimSchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/service"
+)
+
+type SLAHandler struct {
+	service *service.SLAService
+	logger  *slog.Logger
+}
+
+func NewSLAHandler(service *service.SLAService, logger *slog.Logger) *SLAHandler {
+	return &SLAHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *SLAHandler) SetTeamSLA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req models.TeamSLA
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	sla, err := h.service.SetTeamSLA(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSLA) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else if errors.Is(err, service.ErrTeamNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to set review SLA", "error", err, "team_name", req.TeamName)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"sla": sla})
+}
+
+func (h *SLAHandler) GetTeamSLA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get("team_name")
+
+	if teamName == "" {
+		h.logger.WarnContext(ctx, "team_name parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "team_name is required")
+		return
+	}
+
+	sla, err := h.service.GetTeamSLA(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, service.ErrSLANotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Team not found or has no review SLA")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get review SLA", "error", err, "team_name", teamName)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sla)
+}
+
+// ListOverdue отдаёт назначения с истёкшим сроком первого ревью
+func (h *SLAHandler) ListOverdue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get("team_name")
+
+	overdue, err := h.service.ListOverdue(ctx, teamName)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list overdue reviews", "error", err, "team_name", teamName)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"overdue": overdue})
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/reviewer-service/internal/models"
 	"github.com/reviewer-service/internal/service"
 )
 
@@ -19,18 +25,168 @@ func NewStatisticsHandler(service *service.StatisticsService, logger *slog.Logge
 	}
 }
 
+// GetStatistics отдаёт статистику в JSON, CSV или формате Prometheus
+// по заголовку Accept; from/to ограничивают раздел нагрузки.
 func (h *StatisticsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	stats, err := h.service.GetStatistics(ctx)
+	format, ok := negotiateStatisticsFormat(r.Header.Get("Accept"))
+	if !ok {
+		h.logger.WarnContext(ctx, "unsupported Accept for statistics", "accept", r.Header.Get("Accept"))
+		respondError(w, http.StatusNotAcceptable, "NOT_ACCEPTABLE", "supported formats: application/json, text/csv, text/plain; version=0.0.4")
+		return
+	}
+
+	q, err := parseStatisticsQuery(r.URL.Query())
+	if err != nil {
+		h.logger.WarnContext(ctx, "invalid statistics parameters", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	stats, err := h.service.GetStatistics(ctx, q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get statistics", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get statistics")
+		}
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	switch format {
+	case statisticsFormatCSV:
+		body, err := statisticsCSV(stats)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to encode statistics CSV", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get statistics")
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeCSV)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	case statisticsFormatPrometheus:
+		w.Header().Set("Content-Type", ContentTypePrometheus)
+		w.WriteHeader(http.StatusOK)
+		w.Write(statisticsPrometheus(stats))
+	default:
+		respondJSON(w, http.StatusOK, stats)
+	}
+}
+
+func parseStatisticsQuery(values url.Values) (service.StatisticsQuery, error) {
+	var q service.StatisticsQuery
+	dates := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &q.From},
+		{"to", &q.To},
+	}
+	for _, d := range dates {
+		v := values.Get(d.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("%s must be an RFC 3339 date-time", d.name)
+		}
+		*d.dst = &t
+	}
+	return q, nil
+}
+
+func (h *StatisticsHandler) GetTeamStatistics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get("team_name")
+
+	if teamName == "" {
+		h.logger.WarnContext(ctx, "team_name parameter missing")
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "team_name is required")
+		return
+	}
+
+	stats, err := h.service.GetTeamStatistics(ctx, teamName)
 	if err != nil {
-		h.logger.ErrorContext(ctx, "failed to get statistics", "error", err)
-		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get statistics")
+		if errors.Is(err, service.ErrTeamNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get team statistics", "error", err, "team_name", teamName)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get team statistics")
+		}
 		return
 	}
 
 	respondJSON(w, http.StatusOK, stats)
 }
 
+// DefaultTimingsRange — период /statistics/timings, если from не задан
+const DefaultTimingsRange = 30 * 24 * time.Hour
+
+func (h *StatisticsHandler) GetReviewTimings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	q, err := parseTimingsQuery(r.URL.Query(), time.Now().UTC())
+	if err != nil {
+		h.logger.WarnContext(ctx, "invalid timings parameters", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	timings, err := h.service.GetReviewTimings(ctx, q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else {
+			h.logger.ErrorContext(ctx, "failed to get review timings", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get review timings")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, timings)
+}
+
+// parseTimingsQuery читает from/to (RFC 3339) и bucket; без to берётся now,
+// без from — DefaultTimingsRange до to
+func parseTimingsQuery(values url.Values, now time.Time) (service.ReviewTimingsQuery, error) {
+	q := service.ReviewTimingsQuery{
+		To:     now,
+		Bucket: values.Get("bucket"),
+	}
 
+	if v := values.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("to must be an RFC 3339 date-time")
+		}
+		q.To = t
+	}
+	q.From = q.To.Add(-DefaultTimingsRange)
+	if v := values.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("from must be an RFC 3339 date-time")
+		}
+		q.From = t
+	}
 
+	switch q.Bucket {
+	case "", models.TimingBucketDay, models.TimingBucketWeek:
+	default:
+		return q, fmt.Errorf("bucket must be day or week")
+	}
+
+	if v := values.Get("working_hours"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("working_hours must be a boolean")
+		}
+		q.WorkingHours = b
+	}
+
+	return q, nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// Форматы /statistics, выбираемые по заголовку Accept
+const (
+	ContentTypeJSON       = "application/json"
+	ContentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+const (
+	statisticsFormatJSON       = "json"
+	statisticsFormatCSV        = "csv"
+	statisticsFormatPrometheus = "prometheus"
+)
+
+// statisticsLoadColumns — колонки CSV-выгрузки нагрузки: строка на команду
+// (scope=team) и на ревьювера (scope=reviewer). Итоги по командам,
+// пользователям, PR и назначениям в CSV не попадают: у них другие колонки,
+// и они есть в JSON и Prometheus.
+var statisticsLoadColumns = []string{"scope", "team_name", "user_id", "open_assignments", "total_assignments"}
+
+// negotiateStatisticsFormat выбирает формат по Accept с учётом q-значений.
+// Пустой Accept и */* дают JSON; text/plain — формат Prometheus версии 0.0.4.
+func negotiateStatisticsFormat(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return statisticsFormatJSON, true
+	}
+
+	type candidate struct {
+		format string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		var format string
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			format = statisticsFormatJSON
+		case "text/csv":
+			format = statisticsFormatCSV
+		case "text/plain":
+			if v, ok := params["version"]; ok && v != "0.0.4" {
+				continue
+			}
+			format = statisticsFormatPrometheus
+		default:
+			continue
+		}
+		candidates = append(candidates, candidate{format: format, q: q})
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].format, true
+}
+
+func statisticsCSV(stats *models.Statistics) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(statisticsLoadColumns); err != nil {
+		return nil, err
+	}
+	if stats.Load != nil {
+		for _, t := range stats.Load.ByTeam {
+			if err := w.Write([]string{"team", t.TeamName, "", strconv.Itoa(t.OpenAssignments), strconv.Itoa(t.TotalAssignments)}); err != nil {
+				return nil, err
+			}
+		}
+		for _, r := range stats.Load.ByReviewer {
+			if err := w.Write([]string{"reviewer", r.TeamName, r.UserID, strconv.Itoa(r.OpenAssignments), strconv.Itoa(r.TotalAssignments)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// statisticsPrometheus пишет статистику в текстовом формате Prometheus 0.0.4.
+// Все метрики — gauge: значения могут уменьшаться при архивации и merge.
+func statisticsPrometheus(stats *models.Statistics) []byte {
+	var buf bytes.Buffer
+	metric := func(name, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	sample := func(name string, value interface{}, labels ...string) {
+		buf.WriteString(name)
+		if len(labels) > 0 {
+			buf.WriteByte('{')
+			for i := 0; i < len(labels); i += 2 {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				fmt.Fprintf(&buf, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+			}
+			buf.WriteByte('}')
+		}
+		fmt.Fprintf(&buf, " %v\n", value)
+	}
+
+	metric("reviewer_service_teams", "Non-archived teams.")
+	sample("reviewer_service_teams", stats.Teams.Total)
+
+	metric("reviewer_service_users", "Non-archived users by activity.")
+	sample("reviewer_service_users", stats.Users.Active, "state", "active")
+	sample("reviewer_service_users", stats.Users.Inactive, "state", "inactive")
+
+	metric("reviewer_service_pull_requests", "Pull requests by status.")
+	sample("reviewer_service_pull_requests", stats.PullRequests.Open, "status", "OPEN")
+	sample("reviewer_service_pull_requests", stats.PullRequests.Merged, "status", "MERGED")
+
+	metric("reviewer_service_review_assignments", "Current reviewer assignments, including merged pull requests.")
+	sample("reviewer_service_review_assignments", stats.ReviewAssignments.Total)
+
+	if stats.Load != nil {
+		metric("reviewer_service_team_open_review_assignments", "Reviewer assignments on open pull requests by reviewer team.")
+		for _, t := range stats.Load.ByTeam {
+			sample("reviewer_service_team_open_review_assignments", t.OpenAssignments, "team", t.TeamName)
+		}
+		metric("reviewer_service_team_review_assignments", "All reviewer assignments by reviewer team.")
+		for _, t := range stats.Load.ByTeam {
+			sample("reviewer_service_team_review_assignments", t.TotalAssignments, "team", t.TeamName)
+		}
+		metric("reviewer_service_reviewer_open_review_assignments", "Reviewer assignments on open pull requests by reviewer.")
+		for _, r := range stats.Load.ByReviewer {
+			sample("reviewer_service_reviewer_open_review_assignments", r.OpenAssignments, "user_id", r.UserID, "team", r.TeamName)
+		}
+		metric("reviewer_service_reviewer_review_assignments", "All reviewer assignments by reviewer.")
+		for _, r := range stats.Load.ByReviewer {
+			sample("reviewer_service_reviewer_review_assignments", r.TotalAssignments, "user_id", r.UserID, "team", r.TeamName)
+		}
+	}
+
+	metric("reviewer_service_statistics_generated_timestamp_seconds", "Unix time the statistics snapshot was computed.")
+	sample("reviewer_service_statistics_generated_timestamp_seconds", stats.GeneratedAt.Unix())
+
+	return buf.Bytes()
+}
+
+// escapeLabelValue экранирует значение метки по правилам текстового формата
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	reviewerservice "github.com/reviewer-service"
+	"github.com/reviewer-service/internal/models"
+)
+
+func TestNegotiateStatisticsFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", statisticsFormatJSON, true},
+		{"*/*", statisticsFormatJSON, true},
+		{"application/json", statisticsFormatJSON, true},
+		{"text/csv", statisticsFormatCSV, true},
+		{"text/plain; version=0.0.4", statisticsFormatPrometheus, true},
+		{"text/plain", statisticsFormatPrometheus, true},
+		{"application/json;q=0.5, text/csv", statisticsFormatCSV, true},
+		{"text/csv;q=0.2, text/plain;version=0.0.4;q=0.9", statisticsFormatPrometheus, true},
+		{"application/xml, text/csv;q=0", "", false},
+		{"text/plain; version=1.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := negotiateStatisticsFormat(tt.accept)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("negotiateStatisticsFormat(%q) = %q, %v; want %q, %v", tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStatisticsExport(t *testing.T) {
+	stats := &models.Statistics{
+		GeneratedAt: time.Unix(1700000000, 0),
+		Load: &models.ReviewLoad{
+			ByTeam:     []models.TeamLoad{{TeamName: "backend", OpenAssignments: 2, TotalAssignments: 5}},
+			ByReviewer: []models.ReviewerLoad{{UserID: "u\"1", TeamName: "backend", OpenAssignments: 2, TotalAssignments: 5}},
+		},
+	}
+	stats.Teams.Total = 1
+	stats.PullRequests.Total = 7
+	stats.ReviewAssignments.Total = 5
+
+	csvBody, err := statisticsCSV(stats)
+	if err != nil {
+		t.Fatalf("statisticsCSV: %v", err)
+	}
+	wantCSV := "scope,team_name,user_id,open_assignments,total_assignments\n" +
+		"team,backend,,2,5\n" +
+		"reviewer,backend,\"u\"\"1\",2,5\n"
+	if string(csvBody) != wantCSV {
+		t.Errorf("CSV = %q, want %q", csvBody, wantCSV)
+	}
+	// Колонки CSV описаны в спецификации; итоги в выгрузку не входят
+	if !strings.Contains(string(reviewerservice.OpenAPISpec), strings.Join(statisticsLoadColumns, ",")+"\n") {
+		t.Errorf("OpenAPI CSV example does not list columns %v", statisticsLoadColumns)
+	}
+
+	prom := string(statisticsPrometheus(stats))
+	for _, line := range []string{
+		"# TYPE reviewer_service_teams gauge\n",
+		"reviewer_service_teams 1\n",
+		`reviewer_service_team_open_review_assignments{team="backend"} 2` + "\n",
+		`reviewer_service_reviewer_review_assignments{user_id="u\"1",team="backend"} 5` + "\n",
+		"reviewer_service_statistics_generated_timestamp_seconds 1700000000\n",
+	} {
+		if !strings.Contains(prom, line) {
+			t.Errorf("Prometheus output lacks %q:\n%s", line, prom)
+		}
+	}
+}
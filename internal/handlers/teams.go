@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/reviewer-service/internal/models"
 	"github.com/reviewer-service/internal/service"
@@ -44,7 +46,7 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	created, err := h.service.GetTeam(ctx, team.TeamName)
+	created, err := h.service.GetTeam(ctx, team.TeamName, false)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "failed to get created team", "error", err, "team_name", team.TeamName)
 		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
@@ -65,7 +67,17 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team, err := h.service.GetTeam(ctx, teamName)
+	includeArchived := false
+	if v := r.URL.Query().Get("include_archived"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "include_archived must be a boolean")
+			return
+		}
+		includeArchived = parsed
+	}
+
+	team, err := h.service.GetTeam(ctx, teamName, includeArchived)
 	if err != nil {
 		if errors.Is(err, service.ErrTeamNotFound) {
 			// OpenAPI: 404 Not Found с кодом NOT_FOUND
@@ -110,3 +122,189 @@ func (h *TeamHandler) DeactivateTeamMembers(w http.ResponseWriter, r *http.Reque
 
 	respondJSON(w, http.StatusOK, result)
 }
+
+func (h *TeamHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		IsActive *bool  `json:"is_active"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	team, reassigned, err := h.service.AddMember(ctx, req.TeamName, req.UserID, req.Username, req.IsActive)
+	if err != nil {
+		h.respondMembershipError(ctx, w, err, "failed to add team member", req.TeamName, req.UserID)
+		return
+	}
+
+	// OpenAPI: 200 OK с { "team": {...}, "reassigned_prs": N }
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"team":           team,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	team, reassigned, err := h.service.RemoveMember(ctx, req.TeamName, req.UserID)
+	if err != nil {
+		h.respondMembershipError(ctx, w, err, "failed to remove team member", req.TeamName, req.UserID)
+		return
+	}
+
+	// OpenAPI: 200 OK с { "team": {...}, "reassigned_prs": N }
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"team":           team,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) MoveUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	user, reassigned, err := h.service.MoveUser(ctx, req.UserID, req.TeamName)
+	if err != nil {
+		h.respondMembershipError(ctx, w, err, "failed to move user", req.TeamName, req.UserID)
+		return
+	}
+
+	// OpenAPI: 200 OK с { "user": {...}, "reassigned_prs": N }
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":           user,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) ArchiveTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		TeamName string `json:"team_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	team, archivedUsers, reassigned, err := h.service.ArchiveTeam(ctx, req.TeamName)
+	if err != nil {
+		h.respondMembershipError(ctx, w, err, "failed to archive team", req.TeamName, "")
+		return
+	}
+
+	// OpenAPI: 200 OK с { "team": {...}, "archived_users": [...], "reassigned_prs": N } (идемпотентно)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"team":           team,
+		"archived_users": archivedUsers,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) ArchiveUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	user, reassigned, err := h.service.ArchiveUser(ctx, req.UserID)
+	if err != nil {
+		h.respondMembershipError(ctx, w, err, "failed to archive user", "", req.UserID)
+		return
+	}
+
+	// OpenAPI: 200 OK с { "user": {...}, "reassigned_prs": N } (идемпотентно)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":           user,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) SyncTeams(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req struct {
+		Teams []models.Team `json:"teams"`
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "dry_run must be a boolean")
+			return
+		}
+		dryRun = parsed
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	changes, reassigned, err := h.service.SyncTeams(ctx, req.Teams, dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidManifest) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		} else {
+			h.logger.ErrorContext(ctx, "failed to sync teams", "error", err)
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	// OpenAPI: 200 OK с { "dry_run": bool, "changes": [...], "reassigned_prs": N }
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"dry_run":        dryRun,
+		"changes":        changes,
+		"reassigned_prs": reassigned,
+	})
+}
+
+func (h *TeamHandler) respondMembershipError(ctx context.Context, w http.ResponseWriter, err error, msg, teamName, userID string) {
+	switch {
+	case errors.Is(err, service.ErrTeamNotFound):
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Team not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found")
+	case errors.Is(err, service.ErrInvalidTeamMember):
+		respondError(w, http.StatusBadRequest, "INVALID_TEAM_MEMBER", "User is not a member of the specified team")
+	default:
+		h.logger.ErrorContext(ctx, msg, "error", err, "team_name", teamName, "user_id", userID)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	}
+}
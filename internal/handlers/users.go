@@ -3,8 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/reviewer-service/internal/service"
 )
@@ -61,13 +64,23 @@ func (h *UserHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reviews, err := h.service.GetUserReviews(ctx, userID)
+	q, err := parseReviewsQuery(r.URL.Query())
 	if err != nil {
-		if errors.Is(err, service.ErrUserNotFound) {
+		h.logger.WarnContext(ctx, "invalid user reviews parameters", "error", err, "user_id", userID)
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	reviews, next, err := h.service.GetUserReviews(ctx, userID, q)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
 			// OpenAPI: возвращает 200 даже если пользователя нет, с пустым списком
 			// Но для консистентности можно возвращать 404
 			respondError(w, http.StatusNotFound, "NOT_FOUND", "User not found")
-		} else {
+		case errors.Is(err, service.ErrInvalidCursor), errors.Is(err, service.ErrInvalidSort), errors.Is(err, service.ErrInvalidStatus):
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		default:
 			// Ошибки БД или другие ошибки репозитория
 			h.logger.ErrorContext(ctx, "failed to get user reviews", "error", err, "user_id", userID)
 			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
@@ -75,9 +88,40 @@ func (h *UserHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// OpenAPI: 200 OK с { "user_id": "...", "pull_requests": [...] }
+	// OpenAPI: 200 OK с { "user_id": "...", "pull_requests": [...], "next_cursor": "..." | null }
+	var nextCursor interface{}
+	if next != "" {
+		nextCursor = next
+	}
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"user_id":       userID,
 		"pull_requests": reviews,
+		"next_cursor":   nextCursor,
 	})
 }
+
+func parseReviewsQuery(values url.Values) (service.UserReviewsQuery, error) {
+	q := service.UserReviewsQuery{
+		Status: values.Get("status"),
+		SortBy: values.Get("sort"),
+		Cursor: values.Get("cursor"),
+	}
+
+	if v := values.Get("include_authored"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, fmt.Errorf("include_authored must be a boolean")
+		}
+		q.IncludeAuthored = include
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > service.MaxListLimit {
+			return q, fmt.Errorf("limit must be an integer from 1 to %d", service.MaxListLimit)
+		}
+		q.Limit = limit
+	}
+
+	return q, nil
+}
@@ -10,20 +10,21 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
+	"github.com/reviewer-service/internal/config"
 	"github.com/reviewer-service/internal/handlers"
-	"github.com/reviewer-service/internal/middleware"
 	"github.com/reviewer-service/internal/models"
-	"github.com/reviewer-service/internal/repository"
-	"github.com/reviewer-service/internal/service"
+	"github.com/reviewer-service/internal/server"
+	"github.com/reviewer-service/internal/storage"
 )
 
-var testDB *sql.DB
-
 func setupTestDB(t *testing.T) *sql.DB {
 	host := getEnv("DB_HOST", "localhost")
 	port := getEnv("DB_PORT", "5432")
@@ -59,6 +60,8 @@ func getEnv(key, defaultValue string) string {
 
 func cleanupDB(t *testing.T, db *sql.DB) {
 	queries := []string{
+		"DELETE FROM idempotency_keys",
+		"DELETE FROM pr_events",
 		"DELETE FROM pr_reviewers",
 		"DELETE FROM pull_requests",
 		"DELETE FROM users",
@@ -72,424 +75,1045 @@ func cleanupDB(t *testing.T, db *sql.DB) {
 	}
 }
 
-func setupTestServer(db *sql.DB) *httptest.Server {
+func migrated(t *testing.T, st *storage.Storage) *storage.Storage {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
-
-	teamRepo := repository.NewTeamRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	prRepo := repository.NewPullRequestRepository(db)
-	statsRepo := repository.NewStatisticsRepository(db)
-
-	teamService := service.NewTeamService(teamRepo, userRepo, prRepo, db, logger)
-	userService := service.NewUserService(userRepo, prRepo, logger)
-	prService := service.NewPullRequestService(prRepo, userRepo, logger)
-	statsService := service.NewStatisticsService(statsRepo, logger)
-
-	teamHandler := handlers.NewTeamHandler(teamService, logger)
-	userHandler := handlers.NewUserHandler(userService, logger)
-	prHandler := handlers.NewPullRequestHandler(prService, logger)
-	statsHandler := handlers.NewStatisticsHandler(statsService, logger)
-
-	r := mux.NewRouter()
-	r.Use(middleware.LoggingMiddleware(logger))
-
-	r.HandleFunc("/team/add", teamHandler.AddTeam).Methods("POST")
-	r.HandleFunc("/team/get", teamHandler.GetTeam).Methods("GET")
-	r.HandleFunc("/team/deactivateMembers", teamHandler.DeactivateTeamMembers).Methods("POST")
-	r.HandleFunc("/users/setIsActive", userHandler.SetUserActive).Methods("POST")
-	r.HandleFunc("/users/getReview", userHandler.GetUserReviews).Methods("GET")
-	r.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods("POST")
-	r.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods("POST")
-	r.HandleFunc("/pullRequest/reassign", prHandler.ReassignReviewer).Methods("POST")
-	r.HandleFunc("/statistics", statsHandler.GetStatistics).Methods("GET")
-
-	return httptest.NewServer(r)
+	if err := st.Migrate(context.Background(), logger); err != nil {
+		st.Close()
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	return st
 }
 
-func TestE2E_FullPRFlow(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
+func setupTestServer(t *testing.T, st *storage.Storage) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	cfg := &config.Config{
+		Idempotency: config.IdempotencyConfig{TTL: time.Hour},
+		SCIM:        config.SCIMConfig{Token: scimToken},
 	}
+	router, err := server.NewRouter(st, cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	return httptest.NewServer(router)
+}
 
-	db := setupTestDB(t)
-	defer db.Close()
-	cleanupDB(t, db)
-
-	srv := setupTestServer(db)
-	defer srv.Close()
+type backend struct {
+	name  string
+	setup func(t *testing.T) *storage.Storage
+}
 
-	// Шаг 1: Создание команды
-	teamPayload := map[string]interface{}{
-		"team_name": "backend",
-		"members": []map[string]interface{}{
-			{"user_id": "user-1", "username": "Alice", "is_active": true},
-			{"user_id": "user-2", "username": "Bob", "is_active": true},
-			{"user_id": "user-3", "username": "Charlie", "is_active": true},
+// backends — хранилища, против которых прогоняются все сценарии.
+// Postgres пропускается в коротком режиме и при недоступной БД.
+var backends = []backend{
+	{
+		name: storage.DriverMemory,
+		setup: func(t *testing.T) *storage.Storage {
+			return storage.NewMemory()
 		},
-	}
+	},
+	{
+		name: storage.DriverSQLite,
+		setup: func(t *testing.T) *storage.Storage {
+			db, err := storage.OpenSQLite(filepath.Join(t.TempDir(), "reviewers.db"))
+			if err != nil {
+				t.Fatalf("Failed to open sqlite database: %v", err)
+			}
+			return migrated(t, storage.NewSQL(db, storage.DriverSQLite))
+		},
+	},
+	{
+		name: storage.DriverPostgres,
+		setup: func(t *testing.T) *storage.Storage {
+			if testing.Short() {
+				t.Skip("Skipping postgres integration test in short mode")
+			}
+			db := setupTestDB(t)
+			st := migrated(t, storage.NewSQL(db, storage.DriverPostgres))
+			cleanupDB(t, db)
+			return st
+		},
+	},
+}
 
-	resp := makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
-	if resp.StatusCode != http.StatusCreated {
-		t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, readBody(t, resp))
-	}
+func runOnBackends(t *testing.T, scenario func(t *testing.T, srv *httptest.Server)) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			st := b.setup(t)
+			defer st.Close()
 
-	var respWrapper struct {
-		Team models.Team `json:"team"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&respWrapper); err != nil {
-		t.Fatalf("Failed to decode team response: %v, body: %s", err, readBody(t, resp))
-	}
-	if respWrapper.Team.TeamName != "backend" || len(respWrapper.Team.Members) != 3 {
-		t.Fatalf("Invalid team response: %+v", respWrapper.Team)
-	}
+			srv := setupTestServer(t, st)
+			defer srv.Close()
 
-	// Шаг 2: Создание PR
-	prPayload := map[string]string{
-		"pull_request_id":   "pr-1",
-		"pull_request_name": "Add feature",
-		"author_id":         "user-1",
+			scenario(t, srv)
+		})
 	}
+}
 
-	resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
-	if resp.StatusCode != http.StatusCreated {
-		t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, readBody(t, resp))
-	}
+func TestE2E_FullPRFlow(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		// Шаг 1: Создание команды
+		teamPayload := map[string]interface{}{
+			"team_name": "backend",
+			"members": []map[string]interface{}{
+				{"user_id": "user-1", "username": "Alice", "is_active": true},
+				{"user_id": "user-2", "username": "Bob", "is_active": true},
+				{"user_id": "user-3", "username": "Charlie", "is_active": true},
+			},
+		}
 
-	var prRespWrapper struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
-		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp := prRespWrapper.PR
-	if prResp.Status != "OPEN" {
-		t.Fatalf("Expected OPEN status, got %s", prResp.Status)
-	}
-	if len(prResp.AssignedReviewers) == 0 || len(prResp.AssignedReviewers) > 2 {
-		t.Fatalf("Expected 1-2 reviewers, got %d", len(prResp.AssignedReviewers))
-	}
-	if contains(prResp.AssignedReviewers, "user-1") {
-		t.Fatal("Author should not be assigned as reviewer")
-	}
+		resp := makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
 
-	// Шаг 3: Получение PR пользователя
-	reviewer := prResp.AssignedReviewers[0]
-	resp = makeRequest(t, srv.URL+"/users/getReview?user_id="+reviewer, "GET", nil)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected 200, got %d", resp.StatusCode)
-	}
+		var respWrapper struct {
+			Team models.Team `json:"team"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&respWrapper); err != nil {
+			t.Fatalf("Failed to decode team response: %v, body: %s", err, readBody(t, resp))
+		}
+		if respWrapper.Team.TeamName != "backend" || len(respWrapper.Team.Members) != 3 {
+			t.Fatalf("Invalid team response: %+v", respWrapper.Team)
+		}
 
-	var reviewResp struct {
-		PullRequests []models.PullRequestShort `json:"pull_requests"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&reviewResp); err != nil {
-		t.Fatalf("Failed to decode review response: %v, body: %s", err, readBody(t, resp))
-	}
-	if len(reviewResp.PullRequests) != 1 {
-		t.Fatalf("Expected 1 PR, got %d", len(reviewResp.PullRequests))
-	}
+		// Шаг 2: Создание PR
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-1",
+			"pull_request_name": "Add feature",
+			"author_id":         "user-1",
+		}
 
-	// Шаг 4: Merge PR
-	mergePayload := map[string]string{"pull_request_id": "pr-1"}
-	resp = makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
-	}
+		resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
 
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
-		t.Fatalf("Failed to decode merge PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp = prRespWrapper.PR
-	if prResp.Status != "MERGED" {
-		t.Fatalf("Expected MERGED status, got %s", prResp.Status)
-	}
+		var prRespWrapper struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp := prRespWrapper.PR
+		if prResp.Status != "OPEN" {
+			t.Fatalf("Expected OPEN status, got %s", prResp.Status)
+		}
+		if len(prResp.AssignedReviewers) == 0 || len(prResp.AssignedReviewers) > 2 {
+			t.Fatalf("Expected 1-2 reviewers, got %d", len(prResp.AssignedReviewers))
+		}
+		if contains(prResp.AssignedReviewers, "user-1") {
+			t.Fatal("Author should not be assigned as reviewer")
+		}
 
-	// Шаг 5: Попытка переназначения после merge (должна вернуть ошибку)
-	reassignPayload := map[string]string{
-		"pull_request_id": "pr-1",
-		"old_user_id":     reviewer,
-	}
-	resp = makeRequest(t, srv.URL+"/pullRequest/reassign", "POST", reassignPayload)
-	if resp.StatusCode != http.StatusConflict {
-		t.Fatalf("Expected 409, got %d", resp.StatusCode)
-	}
+		// Шаг 3: Получение PR пользователя
+		reviewer := prResp.AssignedReviewers[0]
+		resp = makeRequest(t, srv.URL+"/users/getReview?user_id="+reviewer, "GET", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
 
-	// Шаг 6: Проверка статистики
-	resp = makeRequest(t, srv.URL+"/statistics", "GET", nil)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected 200, got %d", resp.StatusCode)
-	}
+		var reviewResp struct {
+			PullRequests []models.PullRequestShort `json:"pull_requests"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&reviewResp); err != nil {
+			t.Fatalf("Failed to decode review response: %v, body: %s", err, readBody(t, resp))
+		}
+		if len(reviewResp.PullRequests) != 1 {
+			t.Fatalf("Expected 1 PR, got %d", len(reviewResp.PullRequests))
+		}
 
-	var stats models.Statistics
-	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
-		t.Fatalf("Failed to decode statistics response: %v, body: %s", err, readBody(t, resp))
-	}
-	if stats.Teams.Total != 1 {
-		t.Errorf("Expected 1 team, got %d", stats.Teams.Total)
-	}
-	if stats.Users.Total != 3 {
-		t.Errorf("Expected 3 users, got %d", stats.Users.Total)
-	}
-	if stats.PullRequests.Total != 1 {
-		t.Errorf("Expected 1 PR, got %d", stats.PullRequests.Total)
-	}
+		// Шаг 4: Merge PR
+		mergePayload := map[string]string{"pull_request_id": "pr-1"}
+		resp = makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode merge PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp = prRespWrapper.PR
+		if prResp.Status != "MERGED" {
+			t.Fatalf("Expected MERGED status, got %s", prResp.Status)
+		}
+
+		// Шаг 5: Попытка переназначения после merge (должна вернуть ошибку)
+		reassignPayload := map[string]string{
+			"pull_request_id": "pr-1",
+			"old_user_id":     reviewer,
+		}
+		resp = makeRequest(t, srv.URL+"/pullRequest/reassign", "POST", reassignPayload)
+		if resp.StatusCode != http.StatusConflict {
+			t.Fatalf("Expected 409, got %d", resp.StatusCode)
+		}
+
+		// Шаг 6: Проверка статистики
+		resp = makeRequest(t, srv.URL+"/statistics", "GET", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats models.Statistics
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			t.Fatalf("Failed to decode statistics response: %v, body: %s", err, readBody(t, resp))
+		}
+		if stats.Teams.Total != 1 {
+			t.Errorf("Expected 1 team, got %d", stats.Teams.Total)
+		}
+		if stats.Users.Total != 3 {
+			t.Errorf("Expected 3 users, got %d", stats.Users.Total)
+		}
+		if stats.PullRequests.Total != 1 {
+			t.Errorf("Expected 1 PR, got %d", stats.PullRequests.Total)
+		}
+	})
 }
 
 func TestE2E_ReassignReviewer(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
-
-	db := setupTestDB(t)
-	defer db.Close()
-	cleanupDB(t, db)
-
-	srv := setupTestServer(db)
-	defer srv.Close()
-
-	// Создание команды
-	teamPayload := map[string]interface{}{
-		"team_name": "frontend",
-		"members": []map[string]interface{}{
-			{"user_id": "dev-1", "username": "Dev1", "is_active": true},
-			{"user_id": "dev-2", "username": "Dev2", "is_active": true},
-			{"user_id": "dev-3", "username": "Dev3", "is_active": true},
-			{"user_id": "dev-4", "username": "Dev4", "is_active": true},
-		},
-	}
-	makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		// Создание команды
+		teamPayload := map[string]interface{}{
+			"team_name": "frontend",
+			"members": []map[string]interface{}{
+				{"user_id": "dev-1", "username": "Dev1", "is_active": true},
+				{"user_id": "dev-2", "username": "Dev2", "is_active": true},
+				{"user_id": "dev-3", "username": "Dev3", "is_active": true},
+				{"user_id": "dev-4", "username": "Dev4", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
 
-	// Создание PR
-	prPayload := map[string]string{
-		"pull_request_id":   "pr-reassign",
-		"pull_request_name": "Feature X",
-		"author_id":         "dev-1",
-	}
-	resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
-	var prRespWrapper struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
-		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp := prRespWrapper.PR
+		// Создание PR
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-reassign",
+			"pull_request_name": "Feature X",
+			"author_id":         "dev-1",
+		}
+		resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		var prRespWrapper struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp := prRespWrapper.PR
 
-	if len(prResp.AssignedReviewers) == 0 {
-		t.Fatal("Expected at least 1 reviewer")
-	}
+		if len(prResp.AssignedReviewers) == 0 {
+			t.Fatal("Expected at least 1 reviewer")
+		}
 
-	oldReviewer := prResp.AssignedReviewers[0]
+		oldReviewer := prResp.AssignedReviewers[0]
 
-	// Переназначение ревьювера
-	reassignPayload := map[string]string{
-		"pull_request_id": "pr-reassign",
-		"old_user_id":     oldReviewer,
-	}
-	resp = makeRequest(t, srv.URL+"/pullRequest/reassign", "POST", reassignPayload)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
-	}
+		// Переназначение ревьювера
+		reassignPayload := map[string]string{
+			"pull_request_id": "pr-reassign",
+			"old_user_id":     oldReviewer,
+		}
+		resp = makeRequest(t, srv.URL+"/pullRequest/reassign", "POST", reassignPayload)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
 
-	var reassignResp struct {
-		PR         models.PullRequest `json:"pr"`
-		ReplacedBy string             `json:"replaced_by"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&reassignResp); err != nil {
-		t.Fatalf("Failed to decode reassign response: %v, body: %s", err, readBody(t, resp))
-	}
+		var reassignResp struct {
+			PR         models.PullRequest `json:"pr"`
+			ReplacedBy string             `json:"replaced_by"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&reassignResp); err != nil {
+			t.Fatalf("Failed to decode reassign response: %v, body: %s", err, readBody(t, resp))
+		}
 
-	if reassignResp.ReplacedBy == oldReviewer {
-		t.Error("New reviewer should be different from old reviewer")
-	}
-	if contains(reassignResp.PR.AssignedReviewers, oldReviewer) {
-		t.Error("Old reviewer should be removed")
-	}
-	if !contains(reassignResp.PR.AssignedReviewers, reassignResp.ReplacedBy) {
-		t.Error("New reviewer should be assigned")
-	}
+		if reassignResp.ReplacedBy == oldReviewer {
+			t.Error("New reviewer should be different from old reviewer")
+		}
+		if contains(reassignResp.PR.AssignedReviewers, oldReviewer) {
+			t.Error("Old reviewer should be removed")
+		}
+		if !contains(reassignResp.PR.AssignedReviewers, reassignResp.ReplacedBy) {
+			t.Error("New reviewer should be assigned")
+		}
+	})
 }
 
 func TestE2E_InactiveUserNotAssigned(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		// Создание команды с 1 активным и 1 неактивным
+		teamPayload := map[string]interface{}{
+			"team_name": "team-inactive",
+			"members": []map[string]interface{}{
+				{"user_id": "active-1", "username": "Active", "is_active": true},
+				{"user_id": "inactive-1", "username": "Inactive", "is_active": false},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
 
-	db := setupTestDB(t)
-	defer db.Close()
-	cleanupDB(t, db)
+		// Создание PR от активного пользователя
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-inactive",
+			"pull_request_name": "Test",
+			"author_id":         "active-1",
+		}
+		resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		var prRespWrapper struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp := prRespWrapper.PR
 
-	srv := setupTestServer(db)
-	defer srv.Close()
+		// Не должно быть ревьюверов (автор - единственный активный)
+		if len(prResp.AssignedReviewers) != 0 {
+			t.Errorf("Expected 0 reviewers, got %d (inactive should not be assigned)", len(prResp.AssignedReviewers))
+		}
+	})
+}
 
-	// Создание команды с 1 активным и 1 неактивным
-	teamPayload := map[string]interface{}{
-		"team_name": "team-inactive",
-		"members": []map[string]interface{}{
-			{"user_id": "active-1", "username": "Active", "is_active": true},
-			{"user_id": "inactive-1", "username": "Inactive", "is_active": false},
-		},
-	}
-	makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+func TestE2E_DeactivateTeamMembers(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		// Создание команды
+		teamPayload := map[string]interface{}{
+			"team_name": "deactivate-team",
+			"members": []map[string]interface{}{
+				{"user_id": "lead-1", "username": "Lead", "is_active": true},
+				{"user_id": "member-1", "username": "Member1", "is_active": true},
+				{"user_id": "member-2", "username": "Member2", "is_active": true},
+				{"user_id": "member-3", "username": "Member3", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
 
-	// Создание PR от активного пользователя
-	prPayload := map[string]string{
-		"pull_request_id":   "pr-inactive",
-		"pull_request_name": "Test",
-		"author_id":         "active-1",
-	}
-	resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
-	var prRespWrapper struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
-		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp := prRespWrapper.PR
+		// Создание PR
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-deactivate",
+			"pull_request_name": "Test",
+			"author_id":         "lead-1",
+		}
+		resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		var prRespWrapper struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp := prRespWrapper.PR
 
-	// Не должно быть ревьюверов (автор - единственный активный)
-	if len(prResp.AssignedReviewers) != 0 {
-		t.Errorf("Expected 0 reviewers, got %d (inactive should not be assigned)", len(prResp.AssignedReviewers))
-	}
+		if len(prResp.AssignedReviewers) == 0 {
+			t.Fatal("Expected reviewers to be assigned")
+		}
+
+		// Деактивация большинства членов команды (оставляем member-3)
+		deactivatePayload := map[string]interface{}{
+			"team_name": "deactivate-team",
+			"user_ids":  []string{"member-1", "member-2"},
+		}
+		resp = makeRequest(t, srv.URL+"/team/deactivateMembers", "POST", deactivatePayload)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+
+		// Создание нового PR (должен быть назначен только member-3)
+		prPayload2 := map[string]string{
+			"pull_request_id":   "pr-deactivate-2",
+			"pull_request_name": "Test2",
+			"author_id":         "lead-1",
+		}
+		resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload2)
+		var prRespWrapper2 struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper2); err != nil {
+			t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+		}
+		prResp2 := prRespWrapper2.PR
+
+		if len(prResp2.AssignedReviewers) != 1 {
+			t.Errorf("Expected 1 reviewer (member-3), got %d", len(prResp2.AssignedReviewers))
+		} else if prResp2.AssignedReviewers[0] != "member-3" {
+			t.Errorf("Expected member-3, got %s", prResp2.AssignedReviewers[0])
+		}
+	})
 }
 
-func TestE2E_DeactivateTeamMembers(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+// Кандидаты на замену перебираются по user_id, и автор PR не становится
+// его ревьювером, даже если очередь дошла до него
+func TestE2E_DeactivateTeamMembers_SkipsAuthor(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		teamPayload := map[string]interface{}{
+			"team_name": "handover-team",
+			"members": []map[string]interface{}{
+				{"user_id": "a-author", "username": "Author", "is_active": true},
+				{"user_id": "b-reviewer", "username": "Reviewer1", "is_active": true},
+				{"user_id": "c-reviewer", "username": "Reviewer2", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
 
-	db := setupTestDB(t)
-	defer db.Close()
-	cleanupDB(t, db)
-
-	srv := setupTestServer(db)
-	defer srv.Close()
-
-	// Создание команды
-	teamPayload := map[string]interface{}{
-		"team_name": "deactivate-team",
-		"members": []map[string]interface{}{
-			{"user_id": "lead-1", "username": "Lead", "is_active": true},
-			{"user_id": "member-1", "username": "Member1", "is_active": true},
-			{"user_id": "member-2", "username": "Member2", "is_active": true},
-			{"user_id": "member-3", "username": "Member3", "is_active": true},
-		},
-	}
-	makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-handover",
+			"pull_request_name": "Handover",
+			"author_id":         "a-author",
+		}
+		resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp.Body.Close()
 
-	// Создание PR
-	prPayload := map[string]string{
-		"pull_request_id":   "pr-deactivate",
-		"pull_request_name": "Test",
-		"author_id":         "lead-1",
-	}
-	resp := makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
-	var prRespWrapper struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
-		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp := prRespWrapper.PR
+		// Остаются a-author и c-reviewer; первым по user_id идёт автор
+		deactivatePayload := map[string]interface{}{
+			"team_name": "handover-team",
+			"user_ids":  []string{"b-reviewer"},
+		}
+		resp = makeRequest(t, srv.URL+"/team/deactivateMembers", "POST", deactivatePayload)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp.Body.Close()
 
-	if len(prResp.AssignedReviewers) == 0 {
-		t.Fatal("Expected reviewers to be assigned")
-	}
+		resp = makeRequest(t, srv.URL+"/pullRequest/get?pull_request_id=pr-handover", "GET", nil)
+		var prRespWrapper struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper); err != nil {
+			t.Fatalf("Failed to decode PR response: %v", err)
+		}
+		resp.Body.Close()
 
-	// Деактивация большинства членов команды (оставляем member-3)
-	deactivatePayload := map[string]interface{}{
-		"team_name": "deactivate-team",
-		"user_ids":  []string{"member-1", "member-2"},
-	}
-	resp = makeRequest(t, srv.URL+"/team/deactivateMembers", "POST", deactivatePayload)
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
-	}
+		if got := prRespWrapper.PR.AssignedReviewers; !reflect.DeepEqual(got, []string{"c-reviewer"}) {
+			t.Errorf("Expected only c-reviewer to remain, got %v", got)
+		}
+	})
+}
 
-	// Создание нового PR (должен быть назначен только member-3)
-	prPayload2 := map[string]string{
-		"pull_request_id":   "pr-deactivate-2",
-		"pull_request_name": "Test2",
-		"author_id":         "lead-1",
-	}
-	resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload2)
-	var prRespWrapper2 struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&prRespWrapper2); err != nil {
-		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
-	}
-	prResp2 := prRespWrapper2.PR
+func TestE2E_DeactivateTeamMembers_DeterministicHandover(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "order-authors",
+			"members":   []map[string]interface{}{{"user_id": "o-author", "username": "Author", "is_active": true}},
+		})
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "order-team",
+			"members": []map[string]interface{}{
+				{"user_id": "o-r1", "username": "R1", "is_active": true},
+				{"user_id": "o-r2", "username": "R2", "is_active": true},
+				{"user_id": "o-x", "username": "X", "is_active": true},
+				{"user_id": "o-y", "username": "Y", "is_active": true},
+				{"user_id": "o-z", "username": "Z", "is_active": true},
+			},
+		})
+		prs := `{"pull_request_id":"pr-o1","pull_request_name":"O1","author_id":"o-author","assigned_reviewers":["o-r1"]}
+{"pull_request_id":"pr-o2","pull_request_name":"O2","author_id":"o-author","assigned_reviewers":["o-r2"]}
+{"pull_request_id":"pr-o3","pull_request_name":"O3","author_id":"o-author","assigned_reviewers":["o-r1","o-r2"]}
+`
+		resp := postRaw(t, srv.URL+"/pullRequest/import", handlers.ContentTypeNDJSON, prs)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp.Body.Close()
+
+		resp = makeRequest(t, srv.URL+"/team/deactivateMembers", "POST", map[string]interface{}{
+			"team_name": "order-team",
+			"user_ids":  []string{"o-r2", "o-r1"},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp.Body.Close()
+
+		// Очередь o-x, o-y, o-z идёт по PR o-r1 (pr-o1, pr-o3), затем o-r2
+		// (pr-o2, pr-o3), а не в порядке выборки
+		want := map[string][]string{
+			"pr-o1": {"o-x"},
+			"pr-o2": {"o-z"},
+			"pr-o3": {"o-x", "o-y"},
+		}
+		for prID, reviewers := range want {
+			got := getPR(t, srv, prID).AssignedReviewers
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, reviewers) {
+				t.Errorf("%s: expected reviewers %v, got %v", prID, reviewers, got)
+			}
+		}
+	})
+}
 
-	if len(prResp2.AssignedReviewers) != 1 {
-		t.Errorf("Expected 1 reviewer (member-3), got %d", len(prResp2.AssignedReviewers))
-	} else if prResp2.AssignedReviewers[0] != "member-3" {
-		t.Errorf("Expected member-3, got %s", prResp2.AssignedReviewers[0])
-	}
+func TestE2E_TeamMembership(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		teamPayload := map[string]interface{}{
+			"team_name": "move-a",
+			"members": []map[string]interface{}{
+				{"user_id": "a1", "username": "Author", "is_active": true},
+				{"user_id": "a2", "username": "A2", "is_active": true},
+				{"user_id": "a3", "username": "A3", "is_active": true},
+				{"user_id": "a4", "username": "A4", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "move-b",
+			"members":   []map[string]interface{}{{"user_id": "b1", "username": "B1", "is_active": true}},
+		})
+
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-move",
+			"pull_request_name": "Test",
+			"author_id":         "a1",
+		}
+		pr := decodePR(t, makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload))
+		if len(pr.AssignedReviewers) != 2 {
+			t.Fatalf("Expected 2 reviewers, got %v", pr.AssignedReviewers)
+		}
+		moved, kept := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+
+		// Перевод ревьювера: его место занимает оставшийся участник старой команды
+		resp := makeRequest(t, srv.URL+"/users/moveTeam", "POST", map[string]string{"user_id": moved, "team_name": "move-b"})
+		var moveResp struct {
+			User          models.User `json:"user"`
+			ReassignedPRs int         `json:"reassigned_prs"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&moveResp); err != nil {
+			t.Fatalf("Failed to decode move response: %v, body: %s", err, readBody(t, resp))
+		}
+		if moveResp.User.TeamName != "move-b" || moveResp.ReassignedPRs != 1 {
+			t.Errorf("Expected move to move-b with 1 reassigned PR, got %+v", moveResp)
+		}
+		pr = getPR(t, srv, "pr-move")
+		if len(pr.AssignedReviewers) != 2 || contains(pr.AssignedReviewers, moved) || !contains(pr.AssignedReviewers, kept) {
+			t.Errorf("Expected %s to be replaced, got %v", moved, pr.AssignedReviewers)
+		}
+
+		// Кандидатов не осталось: ревьювер просто снимается, автор не меняется
+		resp = makeRequest(t, srv.URL+"/team/removeMember", "POST", map[string]string{"team_name": "move-a", "user_id": kept})
+		var removeResp struct {
+			Team          models.Team `json:"team"`
+			ReassignedPRs int         `json:"reassigned_prs"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&removeResp); err != nil {
+			t.Fatalf("Failed to decode remove response: %v, body: %s", err, readBody(t, resp))
+		}
+		if len(removeResp.Team.Members) != 2 || removeResp.ReassignedPRs != 0 {
+			t.Errorf("Expected 2 members left and no reassignment, got %+v", removeResp)
+		}
+		pr = getPR(t, srv, "pr-move")
+		if len(pr.AssignedReviewers) != 1 || contains(pr.AssignedReviewers, kept) || pr.AuthorID != "a1" {
+			t.Errorf("Expected %s to be removed from reviewers, got %+v", kept, pr)
+		}
+
+		// Повторное добавление существующего пользователя — upsert, а не 500
+		resp = makeRequest(t, srv.URL+"/team/addMember", "POST", map[string]interface{}{"team_name": "move-a", "user_id": moved, "username": "Back"})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp = makeRequest(t, srv.URL+"/team/get?team_name=move-b", "GET", nil)
+		var teamB models.Team
+		if err := json.NewDecoder(resp.Body).Decode(&teamB); err != nil {
+			t.Fatalf("Failed to decode team: %v", err)
+		}
+		if len(teamB.Members) != 1 || teamB.Members[0].UserID != "b1" {
+			t.Errorf("Expected %s to leave move-b, got %+v", moved, teamB.Members)
+		}
+	})
+}
+
+func TestE2E_Archive(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		teamPayload := map[string]interface{}{
+			"team_name": "archive-team",
+			"members": []map[string]interface{}{
+				{"user_id": "ar-1", "username": "Author", "is_active": true},
+				{"user_id": "ar-2", "username": "R2", "is_active": true},
+				{"user_id": "ar-3", "username": "R3", "is_active": true},
+				{"user_id": "ar-4", "username": "R4", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-archive",
+			"pull_request_name": "Test",
+			"author_id":         "ar-1",
+		}
+		pr := decodePR(t, makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload))
+		if len(pr.AssignedReviewers) != 2 {
+			t.Fatalf("Expected 2 reviewers, got %v", pr.AssignedReviewers)
+		}
+
+		// Архивация автора: PR переходит к коллеге, ревьюверы остаются
+		resp := makeRequest(t, srv.URL+"/users/archive", "POST", map[string]string{"user_id": "ar-1"})
+		var archiveResp struct {
+			User          models.User `json:"user"`
+			ReassignedPRs int         `json:"reassigned_prs"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&archiveResp); err != nil {
+			t.Fatalf("Failed to decode archive response: %v, body: %s", err, readBody(t, resp))
+		}
+		if archiveResp.User.ArchivedAt == nil || archiveResp.User.IsActive || archiveResp.ReassignedPRs != 1 {
+			t.Errorf("Expected archived author with 1 reassignment, got %+v", archiveResp)
+		}
+		pr = getPR(t, srv, "pr-archive")
+		if pr.AuthorID == "ar-1" || len(pr.AssignedReviewers) != 2 {
+			t.Errorf("Expected authorship to be transferred, got %+v", pr)
+		}
+
+		resp = makeRequest(t, srv.URL+"/team/get?team_name=archive-team", "GET", nil)
+		var team models.Team
+		if err := json.NewDecoder(resp.Body).Decode(&team); err != nil {
+			t.Fatalf("Failed to decode team: %v", err)
+		}
+		if len(team.Members) != 3 {
+			t.Errorf("Expected archived user to be hidden, got %+v", team.Members)
+		}
+
+		// Архивный автор остаётся в истории и во входящих ревью
+		resp = makeRequest(t, srv.URL+"/users/getReview?user_id=ar-1&include_authored=true&status=ALL", "GET", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected archived user to stay resolvable, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", map[string]string{
+			"pull_request_id": "pr-archive-2", "pull_request_name": "Test2", "author_id": "ar-1",
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected archived author to be rejected, got %d", resp.StatusCode)
+		}
+
+		// Архивация команды снимает оставшихся ревьюверов
+		resp = makeRequest(t, srv.URL+"/team/archive", "POST", map[string]string{"team_name": "archive-team"})
+		var teamResp struct {
+			Team          models.Team `json:"team"`
+			ArchivedUsers []string    `json:"archived_users"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&teamResp); err != nil {
+			t.Fatalf("Failed to decode team archive response: %v, body: %s", err, readBody(t, resp))
+		}
+		if teamResp.Team.ArchivedAt == nil || len(teamResp.ArchivedUsers) != 3 {
+			t.Errorf("Expected team and 3 members to be archived, got %+v", teamResp)
+		}
+		pr = getPR(t, srv, "pr-archive")
+		if len(pr.AssignedReviewers) != 0 || pr.Status != "OPEN" {
+			t.Errorf("Expected reviewers to be released, got %+v", pr)
+		}
+		resp = makeRequest(t, srv.URL+"/team/get?team_name=archive-team", "GET", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected archived team to be hidden, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestE2E_TeamSync(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "sync-a",
+			"members": []map[string]interface{}{
+				{"user_id": "s1", "username": "Author", "is_active": true},
+				{"user_id": "s2", "username": "R2", "is_active": true},
+				{"user_id": "s3", "username": "R3", "is_active": true},
+				{"user_id": "s4", "username": "R4", "is_active": true},
+			},
+		})
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "sync-b",
+			"members":   []map[string]interface{}{{"user_id": "s5", "username": "R5", "is_active": true}},
+		})
+		makeRequest(t, srv.URL+"/pullRequest/create", "POST", map[string]string{
+			"pull_request_id": "pr-sync", "pull_request_name": "Sync", "author_id": "s1",
+		})
+
+		// s2 деактивируется, s4 переходит в sync-b, s5 пропадает из манифеста, sync-c создаётся
+		manifest := map[string]interface{}{
+			"teams": []map[string]interface{}{
+				{"team_name": "sync-a", "members": []map[string]interface{}{
+					{"user_id": "s1", "username": "Author", "is_active": true},
+					{"user_id": "s2", "username": "R2", "is_active": false},
+					{"user_id": "s3", "username": "Renamed", "is_active": true},
+				}},
+				{"team_name": "sync-b", "members": []map[string]interface{}{
+					{"user_id": "s4", "username": "R4", "is_active": true},
+				}},
+				{"team_name": "sync-c", "members": []map[string]interface{}{
+					{"user_id": "s6", "username": "New", "is_active": true},
+				}},
+			},
+		}
+		type syncResponse struct {
+			DryRun  bool                `json:"dry_run"`
+			Changes []models.SyncChange `json:"changes"`
+		}
+		sync := func(target string) syncResponse {
+			resp := makeRequest(t, srv.URL+target, "POST", manifest)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200 from %s, got %d: %s", target, resp.StatusCode, readBody(t, resp))
+			}
+			var result syncResponse
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode sync response: %v", err)
+			}
+			return result
+		}
+		want := []models.SyncChange{
+			{Action: models.SyncCreateTeam, TeamName: "sync-c"},
+			{Action: models.SyncDeactivateUser, TeamName: "sync-a", UserID: "s2"},
+			{Action: models.SyncUpdateUser, TeamName: "sync-a", UserID: "s3"},
+			{Action: models.SyncMoveUser, TeamName: "sync-b", UserID: "s4", PreviousTeam: "sync-a"},
+			{Action: models.SyncCreateUser, TeamName: "sync-c", UserID: "s6"},
+			{Action: models.SyncDeactivateUser, TeamName: "sync-b", UserID: "s5"},
+		}
+
+		plan := sync("/team/sync?dry_run=true")
+		if !plan.DryRun || !reflect.DeepEqual(plan.Changes, want) {
+			t.Errorf("Unexpected dry run plan: %+v", plan)
+		}
+		if resp := makeRequest(t, srv.URL+"/team/get?team_name=sync-c", "GET", nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Dry run must not create teams, got %d", resp.StatusCode)
+		}
+		if pr := getPR(t, srv, "pr-sync"); len(pr.AssignedReviewers) != 2 {
+			t.Errorf("Dry run must not reassign reviewers, got %+v", pr)
+		}
+
+		applied := sync("/team/sync")
+		if applied.DryRun || !reflect.DeepEqual(applied.Changes, want) {
+			t.Errorf("Unexpected applied changes: %+v", applied)
+		}
+
+		// У PR остаётся единственный активный коллега автора в sync-a
+		pr := getPR(t, srv, "pr-sync")
+		if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "s3" {
+			t.Errorf("Expected reviewers [s3], got %v", pr.AssignedReviewers)
+		}
+
+		resp := makeRequest(t, srv.URL+"/team/get?team_name=sync-b", "GET", nil)
+		var team models.Team
+		if err := json.NewDecoder(resp.Body).Decode(&team); err != nil {
+			t.Fatalf("Failed to decode team: %v", err)
+		}
+		active := map[string]bool{}
+		for _, m := range team.Members {
+			active[m.UserID] = m.IsActive
+		}
+		if len(active) != 2 || !active["s4"] || active["s5"] {
+			t.Errorf("Expected s4 active and s5 deactivated in sync-b, got %+v", team.Members)
+		}
+
+		if again := sync("/team/sync"); len(again.Changes) != 0 {
+			t.Errorf("Expected repeated sync to be a no-op, got %+v", again.Changes)
+		}
+	})
+}
+
+func TestE2E_TeamSync_MoveInactiveAndForeignTeams(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		for team, members := range map[string][]string{"mv-a": {"m1", "m2"}, "mv-b": {"m3"}, "mv-other": {"m4"}} {
+			list := make([]map[string]interface{}, 0, len(members))
+			for _, id := range members {
+				list = append(list, map[string]interface{}{"user_id": id, "username": id, "is_active": true})
+			}
+			makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{"team_name": team, "members": list})
+		}
+		resp := postRaw(t, srv.URL+"/pullRequest/import", handlers.ContentTypeNDJSON,
+			`{"pull_request_id":"pr-mv","pull_request_name":"Move","author_id":"m1","assigned_reviewers":[]}`+"\n")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp.Body.Close()
+
+		// m1 переходит в mv-b неактивным; mv-other в манифесте не упомянута
+		resp = makeRequest(t, srv.URL+"/team/sync", "POST", map[string]interface{}{
+			"teams": []map[string]interface{}{
+				{"team_name": "mv-a", "members": []map[string]interface{}{
+					{"user_id": "m2", "username": "m2", "is_active": true},
+				}},
+				{"team_name": "mv-b", "members": []map[string]interface{}{
+					{"user_id": "m3", "username": "m3", "is_active": true},
+					{"user_id": "m1", "username": "m1", "is_active": false},
+				}},
+			},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		var result struct {
+			Changes []models.SyncChange `json:"changes"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode sync response: %v", err)
+		}
+		resp.Body.Close()
+		want := []models.SyncChange{
+			{Action: models.SyncMoveUser, TeamName: "mv-b", UserID: "m1", PreviousTeam: "mv-a"},
+			{Action: models.SyncDeactivateUser, TeamName: "mv-b", UserID: "m1"},
+		}
+		if !reflect.DeepEqual(result.Changes, want) {
+			t.Errorf("Expected changes %+v, got %+v", want, result.Changes)
+		}
+
+		if pr := getPR(t, srv, "pr-mv"); pr.AuthorID != "m2" {
+			t.Errorf("Expected authorship to pass to m2, got %s", pr.AuthorID)
+		}
+		resp = makeRequest(t, srv.URL+"/team/get?team_name=mv-other", "GET", nil)
+		var team models.Team
+		if err := json.NewDecoder(resp.Body).Decode(&team); err != nil {
+			t.Fatalf("Failed to decode team: %v", err)
+		}
+		resp.Body.Close()
+		if len(team.Members) != 1 || !team.Members[0].IsActive {
+			t.Errorf("Expected m4 to stay active in mv-other, got %+v", team.Members)
+		}
+	})
+}
+
+func TestE2E_SCIM(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		resp := scimRequest(t, srv.URL+"/scim/v2/Groups", "POST", map[string]interface{}{
+			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:Group"},
+			"displayName": "scim-team",
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201 for group, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		members := make([]map[string]string, 0)
+		for _, id := range []string{"sc1", "sc2", "sc3", "sc4"} {
+			resp := scimRequest(t, srv.URL+"/scim/v2/Users", "POST", map[string]interface{}{
+				"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"userName":    id,
+				"displayName": "User " + id,
+			})
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("Expected 201 for %s, got %d: %s", id, resp.StatusCode, readBody(t, resp))
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != handlers.SCIMContentType {
+				t.Errorf("Expected %s content type, got %q", handlers.SCIMContentType, ct)
+			}
+			members = append(members, map[string]string{"value": id})
+		}
+
+		resp = scimRequest(t, srv.URL+"/scim/v2/Groups/scim-team", "PATCH", map[string]interface{}{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]interface{}{{"op": "add", "path": "members", "value": members}},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 for group patch, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		var group struct {
+			Members []struct {
+				Value string `json:"value"`
+			} `json:"members"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&group); err != nil {
+			t.Fatalf("Failed to decode group: %v", err)
+		}
+		if len(group.Members) != 4 {
+			t.Fatalf("Expected 4 members, got %+v", group.Members)
+		}
+
+		resp = makeRequest(t, srv.URL+"/pullRequest/create", "POST", map[string]string{
+			"pull_request_id": "pr-scim", "pull_request_name": "SCIM", "author_id": "sc1",
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201 for PR, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		pr := decodePR(t, resp)
+		if len(pr.AssignedReviewers) != 2 {
+			t.Fatalf("Expected 2 reviewers, got %v", pr.AssignedReviewers)
+		}
+
+		// Отключение в провайдере снимает пользователя с открытых ревью
+		leaving := pr.AssignedReviewers[0]
+		resp = scimRequest(t, srv.URL+"/scim/v2/Users/"+leaving, "PATCH", map[string]interface{}{
+			"Operations": []map[string]interface{}{{"op": "replace", "value": map[string]interface{}{"active": false}}},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 for user patch, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		pr = getPR(t, srv, "pr-scim")
+		if contains(pr.AssignedReviewers, leaving) {
+			t.Errorf("Expected %s to be released from review, got %v", leaving, pr.AssignedReviewers)
+		}
+
+		resp = scimRequest(t, srv.URL+"/scim/v2/Groups/scim-team", "DELETE", nil)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204 for group delete, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		resp = makeRequest(t, srv.URL+"/team/get?team_name=scim-team", "GET", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected deleted group to be archived, got %d", resp.StatusCode)
+		}
+
+		resp = makeRequest(t, srv.URL+"/scim/v2/Users", "GET", nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without SCIM token, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestE2E_BulkExportImport(t *testing.T) {
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		makeRequest(t, srv.URL+"/team/add", "POST", map[string]interface{}{
+			"team_name": "bulk",
+			"members": []map[string]interface{}{
+				{"user_id": "b1", "username": "Author", "is_active": true},
+				{"user_id": "b2", "username": "R2", "is_active": true},
+				{"user_id": "b3", "username": "R3", "is_active": false},
+			},
+		})
+		makeRequest(t, srv.URL+"/pullRequest/create", "POST", map[string]string{
+			"pull_request_id": "pr-bulk", "pull_request_name": "Bulk", "author_id": "b1",
+		})
+		makeRequest(t, srv.URL+"/pullRequest/merge", "POST", map[string]string{"pull_request_id": "pr-bulk"})
+		original := getPR(t, srv, "pr-bulk")
+
+		export := func(target string) string {
+			resp := makeRequest(t, srv.URL+target, "GET", nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200 from %s, got %d: %s", target, resp.StatusCode, readBody(t, resp))
+			}
+			return readBody(t, resp)
+		}
+		teams := export("/team/export")
+		users := export("/users/export?format=csv")
+		prs := export("/pullRequest/export?format=csv")
+		if !strings.HasPrefix(users, "user_id,username,team_name,is_active\n") || !strings.Contains(users, "b3,R3,bulk,false") {
+			t.Errorf("Unexpected users CSV:\n%s", users)
+		}
+
+		// Выгрузка загружается в пустой сервис без изменений
+		dst := setupTestServer(t, migrated(t, storage.NewMemory()))
+		defer dst.Close()
+		for _, step := range []struct{ target, contentType, body string }{
+			{"/team/import", handlers.ContentTypeNDJSON, teams},
+			{"/users/import", handlers.ContentTypeCSV, users},
+			{"/pullRequest/import", handlers.ContentTypeCSV, prs},
+		} {
+			resp := postRaw(t, dst.URL+step.target, step.contentType, step.body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200 from %s, got %d: %s", step.target, resp.StatusCode, readBody(t, resp))
+			}
+		}
+
+		imported := getPR(t, dst, "pr-bulk")
+		if imported.Status != "MERGED" || !reflect.DeepEqual(imported.AssignedReviewers, original.AssignedReviewers) {
+			t.Errorf("Expected %+v, got %+v", original, imported)
+		}
+		if !imported.CreatedAt.Equal(*original.CreatedAt) || !imported.MergedAt.Equal(*original.MergedAt) {
+			t.Errorf("Expected original timestamps, got %v / %v", imported.CreatedAt, imported.MergedAt)
+		}
+
+		// Одна ошибочная строка отменяет весь импорт
+		body := `{"user_id":"b4","username":"New","team_name":"bulk"}` + "\n" +
+			`{"user_id":"b1","username":"Dup","team_name":"bulk"}` + "\n" +
+			`{"user_id":"b5"}` + "\n"
+		resp := postRaw(t, srv.URL+"/users/import", handlers.ContentTypeNDJSON, body)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected 400, got %d: %s", resp.StatusCode, readBody(t, resp))
+		}
+		var rejected models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&rejected); err != nil {
+			t.Fatalf("Failed to decode error: %v", err)
+		}
+		want := []models.FieldViolation{
+			{Field: "rows.2.user_id", Message: "user already exists"},
+			{Field: "rows.3.username", Message: "is required"},
+		}
+		if !reflect.DeepEqual(rejected.Error.Details, want) {
+			t.Errorf("Expected %+v, got %+v", want, rejected.Error.Details)
+		}
+		if users := export("/users/export"); strings.Contains(users, `"b4"`) {
+			t.Errorf("Rejected import must not create users:\n%s", users)
+		}
+	})
 }
 
 func TestE2E_IdempotentMerge(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	runOnBackends(t, func(t *testing.T, srv *httptest.Server) {
+		// Создание команды и PR
+		teamPayload := map[string]interface{}{
+			"team_name": "idempotent-team",
+			"members": []map[string]interface{}{
+				{"user_id": "idem-1", "username": "User1", "is_active": true},
+			},
+		}
+		makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
 
-	db := setupTestDB(t)
-	defer db.Close()
-	cleanupDB(t, db)
+		prPayload := map[string]string{
+			"pull_request_id":   "pr-idempotent",
+			"pull_request_name": "Test",
+			"author_id":         "idem-1",
+		}
+		makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
 
-	srv := setupTestServer(db)
-	defer srv.Close()
+		// Первый merge
+		mergePayload := map[string]string{"pull_request_id": "pr-idempotent"}
+		resp1 := makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
+		if resp1.StatusCode != http.StatusOK {
+			t.Fatalf("First merge failed: %d", resp1.StatusCode)
+		}
 
-	// Создание команды и PR
-	teamPayload := map[string]interface{}{
-		"team_name": "idempotent-team",
-		"members": []map[string]interface{}{
-			{"user_id": "idem-1", "username": "User1", "is_active": true},
-		},
-	}
-	makeRequest(t, srv.URL+"/team/add", "POST", teamPayload)
+		var prRespWrapper1 struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp1.Body).Decode(&prRespWrapper1); err != nil {
+			t.Fatalf("Failed to decode first merge PR response: %v, body: %s", err, readBody(t, resp1))
+		}
+		pr1 := prRespWrapper1.PR
 
-	prPayload := map[string]string{
-		"pull_request_id":   "pr-idempotent",
-		"pull_request_name": "Test",
-		"author_id":         "idem-1",
-	}
-	makeRequest(t, srv.URL+"/pullRequest/create", "POST", prPayload)
+		// Второй merge (идемпотентность)
+		resp2 := makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
+		if resp2.StatusCode != http.StatusOK {
+			t.Fatalf("Second merge failed: %d", resp2.StatusCode)
+		}
 
-	// Первый merge
-	mergePayload := map[string]string{"pull_request_id": "pr-idempotent"}
-	resp1 := makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
-	if resp1.StatusCode != http.StatusOK {
-		t.Fatalf("First merge failed: %d", resp1.StatusCode)
-	}
+		var prRespWrapper2 struct {
+			PR models.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(resp2.Body).Decode(&prRespWrapper2); err != nil {
+			t.Fatalf("Failed to decode second merge PR response: %v, body: %s", err, readBody(t, resp2))
+		}
+		pr2 := prRespWrapper2.PR
 
-	var prRespWrapper1 struct {
-		PR models.PullRequest `json:"pr"`
-	}
-	if err := json.NewDecoder(resp1.Body).Decode(&prRespWrapper1); err != nil {
-		t.Fatalf("Failed to decode first merge PR response: %v, body: %s", err, readBody(t, resp1))
+		if pr1.Status != pr2.Status || pr1.PullRequestID != pr2.PullRequestID {
+			t.Error("Merge is not idempotent")
+		}
+	})
+}
+
+func makeRequest(t *testing.T, url, method string, payload interface{}) *http.Response {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Failed to marshal payload: %v", err)
+		}
 	}
-	pr1 := prRespWrapper1.PR
 
-	// Второй merge (идемпотентность)
-	resp2 := makeRequest(t, srv.URL+"/pullRequest/merge", "POST", mergePayload)
-	if resp2.StatusCode != http.StatusOK {
-		t.Fatalf("Second merge failed: %d", resp2.StatusCode)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	var prRespWrapper2 struct {
-		PR models.PullRequest `json:"pr"`
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
 	}
-	if err := json.NewDecoder(resp2.Body).Decode(&prRespWrapper2); err != nil {
-		t.Fatalf("Failed to decode second merge PR response: %v, body: %s", err, readBody(t, resp2))
+	return resp
+}
+
+func postRaw(t *testing.T, url, contentType, body string) *http.Response {
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
 	}
-	pr2 := prRespWrapper2.PR
+	req.Header.Set("Content-Type", contentType)
 
-	if pr1.Status != pr2.Status || pr1.PullRequestID != pr2.PullRequestID {
-		t.Error("Merge is not idempotent")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
 	}
+	return resp
 }
 
-func makeRequest(t *testing.T, url, method string, payload interface{}) *http.Response {
+const scimToken = "scim-secret"
+
+func scimRequest(t *testing.T, url, method string, payload interface{}) *http.Response {
 	var body []byte
 	if payload != nil {
 		var err error
@@ -503,7 +1127,8 @@ func makeRequest(t *testing.T, url, method string, payload interface{}) *http.Re
 	if err != nil {
 		t.Fatalf("Failed to create request: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", handlers.SCIMContentType)
+	req.Header.Set("Authorization", "Bearer "+scimToken)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -512,6 +1137,20 @@ func makeRequest(t *testing.T, url, method string, payload interface{}) *http.Re
 	return resp
 }
 
+func decodePR(t *testing.T, resp *http.Response) models.PullRequest {
+	var wrapper struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+		t.Fatalf("Failed to decode PR response: %v, body: %s", err, readBody(t, resp))
+	}
+	return wrapper.PR
+}
+
+func getPR(t *testing.T, srv *httptest.Server, prID string) models.PullRequest {
+	return decodePR(t, makeRequest(t, srv.URL+"/pullRequest/get?pull_request_id="+prID, "GET", nil))
+}
+
 func readBody(t *testing.T, resp *http.Response) string {
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(resp.Body)
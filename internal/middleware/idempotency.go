@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// recordingWriter пишет ответ клиенту и одновременно запоминает его,
+// чтобы повтор с тем же ключом вернул ответ байт в байт.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func IdempotencyMiddleware(repo repository.IdempotencyRepository, ttl time.Duration, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			if len(key) > maxIdempotencyKeyLength {
+				writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Idempotency-Key is too long")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentRequestBytes+1))
+			if err != nil {
+				logger.WarnContext(ctx, "failed to read request body", "error", err)
+				writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+				return
+			}
+			if len(body) > maxIdempotentRequestBytes {
+				// Большие тела (массовая загрузка) не буферизуем ради хэша:
+				// запрос выполняется без ключа, как будто заголовка не было
+				logger.WarnContext(ctx, "request body too large for idempotency, key ignored", "path", r.URL.Path)
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+			key = scopedKey(callerScope(r, body), key)
+			existing, err := repo.Reserve(key, hash, time.Now().UTC().Add(ttl))
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to reserve idempotency key", "error", err)
+				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+				return
+			}
+
+			if existing != nil {
+				switch {
+				case existing.RequestHash != hash:
+					logger.WarnContext(ctx, "idempotency key reused with different request", "path", r.URL.Path)
+					writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used with a different request")
+				case !existing.Completed:
+					writeError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "A request with this Idempotency-Key is still in progress")
+				default:
+					logger.InfoContext(ctx, "replaying idempotent response", "path", r.URL.Path, "status", existing.StatusCode)
+					if existing.ContentType != "" {
+						w.Header().Set("Content-Type", existing.ContentType)
+					}
+					w.Header().Set(IdempotentReplayedHeader, "true")
+					w.WriteHeader(existing.StatusCode)
+					w.Write(existing.Body)
+				}
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Ключ освобождаем, если ответ не удалось сохранить или обработчик
+				// запаниковал: иначе повторы получат 409 до истечения TTL
+				if completed {
+					return
+				}
+				if err := repo.Delete(key); err != nil {
+					logger.ErrorContext(ctx, "failed to release idempotency key", "error", err)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if !replayableStatus(rec.status) {
+				return
+			}
+
+			if err := repo.Save(key, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+				logger.ErrorContext(ctx, "failed to save idempotent response", "error", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// readCloser дочитывает тело после уже прочитанного начала и закрывает исходное.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// replayableStatus — сохраняются только успешные ответы и ошибки, которые
+// повтор того же запроса получил бы снова. 401/403 проверок доступа после
+// middleware, 429 и 5xx зависят не от запроса, поэтому повтор выполняет
+// его заново.
+func replayableStatus(status int) bool {
+	if status >= 200 && status < 300 {
+		return true
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{'?'})
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// callerScope определяет, чьи это ключи: один и тот же Idempotency-Key
+// разных вызывающих не должен возвращать чужой ответ. Учётные данные —
+// заголовок Authorization (токен SCIM или API), для запросов Slack без
+// него — команда Slack из формы. Пустая строка — вызывающий не известен.
+func callerScope(r *http.Request, body []byte) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return "auth:" + auth
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		return ""
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	if team := form.Get("team_id"); team != "" {
+		return "slack:" + team
+	}
+	var payload struct {
+		Team struct {
+			ID string `json:"id"`
+		} `json:"team"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err == nil && payload.Team.ID != "" {
+		return "slack:" + payload.Team.ID
+	}
+	return ""
+}
+
+// scopedKey — ключ в хранилище. Без области остаётся ключ клиента, иначе
+// область и ключ хэшируются, чтобы уложиться в длину колонки.
+func scopedKey(scope, key string) string {
+	if scope == "" {
+		return key
+	}
+	scopeHash := sha256.Sum256([]byte(scope))
+	keyHash := sha256.Sum256([]byte(key))
+	return "scoped:" + hex.EncodeToString(scopeHash[:16]) + ":" + hex.EncodeToString(keyHash[:])
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: models.ErrorDetail{
+			Code:    code,
+			Message: message,
+		},
+	}); err != nil {
+		slog.Error("failed to encode error response", "error", err)
+	}
+}
@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+type mockIdempotencyRepository struct {
+	records map[string]*models.IdempotencyRecord
+	saveErr error
+}
+
+func newMockIdempotencyRepository() *mockIdempotencyRepository {
+	return &mockIdempotencyRepository{records: make(map[string]*models.IdempotencyRecord)}
+}
+
+func (m *mockIdempotencyRepository) Reserve(key, requestHash string, expiresAt time.Time) (*models.IdempotencyRecord, error) {
+	if rec, exists := m.records[key]; exists && rec.ExpiresAt.After(time.Now().UTC()) {
+		copied := *rec
+		return &copied, nil
+	}
+	m.records[key] = &models.IdempotencyRecord{Key: key, RequestHash: requestHash, ExpiresAt: expiresAt}
+	return nil, nil
+}
+
+func (m *mockIdempotencyRepository) Save(key string, statusCode int, contentType string, body []byte) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	rec, exists := m.records[key]
+	if !exists {
+		return errors.New("key not reserved")
+	}
+	rec.StatusCode = statusCode
+	rec.ContentType = contentType
+	rec.Body = append([]byte(nil), body...)
+	rec.Completed = true
+	return nil
+}
+
+func (m *mockIdempotencyRepository) Delete(key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func (m *mockIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	var deleted int64
+	for key, rec := range m.records {
+		if rec.ExpiresAt.Before(now) {
+			delete(m.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func setupTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func doIdempotentRequest(handler http.Handler, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/pullRequest/reassign", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"call": calls, "echo": string(body)})
+	})
+
+	t.Run("replay returns original response", func(t *testing.T) {
+		calls = 0
+		handler := IdempotencyMiddleware(newMockIdempotencyRepository(), time.Hour, setupTestLogger())(next)
+
+		first := doIdempotentRequest(handler, "POST", "key-1", `{"pull_request_id":"pr-1"}`)
+		second := doIdempotentRequest(handler, "POST", "key-1", `{"pull_request_id":"pr-1"}`)
+
+		if calls != 1 {
+			t.Fatalf("expected handler to be called once, got %d", calls)
+		}
+		if second.Code != first.Code {
+			t.Errorf("expected status %d, got %d", first.Code, second.Code)
+		}
+		if !bytes.Equal(first.Body.Bytes(), second.Body.Bytes()) {
+			t.Errorf("expected identical body, got %q and %q", first.Body.String(), second.Body.String())
+		}
+		if second.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Error("expected replayed response to be marked")
+		}
+	})
+
+	t.Run("key reused with different body", func(t *testing.T) {
+		calls = 0
+		handler := IdempotencyMiddleware(newMockIdempotencyRepository(), time.Hour, setupTestLogger())(next)
+
+		doIdempotentRequest(handler, "POST", "key-2", `{"pull_request_id":"pr-1"}`)
+		w := doIdempotentRequest(handler, "POST", "key-2", `{"pull_request_id":"pr-2"}`)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, got %d", w.Code)
+		}
+		var response models.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Error.Code != "IDEMPOTENCY_KEY_REUSED" {
+			t.Errorf("expected error code IDEMPOTENCY_KEY_REUSED, got %s", response.Error.Code)
+		}
+		if calls != 1 {
+			t.Errorf("expected handler to be called once, got %d", calls)
+		}
+	})
+
+	t.Run("request without key is not cached", func(t *testing.T) {
+		calls = 0
+		handler := IdempotencyMiddleware(newMockIdempotencyRepository(), time.Hour, setupTestLogger())(next)
+
+		doIdempotentRequest(handler, "POST", "", `{}`)
+		doIdempotentRequest(handler, "POST", "", `{}`)
+
+		if calls != 2 {
+			t.Errorf("expected handler to be called twice, got %d", calls)
+		}
+	})
+
+	t.Run("server and auth errors are not cached", func(t *testing.T) {
+		for _, status := range []int{http.StatusInternalServerError, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests} {
+			failures := 0
+			failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				failures++
+				writeError(w, status, "ERROR", "Error")
+			})
+			repo := newMockIdempotencyRepository()
+			handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(failing)
+
+			doIdempotentRequest(handler, "POST", "key-3", `{}`)
+			doIdempotentRequest(handler, "POST", "key-3", `{}`)
+
+			if failures != 2 {
+				t.Errorf("status %d: expected handler to be called twice, got %d", status, failures)
+			}
+		}
+	})
+
+	t.Run("large body passes through without key", func(t *testing.T) {
+		calls = 0
+		repo := newMockIdempotencyRepository()
+		handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(next)
+		body := `"` + strings.Repeat("x", maxIdempotentRequestBytes) + `"`
+
+		first := doIdempotentRequest(handler, "POST", "key-large", body)
+		second := doIdempotentRequest(handler, "POST", "key-large", body)
+
+		if calls != 2 {
+			t.Errorf("expected handler to be called twice, got %d", calls)
+		}
+		if first.Code != http.StatusOK || second.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("expected unkeyed responses, got %d", second.Code)
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(first.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response["echo"] != body {
+			t.Error("expected handler to receive the whole body")
+		}
+		if len(repo.records) != 0 {
+			t.Errorf("expected no stored keys, got %d", len(repo.records))
+		}
+	})
+
+	t.Run("client errors are cached", func(t *testing.T) {
+		failures := 0
+		failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			failures++
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "resource not found")
+		})
+		handler := IdempotencyMiddleware(newMockIdempotencyRepository(), time.Hour, setupTestLogger())(failing)
+
+		doIdempotentRequest(handler, "POST", "key-5", `{}`)
+		w := doIdempotentRequest(handler, "POST", "key-5", `{}`)
+
+		if failures != 1 {
+			t.Errorf("expected handler to be called once, got %d", failures)
+		}
+		if w.Code != http.StatusNotFound || w.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("expected replayed 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		repo := newMockIdempotencyRepository()
+		repo.Reserve("key-4", requestHash(httptest.NewRequest("POST", "/pullRequest/reassign", nil), []byte(`{}`)), time.Now().UTC().Add(time.Hour))
+		handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(next)
+
+		w := doIdempotentRequest(handler, "POST", "key-4", `{}`)
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("failed save releases key", func(t *testing.T) {
+		calls = 0
+		repo := newMockIdempotencyRepository()
+		repo.saveErr = errors.New("database is unavailable")
+		handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(next)
+
+		first := doIdempotentRequest(handler, "POST", "key-5", `{}`)
+		if first.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", first.Code)
+		}
+		if _, exists := repo.records["key-5"]; exists {
+			t.Fatal("expected key to be released after failed save")
+		}
+
+		second := doIdempotentRequest(handler, "POST", "key-5", `{}`)
+		if second.Code == http.StatusConflict {
+			t.Fatal("expected retry not to be rejected as in progress")
+		}
+		if calls != 2 {
+			t.Errorf("expected handler to be called twice, got %d", calls)
+		}
+	})
+
+	t.Run("panicking handler releases key", func(t *testing.T) {
+		panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+		repo := newMockIdempotencyRepository()
+		handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(panicking)
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic to propagate")
+				}
+			}()
+			doIdempotentRequest(handler, "POST", "key-6", `{}`)
+		}()
+
+		if _, exists := repo.records["key-6"]; exists {
+			t.Error("expected key to be released after panic")
+		}
+	})
+
+	t.Run("query string is part of the request", func(t *testing.T) {
+		calls = 0
+		handler := IdempotencyMiddleware(newMockIdempotencyRepository(), time.Hour, setupTestLogger())(next)
+
+		send := func(target string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", target, bytes.NewBufferString(`{}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-7")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w
+		}
+		send("/team/sync?dry_run=true")
+		w := send("/team/sync")
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status 422 for a different query, got %d", w.Code)
+		}
+		if w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Error("expected dry-run response not to be replayed")
+		}
+	})
+
+	t.Run("keys are scoped per credential", func(t *testing.T) {
+		calls = 0
+		repo := newMockIdempotencyRepository()
+		handler := IdempotencyMiddleware(repo, time.Hour, setupTestLogger())(next)
+
+		send := func(token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/scim/v2/Users", bytes.NewBufferString(`{}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-8")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w
+		}
+		send("token-a")
+		w := send("token-b")
+		replay := send("token-a")
+
+		if calls != 2 || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("expected another caller's key to run the handler, got %d calls", calls)
+		}
+		if replay.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Error("expected the same caller to get the stored response")
+		}
+		if _, exists := repo.records["key-8"]; exists {
+			t.Error("expected scoped keys not to be stored as is")
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import "net/http"
+
+// Invalidator сбрасывает производные данные, например кэш статистики.
+type Invalidator interface {
+	Invalidate()
+}
+
+// InvalidateOnWrite вызывает Invalidate после каждого успешного (2xx)
+// изменяющего запроса. Сброс идёт после ответа обработчика, то есть после
+// фиксации транзакции, поэтому следующий GET увидит новые данные.
+func InvalidateOnWrite(inv Invalidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped := &responseWriter{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+			}
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.status >= 200 && wrapped.status < 300 {
+				inv.Invalidate()
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type countingInvalidator struct {
+	calls int
+}
+
+func (c *countingInvalidator) Invalidate() {
+	c.calls++
+}
+
+func TestInvalidateOnWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+		want   int
+	}{
+		{name: "successful POST", method: http.MethodPost, status: http.StatusOK, want: 1},
+		{name: "created", method: http.MethodPost, status: http.StatusCreated, want: 1},
+		{name: "successful PATCH", method: http.MethodPatch, status: http.StatusOK, want: 1},
+		{name: "successful DELETE", method: http.MethodDelete, status: http.StatusNoContent, want: 1},
+		{name: "rejected POST", method: http.MethodPost, status: http.StatusConflict, want: 0},
+		{name: "failed POST", method: http.MethodPost, status: http.StatusInternalServerError, want: 0},
+		{name: "GET", method: http.MethodGet, status: http.StatusOK, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &countingInvalidator{}
+			handler := InvalidateOnWrite(inv)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, "/pullRequest/create", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d to pass through, got %d", tt.status, w.Code)
+			}
+			if inv.calls != tt.want {
+				t.Errorf("expected %d invalidations, got %d", tt.want, inv.calls)
+			}
+		})
+	}
+}
@@ -23,6 +23,12 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Unwrap открывает исходный ResponseWriter для http.ResponseController,
+// чтобы потоковые ответы могли сбрасывать буфер.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/reviewer-service/internal/models"
+)
+
+const (
+	bodyField = "body"
+	// maxValidatedBodyBytes ограничивает тело, которое читается для проверки
+	// до авторизации в обработчиках; JSON-запросы API намного меньше
+	maxValidatedBodyBytes = 1 << 20
+)
+
+// RequestValidationMiddleware проверяет параметры и тело запроса по схемам
+// из спецификации. Маршруты, которых нет в спецификации (/docs и т.п.),
+// пропускаются без проверки. Тело массовой загрузки (CSV, NDJSON) до
+// 64 МБ разбирает построчно обработчик, поэтому здесь его не читаем;
+// остальные тела читаются не больше maxValidatedBodyBytes.
+func RequestValidationMiddleware(spec []byte, logger *slog.Logger) (func(http.Handler) http.Handler, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		// SCIM-токен проверяет обработчик, остальные схемы безопасности только документируются
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+	skipBody := *options
+	skipBody.ExcludeRequestBody = true
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Обработчики всегда читают JSON, поэтому запрос без Content-Type
+			// проверяем так же, как application/json
+			if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			var body []byte
+			switch {
+			case bulkMediaTypes[mediaType]:
+				input.Options = &skipBody
+			case r.Body != nil && r.Body != http.NoBody:
+				body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxValidatedBodyBytes))
+				if err != nil {
+					logger.WarnContext(r.Context(), "failed to read request body", "path", r.URL.Path, "error", err)
+					writeValidationError(w, []models.FieldViolation{{Field: bodyField, Message: bodyReadError(err)}})
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			if mediaType == "application/scim+json" {
+				// Спецификация описывает тела SCIM и как application/json,
+				// поэтому проверяем копию запроса с этим типом
+				input.Request = r.Clone(r.Context())
+				input.Request.Header.Set("Content-Type", "application/json")
+				input.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				violations := fieldViolations(err)
+				logger.WarnContext(r.Context(), "request does not match OpenAPI spec",
+					"path", r.URL.Path, "violations", len(violations), "error", err)
+				writeValidationError(w, violations)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// bulkMediaTypes — тела массовой загрузки, которые проверяет обработчик
+var bulkMediaTypes = map[string]bool{
+	"text/csv":             true,
+	"application/x-ndjson": true,
+}
+
+func bodyReadError(err error) string {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit)
+	}
+	return "failed to read request body"
+}
+
+// fieldViolations раскладывает ошибки kin-openapi на нарушения по полям.
+// Порядок стабилен, чтобы клиенты и тесты могли на него опираться.
+func fieldViolations(err error) []models.FieldViolation {
+	var violations []models.FieldViolation
+	collectViolations(err, "", &violations)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+	return violations
+}
+
+func collectViolations(err error, field string, out *[]models.FieldViolation) {
+	// Разбираем ошибки по типам без errors.As: обёртки вложены друг в друга,
+	// и разворачивание цепочки потеряло бы имя параметра
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectViolations(inner, field, out)
+		}
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			collectViolations(unwrapOr(e), e.Parameter.Name, out)
+		case e.RequestBody != nil:
+			var parseErr *openapi3filter.ParseError
+			if errors.As(e.Err, &parseErr) {
+				*out = append(*out, models.FieldViolation{Field: bodyField, Message: "request body is not valid JSON"})
+				return
+			}
+			collectViolations(unwrapOr(e), "", out)
+		default:
+			*out = append(*out, models.FieldViolation{Field: orBody(field), Message: e.Error()})
+		}
+	case *openapi3.SchemaError:
+		path := e.JSONPointer()
+		if field != "" {
+			path = append([]string{field}, path...)
+		}
+		*out = append(*out, models.FieldViolation{Field: orBody(strings.Join(path, ".")), Message: e.Reason})
+	default:
+		*out = append(*out, models.FieldViolation{Field: orBody(field), Message: err.Error()})
+	}
+}
+
+// unwrapOr возвращает причину ошибки, а если её нет — саму ошибку без обёртки
+func unwrapOr(reqErr *openapi3filter.RequestError) error {
+	if reqErr.Err != nil {
+		return reqErr.Err
+	}
+	return errors.New(reqErr.Reason)
+}
+
+func orBody(field string) string {
+	if field == "" {
+		return bodyField
+	}
+	return field
+}
+
+func writeValidationError(w http.ResponseWriter, violations []models.FieldViolation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: models.ErrorDetail{
+			Code:    "INVALID_REQUEST",
+			Message: "Request does not match the API specification",
+			Details: violations,
+		},
+	}); err != nil {
+		slog.Error("failed to encode error response", "error", err)
+	}
+}
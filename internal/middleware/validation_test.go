@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	reviewerservice "github.com/reviewer-service"
+	"github.com/reviewer-service/internal/models"
+)
+
+func TestRequestValidationMiddleware(t *testing.T) {
+	validation, err := RequestValidationMiddleware(reviewerservice.OpenAPISpec, setupTestLogger())
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	calls := 0
+	handler := validation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		contentType string
+		wantStatus  int
+		wantDetails []models.FieldViolation
+	}{
+		{
+			name:       "valid body",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "body without content type",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"pr-1"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "empty ids and missing field",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"","author_id":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "author_id", Message: "minimum string length is 1"},
+				{Field: "pull_request_id", Message: "minimum string length is 1"},
+				{Field: "pull_request_name", Message: `property "pull_request_name" is missing`},
+			},
+		},
+		{
+			name:       "unknown field",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"pr-1","force":true}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "body", Message: `property "force" is unsupported`},
+			},
+		},
+		{
+			name:       "wrong types in nested objects",
+			method:     http.MethodPost,
+			target:     "/team/add",
+			body:       `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":"yes"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "members.0.is_active", Message: "value must be a boolean"},
+			},
+		},
+		{
+			name:       "too long id",
+			method:     http.MethodPost,
+			target:     "/users/setIsActive",
+			body:       `{"user_id":"` + strings.Repeat("u", 256) + `","is_active":true}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "user_id", Message: "maximum string length is 255"},
+			},
+		},
+		{
+			name:       "malformed json",
+			method:     http.MethodPost,
+			target:     "/team/deactivateMembers",
+			body:       `{"team_name":`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "body", Message: "request body is not valid JSON"},
+			},
+		},
+		{
+			name:       "missing body",
+			method:     http.MethodPost,
+			target:     "/pullRequest/reassign",
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "body", Message: "value is required but missing"},
+			},
+		},
+		{
+			name:       "missing query parameter",
+			method:     http.MethodGet,
+			target:     "/users/getReview",
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "user_id", Message: "value is required but missing"},
+			},
+		},
+		{
+			name:       "empty query parameter",
+			method:     http.MethodGet,
+			target:     "/team/get?team_name=",
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "team_name", Message: "minimum string length is 1"},
+			},
+		},
+		{
+			name:        "bulk import body is left to the handler",
+			method:      http.MethodPost,
+			target:      "/users/import",
+			body:        "not json\n",
+			contentType: "application/x-ndjson",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "scim body is checked as json",
+			method:      http.MethodPost,
+			target:      "/scim/v2/Users",
+			body:        `{"displayName":"Sam"}`,
+			contentType: "application/scim+json",
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "userName", Message: `property "userName" is missing`},
+			},
+		},
+		{
+			name:       "body too large",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"` + strings.Repeat("x", maxValidatedBodyBytes) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []models.FieldViolation{
+				{Field: "body", Message: "request body exceeds 1048576 bytes"},
+			},
+		},
+		{
+			name:       "route outside spec",
+			method:     http.MethodGet,
+			target:     "/docs",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if calls != 1 {
+					t.Errorf("expected handler to be called once, got %d", calls)
+				}
+				return
+			}
+			if calls != 0 {
+				t.Error("handler must not be called for invalid request")
+			}
+
+			var resp models.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error.Code != "INVALID_REQUEST" {
+				t.Errorf("expected INVALID_REQUEST, got %s", resp.Error.Code)
+			}
+			if !reflect.DeepEqual(resp.Error.Details, tt.wantDetails) {
+				t.Errorf("unexpected details:\n got  %+v\n want %+v", resp.Error.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestRequestValidationMiddleware_InvalidSpec(t *testing.T) {
+	if _, err := RequestValidationMiddleware([]byte("openapi: 3.0.3\npaths: [}"), setupTestLogger()); err == nil {
+		t.Error("expected error for invalid spec")
+	}
+}
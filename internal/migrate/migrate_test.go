@@ -0,0 +1,223 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "migrate.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1`, name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	return count > 0
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db := openTestDB(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	m, err := New(db, DriverSQLite, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Fatalf("Up() applied %d migrations, want %d", len(applied), len(m.migrations))
+	}
+	for _, table := range []string{"teams", "users", "pull_requests", "pr_reviewers", "idempotency_keys", "pr_events"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s not created", table)
+		}
+	}
+
+	again, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up() error = %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second Up() applied %d migrations, want 0", len(again))
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	last := m.migrations[len(m.migrations)-1]
+	if len(reverted) != 1 || reverted[0].Version != last.Version {
+		t.Fatalf("Down(1) reverted %v, want version %d", reverted, last.Version)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, s := range statuses {
+		want := s.Version != last.Version
+		if s.Applied != want {
+			t.Errorf("version %d applied = %v, want %v", s.Version, s.Applied, want)
+		}
+		if s.Applied && s.AppliedAt == nil {
+			t.Errorf("version %d has no applied_at", s.Version)
+		}
+	}
+
+	if _, err := m.Down(ctx, 100); err != nil {
+		t.Fatalf("Down(all) error = %v", err)
+	}
+	if tableExists(t, db, "teams") {
+		t.Error("teams table still exists after full rollback")
+	}
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := openTestDB(t)
+	m := &Migrator{
+		db:     db,
+		driver: DriverSQLite,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		migrations: []Migration{
+			{Version: 1, Name: "broken", Up: `CREATE TABLE partial (id INTEGER); SELECT * FROM missing_table;`},
+		},
+	}
+
+	if _, err := m.Up(context.Background()); err == nil {
+		t.Fatal("Up() error = nil, want error")
+	}
+	if tableExists(t, db, "partial") {
+		t.Error("partial table exists after failed migration")
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Applied {
+		t.Errorf("Status() = %+v, want single pending migration", statuses)
+	}
+}
+
+// Пересоздание родительской таблицы в SQLite требует выключенных внешних
+// ключей; после миграции проверка должна снова работать, а висячие ссылки —
+// откатывать миграцию.
+func TestMigrator_SQLiteTableRebuild(t *testing.T) {
+	db := openTestDB(t)
+	// Одно соединение: проверяем, что мигратор вернул ему foreign_keys
+	db.SetMaxOpenConns(1)
+	m := &Migrator{
+		db:     db,
+		driver: DriverSQLite,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		migrations: []Migration{
+			{Version: 1, Name: "init", Up: `
+				CREATE TABLE parent (id TEXT PRIMARY KEY, name TEXT NOT NULL);
+				CREATE TABLE child (id TEXT PRIMARY KEY, parent_id TEXT NOT NULL REFERENCES parent(id));
+				INSERT INTO parent VALUES ('p1', 'first');
+				INSERT INTO child VALUES ('c1', 'p1');`},
+			{Version: 2, Name: "rebuild_parent", Up: `
+				CREATE TABLE parent_new (id TEXT PRIMARY KEY, name TEXT);
+				INSERT INTO parent_new (id, name) SELECT id, name FROM parent;
+				DROP TABLE parent;
+				ALTER TABLE parent_new RENAME TO parent;`},
+		},
+	}
+	ctx := context.Background()
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO parent VALUES ('p2', NULL)`); err != nil {
+		t.Errorf("NOT NULL constraint was not dropped: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO child VALUES ('c2', 'missing')`); err == nil {
+		t.Error("foreign keys are not enforced after migration")
+	}
+
+	m.migrations = append(m.migrations, Migration{Version: 3, Name: "dangling", Up: `DELETE FROM parent WHERE id = 'p1';`})
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Up() error = nil, want foreign key violation")
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM parent WHERE id = 'p1'`).Scan(&count); err != nil || count != 1 {
+		t.Errorf("dangling migration was not rolled back: count = %d, err = %v", count, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"010_b.up.sql":   {Data: []byte("SELECT 1")},
+				"002_a.up.sql":   {Data: []byte("SELECT 1")},
+				"002_a.down.sql": {Data: []byte("SELECT 1")},
+			},
+			want: []int64{2, 10},
+		},
+		{
+			name:    "bad file name",
+			files:   fstest.MapFS{"init.sql": {Data: []byte("SELECT 1")}},
+			wantErr: true,
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"001_a.down.sql": {Data: []byte("SELECT 1")}},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"001_a.up.sql": {Data: []byte("SELECT 1")},
+				"001_b.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := Load(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(list) != len(tt.want) {
+				t.Fatalf("Load() returned %d migrations, want %d", len(list), len(tt.want))
+			}
+			for i, v := range tt.want {
+				if list[i].Version != v {
+					t.Errorf("list[%d].Version = %d, want %d", i, list[i].Version, v)
+				}
+			}
+		})
+	}
+}
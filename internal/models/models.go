@@ -2,24 +2,47 @@ package models
 
 import "time"
 
+// User — архивный пользователь (ArchivedAt != nil) остаётся в истории PR,
+// но не считается участником команды и не назначается ревьювером.
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	TeamName   string     `json:"team_name"`
+	IsActive   bool       `json:"is_active"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	IsActive   bool       `json:"is_active"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName   string       `json:"team_name"`
+	Members    []TeamMember `json:"members"`
+	ArchivedAt *time.Time   `json:"archived_at,omitempty"`
+}
+
+// SyncChange — изменение, которое синхронизация с манифестом команд вносит
+// в БД. PreviousTeam заполняется для MOVE_USER.
+type SyncChange struct {
+	Action       string `json:"action"`
+	TeamName     string `json:"team_name"`
+	UserID       string `json:"user_id,omitempty"`
+	PreviousTeam string `json:"previous_team,omitempty"`
 }
 
+// Действия синхронизации команд
+const (
+	SyncCreateTeam     = "CREATE_TEAM"
+	SyncCreateUser     = "CREATE_USER"
+	SyncUpdateUser     = "UPDATE_USER"
+	SyncMoveUser       = "MOVE_USER"
+	SyncDeactivateUser = "DEACTIVATE_USER"
+)
+
 type PullRequest struct {
 	PullRequestID     string     `json:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name"`
@@ -30,11 +53,196 @@ type PullRequest struct {
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
 }
 
+// PullRequestShort — запись во входящих ревью пользователя. Для собственных
+// PR (Role = AUTHOR) AssignedAt равен времени создания PR.
+// ReviewWorkingAgeSeconds — тот же возраст в рабочем времени пользователя.
 type PullRequestShort struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
-	Status          string `json:"status"`
+	PullRequestID           string     `json:"pull_request_id"`
+	PullRequestName         string     `json:"pull_request_name"`
+	AuthorID                string     `json:"author_id"`
+	Status                  string     `json:"status"`
+	Role                    string     `json:"role"`
+	AssignedAt              *time.Time `json:"assigned_at,omitempty"`
+	MergedAt                *time.Time `json:"mergedAt,omitempty"`
+	ReviewAgeSeconds        int64      `json:"review_age_seconds"`
+	ReviewWorkingAgeSeconds int64      `json:"review_working_age_seconds"`
+}
+
+// Роль пользователя в PR из входящих ревью
+const (
+	ReviewRoleReviewer = "REVIEWER"
+	ReviewRoleAuthor   = "AUTHOR"
+)
+
+// Типы событий журнала изменений PR.
+const (
+	EventPRCreated        = "PR_CREATED"
+	EventPRMerged         = "PR_MERGED"
+	EventReviewerAssigned = "REVIEWER_ASSIGNED"
+	EventReviewerRemoved  = "REVIEWER_REMOVED"
+	EventAuthorChanged    = "AUTHOR_CHANGED"
+	EventReviewSubmitted  = "REVIEW_SUBMITTED"
+	EventUserDeactivated  = "USER_DEACTIVATED" // без PR
+)
+
+type PREvent struct {
+	ID             int64     `json:"id"`
+	PullRequestID  string    `json:"pull_request_id,omitempty"`
+	Type           string    `json:"type"`
+	UserID         string    `json:"user_id,omitempty"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// StreamEvent — событие журнала для /events/stream с автором PR и командой:
+// для событий PR это команда автора, для USER_DEACTIVATED — пользователя.
+type StreamEvent struct {
+	PREvent
+	AuthorID string `json:"author_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+}
+
+// WorkingHours — недельное расписание пользователя в часовом поясе IANA
+// (например, Europe/Moscow). Periods — рабочие промежутки по дням недели.
+type WorkingHours struct {
+	TimeZone string          `json:"time_zone"`
+	Periods  []WorkingPeriod `json:"periods"`
+}
+
+// WorkingPeriod — промежуток "HH:MM"–"HH:MM" дня Weekday (monday ... sunday);
+// End "24:00" — до конца суток.
+type WorkingPeriod struct {
+	Weekday string `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// Holiday — нерабочий день команды, дата YYYY-MM-DD в часовом поясе участника
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+// NotificationPreferences — адрес пользователя и подписки на письма: утренняя
+// сводка открытых ревью, уведомления о назначении и напоминания о сроках.
+type NotificationPreferences struct {
+	UserID            string `json:"user_id"`
+	Email             string `json:"email"`
+	DailyDigest       bool   `json:"daily_digest"`
+	AssignmentNotices bool   `json:"assignment_notices"`
+	OverdueReminders  bool   `json:"overdue_reminders"`
+}
+
+// TeamSLA — срок первого ревью для PR авторов команды. Если задан
+// ReassignAfterHours, по его истечении ревьювер заменяется автоматически.
+type TeamSLA struct {
+	TeamName           string `json:"team_name"`
+	FirstReviewHours   int    `json:"first_review_hours"`
+	ReassignAfterHours *int   `json:"reassign_after_hours,omitempty"`
+}
+
+// OverdueReview — назначение в открытом PR, по которому ревью не отправлено
+// дольше срока команды автора. ReassignAt заполняется, если у команды
+// включена автоматическая замена ревьювера.
+type OverdueReview struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	ReviewerID      string     `json:"reviewer_id"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	DueAt           time.Time  `json:"due_at"`
+	OverdueSeconds  int64      `json:"overdue_seconds"`
+	RemindedAt      *time.Time `json:"reminded_at,omitempty"`
+	ReassignAt      *time.Time `json:"reassign_at,omitempty"`
+}
+
+// Оценка нагрузки участника относительно среднего по команде
+const (
+	LoadOverloaded  = "OVERLOADED"
+	LoadUnderloaded = "UNDERLOADED"
+	LoadBalanced    = "BALANCED"
+	LoadInactive    = "INACTIVE"
+)
+
+type MemberLoad struct {
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	IsActive         bool   `json:"is_active"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+	OpenAuthored     int    `json:"open_authored"`
+	TotalAuthored    int    `json:"total_authored"`
+	Load             string `json:"load"`
+}
+
+// TeamFairness — равномерность открытых назначений среди активных участников.
+// Gini: 0 — поровну, ближе к 1 — всё у одного. MaxMinRatio не заполняется,
+// если у кого-то нет открытых назначений.
+type TeamFairness struct {
+	MeanOpenAssignments float64  `json:"mean_open_assignments"`
+	Gini                float64  `json:"gini"`
+	MaxMinRatio         *float64 `json:"max_min_ratio,omitempty"`
+}
+
+type TeamStatistics struct {
+	TeamName         string       `json:"team_name"`
+	OpenAssignments  int          `json:"open_assignments"`
+	TotalAssignments int          `json:"total_assignments"`
+	Fairness         TeamFairness `json:"fairness"`
+	Members          []MemberLoad `json:"members"`
+}
+
+// Интервалы группировки /statistics/timings: сутки и недели с понедельника, UTC
+const (
+	TimingBucketDay  = "day"
+	TimingBucketWeek = "week"
+)
+
+// Percentiles — перцентили длительностей в секундах методом ближайшего
+// ранга. Без замеров (Count = 0) перцентили не заполняются.
+type Percentiles struct {
+	Count int    `json:"count"`
+	P50   *int64 `json:"p50_seconds,omitempty"`
+	P90   *int64 `json:"p90_seconds,omitempty"`
+	P99   *int64 `json:"p99_seconds,omitempty"`
+}
+
+type TimingSummary struct {
+	TimeToMerge       Percentiles `json:"time_to_merge"`
+	TimeToFirstReview Percentiles `json:"time_to_first_review"`
+}
+
+type TeamTimings struct {
+	TeamName string `json:"team_name"`
+	TimingSummary
+}
+
+type ReviewerTimings struct {
+	UserID string `json:"user_id"`
+	TimingSummary
+}
+
+type TimingBreakdown struct {
+	Overall    TimingSummary     `json:"overall"`
+	ByTeam     []TeamTimings     `json:"by_team"`
+	ByReviewer []ReviewerTimings `json:"by_reviewer"`
+}
+
+type TimingBucket struct {
+	Start time.Time `json:"start"`
+	TimingBreakdown
+}
+
+// ReviewTimings — ответ /statistics/timings: итог за [From, To) и те же
+// показатели по интервалам Bucket. WorkingHours — длительности в рабочем времени.
+type ReviewTimings struct {
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Bucket       string          `json:"bucket"`
+	WorkingHours bool            `json:"working_hours"`
+	Total        TimingBreakdown `json:"total"`
+	Buckets      []TimingBucket  `json:"buckets"`
 }
 
 type ErrorResponse struct {
@@ -42,12 +250,22 @@ type ErrorResponse struct {
 }
 
 type ErrorDetail struct {
-	Code    string `json:"code"`
+	Code    string           `json:"code"`
+	Message string           `json:"message"`
+	Details []FieldViolation `json:"details,omitempty"`
+}
+
+// FieldViolation описывает нарушение схемы в конкретном поле запроса
+type FieldViolation struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
+// Statistics — снимок /statistics; GeneratedAt — момент подсчёта,
+// для ответа из кэша он старше момента запроса.
 type Statistics struct {
-	Teams struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Teams       struct {
 		Total int `json:"total"`
 	} `json:"teams"`
 	Users struct {
@@ -64,9 +282,43 @@ type Statistics struct {
 		Total        int                    `json:"total"`
 		ByReviewer   []ReviewerAssignment   `json:"by_reviewer"`
 	} `json:"review_assignments"`
+	Load *ReviewLoad `json:"load,omitempty"`
+}
+
+// ReviewLoad — открытые и все назначения по командам и ревьюверам для PR,
+// созданных в [From, To); без границ учитываются все PR.
+type ReviewLoad struct {
+	From       *time.Time     `json:"from,omitempty"`
+	To         *time.Time     `json:"to,omitempty"`
+	ByTeam     []TeamLoad     `json:"by_team"`
+	ByReviewer []ReviewerLoad `json:"by_reviewer"`
+}
+
+type TeamLoad struct {
+	TeamName         string `json:"team_name"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+// ReviewerLoad.TeamName — текущая неархивная команда ревьювера, пусто если её нет
+type ReviewerLoad struct {
+	UserID           string `json:"user_id"`
+	TeamName         string `json:"team_name,omitempty"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
 }
 
 type ReviewerAssignment struct {
 	UserID  string `json:"user_id"`
 	Count   int    `json:"count"`
 }
+
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	Completed   bool
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	auth string
+	tls  bool
+	data []byte
+}
+
+// fakeSMTP — SMTP-сервер в процессе теста: понимает EHLO, STARTTLS,
+// AUTH PLAIN, MAIL, RCPT, DATA и QUIT и складывает письма в mails.
+type fakeSMTP struct {
+	ln        net.Listener
+	tlsConfig *tls.Config // nil — STARTTLS не предлагается
+
+	mu    sync.Mutex
+	mails []receivedMail
+}
+
+func startFakeSMTP(t *testing.T, tlsConfig *tls.Config, implicitTLS bool) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if implicitTLS {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	s := &fakeSMTP{ln: ln, tlsConfig: tlsConfig}
+	if implicitTLS {
+		s.tlsConfig = nil
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, implicitTLS)
+		}
+	}()
+	return s
+}
+
+func portOf(addr net.Addr) string {
+	_, port, _ := net.SplitHostPort(addr.String())
+	return port
+}
+
+func (s *fakeSMTP) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.mails...)
+}
+
+func (s *fakeSMTP) serve(conn net.Conn, secure bool) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var cur receivedMail
+	tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			ext := []string{"250-fake", "250-AUTH PLAIN"}
+			if s.tlsConfig != nil && !secure {
+				ext = append(ext, "250-STARTTLS")
+			}
+			for _, l := range ext {
+				tp.PrintfLine("%s", l)
+			}
+			tp.PrintfLine("250 8BITMIME")
+		case "STARTTLS":
+			tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			_, creds, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(creds)
+			cur.auth = string(decoded)
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			cur.from = strings.Trim(strings.TrimPrefix(strings.Fields(arg)[0], "FROM:"), "<>")
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			cur.to = append(cur.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 end with <CRLF>.<CRLF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			cur.data, cur.tls = data, secure
+			s.mu.Lock()
+			s.mails = append(s.mails, cur)
+			s.mu.Unlock()
+			cur = receivedMail{}
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// selfSignedTLS возвращает конфиг сервера и конфиг клиента, доверяющий ему
+func selfSignedTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	return server, client
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+
+	tests := []struct {
+		name     string
+		mode     string
+		implicit bool
+		username string
+	}{
+		{name: "plain relay", mode: TLSNone},
+		{name: "starttls with auth", mode: TLSStartTLS, username: "bot"},
+		{name: "implicit tls", mode: TLSImplicit, implicit: true, username: "bot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, serverTLS, tt.implicit)
+			sender, err := NewSMTPSender(SMTPConfig{
+				Host: "127.0.0.1", Port: portOf(srv.ln.Addr()), TLS: tt.mode, TLSConfig: clientTLS,
+				Username: tt.username, Password: "secret", From: "Reviewer Service <reviews@example.com>",
+			})
+			if err != nil {
+				t.Fatalf("NewSMTPSender() error = %v", err)
+			}
+
+			err = sender.Send(context.Background(), Message{
+				To:      "alice@example.com",
+				Subject: "Ревью ждёт: Add search",
+				Body:    "Hi Alice,\n" + strings.Repeat("long line ", 20) + "\n",
+			})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			mails := srv.received()
+			if len(mails) != 1 {
+				t.Fatalf("expected one mail, got %d", len(mails))
+			}
+			got := mails[0]
+			if got.from != "reviews@example.com" || len(got.to) != 1 || got.to[0] != "alice@example.com" {
+				t.Errorf("unexpected envelope: from %q to %v", got.from, got.to)
+			}
+			if got.tls != (tt.mode != TLSNone) {
+				t.Errorf("expected tls=%v, got %v", tt.mode != TLSNone, got.tls)
+			}
+			if wantAuth := "\x00bot\x00secret"; tt.username != "" && got.auth != wantAuth {
+				t.Errorf("expected PLAIN auth %q, got %q", wantAuth, got.auth)
+			}
+
+			msg, err := mail.ReadMessage(strings.NewReader(string(got.data)))
+			if err != nil {
+				t.Fatalf("invalid message: %v", err)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil || subject != "Ревью ждёт: Add search" {
+				t.Errorf("unexpected subject %q, %v", subject, err)
+			}
+			if msg.Header.Get("Message-ID") == "" || msg.Header.Get("Date") == "" {
+				t.Errorf("expected Message-ID and Date headers, got %v", msg.Header)
+			}
+			// ReadDotBytes уже заменил CRLF на LF; quoted-printable держит строки тела короче 78
+			_, rawBody, _ := strings.Cut(string(got.data), "\n\n")
+			for _, line := range strings.Split(rawBody, "\n") {
+				if len(line) > 78 {
+					t.Errorf("line is longer than 78 characters: %q", line)
+				}
+			}
+			body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+			if err != nil || !strings.HasPrefix(string(body), "Hi Alice,\nlong line long line") {
+				t.Errorf("unexpected body %q, %v", body, err)
+			}
+		})
+	}
+}
+
+func TestSMTPSender_RequiresStartTLS(t *testing.T) {
+	srv := startFakeSMTP(t, nil, false)
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: portOf(srv.ln.Addr()), TLS: TLSStartTLS, From: "reviews@example.com"})
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	err = sender.Send(context.Background(), Message{To: "alice@example.com", Subject: "s", Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected STARTTLS error, got %v", err)
+	}
+	if len(srv.received()) != 0 {
+		t.Error("mail must not be sent without TLS")
+	}
+}
+
+func TestSMTPSender_RejectsHeaderInjection(t *testing.T) {
+	srv := startFakeSMTP(t, nil, false)
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: portOf(srv.ln.Addr()), TLS: TLSNone, From: "reviews@example.com"})
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+	msgs := []Message{
+		{To: "alice@example.com", Subject: "hi\r\nBcc: eve@example.com", Body: "b"},
+		{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "hi", Body: "b"},
+	}
+	for _, msg := range msgs {
+		if err := sender.Send(context.Background(), msg); err == nil {
+			t.Errorf("expected error for %+v", msg)
+		}
+	}
+	if len(srv.received()) != 0 {
+		t.Error("no mail must be sent")
+	}
+}
+
+func TestNewSMTPSender_Validation(t *testing.T) {
+	configs := []SMTPConfig{
+		{Port: "25", TLS: TLSNone, From: "reviews@example.com"},
+		{Host: "smtp.example.com", Port: "25", TLS: "ssl", From: "reviews@example.com"},
+		{Host: "smtp.example.com", Port: "25", TLS: TLSNone, From: "not an address"},
+	}
+	for _, cfg := range configs {
+		if _, err := NewSMTPSender(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
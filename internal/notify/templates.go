@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// Имена шаблонов; файл шаблона — <имя>.tmpl с блоками "subject" и "body"
+const (
+	TemplateDigest   = "digest"
+	TemplateAssigned = "assigned"
+	TemplateOverdue  = "overdue"
+)
+
+var templateNames = []string{TemplateDigest, TemplateAssigned, TemplateOverdue}
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// DigestData — утренняя сводка: открытые ревью пользователя, дольше ждущие первыми
+type DigestData struct {
+	Username string
+	Date     string
+	Reviews  []*models.PullRequestShort
+}
+
+// AssignmentData — уведомление о назначении; PreviousReviewerID заполнен,
+// если пользователь заменил другого ревьювера.
+type AssignmentData struct {
+	Username           string
+	PullRequest        *models.PullRequest
+	PreviousReviewerID string
+}
+
+// OverdueData — напоминание о просроченном ревью
+type OverdueData struct {
+	Username string
+	Review   models.OverdueReview
+}
+
+type Templates struct {
+	sets map[string]*template.Template
+}
+
+// LoadTemplates берёт встроенные шаблоны и заменяет их файлами <имя>.tmpl
+// из dir, если они там есть; пустой dir — только встроенные.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{sets: make(map[string]*template.Template, len(templateNames))}
+	for _, name := range templateNames {
+		src, err := fs.ReadFile(defaultTemplates, "templates/"+name+".tmpl")
+		if err != nil {
+			return nil, err
+		}
+		if dir != "" {
+			custom, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+			switch {
+			case err == nil:
+				src = custom
+			case !errors.Is(err, fs.ErrNotExist):
+				return nil, err
+			}
+		}
+		set, err := template.New(name).Funcs(template.FuncMap{"duration": formatSeconds}).Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		if set.Lookup("subject") == nil || set.Lookup("body") == nil {
+			return nil, fmt.Errorf("template %s must define \"subject\" and \"body\"", name)
+		}
+		t.sets[name] = set
+	}
+	return t, nil
+}
+
+// Render возвращает письмо без получателя; тема сводится к одной строке
+func (t *Templates) Render(name string, data any) (Message, error) {
+	set, ok := t.sets[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown template %q", name)
+	}
+	var subject, body strings.Builder
+	if err := set.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := set.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Body:    strings.TrimLeft(body.String(), "\n"),
+	}, nil
+}
+
+// formatSeconds показывает длительность крупными единицами: "2d 3h", "5h 10m", "4m"
+func formatSeconds(seconds int64) string {
+	days, hours, minutes := seconds/86400, seconds%86400/3600, seconds%3600/60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	digest, err := templates.Render(TemplateDigest, DigestData{
+		Username: "Bob",
+		Date:     "2025-10-22",
+		Reviews: []*models.PullRequestShort{
+			{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", ReviewAgeSeconds: 2*86400 + 3*3600},
+			{PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "u3", ReviewAgeSeconds: 600},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render(digest) error = %v", err)
+	}
+	if digest.Subject != "2 open reviews waiting for you" {
+		t.Errorf("unexpected digest subject %q", digest.Subject)
+	}
+	for _, want := range []string{"Good morning, Bob!", `pr-1 "Add search" by u1 - waiting 2d 3h`, `pr-2 "Fix login" by u3 - waiting 10m`} {
+		if !strings.Contains(digest.Body, want) {
+			t.Errorf("digest body lacks %q:\n%s", want, digest.Body)
+		}
+	}
+
+	pr := &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"}
+	assigned, err := templates.Render(TemplateAssigned, AssignmentData{Username: "Bob", PullRequest: pr})
+	if err != nil || assigned.Subject != "Review requested: Add search" || !strings.Contains(assigned.Body, "You were assigned to review pr-1") {
+		t.Errorf("unexpected assignment notice %+v, %v", assigned, err)
+	}
+	replaced, err := templates.Render(TemplateAssigned, AssignmentData{Username: "Bob", PullRequest: pr, PreviousReviewerID: "u4"})
+	if err != nil || !strings.Contains(replaced.Body, "You replace u4 as a reviewer of pr-1") {
+		t.Errorf("unexpected reassignment notice %+v, %v", replaced, err)
+	}
+
+	reassignAt := time.Date(2025, 10, 23, 9, 0, 0, 0, time.UTC)
+	overdue, err := templates.Render(TemplateOverdue, OverdueData{Username: "Bob", Review: models.OverdueReview{
+		PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1",
+		DueAt: time.Date(2025, 10, 22, 9, 0, 0, 0, time.UTC), OverdueSeconds: 5400, ReassignAt: &reassignAt,
+	}})
+	if err != nil || !strings.Contains(overdue.Body, "is 1h 30m overdue") || !strings.Contains(overdue.Body, "reassigned at 2025-10-23 09:00 UTC") {
+		t.Errorf("unexpected overdue notice %+v, %v", overdue, err)
+	}
+}
+
+func TestLoadTemplates_CustomDir(t *testing.T) {
+	dir := t.TempDir()
+	custom := `{{define "subject"}}Ревью: {{len .Reviews}}{{end}}{{define "body"}}Привет, {{.Username}}{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, TemplateDigest+".tmpl"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	digest, err := templates.Render(TemplateDigest, DigestData{Username: "Bob"})
+	if err != nil || digest.Subject != "Ревью: 0" || digest.Body != "Привет, Bob" {
+		t.Errorf("expected custom digest, got %+v, %v", digest, err)
+	}
+	// Остальные шаблоны остаются встроенными
+	if _, err := templates.Render(TemplateAssigned, AssignmentData{PullRequest: &models.PullRequest{}}); err != nil {
+		t.Errorf("expected built-in assignment template, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, TemplateOverdue+".tmpl"), []byte(`{{define "subject"}}x{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Error("expected error for template without body")
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// ChatRepository — привязка пользователей чата (Slack) к users.user_id.
+// У каждой стороны не больше одной привязки.
+type ChatRepository interface {
+	LinkUser(chatUserID, userID string) error
+	GetUserID(chatUserID string) (string, error)
+}
+
+type chatRepository struct {
+	db *sql.DB
+}
+
+func NewChatRepository(db *sql.DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+// LinkUser заменяет прежние привязки chatUserID и userID; sql.ErrNoRows —
+// пользователя нет или он в архиве.
+func (r *chatRepository) LinkUser(chatUserID, userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM users WHERE user_id = $1 AND archived_at IS NULL`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM chat_users WHERE chat_user_id = $1 OR user_id = $2`, chatUserID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO chat_users (chat_user_id, user_id) VALUES ($1, $2)`, chatUserID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetUserID возвращает sql.ErrNoRows, если привязки нет или пользователь в архиве
+func (r *chatRepository) GetUserID(chatUserID string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(`
+		SELECT c.user_id FROM chat_users c
+		JOIN users u ON u.user_id = c.user_id
+		WHERE c.chat_user_id = $1 AND u.archived_at IS NULL`, chatUserID).Scan(&userID)
+	if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// EventFilter отбирает события журнала; пустые поля не ограничивают.
+// UserID совпадает с пользователем события, заменённым пользователем или
+// автором PR, TeamName — с командой события (см. models.StreamEvent).
+type EventFilter struct {
+	Types         []string
+	TeamName      string
+	UserID        string
+	PullRequestID string
+}
+
+// Matches проверяет событие по тем же правилам, что и ListAfter.
+func (f EventFilter) Matches(e *models.StreamEvent) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.TeamName != "" && e.TeamName != f.TeamName {
+		return false
+	}
+	if f.PullRequestID != "" && e.PullRequestID != f.PullRequestID {
+		return false
+	}
+	if f.UserID != "" && e.UserID != f.UserID && e.PreviousUserID != f.UserID && e.AuthorID != f.UserID {
+		return false
+	}
+	return true
+}
+
+// EventRepository читает журнал событий для /events/stream. ListAfter
+// возвращает до limit событий с id > afterID по возрастанию id; команда
+// и автор берутся на момент чтения.
+type EventRepository interface {
+	ListAfter(filter EventFilter, afterID int64, limit int) ([]*models.StreamEvent, error)
+	LastID() (int64, error)
+}
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+// eventTeam — команда автора PR, для событий без PR — команда пользователя.
+const eventTeam = `CASE WHEN e.pull_request_id IS NULL THEN u.team_name ELSE a.team_name END`
+
+func (r *eventRepository) ListAfter(filter EventFilter, afterID int64, limit int) ([]*models.StreamEvent, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"e.id > " + arg(afterID)}
+	if len(filter.Types) > 0 {
+		types, typeArgs := inPlaceholders(len(args)+1, filter.Types)
+		args = append(args, typeArgs...)
+		conditions = append(conditions, "e.event_type IN ("+types+")")
+	}
+	if filter.TeamName != "" {
+		conditions = append(conditions, eventTeam+" = "+arg(filter.TeamName))
+	}
+	if filter.PullRequestID != "" {
+		conditions = append(conditions, "e.pull_request_id = "+arg(filter.PullRequestID))
+	}
+	if filter.UserID != "" {
+		p := arg(filter.UserID)
+		conditions = append(conditions, "(e.user_id = "+p+" OR e.previous_user_id = "+p+" OR pr.author_id = "+p+")")
+	}
+
+	query := `
+		SELECT e.id, COALESCE(e.pull_request_id, ''), e.event_type, COALESCE(e.user_id, ''),
+			COALESCE(e.previous_user_id, ''), e.created_at, COALESCE(pr.author_id, ''), COALESCE(` + eventTeam + `, '')
+		FROM pr_events e
+		LEFT JOIN pull_requests pr ON pr.pull_request_id = e.pull_request_id
+		LEFT JOIN users a ON a.user_id = pr.author_id
+		LEFT JOIN users u ON u.user_id = e.user_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY e.id
+		LIMIT ` + arg(limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.StreamEvent, 0)
+	for rows.Next() {
+		var e models.StreamEvent
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.UserID, &e.PreviousUserID, &e.CreatedAt, &e.AuthorID, &e.TeamName); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = e.CreatedAt.UTC()
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+func (r *eventRepository) LastID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM pr_events`).Scan(&id)
+	return id, err
+}
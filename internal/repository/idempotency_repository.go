@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+type IdempotencyRepository interface {
+	Reserve(key, requestHash string, expiresAt time.Time) (*models.IdempotencyRecord, error)
+	Save(key string, statusCode int, contentType string, body []byte) error
+	Delete(key string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve занимает ключ за запросом. Если ключ уже занят, возвращает
+// существующую запись (возможно, ещё без сохранённого ответа), иначе nil.
+func (r *idempotencyRepository) Reserve(key, requestHash string, expiresAt time.Time) (*models.IdempotencyRecord, error) {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND expires_at < $2`, key, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO idempotency_keys (idempotency_key, request_hash, expires_at) VALUES ($1, $2, $3) ON CONFLICT (idempotency_key) DO NOTHING`
+	res, err := r.db.Exec(query, key, requestHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if inserted == 1 {
+		return nil, nil
+	}
+
+	rec := &models.IdempotencyRecord{Key: key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	query = `SELECT request_hash, status_code, content_type, response_body, expires_at FROM idempotency_keys WHERE idempotency_key = $1`
+	err = r.db.QueryRow(query, key).Scan(&rec.RequestHash, &statusCode, &contentType, &rec.Body, &rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	rec.Completed = statusCode.Valid
+	rec.StatusCode = int(statusCode.Int64)
+	rec.ContentType = contentType.String
+	return rec, nil
+}
+
+func (r *idempotencyRepository) Save(key string, statusCode int, contentType string, body []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3 WHERE idempotency_key = $4`
+	_, err := r.db.Exec(query, statusCode, contentType, body, key)
+	return err
+}
+
+func (r *idempotencyRepository) Delete(key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE idempotency_key = $1`, key)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"database/sql"
+
+	"github.com/reviewer-service/internal/repository"
+)
+
+type chatRepository struct {
+	store *Store
+}
+
+func NewChatRepository(store *Store) repository.ChatRepository {
+	return &chatRepository{store: store}
+}
+
+func (r *chatRepository) LinkUser(chatUserID, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if u, exists := r.store.users[userID]; !exists || u.user.ArchivedAt != nil {
+		return sql.ErrNoRows
+	}
+	for chatID, linked := range r.store.chatUsers {
+		if linked == userID {
+			delete(r.store.chatUsers, chatID)
+		}
+	}
+	r.store.chatUsers[chatUserID] = userID
+	return nil
+}
+
+func (r *chatRepository) GetUserID(chatUserID string) (string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	userID, ok := r.store.chatUsers[chatUserID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	if u, exists := r.store.users[userID]; !exists || u.user.ArchivedAt != nil {
+		return "", sql.ErrNoRows
+	}
+	return userID, nil
+}
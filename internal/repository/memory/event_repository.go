@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type eventRepository struct {
+	store *Store
+}
+
+func NewEventRepository(store *Store) repository.EventRepository {
+	return &eventRepository{store: store}
+}
+
+func (r *eventRepository) ListAfter(filter repository.EventFilter, afterID int64, limit int) ([]*models.StreamEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// id события — его номер в журнале, начиная с 1
+	events := make([]*models.StreamEvent, 0)
+	start := afterID
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < int64(len(r.store.events)) && len(events) < limit; i++ {
+		e := r.store.events[i]
+		se := &models.StreamEvent{PREvent: e}
+		if pr, ok := r.store.prs[e.PullRequestID]; ok {
+			se.AuthorID = pr.authorID
+			if author, ok := r.store.users[pr.authorID]; ok {
+				se.TeamName = author.user.TeamName
+			}
+		} else if u, ok := r.store.users[e.UserID]; ok {
+			se.TeamName = u.user.TeamName
+		}
+		if filter.Matches(se) {
+			events = append(events, se)
+		}
+	}
+	return events, nil
+}
+
+func (r *eventRepository) LastID() (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return int64(len(r.store.events)), nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type idempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) repository.IdempotencyRepository {
+	return &idempotencyRepository{store: store}
+}
+
+func (r *idempotencyRepository) Reserve(key, requestHash string, expiresAt time.Time) (*models.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if rec, exists := r.store.idempotency[key]; exists && !rec.ExpiresAt.Before(now()) {
+		copied := *rec
+		copied.Body = append([]byte(nil), rec.Body...)
+		return &copied, nil
+	}
+
+	r.store.idempotency[key] = &models.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   expiresAt,
+	}
+	return nil, nil
+}
+
+func (r *idempotencyRepository) Save(key string, statusCode int, contentType string, body []byte) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, exists := r.store.idempotency[key]
+	if !exists {
+		return nil
+	}
+	rec.StatusCode = statusCode
+	rec.ContentType = contentType
+	rec.Body = append([]byte(nil), body...)
+	rec.Completed = true
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotency, key)
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for key, rec := range r.store.idempotency {
+		if rec.ExpiresAt.Before(now) {
+			delete(r.store.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
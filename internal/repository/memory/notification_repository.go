@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type notificationRecord struct {
+	prefs        models.NotificationPreferences
+	lastDigestOn string
+}
+
+type notificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) repository.NotificationRepository {
+	return &notificationRepository{store: store}
+}
+
+func (r *notificationRepository) SetPreferences(p *models.NotificationPreferences) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if u, exists := r.store.users[p.UserID]; !exists || u.user.ArchivedAt != nil {
+		return sql.ErrNoRows
+	}
+	rec := r.store.notifications[p.UserID]
+	rec.prefs = *p
+	r.store.notifications[p.UserID] = rec
+	return nil
+}
+
+func (r *notificationRepository) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.notifications[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	p := rec.prefs
+	return &p, nil
+}
+
+func (r *notificationRepository) ListPreferences(userIDs []string) (map[string]*models.NotificationPreferences, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	result := make(map[string]*models.NotificationPreferences)
+	for _, userID := range userIDs {
+		if rec, ok := r.store.notifications[userID]; ok {
+			p := rec.prefs
+			result[userID] = &p
+		}
+	}
+	return result, nil
+}
+
+func (r *notificationRepository) ListDigestRecipients() ([]repository.DigestRecipient, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	recipients := make([]repository.DigestRecipient, 0)
+	for userID, rec := range r.store.notifications {
+		u, exists := r.store.users[userID]
+		if !rec.prefs.DailyDigest || !exists || !u.user.IsActive || u.user.ArchivedAt != nil {
+			continue
+		}
+		recipients = append(recipients, repository.DigestRecipient{
+			UserID:       userID,
+			Username:     u.user.Username,
+			TeamName:     u.user.TeamName,
+			Email:        rec.prefs.Email,
+			TimeZone:     r.store.schedules[userID].TimeZone,
+			LastDigestOn: rec.lastDigestOn,
+		})
+	}
+	sort.Slice(recipients, func(i, j int) bool { return recipients[i].UserID < recipients[j].UserID })
+	return recipients, nil
+}
+
+func (r *notificationRepository) MarkDigestSent(userID, date string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.notifications[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	rec.lastDigestOn = date
+	r.store.notifications[userID] = rec
+	return nil
+}
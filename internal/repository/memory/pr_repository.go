@@ -0,0 +1,479 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type pullRequestRepository struct {
+	store *Store
+}
+
+func NewPullRequestRepository(store *Store) repository.PullRequestRepository {
+	return &pullRequestRepository{store: store}
+}
+
+func (r *pullRequestRepository) Create(pr *models.PullRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.prs[pr.PullRequestID]; exists {
+		return ErrDuplicateKey
+	}
+	if _, exists := r.store.users[pr.AuthorID]; !exists {
+		return ErrForeignKey
+	}
+
+	rec := &prRecord{
+		id:       pr.PullRequestID,
+		name:     pr.PullRequestName,
+		authorID: pr.AuthorID,
+		status:   pr.Status,
+	}
+	ts := now()
+	createdAt := ts
+	if pr.CreatedAt != nil {
+		createdAt = pr.CreatedAt.UTC()
+	}
+	rec.createdAt = &createdAt
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, exists := r.store.users[reviewerID]; !exists {
+			return ErrForeignKey
+		}
+		if rec.hasReviewer(reviewerID) {
+			return ErrDuplicateKey
+		}
+		rec.reviewers = append(rec.reviewers, reviewerRecord{reviewerID: reviewerID, assignedAt: ts})
+	}
+
+	r.store.prs[pr.PullRequestID] = rec
+	r.store.appendEvent(rec.id, models.EventPRCreated, rec.authorID, "", ts)
+	for _, rv := range rec.reviewers {
+		r.store.appendEvent(rec.id, models.EventReviewerAssigned, rv.reviewerID, "", ts)
+	}
+	return nil
+}
+
+func (r *pullRequestRepository) Import(tx repository.Tx, pr *models.PullRequest) error {
+	r.store.mu.RLock()
+	_, exists := r.store.prs[pr.PullRequestID]
+	_, authorExists := r.store.users[pr.AuthorID]
+	reviewersExist := true
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, ok := r.store.users[reviewerID]; !ok {
+			reviewersExist = false
+		}
+	}
+	r.store.mu.RUnlock()
+	if exists {
+		return repository.ErrPRExists
+	}
+	if !authorExists || !reviewersExist {
+		return ErrForeignKey
+	}
+
+	rec := &prRecord{
+		id:       pr.PullRequestID,
+		name:     pr.PullRequestName,
+		authorID: pr.AuthorID,
+		status:   pr.Status,
+	}
+	createdAt := now()
+	if pr.CreatedAt != nil {
+		createdAt = pr.CreatedAt.UTC()
+	}
+	rec.createdAt = &createdAt
+	if pr.MergedAt != nil {
+		mergedAt := pr.MergedAt.UTC()
+		rec.mergedAt = &mergedAt
+	}
+	for _, reviewerID := range pr.AssignedReviewers {
+		if rec.hasReviewer(reviewerID) {
+			return ErrDuplicateKey
+		}
+		rec.reviewers = append(rec.reviewers, reviewerRecord{reviewerID: reviewerID, assignedAt: createdAt})
+	}
+
+	return r.store.enqueue(tx, func() {
+		if _, exists := r.store.prs[rec.id]; exists {
+			return
+		}
+		r.store.prs[rec.id] = rec
+		r.store.appendEvent(rec.id, models.EventPRCreated, rec.authorID, "", createdAt)
+		for _, rv := range rec.reviewers {
+			r.store.appendEvent(rec.id, models.EventReviewerAssigned, rv.reviewerID, "", createdAt)
+		}
+		if rec.mergedAt != nil {
+			r.store.appendEvent(rec.id, models.EventPRMerged, "", "", *rec.mergedAt)
+		}
+	})
+}
+
+func (r *pullRequestRepository) ExistingIDs(tx repository.Tx, prIDs []string) (map[string]bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	existing := make(map[string]bool)
+	for _, prID := range prIDs {
+		if _, ok := r.store.prs[prID]; ok {
+			existing[prID] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *pullRequestRepository) GetByID(prID string) (*models.PullRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, exists := r.store.prs[prID]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return rec.toModel(), nil
+}
+
+func (r *pullRequestRepository) UpdateStatus(prID string, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, exists := r.store.prs[prID]
+	if !exists {
+		return nil
+	}
+	rec.status = status
+	if status == "MERGED" {
+		mergedAt := now()
+		rec.mergedAt = &mergedAt
+		r.store.appendEvent(prID, models.EventPRMerged, "", "", mergedAt)
+	}
+	return nil
+}
+
+func (r *pullRequestRepository) UpdateReviewers(prID string, reviewers []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, exists := r.store.prs[prID]
+	if !exists {
+		return ErrForeignKey
+	}
+
+	ts := now()
+	wanted := toSet(reviewers)
+	updated := make([]reviewerRecord, 0, len(reviewers))
+	for _, rv := range rec.reviewers {
+		if wanted[rv.reviewerID] {
+			updated = append(updated, rv)
+		}
+	}
+	for _, reviewerID := range reviewers {
+		if _, exists := r.store.users[reviewerID]; !exists {
+			return ErrForeignKey
+		}
+		if rec.hasReviewer(reviewerID) {
+			continue
+		}
+		for _, rv := range updated {
+			if rv.reviewerID == reviewerID {
+				return ErrDuplicateKey
+			}
+		}
+		updated = append(updated, reviewerRecord{reviewerID: reviewerID, assignedAt: ts})
+	}
+
+	for _, rv := range rec.reviewers {
+		if !wanted[rv.reviewerID] {
+			r.store.appendEvent(prID, models.EventReviewerRemoved, rv.reviewerID, "", ts)
+		}
+	}
+	for _, rv := range updated {
+		if !rec.hasReviewer(rv.reviewerID) {
+			r.store.appendEvent(prID, models.EventReviewerAssigned, rv.reviewerID, "", ts)
+		}
+	}
+	rec.reviewers = updated
+	return nil
+}
+
+func (r *pullRequestRepository) GetByReviewerID(userID string, q repository.ReviewQuery) ([]*models.PullRequestShort, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	prs := make([]*models.PullRequestShort, 0)
+	for _, rec := range r.store.prs {
+		if q.Status != "" && rec.status != q.Status {
+			continue
+		}
+		pr := &models.PullRequestShort{
+			PullRequestID:   rec.id,
+			PullRequestName: rec.name,
+			AuthorID:        rec.authorID,
+			Status:          rec.status,
+			Role:            models.ReviewRoleReviewer,
+			MergedAt:        copyTime(rec.mergedAt),
+		}
+		if rv, ok := rec.reviewer(userID); ok {
+			assignedAt := rv.assignedAt
+			pr.AssignedAt = &assignedAt
+		} else if q.IncludeAuthored && rec.authorID == userID {
+			pr.Role = models.ReviewRoleAuthor
+			pr.AssignedAt = copyTime(rec.createdAt)
+		} else {
+			continue
+		}
+		if q.After != nil && !lessReview(&models.PullRequestShort{PullRequestID: q.After.PullRequestID, AssignedAt: &q.After.At}, pr, q.SortBy) {
+			continue
+		}
+		prs = append(prs, pr)
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		return lessReview(prs[i], prs[j], q.SortBy)
+	})
+	if q.Limit > 0 && len(prs) > q.Limit {
+		prs = prs[:q.Limit]
+	}
+	return prs, nil
+}
+
+func lessReview(a, b *models.PullRequestShort, sortBy string) bool {
+	if sortBy == repository.ReviewSortAssignedAt {
+		var at, bt time.Time
+		if a.AssignedAt != nil {
+			at = *a.AssignedAt
+		}
+		if b.AssignedAt != nil {
+			bt = *b.AssignedAt
+		}
+		if !at.Equal(bt) {
+			return at.Before(bt)
+		}
+	}
+	return a.PullRequestID < b.PullRequestID
+}
+
+func (r *pullRequestRepository) GetOpenPRsByAuthors(userIDs []string) ([]*models.PullRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	authors := toSet(userIDs)
+	prs := make([]*models.PullRequest, 0)
+	for _, rec := range r.store.prs {
+		if rec.status == "OPEN" && authors[rec.authorID] {
+			prs = append(prs, rec.toModel())
+		}
+	}
+	return prs, nil
+}
+
+func (r *pullRequestRepository) GetOpenPRsByReviewers(userIDs []string) (map[string][]*models.PullRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reviewers := toSet(userIDs)
+	result := make(map[string][]*models.PullRequest)
+	for _, rec := range r.store.prs {
+		if rec.status != "OPEN" {
+			continue
+		}
+		for _, rv := range rec.reviewers {
+			if reviewers[rv.reviewerID] {
+				result[rv.reviewerID] = append(result[rv.reviewerID], rec.toModel())
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *pullRequestRepository) ReassignAuthor(tx repository.Tx, prID, newAuthorID string) error {
+	r.store.mu.RLock()
+	_, exists := r.store.users[newAuthorID]
+	r.store.mu.RUnlock()
+	if !exists {
+		return ErrForeignKey
+	}
+
+	return r.store.enqueue(tx, func() {
+		if rec, exists := r.store.prs[prID]; exists && rec.authorID != newAuthorID {
+			r.store.appendEvent(prID, models.EventAuthorChanged, newAuthorID, rec.authorID, now())
+			rec.authorID = newAuthorID
+		}
+	})
+}
+
+func (r *pullRequestRepository) RemoveReviewer(tx repository.Tx, prID, reviewerID string) error {
+	return r.store.enqueue(tx, func() {
+		rec, exists := r.store.prs[prID]
+		if !exists {
+			return
+		}
+		if !rec.hasReviewer(reviewerID) {
+			return
+		}
+		kept := rec.reviewers[:0]
+		for _, rv := range rec.reviewers {
+			if rv.reviewerID != reviewerID {
+				kept = append(kept, rv)
+			}
+		}
+		rec.reviewers = kept
+		r.store.appendEvent(prID, models.EventReviewerRemoved, reviewerID, "", now())
+	})
+}
+
+func (r *pullRequestRepository) AddReviewer(tx repository.Tx, prID, reviewerID string) error {
+	r.store.mu.RLock()
+	_, prExists := r.store.prs[prID]
+	_, userExists := r.store.users[reviewerID]
+	r.store.mu.RUnlock()
+	if !prExists || !userExists {
+		return ErrForeignKey
+	}
+
+	return r.store.enqueue(tx, func() {
+		rec, exists := r.store.prs[prID]
+		if !exists || rec.hasReviewer(reviewerID) {
+			return
+		}
+		ts := now()
+		rec.reviewers = append(rec.reviewers, reviewerRecord{reviewerID: reviewerID, assignedAt: ts})
+		r.store.appendEvent(prID, models.EventReviewerAssigned, reviewerID, "", ts)
+	})
+}
+
+func (r *pullRequestRepository) SubmitReview(prID, reviewerID string) (time.Time, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, exists := r.store.prs[prID]
+	if !exists {
+		return time.Time{}, sql.ErrNoRows
+	}
+	for i := range rec.reviewers {
+		rv := &rec.reviewers[i]
+		if rv.reviewerID != reviewerID {
+			continue
+		}
+		if rv.reviewedAt != nil {
+			return *rv.reviewedAt, nil
+		}
+		ts := now()
+		rv.reviewedAt = &ts
+		r.store.appendEvent(prID, models.EventReviewSubmitted, reviewerID, "", ts)
+		return ts, nil
+	}
+	return time.Time{}, sql.ErrNoRows
+}
+
+func (r *pullRequestRepository) GetEvents(prID string) ([]*models.PREvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := make([]*models.PREvent, 0)
+	for i := range r.store.events {
+		if r.store.events[i].PullRequestID == prID {
+			e := r.store.events[i]
+			events = append(events, &e)
+		}
+	}
+	return events, nil
+}
+
+func (r *pullRequestRepository) List(filter repository.PullRequestFilter, page repository.PullRequestPage) ([]*models.PullRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := make([]*prRecord, 0)
+	for _, rec := range r.store.prs {
+		if r.matches(rec, filter) && afterCursor(rec, page) {
+			matched = append(matched, rec)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if page.Desc {
+			return lessPR(matched[j], matched[i], page.SortBy)
+		}
+		return lessPR(matched[i], matched[j], page.SortBy)
+	})
+	if page.Limit > 0 && len(matched) > page.Limit {
+		matched = matched[:page.Limit]
+	}
+
+	prs := make([]*models.PullRequest, 0, len(matched))
+	for _, rec := range matched {
+		prs = append(prs, rec.toModel())
+	}
+	return prs, nil
+}
+
+func (r *pullRequestRepository) matches(rec *prRecord, filter repository.PullRequestFilter) bool {
+	switch {
+	case filter.Status != "" && rec.status != filter.Status,
+		filter.AuthorID != "" && rec.authorID != filter.AuthorID,
+		filter.ReviewerID != "" && !rec.hasReviewer(filter.ReviewerID),
+		!inRange(rec.createdAt, filter.CreatedFrom, filter.CreatedTo),
+		!inRange(rec.mergedAt, filter.MergedFrom, filter.MergedTo),
+		filter.ReviewersBelow > 0 && (rec.status != "OPEN" || len(rec.reviewers) >= filter.ReviewersBelow):
+		return false
+	}
+	if filter.TeamName != "" {
+		author, exists := r.store.users[rec.authorID]
+		if !exists || author.user.TeamName != filter.TeamName {
+			return false
+		}
+	}
+	return true
+}
+
+// inRange повторяет семантику SQL: при заданной границе NULL не проходит фильтр
+func inRange(at *time.Time, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if at == nil {
+		return false
+	}
+	return (from == nil || !at.Before(*from)) && (to == nil || at.Before(*to))
+}
+
+func lessPR(a, b *prRecord, sortBy string) bool {
+	if sortBy == repository.PRSortCreatedAt {
+		at, bt := createdAtOf(a), createdAtOf(b)
+		if !at.Equal(bt) {
+			return at.Before(bt)
+		}
+	}
+	return a.id < b.id
+}
+
+func afterCursor(rec *prRecord, page repository.PullRequestPage) bool {
+	if page.After == nil {
+		return true
+	}
+	cursor := &prRecord{id: page.After.PullRequestID, createdAt: &page.After.At}
+	if page.Desc {
+		return lessPR(rec, cursor, page.SortBy)
+	}
+	return lessPR(cursor, rec, page.SortBy)
+}
+
+func createdAtOf(rec *prRecord) time.Time {
+	if rec.createdAt == nil {
+		return time.Time{}
+	}
+	return *rec.createdAt
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
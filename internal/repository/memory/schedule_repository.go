@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type scheduleRepository struct {
+	store *Store
+}
+
+func NewScheduleRepository(store *Store) repository.ScheduleRepository {
+	return &scheduleRepository{store: store}
+}
+
+func (r *scheduleRepository) SetWorkingHours(userID string, wh *models.WorkingHours) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if u, exists := r.store.users[userID]; !exists || u.user.ArchivedAt != nil {
+		return sql.ErrNoRows
+	}
+	r.store.schedules[userID] = copyWorkingHours(wh)
+	return nil
+}
+
+func (r *scheduleRepository) GetWorkingHours(userID string) (*models.WorkingHours, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wh, ok := r.store.schedules[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	c := copyWorkingHours(&wh)
+	return &c, nil
+}
+
+func (r *scheduleRepository) ListWorkingHours(userIDs []string) (map[string]*models.WorkingHours, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	result := make(map[string]*models.WorkingHours)
+	for _, userID := range userIDs {
+		if wh, ok := r.store.schedules[userID]; ok {
+			c := copyWorkingHours(&wh)
+			result[userID] = &c
+		}
+	}
+	return result, nil
+}
+
+func (r *scheduleRepository) SetHolidays(teamName string, holidays []models.Holiday) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if t, exists := r.store.teams[teamName]; !exists || t.archivedAt != nil {
+		return sql.ErrNoRows
+	}
+	seen := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		if seen[h.Date] {
+			return ErrDuplicateKey
+		}
+		seen[h.Date] = true
+	}
+	sorted := append([]models.Holiday(nil), holidays...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+	r.store.holidays[teamName] = sorted
+	return nil
+}
+
+func (r *scheduleRepository) GetHolidays(teamName string) ([]models.Holiday, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if t, exists := r.store.teams[teamName]; !exists || t.archivedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	return append([]models.Holiday{}, r.store.holidays[teamName]...), nil
+}
+
+func (r *scheduleRepository) ListHolidays(teamNames []string) (map[string][]models.Holiday, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	result := make(map[string][]models.Holiday)
+	for _, teamName := range teamNames {
+		if holidays := r.store.holidays[teamName]; len(holidays) > 0 {
+			result[teamName] = append([]models.Holiday(nil), holidays...)
+		}
+	}
+	return result, nil
+}
+
+func copyWorkingHours(wh *models.WorkingHours) models.WorkingHours {
+	return models.WorkingHours{
+		TimeZone: wh.TimeZone,
+		Periods:  append([]models.WorkingPeriod(nil), wh.Periods...),
+	}
+}
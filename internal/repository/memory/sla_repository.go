@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type slaRepository struct {
+	store *Store
+}
+
+func NewSLARepository(store *Store) repository.SLARepository {
+	return &slaRepository{store: store}
+}
+
+func (r *slaRepository) Set(sla *models.TeamSLA) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if t, exists := r.store.teams[sla.TeamName]; !exists || t.archivedAt != nil {
+		return sql.ErrNoRows
+	}
+	stored := *sla
+	if sla.ReassignAfterHours != nil {
+		hours := *sla.ReassignAfterHours
+		stored.ReassignAfterHours = &hours
+	}
+	r.store.slas[sla.TeamName] = stored
+	return nil
+}
+
+func (r *slaRepository) Get(teamName string) (*models.TeamSLA, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sla, ok := r.activeSLA(teamName)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &sla, nil
+}
+
+func (r *slaRepository) ListPending(teamName string) ([]repository.PendingReview, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	pending := make([]repository.PendingReview, 0)
+	for _, rec := range r.store.prs {
+		if rec.status != "OPEN" {
+			continue
+		}
+		author, exists := r.store.users[rec.authorID]
+		if !exists || author.user.TeamName == "" {
+			continue
+		}
+		if teamName != "" && author.user.TeamName != teamName {
+			continue
+		}
+		sla, ok := r.activeSLA(author.user.TeamName)
+		if !ok {
+			continue
+		}
+		for _, rv := range rec.reviewers {
+			if rv.reviewedAt != nil {
+				continue
+			}
+			p := repository.PendingReview{
+				PullRequestID:   rec.id,
+				PullRequestName: rec.name,
+				AuthorID:        rec.authorID,
+				ReviewerID:      rv.reviewerID,
+				AssignedAt:      rv.assignedAt,
+				SLA:             sla,
+			}
+			if rv.remindedAt != nil {
+				t := *rv.remindedAt
+				p.RemindedAt = &t
+			}
+			pending = append(pending, p)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if !a.AssignedAt.Equal(b.AssignedAt) {
+			return a.AssignedAt.Before(b.AssignedAt)
+		}
+		if a.PullRequestID != b.PullRequestID {
+			return a.PullRequestID < b.PullRequestID
+		}
+		return a.ReviewerID < b.ReviewerID
+	})
+	return pending, nil
+}
+
+func (r *slaRepository) MarkReminded(prID, reviewerID string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, exists := r.store.prs[prID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	for i := range rec.reviewers {
+		if rec.reviewers[i].reviewerID == reviewerID {
+			ts := at.UTC()
+			rec.reviewers[i].remindedAt = &ts
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// activeSLA возвращает копию срока неархивной команды; вызывается под блокировкой.
+func (r *slaRepository) activeSLA(teamName string) (models.TeamSLA, bool) {
+	t, exists := r.store.teams[teamName]
+	if !exists || t.archivedAt != nil {
+		return models.TeamSLA{}, false
+	}
+	sla, ok := r.store.slas[teamName]
+	if !ok {
+		return models.TeamSLA{}, false
+	}
+	if sla.ReassignAfterHours != nil {
+		hours := *sla.ReassignAfterHours
+		sla.ReassignAfterHours = &hours
+	}
+	return sla, true
+}
@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type statisticsRepository struct {
+	store *Store
+}
+
+func NewStatisticsRepository(store *Store) repository.StatisticsRepository {
+	return &statisticsRepository{store: store}
+}
+
+// GetStatistics считает показатели и нагрузку под одной блокировкой,
+// поэтому они относятся к одному состоянию.
+func (r *statisticsRepository) GetStatistics(loadFrom, loadTo *time.Time) (*models.Statistics, []models.ReviewerLoad, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.statistics(), r.reviewerLoad(loadFrom, loadTo), nil
+}
+
+func (r *statisticsRepository) statistics() *models.Statistics {
+	stats := &models.Statistics{}
+	// Архивные команды и пользователи в статистику не попадают
+	for _, t := range r.store.teams {
+		if t.archivedAt == nil {
+			stats.Teams.Total++
+		}
+	}
+
+	for _, u := range r.store.users {
+		if u.user.ArchivedAt != nil {
+			continue
+		}
+		stats.Users.Total++
+		if u.user.IsActive {
+			stats.Users.Active++
+		}
+	}
+	stats.Users.Inactive = stats.Users.Total - stats.Users.Active
+
+	counts := make(map[string]int)
+	stats.PullRequests.Total = len(r.store.prs)
+	for _, rec := range r.store.prs {
+		switch rec.status {
+		case "OPEN":
+			stats.PullRequests.Open++
+		case "MERGED":
+			stats.PullRequests.Merged++
+		}
+		for _, rv := range rec.reviewers {
+			counts[rv.reviewerID]++
+			stats.ReviewAssignments.Total++
+		}
+	}
+
+	var byReviewer []models.ReviewerAssignment
+	for userID, count := range counts {
+		byReviewer = append(byReviewer, models.ReviewerAssignment{UserID: userID, Count: count})
+	}
+	sort.Slice(byReviewer, func(i, j int) bool {
+		if byReviewer[i].Count != byReviewer[j].Count {
+			return byReviewer[i].Count > byReviewer[j].Count
+		}
+		return byReviewer[i].UserID < byReviewer[j].UserID
+	})
+	stats.ReviewAssignments.ByReviewer = byReviewer
+
+	return stats
+}
+
+func (r *statisticsRepository) GetTimingSamples(from, to time.Time) (*repository.TimingSamples, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	sample := func(prID string, start, end time.Time) repository.TimingSample {
+		s := repository.TimingSample{PullRequestID: prID, At: end, Duration: end.Sub(start)}
+		if rec, ok := r.store.prs[prID]; ok {
+			s.AuthorID = rec.authorID
+			if author, ok := r.store.users[rec.authorID]; ok {
+				s.TeamName = author.user.TeamName
+			}
+		}
+		return s
+	}
+
+	samples := &repository.TimingSamples{
+		Merge:            make([]repository.TimingSample, 0),
+		FirstReview:      make([]repository.TimingSample, 0),
+		ReviewerResponse: make([]repository.TimingSample, 0),
+	}
+	for _, rec := range r.store.prs {
+		if rec.mergedAt == nil || !inRange(*rec.mergedAt) {
+			continue
+		}
+		s := sample(rec.id, *rec.createdAt, *rec.mergedAt)
+		for _, rv := range rec.reviewers {
+			s.ReviewerIDs = append(s.ReviewerIDs, rv.reviewerID)
+		}
+		sort.Strings(s.ReviewerIDs)
+		samples.Merge = append(samples.Merge, s)
+	}
+	sort.Slice(samples.Merge, func(i, j int) bool {
+		a, b := samples.Merge[i], samples.Merge[j]
+		if !a.At.Equal(b.At) {
+			return a.At.Before(b.At)
+		}
+		return a.PullRequestID < b.PullRequestID
+	})
+
+	// События идут по возрастанию id, как в журнале SQL-хранилищ
+	reviewed := make(map[string]bool)
+	assigned := make(map[string]time.Time)
+	for _, e := range r.store.events {
+		switch e.Type {
+		case models.EventReviewerAssigned:
+			assigned[e.PullRequestID+"\x00"+e.UserID] = e.CreatedAt
+		case models.EventReviewSubmitted:
+			first := !reviewed[e.PullRequestID]
+			reviewed[e.PullRequestID] = true
+			if !inRange(e.CreatedAt) {
+				continue
+			}
+			if rec, ok := r.store.prs[e.PullRequestID]; ok && first {
+				samples.FirstReview = append(samples.FirstReview, sample(e.PullRequestID, *rec.createdAt, e.CreatedAt))
+			}
+			if at, ok := assigned[e.PullRequestID+"\x00"+e.UserID]; ok {
+				s := sample(e.PullRequestID, at, e.CreatedAt)
+				s.ReviewerIDs = []string{e.UserID}
+				samples.ReviewerResponse = append(samples.ReviewerResponse, s)
+			}
+		}
+	}
+
+	return samples, nil
+}
+
+func (r *statisticsRepository) GetTeamLoad(teamName string) ([]models.MemberLoad, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	team, exists := r.store.teams[teamName]
+	if !exists || team.archivedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	loads := make(map[string]*models.MemberLoad)
+	members := make([]models.MemberLoad, 0)
+	for _, u := range r.store.users {
+		if u.user.TeamName != teamName || u.user.ArchivedAt != nil {
+			continue
+		}
+		members = append(members, models.MemberLoad{
+			UserID:   u.user.UserID,
+			Username: u.user.Username,
+			IsActive: u.user.IsActive,
+		})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	for i := range members {
+		loads[members[i].UserID] = &members[i]
+	}
+
+	for _, rec := range r.store.prs {
+		open := rec.status == "OPEN"
+		if m, ok := loads[rec.authorID]; ok {
+			m.TotalAuthored++
+			if open {
+				m.OpenAuthored++
+			}
+		}
+		for _, rv := range rec.reviewers {
+			if m, ok := loads[rv.reviewerID]; ok {
+				m.TotalAssignments++
+				if open {
+					m.OpenAssignments++
+				}
+			}
+		}
+	}
+
+	return members, nil
+}
+
+func (r *statisticsRepository) GetReviewerLoad(from, to *time.Time) ([]models.ReviewerLoad, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.reviewerLoad(from, to), nil
+}
+
+func (r *statisticsRepository) reviewerLoad(from, to *time.Time) []models.ReviewerLoad {
+	byReviewer := make(map[string]*models.ReviewerLoad)
+	for _, rec := range r.store.prs {
+		if from != nil && rec.createdAt.Before(*from) {
+			continue
+		}
+		if to != nil && !rec.createdAt.Before(*to) {
+			continue
+		}
+		for _, rv := range rec.reviewers {
+			l, ok := byReviewer[rv.reviewerID]
+			if !ok {
+				l = &models.ReviewerLoad{UserID: rv.reviewerID}
+				if u, exists := r.store.users[rv.reviewerID]; exists {
+					if team, exists := r.store.teams[u.user.TeamName]; exists && team.archivedAt == nil {
+						l.TeamName = team.name
+					}
+				}
+				byReviewer[rv.reviewerID] = l
+			}
+			l.TotalAssignments++
+			if rec.status == "OPEN" {
+				l.OpenAssignments++
+			}
+		}
+	}
+
+	loads := make([]models.ReviewerLoad, 0, len(byReviewer))
+	for _, l := range byReviewer {
+		loads = append(loads, *l)
+	}
+	sort.Slice(loads, func(i, j int) bool { return loads[i].UserID < loads[j].UserID })
+	return loads
+}
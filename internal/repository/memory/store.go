@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+var (
+	ErrDuplicateKey = errors.New("duplicate key value violates unique constraint")
+	ErrForeignKey   = errors.New("referenced row does not exist")
+)
+
+type teamRecord struct {
+	name       string
+	createdAt  time.Time
+	archivedAt *time.Time
+}
+
+type userRecord struct {
+	user      models.User
+	createdAt time.Time
+	updatedAt time.Time
+	awayUntil *time.Time
+}
+
+type reviewerRecord struct {
+	reviewerID string
+	assignedAt time.Time
+	reviewedAt *time.Time
+	remindedAt *time.Time
+}
+
+type prRecord struct {
+	id        string
+	name      string
+	authorID  string
+	status    string
+	createdAt *time.Time
+	mergedAt  *time.Time
+	reviewers []reviewerRecord
+}
+
+// Store — потокобезопасное хранилище в памяти, общее для всех
+// in-memory репозиториев. Данные живут до остановки процесса.
+type Store struct {
+	mu            sync.RWMutex
+	teams         map[string]*teamRecord
+	users         map[string]*userRecord
+	prs           map[string]*prRecord
+	events        []models.PREvent
+	idempotency   map[string]*models.IdempotencyRecord
+	slas          map[string]models.TeamSLA
+	schedules     map[string]models.WorkingHours
+	holidays      map[string][]models.Holiday
+	notifications map[string]notificationRecord
+	chatUsers     map[string]string // chat_user_id → user_id
+}
+
+func NewStore() *Store {
+	return &Store{
+		teams:         make(map[string]*teamRecord),
+		users:         make(map[string]*userRecord),
+		prs:           make(map[string]*prRecord),
+		idempotency:   make(map[string]*models.IdempotencyRecord),
+		slas:          make(map[string]models.TeamSLA),
+		schedules:     make(map[string]models.WorkingHours),
+		holidays:      make(map[string][]models.Holiday),
+		notifications: make(map[string]notificationRecord),
+		chatUsers:     make(map[string]string),
+	}
+}
+
+// tx накапливает изменения и применяет их атомарно под блокировкой
+// хранилища при Commit; Rollback просто отбрасывает их.
+type tx struct {
+	store *Store
+	ops   []func()
+	done  bool
+}
+
+func (s *Store) BeginTx(ctx context.Context, opts *sql.TxOptions) (repository.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &tx{store: s}, nil
+}
+
+func (t *tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	for _, op := range t.ops {
+		op()
+	}
+	t.ops = nil
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	t.ops = nil
+	return nil
+}
+
+func (s *Store) enqueue(t repository.Tx, op func()) error {
+	mt, ok := t.(*tx)
+	if !ok || mt.store != s {
+		return repository.ErrForeignTx
+	}
+	if mt.done {
+		return sql.ErrTxDone
+	}
+	mt.ops = append(mt.ops, op)
+	return nil
+}
+
+// upsertUser вызывается под блокировкой записи; архивный пользователь
+// восстанавливается.
+func (s *Store) upsertUser(user models.User, ts time.Time) {
+	user.ArchivedAt = nil
+	if u, exists := s.users[user.UserID]; exists {
+		if !user.IsActive {
+			s.logDeactivation(u, ts)
+		}
+		u.user = user
+		u.updatedAt = ts
+		u.awayUntil = nil
+		return
+	}
+	s.users[user.UserID] = &userRecord{user: user, createdAt: ts, updatedAt: ts}
+}
+
+// logDeactivation вызывается под блокировкой записи до изменения активности
+// и пишет USER_DEACTIVATED, если пользователь активен и не в архиве.
+func (s *Store) logDeactivation(u *userRecord, at time.Time) {
+	if u.user.IsActive && u.user.ArchivedAt == nil {
+		s.appendEvent("", models.EventUserDeactivated, u.user.UserID, "", at)
+	}
+}
+
+// appendEvent вызывается под блокировкой записи.
+func (s *Store) appendEvent(prID, eventType, userID, previousUserID string, at time.Time) {
+	s.events = append(s.events, models.PREvent{
+		ID:             int64(len(s.events) + 1),
+		PullRequestID:  prID,
+		Type:           eventType,
+		UserID:         userID,
+		PreviousUserID: previousUserID,
+		CreatedAt:      at,
+	})
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// model возвращает копию пользователя, не разделяющую ArchivedAt с записью.
+func (u *userRecord) model() *models.User {
+	user := u.user
+	user.ArchivedAt = copyTime(u.user.ArchivedAt)
+	return &user
+}
+
+func (r *prRecord) toModel() *models.PullRequest {
+	pr := &models.PullRequest{
+		PullRequestID:     r.id,
+		PullRequestName:   r.name,
+		AuthorID:          r.authorID,
+		Status:            r.status,
+		AssignedReviewers: make([]string, 0, len(r.reviewers)),
+	}
+	pr.CreatedAt = copyTime(r.createdAt)
+	pr.MergedAt = copyTime(r.mergedAt)
+	for _, rv := range r.reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, rv.reviewerID)
+	}
+	return pr
+}
+
+func (r *prRecord) hasReviewer(userID string) bool {
+	_, ok := r.reviewer(userID)
+	return ok
+}
+
+func (r *prRecord) reviewer(userID string) (reviewerRecord, bool) {
+	for _, rv := range r.reviewers {
+		if rv.reviewerID == userID {
+			return rv, true
+		}
+	}
+	return reviewerRecord{}, false
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type teamRepository struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) repository.TeamRepository {
+	return &teamRepository{store: store}
+}
+
+func (r *teamRepository) Create(team *models.Team) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.teams[team.TeamName]; exists {
+		return ErrDuplicateKey
+	}
+
+	ts := now()
+	r.store.teams[team.TeamName] = &teamRecord{name: team.TeamName, createdAt: ts}
+	for _, member := range team.Members {
+		r.store.upsertUser(models.User{
+			UserID:   member.UserID,
+			Username: member.Username,
+			TeamName: team.TeamName,
+			IsActive: member.IsActive,
+		}, ts)
+	}
+	return nil
+}
+
+func (r *teamRepository) Add(tx repository.Tx, teamName string) error {
+	r.store.mu.RLock()
+	_, exists := r.store.teams[teamName]
+	r.store.mu.RUnlock()
+	if exists {
+		return ErrDuplicateKey
+	}
+
+	return r.store.enqueue(tx, func() {
+		if _, exists := r.store.teams[teamName]; !exists {
+			r.store.teams[teamName] = &teamRecord{name: teamName, createdAt: now()}
+		}
+	})
+}
+
+func (r *teamRepository) GetByName(teamName string) (*models.Team, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, exists := r.store.teams[teamName]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return r.team(rec), nil
+}
+
+func (r *teamRepository) List() ([]*models.Team, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	teams := make([]*models.Team, 0, len(r.store.teams))
+	for _, rec := range r.store.teams {
+		teams = append(teams, r.team(rec))
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].TeamName < teams[j].TeamName
+	})
+	return teams, nil
+}
+
+// team собирает модель команды с участниками; вызывается под блокировкой.
+func (r *teamRepository) team(rec *teamRecord) *models.Team {
+	teamName := rec.name
+	team := &models.Team{
+		TeamName:   teamName,
+		Members:    []models.TeamMember{},
+		ArchivedAt: copyTime(rec.archivedAt),
+	}
+	for _, u := range r.store.users {
+		if u.user.TeamName == teamName {
+			team.Members = append(team.Members, models.TeamMember{
+				UserID:     u.user.UserID,
+				Username:   u.user.Username,
+				IsActive:   u.user.IsActive,
+				ArchivedAt: copyTime(u.user.ArchivedAt),
+			})
+		}
+	}
+	sort.Slice(team.Members, func(i, j int) bool {
+		return team.Members[i].UserID < team.Members[j].UserID
+	})
+	return team
+}
+
+func (r *teamRepository) Archive(tx repository.Tx, teamName string, at time.Time) error {
+	return r.store.enqueue(tx, func() {
+		if rec, exists := r.store.teams[teamName]; exists && rec.archivedAt == nil {
+			archivedAt := at
+			rec.archivedAt = &archivedAt
+		}
+	})
+}
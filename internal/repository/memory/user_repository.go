@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) GetByID(userID string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, exists := r.store.users[userID]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return u.model(), nil
+}
+
+func (r *userRepository) UpdateActivity(userID string, isActive bool) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, exists := r.store.users[userID]
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	ts := now()
+	if !isActive {
+		r.store.logDeactivation(u, ts)
+	}
+	u.user.IsActive = isActive
+	u.updatedAt = ts
+	u.awayUntil = nil
+	return u.model(), nil
+}
+
+func (r *userRepository) GetActiveTeamMembers(teamName string, excludeUserID string) ([]*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*models.User, 0)
+	if teamName == "" {
+		return users, nil
+	}
+	for _, u := range r.store.users {
+		if u.user.TeamName == teamName && u.user.IsActive && u.user.ArchivedAt == nil && u.user.UserID != excludeUserID {
+			users = append(users, u.model())
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users, nil
+}
+
+func (r *userRepository) DeactivateUsers(tx repository.Tx, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	ids := append([]string(nil), userIDs...)
+	sort.Strings(ids)
+	return r.store.enqueue(tx, func() {
+		ts := now()
+		for _, id := range ids {
+			if u, exists := r.store.users[id]; exists {
+				r.store.logDeactivation(u, ts)
+				u.user.IsActive = false
+				u.updatedAt = ts
+				u.awayUntil = nil
+			}
+		}
+	})
+}
+
+func (r *userRepository) GetUsersByIDs(userIDs []string) ([]*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if u, exists := r.store.users[id]; exists {
+			users = append(users, u.model())
+		}
+	}
+	return users, nil
+}
+
+// Upsert не проверяет существование команды: команда может создаваться
+// в той же транзакции, а её наличие проверяет сервис.
+func (r *userRepository) Upsert(tx repository.Tx, user *models.User) error {
+	u := *user
+	return r.store.enqueue(tx, func() {
+		r.store.upsertUser(u, now())
+	})
+}
+
+func (r *userRepository) Archive(tx repository.Tx, userIDs []string, at time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	ids := append([]string(nil), userIDs...)
+	sort.Strings(ids)
+	return r.store.enqueue(tx, func() {
+		ts := now()
+		for _, id := range ids {
+			if u, exists := r.store.users[id]; exists && u.user.ArchivedAt == nil {
+				r.store.logDeactivation(u, at)
+				archivedAt := at
+				u.user.IsActive = false
+				u.user.ArchivedAt = &archivedAt
+				u.updatedAt = ts
+				u.awayUntil = nil
+			}
+		}
+	})
+}
+
+func (r *userRepository) List() ([]*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(r.store.users))
+	for _, u := range r.store.users {
+		users = append(users, u.model())
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users, nil
+}
+
+func (r *userRepository) SetAway(userID string, until time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, exists := r.store.users[userID]
+	if !exists || u.user.ArchivedAt != nil {
+		return sql.ErrNoRows
+	}
+	ts := now()
+	r.store.logDeactivation(u, ts)
+	u.user.IsActive = false
+	u.updatedAt = ts
+	u.awayUntil = &until
+	return nil
+}
+
+func (r *userRepository) ReturnFromAway(at time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var returned int64
+	for _, u := range r.store.users {
+		if u.awayUntil != nil && !u.awayUntil.After(at) && u.user.ArchivedAt == nil {
+			u.user.IsActive = true
+			u.updatedAt = now()
+			u.awayUntil = nil
+			returned++
+		}
+	}
+	return returned, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// NotificationRepository — адреса и подписки пользователей на письма.
+// SetPreferences возвращает sql.ErrNoRows, если пользователя нет или он в архиве.
+type NotificationRepository interface {
+	SetPreferences(p *models.NotificationPreferences) error
+	GetPreferences(userID string) (*models.NotificationPreferences, error)
+	ListPreferences(userIDs []string) (map[string]*models.NotificationPreferences, error)
+	ListDigestRecipients() ([]DigestRecipient, error)
+	MarkDigestSent(userID, date string) error
+}
+
+// DigestRecipient — активный пользователь, подписанный на утреннюю сводку.
+// TimeZone берётся из рабочих часов, пусто — UTC; LastDigestOn — дата
+// последней сводки YYYY-MM-DD, пусто — сводок ещё не было.
+type DigestRecipient struct {
+	UserID       string
+	Username     string
+	TeamName     string
+	Email        string
+	TimeZone     string
+	LastDigestOn string
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) SetPreferences(p *models.NotificationPreferences) error {
+	var exists int
+	if err := r.db.QueryRow(`SELECT 1 FROM users WHERE user_id = $1 AND archived_at IS NULL`, p.UserID).Scan(&exists); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO notification_preferences (user_id, email, daily_digest, assignment_notices, overdue_reminders, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = excluded.email,
+			daily_digest = excluded.daily_digest,
+			assignment_notices = excluded.assignment_notices,
+			overdue_reminders = excluded.overdue_reminders,
+			updated_at = excluded.updated_at`,
+		p.UserID, p.Email, p.DailyDigest, p.AssignmentNotices, p.OverdueReminders, time.Now().UTC())
+	return err
+}
+
+// GetPreferences возвращает sql.ErrNoRows, если адрес не задан
+func (r *notificationRepository) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	p := &models.NotificationPreferences{UserID: userID}
+	err := r.db.QueryRow(`
+		SELECT email, daily_digest, assignment_notices, overdue_reminders
+		FROM notification_preferences WHERE user_id = $1`, userID).
+		Scan(&p.Email, &p.DailyDigest, &p.AssignmentNotices, &p.OverdueReminders)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListPreferences возвращает подписки только тех пользователей, у кого задан адрес
+func (r *notificationRepository) ListPreferences(userIDs []string) (map[string]*models.NotificationPreferences, error) {
+	result := make(map[string]*models.NotificationPreferences)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inPlaceholders(1, userIDs)
+	rows, err := r.db.Query(`
+		SELECT user_id, email, daily_digest, assignment_notices, overdue_reminders
+		FROM notification_preferences WHERE user_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &models.NotificationPreferences{}
+		if err := rows.Scan(&p.UserID, &p.Email, &p.DailyDigest, &p.AssignmentNotices, &p.OverdueReminders); err != nil {
+			return nil, err
+		}
+		result[p.UserID] = p
+	}
+	return result, rows.Err()
+}
+
+// ListDigestRecipients возвращает подписчиков сводки по возрастанию user_id
+func (r *notificationRepository) ListDigestRecipients() ([]DigestRecipient, error) {
+	rows, err := r.db.Query(`
+		SELECT n.user_id, u.username, COALESCE(u.team_name, ''), n.email, COALESCE(w.time_zone, ''), COALESCE(n.last_digest_on, '')
+		FROM notification_preferences n
+		JOIN users u ON u.user_id = n.user_id
+		LEFT JOIN user_working_hours w ON w.user_id = n.user_id
+		WHERE n.daily_digest AND u.is_active AND u.archived_at IS NULL
+		ORDER BY n.user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := make([]DigestRecipient, 0)
+	for rows.Next() {
+		var rcpt DigestRecipient
+		if err := rows.Scan(&rcpt.UserID, &rcpt.Username, &rcpt.TeamName, &rcpt.Email, &rcpt.TimeZone, &rcpt.LastDigestOn); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rcpt)
+	}
+	return recipients, rows.Err()
+}
+
+// MarkDigestSent запоминает дату отправленной сводки; sql.ErrNoRows — адрес не задан
+func (r *notificationRepository) MarkDigestSent(userID, date string) error {
+	res, err := r.db.Exec(`UPDATE notification_preferences SET last_digest_on = $1 WHERE user_id = $2`, date, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
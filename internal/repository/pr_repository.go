@@ -2,22 +2,88 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/lib/pq"
 	"github.com/reviewer-service/internal/models"
 )
 
+// ErrPRExists — Import встретил PR с тем же pull_request_id
+var ErrPRExists = errors.New("pull request already exists")
+
+// existingIDsBatch — сколько идентификаторов проверяет один запрос
+// ExistingIDs; держит число параметров в пределах лимита SQLite.
+const existingIDsBatch = 500
+
 type PullRequestRepository interface {
 	Create(pr *models.PullRequest) error
+	Import(tx Tx, pr *models.PullRequest) error
+	ExistingIDs(tx Tx, prIDs []string) (map[string]bool, error)
 	GetByID(prID string) (*models.PullRequest, error)
 	UpdateStatus(prID string, status string) error
 	UpdateReviewers(prID string, reviewers []string) error
-	GetByReviewerID(userID string) ([]*models.PullRequestShort, error)
+	SubmitReview(prID, reviewerID string) (time.Time, error)
+	GetByReviewerID(userID string, q ReviewQuery) ([]*models.PullRequestShort, error)
 	GetOpenPRsByAuthors(userIDs []string) ([]*models.PullRequest, error)
 	GetOpenPRsByReviewers(userIDs []string) (map[string][]*models.PullRequest, error)
-	ReassignAuthor(tx *sql.Tx, prID, newAuthorID string) error
-	RemoveReviewer(tx *sql.Tx, prID, reviewerID string) error
-	AddReviewer(tx *sql.Tx, prID, reviewerID string) error
+	ReassignAuthor(tx Tx, prID, newAuthorID string) error
+	RemoveReviewer(tx Tx, prID, reviewerID string) error
+	AddReviewer(tx Tx, prID, reviewerID string) error
+	GetEvents(prID string) ([]*models.PREvent, error)
+	List(filter PullRequestFilter, page PullRequestPage) ([]*models.PullRequest, error)
+}
+
+// Поля, по которым можно сортировать список PR
+const (
+	PRSortCreatedAt = "created_at"
+	PRSortID        = "pull_request_id"
+)
+
+// PullRequestFilter — условия выборки List. Пустые поля не ограничивают
+// выборку, интервалы дат полуоткрытые: [From, To).
+type PullRequestFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string // команда автора
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	// ReviewersBelow > 0 оставляет открытые PR, у которых ревьюверов меньше этого числа
+	ReviewersBelow int
+}
+
+// PullRequestPage задаёт keyset-пагинацию: After — ключ последней записи
+// предыдущей страницы, следующая страница начинается строго после него.
+type PullRequestPage struct {
+	SortBy string
+	Desc   bool
+	After  *PullRequestCursor
+	Limit  int
+}
+
+// PullRequestCursor — ключ записи для keyset-пагинации. At — значение
+// временного поля сортировки: created_at в List, assigned_at в GetByReviewerID.
+type PullRequestCursor struct {
+	At            time.Time
+	PullRequestID string
+}
+
+// ReviewSortAssignedAt сортирует входящие ревью от дольше всех ожидающих
+const ReviewSortAssignedAt = "assigned_at"
+
+// ReviewQuery — выборка GetByReviewerID. Пустой Status не ограничивает
+// статус; IncludeAuthored добавляет PR, где пользователь автор, — для них
+// ожиданием считается время с создания PR. Сортировка всегда по возрастанию.
+type ReviewQuery struct {
+	Status          string
+	IncludeAuthored bool
+	SortBy          string // PRSortID или ReviewSortAssignedAt
+	After           *PullRequestCursor
+	Limit           int
 }
 
 type pullRequestRepository struct {
@@ -35,9 +101,11 @@ func (r *pullRequestRepository) Create(pr *models.PullRequest) error {
 	}
 	defer tx.Rollback()
 
-	var createdAt interface{}
+	// created_at всегда пишется из Go, чтобы в SQLite все значения имели
+	// один текстовый формат и корректно сравнивались в фильтрах и курсорах
+	createdAt := time.Now().UTC()
 	if pr.CreatedAt != nil {
-		createdAt = pr.CreatedAt
+		createdAt = pr.CreatedAt.UTC()
 	}
 
 	query := `INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at) VALUES ($1, $2, $3, $4, $5)`
@@ -46,35 +114,126 @@ func (r *pullRequestRepository) Create(pr *models.PullRequest) error {
 		return err
 	}
 
+	now := time.Now().UTC()
+	if err := insertEvent(tx, pr.PullRequestID, models.EventPRCreated, pr.AuthorID, "", now); err != nil {
+		return err
+	}
+
 	if len(pr.AssignedReviewers) > 0 {
-		stmt, err := tx.Prepare(`INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`)
+		stmt, err := tx.Prepare(`INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES ($1, $2, $3)`)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
 		for _, reviewerID := range pr.AssignedReviewers {
-			_, err = stmt.Exec(pr.PullRequestID, reviewerID)
+			_, err = stmt.Exec(pr.PullRequestID, reviewerID, now)
 			if err != nil {
 				return err
 			}
+			if err := insertEvent(tx, pr.PullRequestID, models.EventReviewerAssigned, reviewerID, "", now); err != nil {
+				return err
+			}
 		}
 	}
 
 	return tx.Commit()
 }
 
+// Import записывает PR как есть — со статусом, датами и ревьюверами из
+// источника. Ревьюверы считаются назначенными в момент создания PR, события
+// журнала датируются created_at и merged_at. Если PR уже есть, возвращает
+// ErrPRExists, не прерывая транзакцию.
+func (r *pullRequestRepository) Import(tx Tx, pr *models.PullRequest) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	createdAt := time.Now().UTC()
+	if pr.CreatedAt != nil {
+		createdAt = pr.CreatedAt.UTC()
+	}
+	var mergedAt *time.Time
+	if pr.MergedAt != nil {
+		m := pr.MergedAt.UTC()
+		mergedAt = &m
+	}
+
+	query := `INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (pull_request_id) DO NOTHING`
+	res, err := t.Exec(query, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, createdAt, mergedAt)
+	if err != nil {
+		return err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return ErrPRExists
+	}
+	if err := insertEvent(t, pr.PullRequestID, models.EventPRCreated, pr.AuthorID, "", createdAt); err != nil {
+		return err
+	}
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, err := t.Exec(`INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES ($1, $2, $3)`, pr.PullRequestID, reviewerID, createdAt); err != nil {
+			return err
+		}
+		if err := insertEvent(t, pr.PullRequestID, models.EventReviewerAssigned, reviewerID, "", createdAt); err != nil {
+			return err
+		}
+	}
+
+	if mergedAt != nil {
+		return insertEvent(t, pr.PullRequestID, models.EventPRMerged, "", "", *mergedAt)
+	}
+	return nil
+}
+
+// ExistingIDs возвращает те из prIDs, что уже есть в базе, проверяя их
+// пачками в транзакции tx.
+func (r *pullRequestRepository) ExistingIDs(tx Tx, prIDs []string) (map[string]bool, error) {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for start := 0; start < len(prIDs); start += existingIDsBatch {
+		end := start + existingIDsBatch
+		if end > len(prIDs) {
+			end = len(prIDs)
+		}
+		placeholders, args := inPlaceholders(1, prIDs[start:end])
+		rows, err := t.Query(`SELECT pull_request_id FROM pull_requests WHERE pull_request_id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var prID string
+			if err := rows.Scan(&prID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existing[prID] = true
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+	return existing, nil
+}
+
 func (r *pullRequestRepository) GetByID(prID string) (*models.PullRequest, error) {
 	query := `
-		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at,
-		       COALESCE(array_agg(prr.reviewer_id) FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}') as reviewers
-		FROM pull_requests pr
-		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE pr.pull_request_id = $1
-		GROUP BY pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at`
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		FROM pull_requests
+		WHERE pull_request_id = $1`
 
 	var pr models.PullRequest
-	var reviewers pq.StringArray
 	var createdAt, mergedAt sql.NullTime
 
 	err := r.db.QueryRow(query, prID).Scan(
@@ -84,7 +243,6 @@ func (r *pullRequestRepository) GetByID(prID string) (*models.PullRequest, error
 		&pr.Status,
 		&createdAt,
 		&mergedAt,
-		&reviewers,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -99,22 +257,89 @@ func (r *pullRequestRepository) GetByID(prID string) (*models.PullRequest, error
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
-	pr.AssignedReviewers = reviewers
+
+	if err := r.loadReviewers([]*models.PullRequest{&pr}); err != nil {
+		return nil, err
+	}
 
 	return &pr, nil
 }
 
+// loadReviewers заполняет AssignedReviewers отдельным запросом вместо array_agg,
+// чтобы не зависеть от агрегатных функций конкретной СУБД.
+func (r *pullRequestRepository) loadReviewers(prs []*models.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(prs))
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		if !seen[pr.PullRequestID] {
+			seen[pr.PullRequestID] = true
+			ids = append(ids, pr.PullRequestID)
+		}
+	}
+
+	placeholders, args := inPlaceholders(1, ids)
+	query := `SELECT pull_request_id, reviewer_id FROM pr_reviewers WHERE pull_request_id IN (` + placeholders + `) ORDER BY id`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	reviewers := make(map[string][]string, len(ids))
+	for rows.Next() {
+		var prID, reviewerID string
+		if err := rows.Scan(&prID, &reviewerID); err != nil {
+			return err
+		}
+		reviewers[prID] = append(reviewers[prID], reviewerID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, pr := range prs {
+		pr.AssignedReviewers = append([]string{}, reviewers[pr.PullRequestID]...)
+	}
+	return nil
+}
+
 func (r *pullRequestRepository) UpdateStatus(prID string, status string) error {
-	var query string
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	var res sql.Result
 	if status == "MERGED" {
-		query = `UPDATE pull_requests SET status = $1, merged_at = CURRENT_TIMESTAMP WHERE pull_request_id = $2`
+		res, err = tx.Exec(`UPDATE pull_requests SET status = $1, merged_at = $2 WHERE pull_request_id = $3`, status, now, prID)
 	} else {
-		query = `UPDATE pull_requests SET status = $1 WHERE pull_request_id = $2`
+		res, err = tx.Exec(`UPDATE pull_requests SET status = $1 WHERE pull_request_id = $2`, status, prID)
 	}
-	_, err := r.db.Exec(query, status, prID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if status == "MERGED" {
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n > 0 {
+			if err := insertEvent(tx, prID, models.EventPRMerged, "", "", now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
 }
 
+// UpdateReviewers удаляет и добавляет только изменившихся ревьюверов, чтобы
+// у оставшихся сохранился assigned_at, а в журнал попала точная разница.
 func (r *pullRequestRepository) UpdateReviewers(prID string, reviewers []string) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -122,38 +347,92 @@ func (r *pullRequestRepository) UpdateReviewers(prID string, reviewers []string)
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(`DELETE FROM pr_reviewers WHERE pull_request_id = $1`, prID)
+	current, err := reviewerIDs(tx, prID)
 	if err != nil {
 		return err
 	}
 
-	if len(reviewers) > 0 {
-		stmt, err := tx.Prepare(`INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`)
-		if err != nil {
+	wanted := make(map[string]bool, len(reviewers))
+	for _, reviewerID := range reviewers {
+		wanted[reviewerID] = true
+	}
+	existing := make(map[string]bool, len(current))
+	for _, reviewerID := range current {
+		existing[reviewerID] = true
+	}
+
+	now := time.Now().UTC()
+	for _, reviewerID := range current {
+		if wanted[reviewerID] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`, prID, reviewerID); err != nil {
 			return err
 		}
-		defer stmt.Close()
+		if err := insertEvent(tx, prID, models.EventReviewerRemoved, reviewerID, "", now); err != nil {
+			return err
+		}
+	}
 
-		for _, reviewerID := range reviewers {
-			_, err = stmt.Exec(prID, reviewerID)
-			if err != nil {
-				return err
-			}
+	for _, reviewerID := range reviewers {
+		if existing[reviewerID] {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES ($1, $2, $3)`, prID, reviewerID, now); err != nil {
+			return err
+		}
+		if err := insertEvent(tx, prID, models.EventReviewerAssigned, reviewerID, "", now); err != nil {
+			return err
 		}
 	}
 
 	return tx.Commit()
 }
 
-func (r *pullRequestRepository) GetByReviewerID(userID string) ([]*models.PullRequestShort, error) {
-	query := `
-		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+// GetByReviewerID строит входящие ревью одним запросом: LEFT JOIN оставляет
+// назначение пользователя, а собственные PR без назначения попадают в выборку
+// только при IncludeAuthored. Ключ сортировки — COALESCE(assigned_at, created_at).
+func (r *pullRequestRepository) GetByReviewerID(userID string, q ReviewQuery) ([]*models.PullRequestShort, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	user := arg(userID)
+	conditions := []string{"prr.reviewer_id IS NOT NULL"}
+	if q.IncludeAuthored {
+		conditions[0] = "(prr.reviewer_id IS NOT NULL OR pr.author_id = " + user + ")"
+	}
+	if q.Status != "" {
+		conditions = append(conditions, "pr.status = "+arg(q.Status))
+	}
+
+	const waitingSince = "COALESCE(prr.assigned_at, pr.created_at)"
+	orderBy := "pr.pull_request_id"
+	if q.SortBy == ReviewSortAssignedAt {
+		orderBy = waitingSince + ", " + orderBy
+	}
+	if q.After != nil {
+		id := arg(q.After.PullRequestID)
+		if q.SortBy == ReviewSortAssignedAt {
+			at := arg(q.After.At.UTC())
+			conditions = append(conditions, "("+waitingSince+" > "+at+" OR ("+waitingSince+" = "+at+" AND pr.pull_request_id > "+id+"))")
+		} else {
+			conditions = append(conditions, "pr.pull_request_id > "+id)
+		}
+	}
+
+	query := `SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, prr.assigned_at
 		FROM pull_requests pr
-		JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE prr.reviewer_id = $1
-		ORDER BY pr.pull_request_id`
+		LEFT JOIN pr_reviewers prr ON prr.pull_request_id = pr.pull_request_id AND prr.reviewer_id = ` + user + `
+		WHERE ` + strings.Join(conditions, "\n\t\tAND ") + `
+		ORDER BY ` + orderBy
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit)
+	}
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,9 +441,23 @@ func (r *pullRequestRepository) GetByReviewerID(userID string) ([]*models.PullRe
 	prs := make([]*models.PullRequestShort, 0)
 	for rows.Next() {
 		var pr models.PullRequestShort
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+		var createdAt, mergedAt, assignedAt sql.NullTime
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &assignedAt); err != nil {
 			return nil, err
 		}
+		pr.Role = models.ReviewRoleReviewer
+		if !assignedAt.Valid {
+			pr.Role = models.ReviewRoleAuthor
+			assignedAt = createdAt
+		}
+		if assignedAt.Valid {
+			at := assignedAt.Time.UTC()
+			pr.AssignedAt = &at
+		}
+		if mergedAt.Valid {
+			at := mergedAt.Time.UTC()
+			pr.MergedAt = &at
+		}
 		prs = append(prs, &pr)
 	}
 
@@ -180,15 +473,13 @@ func (r *pullRequestRepository) GetOpenPRsByAuthors(userIDs []string) ([]*models
 		return []*models.PullRequest{}, nil
 	}
 
+	placeholders, args := inPlaceholders(1, userIDs)
 	query := `
-		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
-		       COALESCE(array_agg(prr.reviewer_id) FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}') as reviewers
-		FROM pull_requests pr
-		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE pr.author_id = ANY($1) AND pr.status = 'OPEN'
-		GROUP BY pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status`
+		SELECT pull_request_id, pull_request_name, author_id, status
+		FROM pull_requests
+		WHERE author_id IN (` + placeholders + `) AND status = 'OPEN'`
 
-	rows, err := r.db.Query(query, pq.Array(userIDs))
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -197,14 +488,19 @@ func (r *pullRequestRepository) GetOpenPRsByAuthors(userIDs []string) ([]*models
 	prs := make([]*models.PullRequest, 0)
 	for rows.Next() {
 		pr := &models.PullRequest{}
-		var reviewers pq.StringArray
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &reviewers); err != nil {
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
 			return nil, err
 		}
-		pr.AssignedReviewers = reviewers
 		prs = append(prs, pr)
 	}
-	return prs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadReviewers(prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
 }
 
 func (r *pullRequestRepository) GetOpenPRsByReviewers(userIDs []string) (map[string][]*models.PullRequest, error) {
@@ -212,49 +508,285 @@ func (r *pullRequestRepository) GetOpenPRsByReviewers(userIDs []string) (map[str
 		return make(map[string][]*models.PullRequest), nil
 	}
 
+	placeholders, args := inPlaceholders(1, userIDs)
 	query := `
-		SELECT prr.reviewer_id, pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
-		       COALESCE(array_agg(prr2.reviewer_id) FILTER (WHERE prr2.reviewer_id IS NOT NULL), '{}') as reviewers
+		SELECT prr.reviewer_id, pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
 		FROM pr_reviewers prr
 		JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
-		LEFT JOIN pr_reviewers prr2 ON pr.pull_request_id = prr2.pull_request_id
-		WHERE prr.reviewer_id = ANY($1) AND pr.status = 'OPEN'
-		GROUP BY prr.reviewer_id, pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status`
+		WHERE prr.reviewer_id IN (` + placeholders + `) AND pr.status = 'OPEN'`
 
-	rows, err := r.db.Query(query, pq.Array(userIDs))
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	result := make(map[string][]*models.PullRequest)
+	var prs []*models.PullRequest
 	for rows.Next() {
 		var reviewerID string
 		pr := &models.PullRequest{}
-		var reviewers pq.StringArray
-		if err := rows.Scan(&reviewerID, &pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &reviewers); err != nil {
+		if err := rows.Scan(&reviewerID, &pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
 			return nil, err
 		}
-		pr.AssignedReviewers = reviewers
 		result[reviewerID] = append(result[reviewerID], pr)
+		prs = append(prs, pr)
 	}
-	return result, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadReviewers(prs); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (r *pullRequestRepository) ReassignAuthor(tx *sql.Tx, prID, newAuthorID string) error {
+func (r *pullRequestRepository) ReassignAuthor(tx Tx, prID, newAuthorID string) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	var oldAuthorID string
+	if err := t.QueryRow(`SELECT author_id FROM pull_requests WHERE pull_request_id = $1`, prID).Scan(&oldAuthorID); err != nil {
+		return err
+	}
+	if oldAuthorID == newAuthorID {
+		return nil
+	}
+
 	query := `UPDATE pull_requests SET author_id = $1 WHERE pull_request_id = $2`
-	_, err := tx.Exec(query, newAuthorID, prID)
-	return err
+	if _, err := t.Exec(query, newAuthorID, prID); err != nil {
+		return err
+	}
+	return insertEvent(t, prID, models.EventAuthorChanged, newAuthorID, oldAuthorID, time.Now().UTC())
 }
 
-func (r *pullRequestRepository) RemoveReviewer(tx *sql.Tx, prID, reviewerID string) error {
+func (r *pullRequestRepository) RemoveReviewer(tx Tx, prID, reviewerID string) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
 	query := `DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`
-	_, err := tx.Exec(query, prID, reviewerID)
-	return err
+	res, err := t.Exec(query, prID, reviewerID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	return insertEvent(t, prID, models.EventReviewerRemoved, reviewerID, "", time.Now().UTC())
+}
+
+func (r *pullRequestRepository) AddReviewer(tx Tx, prID, reviewerID string) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	query := `INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	res, err := t.Exec(query, prID, reviewerID, now)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	return insertEvent(t, prID, models.EventReviewerAssigned, reviewerID, "", now)
+}
+
+// SubmitReview отмечает ревью отправленным и возвращает время отправки.
+// Повторная отправка возвращает первое время и не пишет событие;
+// sql.ErrNoRows — пользователь не назначен ревьювером.
+func (r *pullRequestRepository) SubmitReview(prID, reviewerID string) (time.Time, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.Exec(`UPDATE pr_reviewers SET reviewed_at = $1 WHERE pull_request_id = $2 AND reviewer_id = $3 AND reviewed_at IS NULL`, now, prID, reviewerID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n == 0 {
+		var reviewedAt sql.NullTime
+		err := tx.QueryRow(`SELECT reviewed_at FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`, prID, reviewerID).Scan(&reviewedAt)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return reviewedAt.Time.UTC(), nil
+	}
+
+	if err := insertEvent(tx, prID, models.EventReviewSubmitted, reviewerID, "", now); err != nil {
+		return time.Time{}, err
+	}
+	return now, tx.Commit()
 }
 
-func (r *pullRequestRepository) AddReviewer(tx *sql.Tx, prID, reviewerID string) error {
-	query := `INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-	_, err := tx.Exec(query, prID, reviewerID)
+func (r *pullRequestRepository) GetEvents(prID string) ([]*models.PREvent, error) {
+	query := `
+		SELECT id, pull_request_id, event_type, user_id, previous_user_id, created_at
+		FROM pr_events
+		WHERE pull_request_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.Query(query, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.PREvent, 0)
+	for rows.Next() {
+		var e models.PREvent
+		var userID, previousUserID sql.NullString
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &userID, &previousUserID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.UserID = userID.String
+		e.PreviousUserID = previousUserID.String
+		e.CreatedAt = e.CreatedAt.UTC()
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func reviewerIDs(tx *sql.Tx, prID string) ([]string, error) {
+	rows, err := tx.Query(`SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1 ORDER BY id`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// insertEvent пишет событие в журнал pr_events в той же транзакции,
+// что и само изменение PR; пустой prID — событие пользователя без PR.
+func insertEvent(tx *sql.Tx, prID, eventType, userID, previousUserID string, at time.Time) error {
+	query := `INSERT INTO pr_events (pull_request_id, event_type, user_id, previous_user_id, created_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := tx.Exec(query, nullString(prID), eventType, nullString(userID), nullString(previousUserID), at)
 	return err
 }
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (r *pullRequestRepository) List(filter PullRequestFilter, page PullRequestPage) ([]*models.PullRequest, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "pr.status = "+arg(filter.Status))
+	}
+	if filter.AuthorID != "" {
+		conditions = append(conditions, "pr.author_id = "+arg(filter.AuthorID))
+	}
+	if filter.ReviewerID != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.reviewer_id = `+arg(filter.ReviewerID)+`)`)
+	}
+	if filter.TeamName != "" {
+		conditions = append(conditions, "pr.author_id IN (SELECT user_id FROM users WHERE team_name = "+arg(filter.TeamName)+")")
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "pr.created_at >= "+arg(filter.CreatedFrom.UTC()))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "pr.created_at < "+arg(filter.CreatedTo.UTC()))
+	}
+	if filter.MergedFrom != nil {
+		conditions = append(conditions, "pr.merged_at >= "+arg(filter.MergedFrom.UTC()))
+	}
+	if filter.MergedTo != nil {
+		conditions = append(conditions, "pr.merged_at < "+arg(filter.MergedTo.UTC()))
+	}
+	if filter.ReviewersBelow > 0 {
+		conditions = append(conditions, `pr.status = 'OPEN' AND (SELECT COUNT(*) FROM pr_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id) < `+arg(filter.ReviewersBelow))
+	}
+
+	op, direction := ">", "ASC"
+	if page.Desc {
+		op, direction = "<", "DESC"
+	}
+	orderBy := "pr.pull_request_id " + direction
+	if page.SortBy == PRSortCreatedAt {
+		orderBy = "pr.created_at " + direction + ", " + orderBy
+	}
+	if page.After != nil {
+		id := arg(page.After.PullRequestID)
+		if page.SortBy == PRSortCreatedAt {
+			at := arg(page.After.At.UTC())
+			conditions = append(conditions, "(pr.created_at "+op+" "+at+" OR (pr.created_at = "+at+" AND pr.pull_request_id "+op+" "+id+"))")
+		} else {
+			conditions = append(conditions, "pr.pull_request_id "+op+" "+id)
+		}
+	}
+
+	query := `SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+		FROM pull_requests pr`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, "\n\t\tAND ")
+	}
+	query += "\n\t\tORDER BY " + orderBy
+	if page.Limit > 0 {
+		query += " LIMIT " + arg(page.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]*models.PullRequest, 0)
+	for rows.Next() {
+		var pr models.PullRequest
+		var createdAt, mergedAt sql.NullTime
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			pr.CreatedAt = &createdAt.Time
+		}
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		prs = append(prs, &pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadReviewers(prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
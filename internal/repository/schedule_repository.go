@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// ScheduleRepository — рабочие часы пользователей и праздники команд.
+// Set* возвращают sql.ErrNoRows, если пользователя или команды нет или они в архиве.
+type ScheduleRepository interface {
+	SetWorkingHours(userID string, wh *models.WorkingHours) error
+	GetWorkingHours(userID string) (*models.WorkingHours, error)
+	ListWorkingHours(userIDs []string) (map[string]*models.WorkingHours, error)
+	SetHolidays(teamName string, holidays []models.Holiday) error
+	GetHolidays(teamName string) ([]models.Holiday, error)
+	ListHolidays(teamNames []string) (map[string][]models.Holiday, error)
+}
+
+type scheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) SetWorkingHours(userID string, wh *models.WorkingHours) error {
+	var exists int
+	if err := r.db.QueryRow(`SELECT 1 FROM users WHERE user_id = $1 AND archived_at IS NULL`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	periods, err := json.Marshal(wh.Periods)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO user_working_hours (user_id, time_zone, periods, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			time_zone = excluded.time_zone,
+			periods = excluded.periods,
+			updated_at = excluded.updated_at`,
+		userID, wh.TimeZone, string(periods), time.Now().UTC())
+	return err
+}
+
+// GetWorkingHours возвращает sql.ErrNoRows, если расписание не задано
+func (r *scheduleRepository) GetWorkingHours(userID string) (*models.WorkingHours, error) {
+	var wh models.WorkingHours
+	var periods string
+	err := r.db.QueryRow(`SELECT time_zone, periods FROM user_working_hours WHERE user_id = $1`, userID).Scan(&wh.TimeZone, &periods)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(periods), &wh.Periods); err != nil {
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// ListWorkingHours возвращает расписания только тех пользователей, у кого они заданы
+func (r *scheduleRepository) ListWorkingHours(userIDs []string) (map[string]*models.WorkingHours, error) {
+	result := make(map[string]*models.WorkingHours)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inPlaceholders(1, userIDs)
+	rows, err := r.db.Query(`SELECT user_id, time_zone, periods FROM user_working_hours WHERE user_id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, periods string
+		wh := &models.WorkingHours{}
+		if err := rows.Scan(&userID, &wh.TimeZone, &periods); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(periods), &wh.Periods); err != nil {
+			return nil, err
+		}
+		result[userID] = wh
+	}
+	return result, rows.Err()
+}
+
+// SetHolidays заменяет весь календарь праздников команды
+func (r *scheduleRepository) SetHolidays(teamName string, holidays []models.Holiday) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM teams WHERE team_name = $1 AND archived_at IS NULL`, teamName).Scan(&exists); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM team_holidays WHERE team_name = $1`, teamName); err != nil {
+		return err
+	}
+	for _, h := range holidays {
+		if _, err := tx.Exec(`INSERT INTO team_holidays (team_name, holiday_date, name) VALUES ($1, $2, $3)`, teamName, h.Date, h.Name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetHolidays возвращает праздники по возрастанию даты; sql.ErrNoRows — команды нет или она в архиве
+func (r *scheduleRepository) GetHolidays(teamName string) ([]models.Holiday, error) {
+	var exists int
+	if err := r.db.QueryRow(`SELECT 1 FROM teams WHERE team_name = $1 AND archived_at IS NULL`, teamName).Scan(&exists); err != nil {
+		return nil, err
+	}
+	holidays, err := r.ListHolidays([]string{teamName})
+	if err != nil {
+		return nil, err
+	}
+	if holidays[teamName] == nil {
+		return []models.Holiday{}, nil
+	}
+	return holidays[teamName], nil
+}
+
+func (r *scheduleRepository) ListHolidays(teamNames []string) (map[string][]models.Holiday, error) {
+	result := make(map[string][]models.Holiday)
+	if len(teamNames) == 0 {
+		return result, nil
+	}
+	placeholders, args := inPlaceholders(1, teamNames)
+	rows, err := r.db.Query(`
+		SELECT team_name, holiday_date, name FROM team_holidays
+		WHERE team_name IN (`+placeholders+`)
+		ORDER BY team_name, holiday_date`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamName string
+		var h models.Holiday
+		if err := rows.Scan(&teamName, &h.Date, &h.Name); err != nil {
+			return nil, err
+		}
+		result[teamName] = append(result[teamName], h)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// SLARepository — сроки ревью по командам и назначения, на которые они
+// распространяются. Архивные команды в выборку не попадают.
+type SLARepository interface {
+	Set(sla *models.TeamSLA) error
+	Get(teamName string) (*models.TeamSLA, error)
+	ListPending(teamName string) ([]PendingReview, error)
+	MarkReminded(prID, reviewerID string, at time.Time) error
+}
+
+// PendingReview — назначение без отправленного ревью в открытом PR, автор
+// которого состоит в команде со сроком SLA. Просрочено ли оно, решает
+// сервис: срок считается от AssignedAt.
+type PendingReview struct {
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	ReviewerID      string
+	AssignedAt      time.Time
+	RemindedAt      *time.Time
+	SLA             models.TeamSLA
+}
+
+type slaRepository struct {
+	db *sql.DB
+}
+
+func NewSLARepository(db *sql.DB) SLARepository {
+	return &slaRepository{db: db}
+}
+
+// Set создаёт или заменяет срок команды; sql.ErrNoRows — команды нет или она в архиве.
+func (r *slaRepository) Set(sla *models.TeamSLA) error {
+	var exists int
+	if err := r.db.QueryRow(`SELECT 1 FROM teams WHERE team_name = $1 AND archived_at IS NULL`, sla.TeamName).Scan(&exists); err != nil {
+		return err
+	}
+
+	var reassignAfter sql.NullInt64
+	if sla.ReassignAfterHours != nil {
+		reassignAfter = sql.NullInt64{Int64: int64(*sla.ReassignAfterHours), Valid: true}
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO team_review_slas (team_name, first_review_hours, reassign_after_hours, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (team_name) DO UPDATE SET
+			first_review_hours = excluded.first_review_hours,
+			reassign_after_hours = excluded.reassign_after_hours,
+			updated_at = excluded.updated_at`,
+		sla.TeamName, sla.FirstReviewHours, reassignAfter, time.Now().UTC())
+	return err
+}
+
+func (r *slaRepository) Get(teamName string) (*models.TeamSLA, error) {
+	sla := &models.TeamSLA{TeamName: teamName}
+	var reassignAfter sql.NullInt64
+	err := r.db.QueryRow(`
+		SELECT s.first_review_hours, s.reassign_after_hours
+		FROM team_review_slas s
+		JOIN teams t ON t.team_name = s.team_name AND t.archived_at IS NULL
+		WHERE s.team_name = $1`, teamName).Scan(&sla.FirstReviewHours, &reassignAfter)
+	if err != nil {
+		return nil, err
+	}
+	if reassignAfter.Valid {
+		hours := int(reassignAfter.Int64)
+		sla.ReassignAfterHours = &hours
+	}
+	return sla, nil
+}
+
+// ListPending возвращает назначения под SLA по возрастанию времени
+// назначения; пустой teamName — по всем командам.
+func (r *slaRepository) ListPending(teamName string) ([]PendingReview, error) {
+	query := `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, prr.reviewer_id,
+			prr.assigned_at, pr.created_at, prr.reminded_at,
+			s.team_name, s.first_review_hours, s.reassign_after_hours
+		FROM pr_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		JOIN users a ON a.user_id = pr.author_id
+		JOIN team_review_slas s ON s.team_name = a.team_name
+		JOIN teams t ON t.team_name = s.team_name AND t.archived_at IS NULL
+		WHERE pr.status = 'OPEN' AND prr.reviewed_at IS NULL`
+	var args []interface{}
+	if teamName != "" {
+		query += ` AND s.team_name = $1`
+		args = append(args, teamName)
+	}
+	query += ` ORDER BY prr.assigned_at, pr.pull_request_id, prr.reviewer_id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := make([]PendingReview, 0)
+	for rows.Next() {
+		var p PendingReview
+		var assignedAt, createdAt, remindedAt sql.NullTime
+		var reassignAfter sql.NullInt64
+		if err := rows.Scan(&p.PullRequestID, &p.PullRequestName, &p.AuthorID, &p.ReviewerID,
+			&assignedAt, &createdAt, &remindedAt,
+			&p.SLA.TeamName, &p.SLA.FirstReviewHours, &reassignAfter); err != nil {
+			return nil, err
+		}
+		// Назначения из ранних версий схемы могли остаться без assigned_at
+		if assignedAt.Valid {
+			p.AssignedAt = assignedAt.Time.UTC()
+		} else {
+			p.AssignedAt = createdAt.Time.UTC()
+		}
+		if remindedAt.Valid {
+			t := remindedAt.Time.UTC()
+			p.RemindedAt = &t
+		}
+		if reassignAfter.Valid {
+			hours := int(reassignAfter.Int64)
+			p.SLA.ReassignAfterHours = &hours
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// MarkReminded запоминает, что о назначении уже напомнили;
+// sql.ErrNoRows — назначения больше нет.
+func (r *slaRepository) MarkReminded(prID, reviewerID string, at time.Time) error {
+	res, err := r.db.Exec(`UPDATE pr_reviewers SET reminded_at = $1 WHERE pull_request_id = $2 AND reviewer_id = $3`, at.UTC(), prID, reviewerID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// inPlaceholders строит список "$n, $n+1, ..." для условия IN и аргументы к нему.
+// Используется вместо ANY($1), чтобы запросы работали и в PostgreSQL, и в SQLite.
+func inPlaceholders(start int, values []string) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = "$" + strconv.Itoa(start+i)
+		args[i] = v
+	}
+	return strings.Join(placeholders, ", "), args
+}
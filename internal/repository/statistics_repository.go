@@ -1,13 +1,43 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
 )
 
 type StatisticsRepository interface {
-	GetStatistics() (*models.Statistics, error)
+	GetStatistics(loadFrom, loadTo *time.Time) (*models.Statistics, []models.ReviewerLoad, error)
+	GetTimingSamples(from, to time.Time) (*TimingSamples, error)
+	GetTeamLoad(teamName string) ([]models.MemberLoad, error)
+	GetReviewerLoad(from, to *time.Time) ([]models.ReviewerLoad, error)
+}
+
+// TimingSample — замер для /statistics/timings: длительность Duration,
+// закончившаяся в At. TeamName — текущая команда автора PR.
+type TimingSample struct {
+	PullRequestID string
+	AuthorID      string
+	TeamName      string
+	ReviewerIDs   []string
+	At            time.Time
+	Duration      time.Duration
+}
+
+// TimingSamples — замеры, закончившиеся в [from, to). Ревью берутся из
+// журнала PR, поэтому учитываются и ревьюверы, которых позже сняли.
+type TimingSamples struct {
+	// Merge — от создания до merge; ReviewerIDs — текущие ревьюверы PR
+	Merge []TimingSample
+	// FirstReview — от создания PR до первого отправленного ревью
+	FirstReview []TimingSample
+	// ReviewerResponse — от назначения ревьювера до его ревью;
+	// в ReviewerIDs ровно один ревьювер
+	ReviewerResponse []TimingSample
 }
 
 type statisticsRepository struct {
@@ -18,86 +48,303 @@ func NewStatisticsRepository(db *sql.DB) StatisticsRepository {
 	return &statisticsRepository{db: db}
 }
 
-func (r *statisticsRepository) GetStatistics() (*models.Statistics, error) {
-	stats := &models.Statistics{}
+// queryer — общее у *sql.DB и *sql.Tx для запросов статистики.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// GetStatistics возвращает показатели и нагрузку ревьюверов по PR, созданным
+// в [loadFrom, loadTo), из одной транзакции чтения: в PostgreSQL REPEATABLE
+// READ даёт обоим запросам один снимок, SQLite держит его до конца транзакции.
+func (r *statisticsRepository) GetStatistics(loadFrom, loadTo *time.Time) (*models.Statistics, []models.ReviewerLoad, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	stats, err := queryStatistics(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	loads, err := queryReviewerLoad(tx, loadFrom, loadTo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, loads, tx.Commit()
+}
 
-	// Count teams
-	var teamsCount int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM teams").Scan(&teamsCount)
+// queryStatistics считает все показатели одним запросом, поэтому они
+// согласованы между собой: итоги повторяются в каждой строке by_reviewer,
+// а без назначений приходит одна строка с NULL вместо ревьювера.
+func queryStatistics(db queryer) (*models.Statistics, error) {
+	rows, err := db.Query(`
+		WITH totals AS (
+			SELECT
+				(SELECT COUNT(*) FROM teams WHERE archived_at IS NULL) AS teams_total,
+				(SELECT COUNT(*) FROM users WHERE archived_at IS NULL) AS users_total,
+				(SELECT COUNT(*) FROM users WHERE is_active = true AND archived_at IS NULL) AS users_active,
+				(SELECT COUNT(*) FROM pull_requests) AS prs_total,
+				(SELECT COUNT(*) FROM pull_requests WHERE status = 'OPEN') AS prs_open,
+				(SELECT COUNT(*) FROM pull_requests WHERE status = 'MERGED') AS prs_merged,
+				(SELECT COUNT(*) FROM pr_reviewers) AS assignments_total
+		),
+		by_reviewer AS (
+			SELECT reviewer_id, COUNT(*) AS assignments
+			FROM pr_reviewers
+			GROUP BY reviewer_id
+		)
+		SELECT t.teams_total, t.users_total, t.users_active, t.prs_total, t.prs_open, t.prs_merged,
+			t.assignments_total, b.reviewer_id, b.assignments
+		FROM totals t
+		LEFT JOIN by_reviewer b ON 1 = 1
+		ORDER BY b.assignments DESC, b.reviewer_id`)
 	if err != nil {
 		return nil, err
 	}
-	stats.Teams.Total = teamsCount
+	defer rows.Close()
 
-	// Count users
-	var usersTotal, usersActive, usersInactive int
-	err = r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&usersTotal)
+	stats := &models.Statistics{}
+	var byReviewer []models.ReviewerAssignment
+	for rows.Next() {
+		var reviewerID sql.NullString
+		var assignments sql.NullInt64
+		if err := rows.Scan(
+			&stats.Teams.Total,
+			&stats.Users.Total,
+			&stats.Users.Active,
+			&stats.PullRequests.Total,
+			&stats.PullRequests.Open,
+			&stats.PullRequests.Merged,
+			&stats.ReviewAssignments.Total,
+			&reviewerID,
+			&assignments,
+		); err != nil {
+			return nil, err
+		}
+		if reviewerID.Valid {
+			byReviewer = append(byReviewer, models.ReviewerAssignment{UserID: reviewerID.String, Count: int(assignments.Int64)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.Users.Inactive = stats.Users.Total - stats.Users.Active
+	stats.ReviewAssignments.ByReviewer = byReviewer
+
+	return stats, nil
+}
+
+func (r *statisticsRepository) GetTimingSamples(from, to time.Time) (*TimingSamples, error) {
+	samples := &TimingSamples{}
+
+	rows, err := r.db.Query(`
+		SELECT p.pull_request_id, p.author_id, COALESCE(u.team_name, ''), p.created_at, p.merged_at
+		FROM pull_requests p
+		LEFT JOIN users u ON u.user_id = p.author_id
+		WHERE p.merged_at >= $1 AND p.merged_at < $2
+		ORDER BY p.merged_at, p.pull_request_id`, from, to)
 	if err != nil {
 		return nil, err
 	}
-	err = r.db.QueryRow("SELECT COUNT(*) FROM users WHERE is_active = true").Scan(&usersActive)
+	samples.Merge, err = scanTimingSamples(rows, false)
 	if err != nil {
 		return nil, err
 	}
-	usersInactive = usersTotal - usersActive
-	stats.Users.Total = usersTotal
-	stats.Users.Active = usersActive
-	stats.Users.Inactive = usersInactive
+	if err := r.loadSampleReviewers(samples.Merge, from, to); err != nil {
+		return nil, err
+	}
 
-	// Count pull requests
-	var prTotal, prOpen, prMerged int
-	err = r.db.QueryRow("SELECT COUNT(*) FROM pull_requests").Scan(&prTotal)
+	// Первое ревью PR — событие, раньше которого по журналу ревью не было
+	rows, err = r.db.Query(`
+		SELECT e.pull_request_id, p.author_id, COALESCE(u.team_name, ''), p.created_at, e.created_at
+		FROM pr_events e
+		JOIN pull_requests p ON p.pull_request_id = e.pull_request_id
+		LEFT JOIN users u ON u.user_id = p.author_id
+		WHERE e.event_type = $3 AND e.created_at >= $1 AND e.created_at < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM pr_events f
+				WHERE f.pull_request_id = e.pull_request_id AND f.event_type = $3 AND f.id < e.id
+			)
+		ORDER BY e.id`, from, to, models.EventReviewSubmitted)
 	if err != nil {
 		return nil, err
 	}
-	err = r.db.QueryRow("SELECT COUNT(*) FROM pull_requests WHERE status = 'OPEN'").Scan(&prOpen)
+	samples.FirstReview, err = scanTimingSamples(rows, false)
 	if err != nil {
 		return nil, err
 	}
-	err = r.db.QueryRow("SELECT COUNT(*) FROM pull_requests WHERE status = 'MERGED'").Scan(&prMerged)
+
+	// Ревью отсчитывается от последнего назначения того же ревьювера перед ним
+	rows, err = r.db.Query(`
+		SELECT e.pull_request_id, p.author_id, COALESCE(u.team_name, ''), e.user_id, a.created_at, e.created_at
+		FROM pr_events e
+		JOIN pull_requests p ON p.pull_request_id = e.pull_request_id
+		LEFT JOIN users u ON u.user_id = p.author_id
+		JOIN pr_events a ON a.pull_request_id = e.pull_request_id AND a.user_id = e.user_id
+			AND a.event_type = $4 AND a.id < e.id
+		WHERE e.event_type = $3 AND e.created_at >= $1 AND e.created_at < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM pr_events l
+				WHERE l.pull_request_id = e.pull_request_id AND l.user_id = e.user_id
+					AND l.event_type = $4 AND l.id > a.id AND l.id < e.id
+			)
+		ORDER BY e.id`, from, to, models.EventReviewSubmitted, models.EventReviewerAssigned)
 	if err != nil {
 		return nil, err
 	}
-	stats.PullRequests.Total = prTotal
-	stats.PullRequests.Open = prOpen
-	stats.PullRequests.Merged = prMerged
-
-	// Count review assignments
-	var assignmentsTotal int
-	err = r.db.QueryRow("SELECT COUNT(*) FROM pr_reviewers").Scan(&assignmentsTotal)
+	samples.ReviewerResponse, err = scanTimingSamples(rows, true)
 	if err != nil {
 		return nil, err
 	}
-	stats.ReviewAssignments.Total = assignmentsTotal
 
-	// Get assignments by reviewer
+	return samples, nil
+}
+
+// scanTimingSamples читает строки (pull_request_id, team_name, [reviewer_id,] start, end)
+func scanTimingSamples(rows *sql.Rows, withReviewer bool) ([]TimingSample, error) {
+	defer rows.Close()
+
+	samples := make([]TimingSample, 0)
+	for rows.Next() {
+		var sample TimingSample
+		var start, end time.Time
+		dest := []interface{}{&sample.PullRequestID, &sample.AuthorID, &sample.TeamName}
+		var reviewerID string
+		if withReviewer {
+			dest = append(dest, &reviewerID)
+		}
+		dest = append(dest, &start, &end)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if withReviewer {
+			sample.ReviewerIDs = []string{reviewerID}
+		}
+		sample.At = end.UTC()
+		sample.Duration = end.Sub(start)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+func (r *statisticsRepository) loadSampleReviewers(samples []TimingSample, from, to time.Time) error {
+	if len(samples) == 0 {
+		return nil
+	}
 	rows, err := r.db.Query(`
-		SELECT reviewer_id, COUNT(*) as count
-		FROM pr_reviewers
-		GROUP BY reviewer_id
-		ORDER BY count DESC
-	`)
+		SELECT rv.pull_request_id, rv.reviewer_id
+		FROM pr_reviewers rv
+		JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+		WHERE p.merged_at >= $1 AND p.merged_at < $2
+		ORDER BY rv.reviewer_id`, from, to)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var byReviewer []models.ReviewerAssignment
+	reviewers := make(map[string][]string)
 	for rows.Next() {
-		var assignment models.ReviewerAssignment
-		if err := rows.Scan(&assignment.UserID, &assignment.Count); err != nil {
-			return nil, err
+		var prID, reviewerID string
+		if err := rows.Scan(&prID, &reviewerID); err != nil {
+			return err
 		}
-		byReviewer = append(byReviewer, assignment)
+		reviewers[prID] = append(reviewers[prID], reviewerID)
 	}
 	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range samples {
+		samples[i].ReviewerIDs = reviewers[samples[i].PullRequestID]
+	}
+	return nil
+}
+
+// GetTeamLoad возвращает нагрузку неархивных участников команды без оценки Load;
+// sql.ErrNoRows — команды нет или она архивирована.
+func (r *statisticsRepository) GetTeamLoad(teamName string) ([]models.MemberLoad, error) {
+	var exists int
+	err := r.db.QueryRow(`SELECT 1 FROM teams WHERE team_name = $1 AND archived_at IS NULL`, teamName).Scan(&exists)
+	if err != nil {
 		return nil, err
 	}
 
-	stats.ReviewAssignments.ByReviewer = byReviewer
+	rows, err := r.db.Query(`
+		SELECT u.user_id, u.username, u.is_active,
+			(SELECT COUNT(*) FROM pr_reviewers rv
+				JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+				WHERE rv.reviewer_id = u.user_id AND p.status = 'OPEN'),
+			(SELECT COUNT(*) FROM pr_reviewers rv WHERE rv.reviewer_id = u.user_id),
+			(SELECT COUNT(*) FROM pull_requests p WHERE p.author_id = u.user_id AND p.status = 'OPEN'),
+			(SELECT COUNT(*) FROM pull_requests p WHERE p.author_id = u.user_id)
+		FROM users u
+		WHERE u.team_name = $1 AND u.archived_at IS NULL
+		ORDER BY u.user_id`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return stats, nil
+	members := make([]models.MemberLoad, 0)
+	for rows.Next() {
+		var m models.MemberLoad
+		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive,
+			&m.OpenAssignments, &m.TotalAssignments, &m.OpenAuthored, &m.TotalAuthored); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
 }
 
+// GetReviewerLoad считает назначения ревьюверов в PR, созданных в [from, to);
+// nil-граница не ограничивает выборку.
+func (r *statisticsRepository) GetReviewerLoad(from, to *time.Time) ([]models.ReviewerLoad, error) {
+	return queryReviewerLoad(r.db, from, to)
+}
 
+func queryReviewerLoad(db queryer, from, to *time.Time) ([]models.ReviewerLoad, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if from != nil {
+		conditions = append(conditions, "p.created_at >= "+arg(from.UTC()))
+	}
+	if to != nil {
+		conditions = append(conditions, "p.created_at < "+arg(to.UTC()))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
 
+	rows, err := db.Query(`
+		SELECT rv.reviewer_id, COALESCE(t.team_name, ''),
+			SUM(CASE WHEN p.status = 'OPEN' THEN 1 ELSE 0 END), COUNT(*)
+		FROM pr_reviewers rv
+		JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+		LEFT JOIN users u ON u.user_id = rv.reviewer_id
+		LEFT JOIN teams t ON t.team_name = u.team_name AND t.archived_at IS NULL
+		`+where+`
+		GROUP BY rv.reviewer_id, t.team_name
+		ORDER BY rv.reviewer_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := make([]models.ReviewerLoad, 0)
+	for rows.Next() {
+		var l models.ReviewerLoad
+		if err := rows.Scan(&l.UserID, &l.TeamName, &l.OpenAssignments, &l.TotalAssignments); err != nil {
+			return nil, err
+		}
+		loads = append(loads, l)
+	}
+	return loads, rows.Err()
+}
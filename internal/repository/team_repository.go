@@ -2,13 +2,19 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
 )
 
+// TeamRepository — GetByName возвращает и архивные команды, и архивных
+// участников; отфильтровывает их сервис.
 type TeamRepository interface {
 	Create(team *models.Team) error
 	GetByName(teamName string) (*models.Team, error)
+	Add(tx Tx, teamName string) error
+	Archive(tx Tx, teamName string, at time.Time) error
+	List() ([]*models.Team, error)
 }
 
 type teamRepository struct {
@@ -33,7 +39,27 @@ func (r *teamRepository) Create(team *models.Team) error {
 	}
 
 	if len(team.Members) > 0 {
-		stmt, err := tx.Prepare(`INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)`)
+		var inactive []string
+		for _, member := range team.Members {
+			if !member.IsActive {
+				inactive = append(inactive, member.UserID)
+			}
+		}
+		if len(inactive) > 0 {
+			if err := insertDeactivations(tx, inactive, time.Now().UTC()); err != nil {
+				return err
+			}
+		}
+
+		// Существующие пользователи переходят в новую команду, а не вызывают ошибку
+		stmt, err := tx.Prepare(`INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET
+				username = excluded.username,
+				team_name = excluded.team_name,
+				is_active = excluded.is_active,
+				away_until = NULL,
+				archived_at = NULL,
+				updated_at = CURRENT_TIMESTAMP`)
 		if err != nil {
 			return err
 		}
@@ -56,16 +82,17 @@ func (r *teamRepository) GetByName(teamName string) (*models.Team, error) {
 		Members:  []models.TeamMember{},
 	}
 
-	var exists bool
-	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, teamName).Scan(&exists)
+	var archivedAt sql.NullTime
+	err := r.db.QueryRow(`SELECT archived_at FROM teams WHERE team_name = $1`, teamName).Scan(&archivedAt)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, sql.ErrNoRows
+	if archivedAt.Valid {
+		t := archivedAt.Time.UTC()
+		team.ArchivedAt = &t
 	}
 
-	query := `SELECT user_id, username, is_active FROM users WHERE team_name = $1 ORDER BY user_id`
+	query := `SELECT user_id, username, is_active, archived_at FROM users WHERE team_name = $1 ORDER BY user_id`
 	rows, err := r.db.Query(query, teamName)
 	if err != nil {
 		return nil, err
@@ -74,9 +101,14 @@ func (r *teamRepository) GetByName(teamName string) (*models.Team, error) {
 
 	for rows.Next() {
 		var member models.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+		var memberArchivedAt sql.NullTime
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &memberArchivedAt); err != nil {
 			return nil, err
 		}
+		if memberArchivedAt.Valid {
+			t := memberArchivedAt.Time.UTC()
+			member.ArchivedAt = &t
+		}
 		team.Members = append(team.Members, member)
 	}
 
@@ -86,3 +118,77 @@ func (r *teamRepository) GetByName(teamName string) (*models.Team, error) {
 
 	return team, nil
 }
+
+// Add создаёт команду без участников в транзакции tx; участники
+// добавляются через UserRepository.Upsert в той же транзакции.
+func (r *teamRepository) Add(tx Tx, teamName string) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+	_, err = t.Exec(`INSERT INTO teams (team_name) VALUES ($1)`, teamName)
+	return err
+}
+
+// Archive помечает команду архивной; её участников архивирует сервис
+// через UserRepository.Archive в той же транзакции.
+func (r *teamRepository) Archive(tx Tx, teamName string, at time.Time) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+	_, err = t.Exec(`UPDATE teams SET archived_at = $1 WHERE team_name = $2 AND archived_at IS NULL`, at, teamName)
+	return err
+}
+
+// List возвращает все команды с участниками в порядке team_name, включая
+// архивные; участники упорядочены по user_id, как в GetByName.
+func (r *teamRepository) List() ([]*models.Team, error) {
+	rows, err := r.db.Query(`SELECT team_name, archived_at FROM teams ORDER BY team_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]*models.Team, 0)
+	byName := make(map[string]*models.Team)
+	for rows.Next() {
+		team := &models.Team{Members: []models.TeamMember{}}
+		var archivedAt sql.NullTime
+		if err := rows.Scan(&team.TeamName, &archivedAt); err != nil {
+			return nil, err
+		}
+		if archivedAt.Valid {
+			t := archivedAt.Time.UTC()
+			team.ArchivedAt = &t
+		}
+		teams = append(teams, team)
+		byName[team.TeamName] = team
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	memberRows, err := r.db.Query(`SELECT team_name, user_id, username, is_active, archived_at FROM users WHERE team_name IS NOT NULL ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var teamName string
+		var member models.TeamMember
+		var archivedAt sql.NullTime
+		if err := memberRows.Scan(&teamName, &member.UserID, &member.Username, &member.IsActive, &archivedAt); err != nil {
+			return nil, err
+		}
+		if archivedAt.Valid {
+			t := archivedAt.Time.UTC()
+			member.ArchivedAt = &t
+		}
+		if team, ok := byName[teamName]; ok {
+			team.Members = append(team.Members, member)
+		}
+	}
+	return teams, memberRows.Err()
+}
@@ -32,16 +32,18 @@ func setupTestDB(t *testing.T) *sql.DB {
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS teams (
 			team_name VARCHAR(255) PRIMARY KEY,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			archived_at TIMESTAMP
 		);
 		
 		CREATE TABLE IF NOT EXISTS users (
 			user_id VARCHAR(255) PRIMARY KEY,
 			username VARCHAR(255) NOT NULL,
-			team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name),
+			team_name VARCHAR(255) REFERENCES teams(team_name),
 			is_active BOOLEAN DEFAULT true,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			archived_at TIMESTAMP
 		);
 	`)
 	if err != nil {
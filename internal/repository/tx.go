@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrForeignTx = errors.New("transaction was not started by this storage")
+
+// Tx — транзакция хранилища. *sql.Tx удовлетворяет интерфейсу напрямую,
+// in-memory хранилище предоставляет собственную реализацию.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+type Transactor interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+type sqlTransactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &sqlTransactor{db: db}
+}
+
+func (t *sqlTransactor) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := t.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func sqlTx(tx Tx) (*sql.Tx, error) {
+	t, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, ErrForeignTx
+	}
+	return t, nil
+}
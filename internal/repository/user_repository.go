@@ -2,17 +2,45 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
-	"github.com/lib/pq"
 	"github.com/reviewer-service/internal/models"
 )
 
+// UserRepository — пользователь без команды хранится с team_name = NULL,
+// в модели это пустая строка. Выборки по идентификатору возвращают и
+// архивных пользователей (с ArchivedAt), кандидаты в ревьюверы — только живых.
+// Любое явное изменение активности отменяет отсутствие из SetAway.
 type UserRepository interface {
 	GetByID(userID string) (*models.User, error)
 	UpdateActivity(userID string, isActive bool) (*models.User, error)
 	GetActiveTeamMembers(teamName string, excludeUserID string) ([]*models.User, error)
-	DeactivateUsers(tx *sql.Tx, userIDs []string) error
+	DeactivateUsers(tx Tx, userIDs []string) error
 	GetUsersByIDs(userIDs []string) ([]*models.User, error)
+	Upsert(tx Tx, user *models.User) error
+	Archive(tx Tx, userIDs []string, at time.Time) error
+	List() ([]*models.User, error)
+	SetAway(userID string, until time.Time) error
+	ReturnFromAway(now time.Time) (int64, error)
+}
+
+const userColumns = `user_id, username, COALESCE(team_name, ''), is_active, archived_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	var archivedAt sql.NullTime
+	if err := row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &archivedAt); err != nil {
+		return nil, err
+	}
+	if archivedAt.Valid {
+		t := archivedAt.Time.UTC()
+		user.ArchivedAt = &t
+	}
+	return &user, nil
 }
 
 type userRepository struct {
@@ -24,29 +52,39 @@ func NewUserRepository(db *sql.DB) UserRepository {
 }
 
 func (r *userRepository) GetByID(userID string) (*models.User, error) {
-	query := `SELECT user_id, username, team_name, is_active FROM users WHERE user_id = $1`
-	var user models.User
-	err := r.db.QueryRow(query, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	query := `SELECT ` + userColumns + ` FROM users WHERE user_id = $1`
+	user, err := scanUser(r.db.QueryRow(query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *userRepository) UpdateActivity(userID string, isActive bool) (*models.User, error) {
-	query := `UPDATE users SET is_active = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2 RETURNING user_id, username, team_name, is_active`
-	var user models.User
-	err := r.db.QueryRow(query, isActive, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if !isActive {
+		if err := insertDeactivations(tx, []string{userID}, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `UPDATE users SET is_active = $1, away_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2 RETURNING ` + userColumns
+	user, err := scanUser(tx.QueryRow(query, isActive, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
 		return nil, err
 	}
-	return &user, nil
+	return user, tx.Commit()
 }
 
 func (r *userRepository) GetActiveTeamMembers(teamName string, excludeUserID string) ([]*models.User, error) {
@@ -54,10 +92,10 @@ func (r *userRepository) GetActiveTeamMembers(teamName string, excludeUserID str
 	var args []interface{}
 
 	if excludeUserID != "" {
-		query = `SELECT user_id, username, team_name, is_active FROM users WHERE team_name = $1 AND is_active = true AND user_id != $2 ORDER BY user_id`
+		query = `SELECT ` + userColumns + ` FROM users WHERE team_name = $1 AND is_active = true AND archived_at IS NULL AND user_id != $2 ORDER BY user_id`
 		args = []interface{}{teamName, excludeUserID}
 	} else {
-		query = `SELECT user_id, username, team_name, is_active FROM users WHERE team_name = $1 AND is_active = true ORDER BY user_id`
+		query = `SELECT ` + userColumns + ` FROM users WHERE team_name = $1 AND is_active = true AND archived_at IS NULL ORDER BY user_id`
 		args = []interface{}{teamName}
 	}
 
@@ -69,11 +107,11 @@ func (r *userRepository) GetActiveTeamMembers(teamName string, excludeUserID str
 
 	users := make([]*models.User, 0)
 	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -83,13 +121,23 @@ func (r *userRepository) GetActiveTeamMembers(teamName string, excludeUserID str
 	return users, nil
 }
 
-func (r *userRepository) DeactivateUsers(tx *sql.Tx, userIDs []string) error {
+func (r *userRepository) DeactivateUsers(tx Tx, userIDs []string) error {
 	if len(userIDs) == 0 {
 		return nil
 	}
 
-	query := `UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE user_id = ANY($1)`
-	_, err := tx.Exec(query, pq.Array(userIDs))
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := insertDeactivations(t, userIDs, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	placeholders, args := inPlaceholders(1, userIDs)
+	query := `UPDATE users SET is_active = false, away_until = NULL, updated_at = CURRENT_TIMESTAMP WHERE user_id IN (` + placeholders + `)`
+	_, err = t.Exec(query, args...)
 	return err
 }
 
@@ -98,8 +146,9 @@ func (r *userRepository) GetUsersByIDs(userIDs []string) ([]*models.User, error)
 		return []*models.User{}, nil
 	}
 
-	query := `SELECT user_id, username, team_name, is_active FROM users WHERE user_id = ANY($1)`
-	rows, err := r.db.Query(query, pq.Array(userIDs))
+	placeholders, args := inPlaceholders(1, userIDs)
+	query := `SELECT ` + userColumns + ` FROM users WHERE user_id IN (` + placeholders + `)`
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -107,11 +156,149 @@ func (r *userRepository) GetUsersByIDs(userIDs []string) ([]*models.User, error)
 
 	users := make([]*models.User, 0)
 	for rows.Next() {
-		var u models.User
-		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, &u)
+		users = append(users, u)
 	}
 	return users, rows.Err()
 }
+
+// Upsert создаёт пользователя или обновляет имя, команду и активность существующего;
+// архивный пользователь при этом восстанавливается.
+func (r *userRepository) Upsert(tx Tx, user *models.User) error {
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsActive {
+		if err := insertDeactivations(t, []string{user.UserID}, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			username = excluded.username,
+			team_name = excluded.team_name,
+			is_active = excluded.is_active,
+			away_until = NULL,
+			archived_at = NULL,
+			updated_at = CURRENT_TIMESTAMP`
+	_, err = t.Exec(query, user.UserID, user.Username, nullString(user.TeamName), user.IsActive)
+	return err
+}
+
+// Archive деактивирует и архивирует пользователей; уже архивные не меняются.
+// Строки не удаляются: на них ссылаются PR, ревьюверы и журнал событий.
+func (r *userRepository) Archive(tx Tx, userIDs []string, at time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	t, err := sqlTx(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := insertDeactivations(t, userIDs, at); err != nil {
+		return err
+	}
+
+	placeholders, args := inPlaceholders(2, userIDs)
+	query := `UPDATE users SET is_active = false, away_until = NULL, archived_at = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE archived_at IS NULL AND user_id IN (` + placeholders + `)`
+	_, err = t.Exec(query, append([]interface{}{at}, args...)...)
+	return err
+}
+
+// List возвращает всех пользователей, включая архивных, в порядке user_id.
+func (r *userRepository) List() ([]*models.User, error) {
+	rows, err := r.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// SetAway деактивирует пользователя до until; sql.ErrNoRows — пользователя
+// нет или он в архиве.
+func (r *userRepository) SetAway(userID string, until time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertDeactivations(tx, []string{userID}, time.Now().UTC()); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE users SET is_active = false, away_until = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2 AND archived_at IS NULL`, until, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+// insertDeactivations пишет в журнал USER_DEACTIVATED для тех из userIDs,
+// кто сейчас активен и не в архиве; вызывается до UPDATE в той же транзакции.
+func insertDeactivations(tx *sql.Tx, userIDs []string, at time.Time) error {
+	placeholders, args := inPlaceholders(1, userIDs)
+	rows, err := tx.Query(`SELECT user_id FROM users
+		WHERE is_active = true AND archived_at IS NULL AND user_id IN (`+placeholders+`)
+		ORDER BY user_id`, args...)
+	if err != nil {
+		return err
+	}
+	var active []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		active = append(active, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range active {
+		if err := insertEvent(tx, "", models.EventUserDeactivated, id, "", at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReturnFromAway возвращает в ротацию тех, чьё отсутствие закончилось к now,
+// и сообщает их число.
+func (r *userRepository) ReturnFromAway(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`UPDATE users SET is_active = true, away_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE away_until <= $1 AND archived_at IS NULL`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
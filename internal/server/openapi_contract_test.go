@@ -0,0 +1,618 @@
+package server_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+	reviewerservice "github.com/reviewer-service"
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/server"
+	"github.com/reviewer-service/internal/storage"
+)
+
+func init() {
+	// Сервер не проверяет тела массовой загрузки и SCIM этими декодерами,
+	// но тест сверяет со спецификацией и их, и ответы в этих форматах
+	openapi3filter.RegisterBodyDecoder("application/scim+json", openapi3filter.JSONBodyDecoder)
+	openapi3filter.RegisterBodyEncoder("application/scim+json", json.Marshal)
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.PlainBodyDecoder)
+}
+
+// nonAPIRoutes — служебные маршруты, которые сознательно не описаны в спецификации.
+var nonAPIRoutes = map[string]bool{
+	"GET /docs":             true,
+	"GET /docs/":            true,
+	"GET /api/openapi.yaml": true,
+	"GET /api/openapi.json": true,
+}
+
+// unreachableStatuses — документированные ответы, которые нельзя получить
+// от роутера поверх in-memory хранилища: авторизация пока не реализована,
+// а 500 требует отказа хранилища.
+var unreachableStatuses = map[string]bool{
+	"401": true,
+	"500": true,
+}
+
+var (
+	csvHeaders    = map[string]string{"Content-Type": "text/csv"}
+	ndjsonHeaders = map[string]string{"Content-Type": "application/x-ndjson"}
+)
+
+const scimToken = "scim-secret"
+
+var scimHeaders = map[string]string{
+	"Authorization": "Bearer " + scimToken,
+	"Content-Type":  "application/scim+json",
+}
+
+const slackSecret = "slack-secret"
+
+// slackHeaders подписывает тело формы так же, как Slack
+func slackHeaders(body string) map[string]string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(slackSecret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return map[string]string{
+		"Content-Type":              "application/x-www-form-urlencoded",
+		"X-Slack-Request-Timestamp": timestamp,
+		"X-Slack-Signature":         "v0=" + hex.EncodeToString(mac.Sum(nil)),
+	}
+}
+
+type contractCase struct {
+	name    string
+	method  string
+	target  string
+	body    string
+	headers map[string]string
+	// setup выполняется перед запросом, например чтобы занять ключ идемпотентности.
+	setup func(t *testing.T, st *storage.Storage)
+	// streamFor — через сколько клиент отключается от потокового ответа.
+	streamFor time.Duration
+
+	status int
+	code   string
+	// invalid помечает запросы, которые сама спецификация должна отвергать.
+	invalid bool
+}
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(reviewerservice.OpenAPISpec)
+	if err != nil {
+		t.Fatalf("failed to load openapi.yaml: %v", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatalf("openapi.yaml is not a valid OpenAPI document: %v", err)
+	}
+	return doc
+}
+
+func newTestRouter(t *testing.T, st *storage.Storage) *mux.Router {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		Idempotency: config.IdempotencyConfig{TTL: time.Hour},
+		SCIM:        config.SCIMConfig{Token: scimToken},
+		Slack:       config.SlackConfig{SigningSecret: slackSecret},
+	}
+	router, err := server.NewRouter(st, cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	return router
+}
+
+func operationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	doc := loadSpec(t)
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[operationKey(method, path)] = true
+		}
+	}
+
+	registered := make(map[string]bool)
+	err := newTestRouter(t, storage.NewMemory()).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || route.GetHandler() == nil {
+			// Префиксы подроутеров (/scim/v2) сами запросы не обслуживают
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return fmt.Errorf("route %s has no methods", path)
+		}
+		for _, method := range methods {
+			registered[operationKey(method, path)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk router: %v", err)
+	}
+
+	for op := range registered {
+		if !documented[op] && !nonAPIRoutes[op] {
+			t.Errorf("route %s is registered but not documented in openapi.yaml", op)
+		}
+	}
+	for op := range documented {
+		if !registered[op] {
+			t.Errorf("operation %s is documented but not registered in the router", op)
+		}
+	}
+}
+
+// contractScenario — последовательность запросов к одному роутеру: состояние,
+// созданное ранними шагами, используется поздними.
+func contractScenario() []contractCase {
+	const team = `{"team_name":"backend","members":[
+		{"user_id":"u1","username":"Alice","is_active":true},
+		{"user_id":"u2","username":"Bob","is_active":true},
+		{"user_id":"u3","username":"Charlie","is_active":true},
+		{"user_id":"u4","username":"Dave","is_active":true}]}`
+	const duo = `{"team_name":"duo","members":[
+		{"user_id":"d1","username":"Eve","is_active":true},
+		{"user_id":"d2","username":"Frank","is_active":true}]}`
+
+	return []contractCase{
+		{name: "create team", method: "POST", target: "/team/add", body: team, status: 201},
+		{name: "create second team", method: "POST", target: "/team/add", body: duo, status: 201},
+		{name: "team exists", method: "POST", target: "/team/add", body: team, status: 400, code: "TEAM_EXISTS"},
+		{name: "team malformed body", method: "POST", target: "/team/add", body: `{"team_name":`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "get team", method: "GET", target: "/team/get?team_name=backend", status: 200},
+		{name: "get missing team", method: "GET", target: "/team/get?team_name=missing", status: 404, code: "NOT_FOUND"},
+		{name: "get team without name", method: "GET", target: "/team/get", status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "set review SLA", method: "POST", target: "/team/setReviewSLA", body: `{"team_name":"backend","first_review_hours":24,"reassign_after_hours":48}`, status: 200},
+		{name: "set SLA with early reassignment", method: "POST", target: "/team/setReviewSLA", body: `{"team_name":"backend","first_review_hours":24,"reassign_after_hours":12}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "set SLA without hours", method: "POST", target: "/team/setReviewSLA", body: `{"team_name":"backend","first_review_hours":0}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set SLA of missing team", method: "POST", target: "/team/setReviewSLA", body: `{"team_name":"missing","first_review_hours":24}`, status: 404, code: "NOT_FOUND"},
+		{name: "get review SLA", method: "GET", target: "/team/getReviewSLA?team_name=backend", status: 200},
+		{name: "get unset review SLA", method: "GET", target: "/team/getReviewSLA?team_name=duo", status: 404, code: "NOT_FOUND"},
+		{name: "get review SLA without team", method: "GET", target: "/team/getReviewSLA", status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "set working hours", method: "POST", target: "/users/setWorkingHours", body: `{"user_id":"u2","time_zone":"Europe/Moscow","periods":[{"weekday":"monday","start":"09:00","end":"18:00"}]}`, status: 200},
+		{name: "set working hours in unknown zone", method: "POST", target: "/users/setWorkingHours", body: `{"user_id":"u2","time_zone":"Mars/Olympus","periods":[{"weekday":"monday","start":"09:00","end":"18:00"}]}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "set working hours without periods", method: "POST", target: "/users/setWorkingHours", body: `{"user_id":"u2","time_zone":"UTC","periods":[]}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set working hours of missing user", method: "POST", target: "/users/setWorkingHours", body: `{"user_id":"missing","time_zone":"UTC","periods":[{"weekday":"monday","start":"09:00","end":"18:00"}]}`, status: 404, code: "NOT_FOUND"},
+		{name: "get working hours", method: "GET", target: "/users/getWorkingHours?user_id=u2", status: 200},
+		{name: "get unset working hours", method: "GET", target: "/users/getWorkingHours?user_id=u3", status: 404, code: "NOT_FOUND"},
+		{name: "get working hours without user", method: "GET", target: "/users/getWorkingHours", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set notification preferences", method: "POST", target: "/users/setNotificationPreferences", body: `{"user_id":"u2","email":"bob@example.com","daily_digest":false}`, status: 200},
+		{name: "set notification preferences with named address", method: "POST", target: "/users/setNotificationPreferences", body: `{"user_id":"u2","email":"Bob <bob@example.com>"}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "set notification preferences without email", method: "POST", target: "/users/setNotificationPreferences", body: `{"user_id":"u2"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set notification preferences of missing user", method: "POST", target: "/users/setNotificationPreferences", body: `{"user_id":"missing","email":"x@example.com"}`, status: 404, code: "NOT_FOUND"},
+		{name: "get notification preferences", method: "GET", target: "/users/getNotificationPreferences?user_id=u2", status: 200},
+		{name: "get unset notification preferences", method: "GET", target: "/users/getNotificationPreferences?user_id=u3", status: 404, code: "NOT_FOUND"},
+		{name: "get notification preferences without user", method: "GET", target: "/users/getNotificationPreferences", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "link chat user", method: "POST", target: "/users/setChatUser", body: `{"user_id":"u2","chat_user_id":"U2"}`, status: 200},
+		{name: "link chat user of missing user", method: "POST", target: "/users/setChatUser", body: `{"user_id":"missing","chat_user_id":"U9"}`, status: 404, code: "NOT_FOUND"},
+		{name: "link chat user without chat id", method: "POST", target: "/users/setChatUser", body: `{"user_id":"u2"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "slack command mine", method: "POST", target: "/slack/commands", body: "command=%2Freview&text=mine&user_id=U2",
+			headers: slackHeaders("command=%2Freview&text=mine&user_id=U2"), status: 200},
+		{name: "slack command from unlinked user", method: "POST", target: "/slack/commands", body: "command=%2Freview&text=mine&user_id=U9",
+			headers: slackHeaders("command=%2Freview&text=mine&user_id=U9"), status: 200},
+		{name: "slack command with bad signature", method: "POST", target: "/slack/commands", body: "command=%2Freview&text=mine&user_id=U2",
+			headers: slackHeaders("command=%2Freview&text=ooo+90d&user_id=U2"), status: 401, code: "UNAUTHORIZED"},
+		{name: "slack command without user", method: "POST", target: "/slack/commands", body: "command=%2Freview&text=mine",
+			headers: slackHeaders("command=%2Freview&text=mine"), status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "slack interaction", method: "POST", target: "/slack/interactions", body: "payload=%7B%22type%22%3A%22block_actions%22%2C%22actions%22%3A%5B%5D%7D",
+			headers: slackHeaders("payload=%7B%22type%22%3A%22block_actions%22%2C%22actions%22%3A%5B%5D%7D"), status: 200},
+		{name: "slack interaction with malformed payload", method: "POST", target: "/slack/interactions", body: "payload=nope",
+			headers: slackHeaders("payload=nope"), status: 400, code: "INVALID_REQUEST"},
+		{name: "slack interaction without payload", method: "POST", target: "/slack/interactions", body: "type=block_actions",
+			headers: slackHeaders("type=block_actions"), status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set holidays", method: "POST", target: "/team/setHolidays", body: `{"team_name":"backend","holidays":[{"date":"2030-01-01","name":"New Year"}]}`, status: 200},
+		{name: "set duplicate holidays", method: "POST", target: "/team/setHolidays", body: `{"team_name":"backend","holidays":[{"date":"2030-01-01"},{"date":"2030-01-01"}]}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "set holidays without list", method: "POST", target: "/team/setHolidays", body: `{"team_name":"backend"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "set holidays of missing team", method: "POST", target: "/team/setHolidays", body: `{"team_name":"missing","holidays":[]}`, status: 404, code: "NOT_FOUND"},
+		{name: "get holidays", method: "GET", target: "/team/getHolidays?team_name=backend", status: 200},
+		{name: "get holidays of missing team", method: "GET", target: "/team/getHolidays?team_name=missing", status: 404, code: "NOT_FOUND"},
+		{name: "get holidays without team", method: "GET", target: "/team/getHolidays", status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "set user inactive", method: "POST", target: "/users/setIsActive", body: `{"user_id":"u4","is_active":false}`, status: 200},
+		{name: "set missing user", method: "POST", target: "/users/setIsActive", body: `{"user_id":"missing","is_active":true}`, status: 404, code: "NOT_FOUND"},
+		{name: "set user malformed body", method: "POST", target: "/users/setIsActive", body: `[]`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "create PR", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`, status: 201},
+		{name: "create duo PR", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-duo","pull_request_name":"Small fix","author_id":"d1"}`, status: 201},
+		{name: "create PR to merge", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-old","pull_request_name":"Old","author_id":"u1"}`, status: 201},
+		{name: "PR exists", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-1","pull_request_name":"Again","author_id":"u1"}`, status: 409, code: "PR_EXISTS"},
+		{name: "PR author missing", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-x","pull_request_name":"X","author_id":"ghost"}`, status: 404, code: "NOT_FOUND"},
+		{name: "create PR with empty id", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"","pull_request_name":"X","author_id":"u1"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "create PR with unknown field", method: "POST", target: "/pullRequest/create", body: `{"pull_request_id":"pr-y","pull_request_name":"Y","author_id":"u1","reviewers":["u2"]}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "create PR malformed body", method: "POST", target: "/pullRequest/create", body: `not json`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "user reviews", method: "GET", target: "/users/getReview?user_id=u2", status: 200},
+		{name: "reviews of missing user", method: "GET", target: "/users/getReview?user_id=missing", status: 404, code: "NOT_FOUND"},
+		{name: "reviews without user", method: "GET", target: "/users/getReview", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "user inbox", method: "GET", target: "/users/getReview?user_id=u1&status=ALL&include_authored=true&sort=assigned_at&limit=1", status: 200},
+		{name: "reviews unknown status", method: "GET", target: "/users/getReview?user_id=u2&status=CLOSED", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "reviews foreign cursor", method: "GET", target: "/users/getReview?user_id=u2&sort=assigned_at&cursor=eyJzIjoicHVsbF9yZXF1ZXN0X2lkIiwiaWQiOiJwci0xIn0", status: 400, code: "INVALID_REQUEST"},
+
+		{name: "submit review", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"pr-1","user_id":"u3"}`, status: 200},
+		{name: "submit review again", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"pr-1","user_id":"u3"}`, status: 200},
+		{name: "submit review not assigned", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"pr-1","user_id":"d2"}`, status: 409, code: "NOT_ASSIGNED"},
+		{name: "submit review missing PR", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"missing","user_id":"u3"}`, status: 404, code: "NOT_FOUND"},
+		{name: "submit review malformed body", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"pr-1"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "reassign reviewer", method: "POST", target: "/pullRequest/reassign", body: `{"pull_request_id":"pr-1","old_user_id":"u2"}`, status: 200},
+		{name: "reassign not assigned", method: "POST", target: "/pullRequest/reassign", body: `{"pull_request_id":"pr-1","old_user_id":"d2"}`, status: 409, code: "NOT_ASSIGNED"},
+		{name: "reassign missing PR", method: "POST", target: "/pullRequest/reassign", body: `{"pull_request_id":"missing","old_user_id":"u2"}`, status: 404, code: "NOT_FOUND"},
+		{name: "deactivate duo author", method: "POST", target: "/users/setIsActive", body: `{"user_id":"d1","is_active":false}`, status: 200},
+		{name: "reassign without candidates", method: "POST", target: "/pullRequest/reassign", body: `{"pull_request_id":"pr-duo","old_user_id":"d2"}`, status: 409, code: "NO_CANDIDATE"},
+		{name: "reassign malformed body", method: "POST", target: "/pullRequest/reassign", body: `{`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "merge PR", method: "POST", target: "/pullRequest/merge", body: `{"pull_request_id":"pr-old"}`, status: 200},
+		{name: "merge PR again", method: "POST", target: "/pullRequest/merge", body: `{"pull_request_id":"pr-old"}`, status: 200},
+		{name: "merge missing PR", method: "POST", target: "/pullRequest/merge", body: `{"pull_request_id":"missing"}`, status: 404, code: "NOT_FOUND"},
+		{name: "merge malformed body", method: "POST", target: "/pullRequest/merge", body: `"pr-old"`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "reassign on merged PR", method: "POST", target: "/pullRequest/reassign", body: `{"pull_request_id":"pr-old","old_user_id":"u2"}`, status: 409, code: "PR_MERGED"},
+		{name: "submit review on merged PR", method: "POST", target: "/pullRequest/submitReview", body: `{"pull_request_id":"pr-old","user_id":"u2"}`, status: 409, code: "PR_MERGED"},
+
+		{name: "PR history", method: "GET", target: "/pullRequest/history?pull_request_id=pr-1", status: 200},
+		{name: "history of missing PR", method: "GET", target: "/pullRequest/history?pull_request_id=missing", status: 404, code: "NOT_FOUND"},
+		{name: "history without PR", method: "GET", target: "/pullRequest/history", status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "get PR", method: "GET", target: "/pullRequest/get?pull_request_id=pr-1", status: 200},
+		{name: "get missing PR", method: "GET", target: "/pullRequest/get?pull_request_id=missing", status: 404, code: "NOT_FOUND"},
+		{name: "get PR without id", method: "GET", target: "/pullRequest/get", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "list PRs", method: "GET", target: "/pullRequest/list?status=OPEN&team_name=backend&sort=pull_request_id&order=asc&limit=1", status: 200},
+		{name: "list PRs by dates", method: "GET", target: "/pullRequest/list?created_from=2020-01-01T00:00:00Z&merged_to=2100-01-01T00:00:00Z&needs_reviewers=false", status: 200},
+		{name: "list PRs with unknown status", method: "GET", target: "/pullRequest/list?status=CLOSED", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "list PRs with foreign cursor", method: "GET", target: "/pullRequest/list?cursor=bm90LWEtY3Vyc29y", status: 400, code: "INVALID_REQUEST"},
+
+		{name: "deactivate members", method: "POST", target: "/team/deactivateMembers", body: `{"team_name":"backend","user_ids":["u3"]}`, status: 200},
+		{name: "deactivate foreign member", method: "POST", target: "/team/deactivateMembers", body: `{"team_name":"backend","user_ids":["d2"]}`, status: 400, code: "INVALID_TEAM_MEMBER"},
+		{name: "deactivate in missing team", method: "POST", target: "/team/deactivateMembers", body: `{"team_name":"missing","user_ids":["u1"]}`, status: 404, code: "NOT_FOUND"},
+		{name: "deactivate malformed body", method: "POST", target: "/team/deactivateMembers", body: `{"user_ids":"u1"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "add member", method: "POST", target: "/team/addMember", body: `{"team_name":"backend","user_id":"u5","username":"Eve"}`, status: 200},
+		{name: "add member to missing team", method: "POST", target: "/team/addMember", body: `{"team_name":"missing","user_id":"u5","username":"Eve"}`, status: 404, code: "NOT_FOUND"},
+		{name: "add member without username", method: "POST", target: "/team/addMember", body: `{"team_name":"backend","user_id":"u5"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "move user", method: "POST", target: "/users/moveTeam", body: `{"user_id":"u5","team_name":"duo"}`, status: 200},
+		{name: "move missing user", method: "POST", target: "/users/moveTeam", body: `{"user_id":"missing","team_name":"duo"}`, status: 404, code: "NOT_FOUND"},
+		{name: "move to missing team", method: "POST", target: "/users/moveTeam", body: `{"user_id":"u5","team_name":"missing"}`, status: 404, code: "NOT_FOUND"},
+		{name: "move malformed body", method: "POST", target: "/users/moveTeam", body: `{"user_id":"u5"}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "remove member", method: "POST", target: "/team/removeMember", body: `{"team_name":"duo","user_id":"u5"}`, status: 200},
+		{name: "remove foreign member", method: "POST", target: "/team/removeMember", body: `{"team_name":"backend","user_id":"d2"}`, status: 400, code: "INVALID_TEAM_MEMBER"},
+		{name: "remove from missing team", method: "POST", target: "/team/removeMember", body: `{"team_name":"missing","user_id":"d2"}`, status: 404, code: "NOT_FOUND"},
+		{name: "remove malformed body", method: "POST", target: "/team/removeMember", body: `{"team_name":"duo","user_id":""}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "archive user", method: "POST", target: "/users/archive", body: `{"user_id":"u2"}`, status: 200},
+		{name: "archive user again", method: "POST", target: "/users/archive", body: `{"user_id":"u2"}`, status: 200},
+		{name: "archive missing user", method: "POST", target: "/users/archive", body: `{"user_id":"missing"}`, status: 404, code: "NOT_FOUND"},
+		{name: "archive user malformed body", method: "POST", target: "/users/archive", body: `{}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "activate archived user", method: "POST", target: "/users/setIsActive", body: `{"user_id":"u2","is_active":true}`, status: 404, code: "NOT_FOUND"},
+		{name: "get team with archived members", method: "GET", target: "/team/get?team_name=backend&include_archived=true", status: 200},
+		{name: "archive team", method: "POST", target: "/team/archive", body: `{"team_name":"duo"}`, status: 200},
+		{name: "get archived team", method: "GET", target: "/team/get?team_name=duo", status: 404, code: "NOT_FOUND"},
+		{name: "archive missing team", method: "POST", target: "/team/archive", body: `{"team_name":"missing"}`, status: 404, code: "NOT_FOUND"},
+		{name: "archive team malformed body", method: "POST", target: "/team/archive", body: `{"team_name":"duo","members":[]}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "sync teams dry run", method: "POST", target: "/team/sync?dry_run=true", body: `{"teams":[{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true}]},{"team_name":"ops","members":[{"user_id":"u9","username":"Ivan","is_active":true}]}]}`, status: 200},
+		{name: "sync archived team", method: "POST", target: "/team/sync", body: `{"teams":[{"team_name":"duo","members":[]}]}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "sync duplicate user", method: "POST", target: "/team/sync", body: `{"teams":[{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true}]},{"team_name":"ops","members":[{"user_id":"u1","username":"Alice","is_active":true}]}]}`, status: 400, code: "INVALID_REQUEST"},
+		{name: "sync malformed dry_run", method: "POST", target: "/team/sync?dry_run=maybe", body: `{"teams":[]}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "sync missing teams", method: "POST", target: "/team/sync", body: `{}`, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "export teams", method: "GET", target: "/team/export", status: 200},
+		{name: "export teams in unknown format", method: "GET", target: "/team/export?format=xml", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "import teams", method: "POST", target: "/team/import", body: "team_name\nimported\n", headers: csvHeaders, status: 200},
+		{name: "import existing team", method: "POST", target: "/team/import", body: "team_name\nbackend\n", headers: csvHeaders, status: 400, code: "INVALID_REQUEST"},
+		{name: "import users", method: "POST", target: "/users/import", body: `{"user_id":"imp1","username":"Imported","team_name":"imported"}` + "\n", headers: ndjsonHeaders, status: 200},
+		{name: "import malformed users", method: "POST", target: "/users/import", body: `{"user_id":"imp2","role":"admin"}`, headers: ndjsonHeaders, status: 400, code: "INVALID_REQUEST"},
+		{name: "export users", method: "GET", target: "/users/export?format=csv", status: 200},
+		{name: "export users in unknown format", method: "GET", target: "/users/export?format=xlsx", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "import PRs", method: "POST", target: "/pullRequest/import", body: "pull_request_id,pull_request_name,author_id,status,assigned_reviewers,createdAt,mergedAt\npr-imp,Imported,imp1,MERGED,u1,2024-03-01T10:00:00Z,2024-03-02T10:00:00Z\n", headers: csvHeaders, status: 200},
+		{name: "import PR with unknown author", method: "POST", target: "/pullRequest/import", body: "pull_request_id,pull_request_name,author_id\npr-ghost,Ghost,ghost\n", headers: csvHeaders, status: 400, code: "INVALID_REQUEST"},
+		{name: "export PRs", method: "GET", target: "/pullRequest/export", status: 200},
+		{name: "export PRs in unknown format", method: "GET", target: "/pullRequest/export?format=json", status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "scim without token", method: "GET", target: "/scim/v2/Users", status: 401},
+		{name: "scim list users", method: "GET", target: `/scim/v2/Users?filter=userName%20eq%20%22u1%22&startIndex=1&count=10`, headers: scimHeaders, status: 200},
+		{name: "scim unsupported user filter", method: "GET", target: `/scim/v2/Users?filter=name.givenName%20co%20%22A%22`, headers: scimHeaders, status: 400},
+		{name: "scim create user", method: "POST", target: "/scim/v2/Users", body: `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"s1","displayName":"Sam","emails":[{"value":"sam@example.com"}]}`, headers: scimHeaders, status: 201},
+		{name: "scim create existing user", method: "POST", target: "/scim/v2/Users", body: `{"userName":"s1"}`, headers: scimHeaders, status: 409},
+		{name: "scim create user without userName", method: "POST", target: "/scim/v2/Users", body: `{"displayName":"Nobody"}`, headers: scimHeaders, status: 400, invalid: true},
+		{name: "scim get user", method: "GET", target: "/scim/v2/Users/s1", headers: scimHeaders, status: 200},
+		{name: "scim get missing user", method: "GET", target: "/scim/v2/Users/missing", headers: scimHeaders, status: 404},
+		{name: "scim patch user", method: "PATCH", target: "/scim/v2/Users/u1", body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","value":{"displayName":"Alice A."}}]}`, headers: scimHeaders, status: 200},
+		{name: "scim patch userName", method: "PATCH", target: "/scim/v2/Users/u1", body: `{"Operations":[{"op":"replace","path":"userName","value":"u100"}]}`, headers: scimHeaders, status: 400},
+		{name: "scim patch missing user", method: "PATCH", target: "/scim/v2/Users/missing", body: `{"Operations":[{"op":"replace","path":"active","value":false}]}`, headers: scimHeaders, status: 404},
+		{name: "scim list groups", method: "GET", target: `/scim/v2/Groups?filter=displayName%20eq%20%22backend%22`, headers: scimHeaders, status: 200},
+		{name: "scim unsupported group filter", method: "GET", target: `/scim/v2/Groups?filter=members.value%20eq%20%22u1%22`, headers: scimHeaders, status: 400},
+		{name: "scim create group", method: "POST", target: "/scim/v2/Groups", body: `{"displayName":"platform","members":[{"value":"s1"}]}`, headers: scimHeaders, status: 201},
+		{name: "scim create existing group", method: "POST", target: "/scim/v2/Groups", body: `{"displayName":"backend"}`, headers: scimHeaders, status: 409},
+		{name: "scim create group with unknown member", method: "POST", target: "/scim/v2/Groups", body: `{"displayName":"ghosts","members":[{"value":"ghost"}]}`, headers: scimHeaders, status: 400},
+		{name: "scim get group", method: "GET", target: "/scim/v2/Groups/platform", headers: scimHeaders, status: 200},
+		{name: "scim get missing group", method: "GET", target: "/scim/v2/Groups/missing", headers: scimHeaders, status: 404},
+		{name: "scim patch group", method: "PATCH", target: "/scim/v2/Groups/platform", body: `{"Operations":[{"op":"add","path":"members","value":[{"value":"u5"}]},{"op":"remove","path":"members[value eq \"s1\"]"}]}`, headers: scimHeaders, status: 200},
+		{name: "scim rename group", method: "PATCH", target: "/scim/v2/Groups/platform", body: `{"Operations":[{"op":"replace","path":"displayName","value":"infra"}]}`, headers: scimHeaders, status: 400},
+		{name: "scim patch missing group", method: "PATCH", target: "/scim/v2/Groups/missing", body: `{"Operations":[{"op":"add","path":"members","value":[]}]}`, headers: scimHeaders, status: 404},
+		{name: "scim delete user", method: "DELETE", target: "/scim/v2/Users/s1", headers: scimHeaders, status: 204},
+		{name: "scim delete user again", method: "DELETE", target: "/scim/v2/Users/s1", headers: scimHeaders, status: 404},
+		{name: "scim delete group", method: "DELETE", target: "/scim/v2/Groups/platform", headers: scimHeaders, status: 204},
+		{name: "scim delete missing group", method: "DELETE", target: "/scim/v2/Groups/platform", headers: scimHeaders, status: 404},
+
+		{name: "overdue reviews", method: "GET", target: "/pullRequest/overdue?team_name=backend", status: 200},
+		{name: "overdue reviews of long team name", method: "GET", target: "/pullRequest/overdue?team_name=" + strings.Repeat("x", 256), status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "event stream", method: "GET", target: "/events/stream?team_name=backend", streamFor: 50 * time.Millisecond, status: 200},
+		{name: "event stream from log", method: "GET", target: "/events/stream?user_id=u2", headers: map[string]string{"Last-Event-ID": "0"}, streamFor: 50 * time.Millisecond, status: 200},
+		{name: "event stream with malformed Last-Event-ID", method: "GET", target: "/events/stream", headers: map[string]string{"Last-Event-ID": "latest"}, status: 400, code: "INVALID_REQUEST", invalid: true},
+
+		{name: "statistics", method: "GET", target: "/statistics", status: 200},
+		{name: "statistics as CSV", method: "GET", target: "/statistics", headers: map[string]string{"Accept": "text/csv"}, status: 200},
+		{name: "statistics as Prometheus", method: "GET", target: "/statistics", headers: map[string]string{"Accept": "text/plain; version=0.0.4"}, status: 200},
+		{name: "statistics for range", method: "GET", target: "/statistics?from=2020-01-01T00:00:00Z&to=2030-01-01T00:00:00Z", status: 200},
+		{name: "statistics with reversed range", method: "GET", target: "/statistics?from=2030-01-01T00:00:00Z&to=2020-01-01T00:00:00Z", status: 400, code: "INVALID_REQUEST"},
+		{name: "statistics with malformed date", method: "GET", target: "/statistics?from=yesterday", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "statistics as XML", method: "GET", target: "/statistics", headers: map[string]string{"Accept": "application/xml"}, status: 406, code: "NOT_ACCEPTABLE"},
+		{name: "team statistics", method: "GET", target: "/statistics/team?team_name=backend", status: 200},
+		{name: "statistics of missing team", method: "GET", target: "/statistics/team?team_name=missing", status: 404, code: "NOT_FOUND"},
+		{name: "team statistics without team", method: "GET", target: "/statistics/team", status: 400, code: "INVALID_REQUEST", invalid: true},
+		{name: "review timings", method: "GET", target: "/statistics/timings", status: 200},
+		{name: "review timings in working hours", method: "GET", target: "/statistics/timings?working_hours=true", status: 200},
+		{name: "weekly review timings", method: "GET", target: "/statistics/timings?from=2020-01-01T00:00:00Z&to=2020-03-01T00:00:00Z&bucket=week", status: 200},
+		{name: "review timings with reversed range", method: "GET", target: "/statistics/timings?from=2020-03-01T00:00:00Z&to=2020-01-01T00:00:00Z", status: 400, code: "INVALID_REQUEST"},
+		{name: "review timings by month", method: "GET", target: "/statistics/timings?bucket=month", status: 400, code: "INVALID_REQUEST", invalid: true},
+	}
+}
+
+// idempotencyCases прогоняет ответы middleware идемпотентности для каждой POST-операции.
+func idempotencyCases(doc *openapi3.T) []contractCase {
+	bodies := map[string]string{
+		"/team/add":                         `{"team_name":"idem","members":[]}`,
+		"/team/deactivateMembers":           `{"team_name":"backend","user_ids":[]}`,
+		"/team/addMember":                   `{"team_name":"backend","user_id":"u6","username":"Idem"}`,
+		"/team/removeMember":                `{"team_name":"backend","user_id":"missing"}`,
+		"/users/moveTeam":                   `{"user_id":"u1","team_name":"backend"}`,
+		"/team/archive":                     `{"team_name":"missing"}`,
+		"/users/archive":                    `{"user_id":"missing"}`,
+		"/team/sync":                        `{"teams":[{"team_name":"x","members":[]},{"team_name":"x","members":[]}]}`,
+		"/scim/v2/Users":                    `{"userName":"idem"}`,
+		"/scim/v2/Groups":                   `{"displayName":"idem"}`,
+		"/team/import":                      `{"team_name":"idem"}`,
+		"/users/import":                     `{"user_id":"idem","username":"Idem"}`,
+		"/pullRequest/import":               `{"pull_request_id":"pr-idem","pull_request_name":"Idem","author_id":"missing"}`,
+		"/users/setIsActive":                `{"user_id":"u1","is_active":true}`,
+		"/pullRequest/create":               `{"pull_request_id":"pr-idem","pull_request_name":"Idem","author_id":"u1"}`,
+		"/pullRequest/merge":                `{"pull_request_id":"pr-1"}`,
+		"/pullRequest/reassign":             `{"pull_request_id":"pr-1","old_user_id":"u1"}`,
+		"/pullRequest/submitReview":         `{"pull_request_id":"pr-1","user_id":"u1"}`,
+		"/team/setReviewSLA":                `{"team_name":"missing","first_review_hours":1}`,
+		"/users/setWorkingHours":            `{"user_id":"missing","time_zone":"UTC","periods":[{"weekday":"monday","start":"09:00","end":"18:00"}]}`,
+		"/team/setHolidays":                 `{"team_name":"missing","holidays":[]}`,
+		"/users/setNotificationPreferences": `{"user_id":"missing","email":"x@example.com"}`,
+		"/users/setChatUser":                `{"user_id":"missing","chat_user_id":"U9"}`,
+		"/slack/commands":                   "command=%2Freview&text=mine&user_id=U9",
+		"/slack/interactions":               "payload=%7B%7D",
+	}
+
+	paths := make([]string, 0)
+	// Валидация подставляет в запрос значения query-параметров по умолчанию,
+	// и middleware хэширует уже их
+	defaultQueries := make(map[string]string)
+	for path, item := range doc.Paths.Map() {
+		if item.Post == nil {
+			continue
+		}
+		paths = append(paths, path)
+		query := url.Values{}
+		for _, param := range item.Post.Parameters {
+			if p := param.Value; p != nil && p.In == "query" && p.Schema != nil && p.Schema.Value != nil && p.Schema.Value.Default != nil {
+				query.Set(p.Name, fmt.Sprint(p.Schema.Value.Default))
+			}
+		}
+		defaultQueries[path] = query.Encode()
+	}
+	sort.Strings(paths)
+
+	// Тела, которые принимаются не в JSON
+	contentTypes := map[string]string{
+		"/team/import":        "application/x-ndjson",
+		"/users/import":       "application/x-ndjson",
+		"/pullRequest/import": "application/x-ndjson",
+		"/slack/commands":     "application/x-www-form-urlencoded",
+		"/slack/interactions": "application/x-www-form-urlencoded",
+	}
+
+	var cases []contractCase
+	for _, path := range paths {
+		body, ok := bodies[path]
+		if !ok {
+			// Новая POST-операция без тела для этих проверок — пусть упадёт покрытие.
+			continue
+		}
+		reused := "reused-" + path
+		inProgress := "in-progress-" + path
+		headers := func(key string) map[string]string {
+			h := map[string]string{"Idempotency-Key": key}
+			if ct, ok := contentTypes[path]; ok {
+				h["Content-Type"] = ct
+			}
+			return h
+		}
+		changed := strings.Replace(body, "{", "{ ", 1)
+		if changed == body {
+			// Тело формы без JSON
+			changed = body + "&"
+		}
+		cases = append(cases,
+			contractCase{name: "first request " + path, method: "POST", target: path, body: body,
+				headers: headers(reused), status: -1},
+			// Ответ 401 проверок SCIM и Slack не сохраняется, поэтому ключ
+			// первого запроса занимается заново, если он был освобождён
+			contractCase{name: "key reused " + path, method: "POST", target: path, body: changed,
+				headers: headers(reused), status: 422, code: "IDEMPOTENCY_KEY_REUSED",
+				setup: reserveKey(reused, "POST", path, defaultQueries[path], body)},
+			contractCase{name: "key in progress " + path, method: "POST", target: path, body: body,
+				headers: headers(inProgress), status: 409, code: "IDEMPOTENCY_IN_PROGRESS",
+				setup: reserveKey(inProgress, "POST", path, defaultQueries[path], body)},
+		)
+	}
+	return cases
+}
+
+// reserveKey занимает ключ так, будто первый запрос с тем же телом ещё выполняется.
+// Хэш повторяет формулу middleware: sha256(method \n path ? query \n body);
+// запросы без Authorization и команды Slack хранятся под ключом клиента.
+func reserveKey(key, method, path, query, body string) func(t *testing.T, st *storage.Storage) {
+	return func(t *testing.T, st *storage.Storage) {
+		sum := sha256.Sum256([]byte(method + "\n" + path + "?" + query + "\n" + body))
+		if _, err := st.Idempotency.Reserve(key, hex.EncodeToString(sum[:]), time.Now().UTC().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to reserve idempotency key: %v", err)
+		}
+	}
+}
+
+func TestOpenAPI_HandlersConformToSpec(t *testing.T) {
+	doc := loadSpec(t)
+	specRouter, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("failed to build spec router: %v", err)
+	}
+
+	st := storage.NewMemory()
+	router := newTestRouter(t, st)
+
+	covered := make(map[string]map[string]bool)
+	cases := append(contractScenario(), idempotencyCases(doc)...)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.setup != nil {
+				tc.setup(t, st)
+			}
+
+			req := newRequest(tc)
+			route, pathParams, err := specRouter.FindRoute(req)
+			if err != nil {
+				t.Fatalf("%s %s is not documented: %v", tc.method, tc.target, err)
+			}
+
+			reqInput := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					MultiError:         true,
+				},
+			}
+			reqErr := openapi3filter.ValidateRequest(context.Background(), reqInput)
+			switch {
+			case tc.invalid && reqErr == nil:
+				t.Errorf("spec accepts request that the handler rejects as invalid")
+			case !tc.invalid && reqErr != nil:
+				t.Errorf("request does not match spec: %v", reqErr)
+			}
+
+			req = newRequest(tc)
+			if tc.streamFor > 0 {
+				ctx, cancel := context.WithTimeout(req.Context(), tc.streamFor)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if tc.status > 0 && rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+			validateResponse(t, reqInput, route, rec)
+
+			if tc.code != "" {
+				var resp struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if resp.Error.Code != tc.code {
+					t.Errorf("expected error code %s, got %s", tc.code, resp.Error.Code)
+				}
+			}
+
+			op := operationKey(route.Method, route.Path)
+			if covered[op] == nil {
+				covered[op] = make(map[string]bool)
+			}
+			covered[op][strconv.Itoa(rec.Code)] = true
+		})
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			key := operationKey(method, path)
+			for status := range op.Responses.Map() {
+				if !covered[key][status] && !unreachableStatuses[status] {
+					t.Errorf("%s: documented response %s is not exercised by the contract test", key, status)
+				}
+			}
+		}
+	}
+}
+
+func newRequest(tc contractCase) *http.Request {
+	var body io.Reader
+	if tc.body != "" {
+		body = strings.NewReader(tc.body)
+	}
+	req := httptest.NewRequest(tc.method, tc.target, body)
+	if tc.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range tc.headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func validateResponse(t *testing.T, reqInput *openapi3filter.RequestValidationInput, route *routers.Route, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	respInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: reqInput,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	respInput.SetBodyBytes(rec.Body.Bytes())
+	if err := openapi3filter.ValidateResponse(context.Background(), respInput); err != nil {
+		t.Errorf("%s %s: response %d does not match spec: %v\nbody: %s", route.Method, route.Path, rec.Code, err, rec.Body.String())
+	}
+}
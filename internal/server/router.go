@@ -0,0 +1,223 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/gorilla/mux"
+	reviewerservice "github.com/reviewer-service"
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/handlers"
+	"github.com/reviewer-service/internal/middleware"
+	"github.com/reviewer-service/internal/notify"
+	"github.com/reviewer-service/internal/service"
+	"github.com/reviewer-service/internal/static"
+	"github.com/reviewer-service/internal/storage"
+)
+
+// Deps — общие с фоновыми задачами зависимости роутера. Нулевое значение
+// допустимо: недостающие сервисы роутер создаёт сам.
+type Deps struct {
+	// Notifications — сервис писем; nil — письма не отправляются,
+	// но подписки можно настраивать.
+	Notifications *service.NotificationService
+	// Events — поток /events/stream, который закрывают при остановке
+	// сервера; nil — роутер создаёт свой.
+	Events *service.EventStream
+	// StatsCache — кэш /statistics; фоновые задачи сбрасывают его после
+	// своих изменений. nil — роутер создаёт кэш по STATS_CACHE_TTL.
+	StatsCache *service.StatisticsCache
+}
+
+// NewRouter собирает API с зависимостями по умолчанию.
+func NewRouter(st *storage.Storage, cfg *config.Config, logger *slog.Logger) (*mux.Router, error) {
+	return NewRouterWithDeps(st, cfg, Deps{}, logger)
+}
+
+// NewRouterWithDeps собирает API поверх переданных зависимостей.
+func NewRouterWithDeps(st *storage.Storage, cfg *config.Config, deps Deps, logger *slog.Logger) (*mux.Router, error) {
+	calendars := service.NewWorkCalendars(st.Schedules)
+	notifications := deps.Notifications
+	if notifications == nil {
+		notifications = service.NewNotificationService(st.Notifications, st.PullRequests, st.Users, calendars, nil, nil, 0, logger)
+	}
+	teamService := service.NewTeamService(st.Teams, st.Users, st.PullRequests, st.Transactor, notifications, logger)
+	userService := service.NewUserService(st.Users, st.PullRequests, calendars, logger)
+	prService := service.NewPullRequestService(st.PullRequests, st.Users, availability(cfg, calendars), notifications, logger)
+	statsCache := deps.StatsCache
+	if statsCache == nil {
+		statsCache = service.NewStatisticsCache(cfg.Statistics.CacheTTL)
+	}
+	statsService := service.NewStatisticsService(st.Statistics, statsCache, calendars, logger)
+	slaService := NewSLAService(st, cfg, notifications, logger)
+	scheduleService := service.NewScheduleService(st.Schedules, logger)
+	bulkService := service.NewBulkService(st.Teams, st.Users, st.PullRequests, st.Transactor, logger)
+	chatService := service.NewChatService(st.Chat, logger)
+	events := deps.Events
+	if events == nil {
+		events = service.NewEventStream(st.Events, cfg.Events.PollInterval, logger)
+	}
+
+	teamHandler := handlers.NewTeamHandler(teamService, logger)
+	userHandler := handlers.NewUserHandler(userService, logger)
+	prHandler := handlers.NewPullRequestHandler(prService, logger)
+	statsHandler := handlers.NewStatisticsHandler(statsService, logger)
+	bulkHandler := handlers.NewBulkHandler(bulkService, logger)
+	slaHandler := handlers.NewSLAHandler(slaService, logger)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService, logger)
+	notificationHandler := handlers.NewNotificationHandler(notifications, logger)
+	chatHandler := handlers.NewChatHandler(chatService, userService, prService, cfg.Slack.SigningSecret, cfg.Slack.ResponseURLPrefix, logger)
+	eventHandler := handlers.NewEventHandler(events, logger)
+	scimHandler := handlers.NewSCIMHandler(teamService, userService, cfg.SCIM.Token, logger)
+
+	docsAssets, err := fs.Sub(static.Docs, "docs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docs assets: %w", err)
+	}
+	docsHandler, err := handlers.NewDocsHandler(reviewerservice.OpenAPISpec, docsAssets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docs handler: %w", err)
+	}
+
+	validation, err := middleware.RequestValidationMiddleware(reviewerservice.OpenAPISpec, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request validation middleware: %w", err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(middleware.LoggingMiddleware(logger))
+	r.Use(validation)
+	r.Use(middleware.IdempotencyMiddleware(st.Idempotency, cfg.Idempotency.TTL, logger))
+	if statsCache != nil {
+		r.Use(middleware.InvalidateOnWrite(statsCache))
+	}
+
+	// API endpoints
+	r.HandleFunc("/team/add", teamHandler.AddTeam).Methods("POST")
+	r.HandleFunc("/team/get", teamHandler.GetTeam).Methods("GET")
+	r.HandleFunc("/team/deactivateMembers", teamHandler.DeactivateTeamMembers).Methods("POST")
+	r.HandleFunc("/team/addMember", teamHandler.AddMember).Methods("POST")
+	r.HandleFunc("/team/removeMember", teamHandler.RemoveMember).Methods("POST")
+	r.HandleFunc("/team/archive", teamHandler.ArchiveTeam).Methods("POST")
+	r.HandleFunc("/team/sync", teamHandler.SyncTeams).Methods("POST")
+	r.HandleFunc("/team/setReviewSLA", slaHandler.SetTeamSLA).Methods("POST")
+	r.HandleFunc("/team/getReviewSLA", slaHandler.GetTeamSLA).Methods("GET")
+	r.HandleFunc("/team/setHolidays", scheduleHandler.SetHolidays).Methods("POST")
+	r.HandleFunc("/team/getHolidays", scheduleHandler.GetHolidays).Methods("GET")
+	r.HandleFunc("/users/setIsActive", userHandler.SetUserActive).Methods("POST")
+	r.HandleFunc("/users/moveTeam", teamHandler.MoveUser).Methods("POST")
+	r.HandleFunc("/users/archive", teamHandler.ArchiveUser).Methods("POST")
+	r.HandleFunc("/users/getReview", userHandler.GetUserReviews).Methods("GET")
+	r.HandleFunc("/users/setWorkingHours", scheduleHandler.SetWorkingHours).Methods("POST")
+	r.HandleFunc("/users/getWorkingHours", scheduleHandler.GetWorkingHours).Methods("GET")
+	r.HandleFunc("/users/setNotificationPreferences", notificationHandler.SetPreferences).Methods("POST")
+	r.HandleFunc("/users/getNotificationPreferences", notificationHandler.GetPreferences).Methods("GET")
+	r.HandleFunc("/users/setChatUser", chatHandler.SetChatUser).Methods("POST")
+	r.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods("POST")
+	r.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods("POST")
+	r.HandleFunc("/pullRequest/reassign", prHandler.ReassignReviewer).Methods("POST")
+	r.HandleFunc("/pullRequest/submitReview", prHandler.SubmitReview).Methods("POST")
+	r.HandleFunc("/pullRequest/history", prHandler.GetHistory).Methods("GET")
+	r.HandleFunc("/pullRequest/get", prHandler.GetPR).Methods("GET")
+	r.HandleFunc("/pullRequest/list", prHandler.ListPRs).Methods("GET")
+	r.HandleFunc("/pullRequest/overdue", slaHandler.ListOverdue).Methods("GET")
+
+	// Массовые выгрузка и загрузка (CSV / NDJSON)
+	r.HandleFunc("/team/export", bulkHandler.ExportTeams).Methods("GET")
+	r.HandleFunc("/team/import", bulkHandler.ImportTeams).Methods("POST")
+	r.HandleFunc("/users/export", bulkHandler.ExportUsers).Methods("GET")
+	r.HandleFunc("/users/import", bulkHandler.ImportUsers).Methods("POST")
+	r.HandleFunc("/pullRequest/export", bulkHandler.ExportPullRequests).Methods("GET")
+	r.HandleFunc("/pullRequest/import", bulkHandler.ImportPullRequests).Methods("POST")
+
+	// SCIM 2.0 провижининг с отдельным токеном
+	scim := r.PathPrefix(handlers.SCIMBasePath).Subrouter()
+	scim.Use(scimHandler.RequireToken)
+	scim.HandleFunc("/Users", scimHandler.ListUsers).Methods("GET")
+	scim.HandleFunc("/Users", scimHandler.CreateUser).Methods("POST")
+	scim.HandleFunc("/Users/{id}", scimHandler.GetUser).Methods("GET")
+	scim.HandleFunc("/Users/{id}", scimHandler.PatchUser).Methods("PATCH")
+	scim.HandleFunc("/Users/{id}", scimHandler.DeleteUser).Methods("DELETE")
+	scim.HandleFunc("/Groups", scimHandler.ListGroups).Methods("GET")
+	scim.HandleFunc("/Groups", scimHandler.CreateGroup).Methods("POST")
+	scim.HandleFunc("/Groups/{id}", scimHandler.GetGroup).Methods("GET")
+	scim.HandleFunc("/Groups/{id}", scimHandler.PatchGroup).Methods("PATCH")
+	scim.HandleFunc("/Groups/{id}", scimHandler.DeleteGroup).Methods("DELETE")
+
+	// Slash-команды и кнопки чата в формате Slack с проверкой подписи
+	r.HandleFunc("/slack/commands", chatHandler.SlackCommand).Methods("POST")
+	r.HandleFunc("/slack/interactions", chatHandler.SlackInteraction).Methods("POST")
+
+	// Поток событий назначений (Server-Sent Events)
+	r.HandleFunc("/events/stream", eventHandler.Stream).Methods("GET")
+
+	// Statistics endpoint
+	r.HandleFunc("/statistics", statsHandler.GetStatistics).Methods("GET")
+	r.HandleFunc("/statistics/team", statsHandler.GetTeamStatistics).Methods("GET")
+	r.HandleFunc("/statistics/timings", statsHandler.GetReviewTimings).Methods("GET")
+
+	// Documentation endpoints
+	r.HandleFunc("/docs", docsHandler.ServeDocs).Methods("GET")
+	r.PathPrefix("/docs/").HandlerFunc(docsHandler.ServeAsset).Methods("GET")
+	r.HandleFunc("/api/openapi.yaml", docsHandler.ServeSpecYAML).Methods("GET")
+	r.HandleFunc("/api/openapi.json", docsHandler.ServeSpecJSON).Methods("GET")
+
+	return r, nil
+}
+
+// NewSLAService собирает сервис сроков ревью с теми же зависимостями, что
+// и в API; его же использует фоновая проверка сроков в cmd/server.
+// Напоминания уходят письмами, если у notifications настроен SMTP, иначе в журнал.
+func NewSLAService(st *storage.Storage, cfg *config.Config, notifications *service.NotificationService, logger *slog.Logger) *service.SLAService {
+	calendars := service.NewWorkCalendars(st.Schedules)
+	var notifier service.ReviewNotifier = service.NewLogNotifier(logger)
+	var assignments service.AssignmentNotifier
+	if notifications != nil {
+		assignments = notifications
+		if notifications.Enabled() {
+			notifier = notifications
+		}
+	}
+	prService := service.NewPullRequestService(st.PullRequests, st.Users, availability(cfg, calendars), assignments, logger)
+	return service.NewSLAService(st.SLAs, calendars, prService, notifier, logger)
+}
+
+// NewNotificationService собирает сервис писем по cfg.Notifications;
+// без SMTP_HOST письма не отправляются.
+func NewNotificationService(st *storage.Storage, cfg *config.Config, logger *slog.Logger) (*service.NotificationService, error) {
+	nc := cfg.Notifications
+	if nc.DigestHour < 0 || nc.DigestHour > 23 {
+		return nil, fmt.Errorf("DIGEST_HOUR must be within 0..23, got %d", nc.DigestHour)
+	}
+	calendars := service.NewWorkCalendars(st.Schedules)
+	if nc.SMTPHost == "" {
+		return service.NewNotificationService(st.Notifications, st.PullRequests, st.Users, calendars, nil, nil, nc.DigestHour, logger), nil
+	}
+
+	sender, err := notify.NewSMTPSender(notify.SMTPConfig{
+		Host:     nc.SMTPHost,
+		Port:     nc.SMTPPort,
+		Username: nc.SMTPUsername,
+		Password: nc.SMTPPassword,
+		From:     nc.SMTPFrom,
+		TLS:      nc.SMTPTLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP configuration: %w", err)
+	}
+	templates, err := notify.LoadTemplates(nc.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	return service.NewNotificationService(st.Notifications, st.PullRequests, st.Users, calendars, sender, templates, nc.DigestHour, logger), nil
+}
+
+// availability возвращает календари для выбора ревьюверов, только если
+// включено предпочтение работающих (PREFER_WORKING_REVIEWERS)
+func availability(cfg *config.Config, calendars *service.WorkCalendars) *service.WorkCalendars {
+	if !cfg.Assignment.PreferWorking {
+		return nil
+	}
+	return calendars
+}
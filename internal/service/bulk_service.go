@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+// maxImportViolations — сколько ошибок строк возвращается клиенту
+const maxImportViolations = 100
+
+// exportPageSize — размер страницы, которой выгружаются PR
+const exportPageSize = 500
+
+// ImportError — импорт отклонён целиком. Violations содержит первые ошибки
+// строк, Total — их общее число.
+type ImportError struct {
+	Violations []models.FieldViolation
+	Total      int
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("import rejected: %d errors", e.Total)
+}
+
+// Add добавляет ошибку строки line (нумерация строк файла с 1).
+func (e *ImportError) Add(line int, field, message string) {
+	e.Total++
+	if len(e.Violations) < maxImportViolations {
+		e.Violations = append(e.Violations, models.FieldViolation{Field: RowField(line, field), Message: message})
+	}
+}
+
+func (e *ImportError) empty() bool {
+	return e.Total == 0
+}
+
+// RowField — путь к полю строки импорта в error.details: rows.<строка>.<поле>
+func RowField(line int, field string) string {
+	if field == "" {
+		return "rows." + strconv.Itoa(line)
+	}
+	return "rows." + strconv.Itoa(line) + "." + field
+}
+
+// TeamRow, UserRow и PullRequestRow — записи импорта вместе с номером
+// строки файла, на которую ссылаются ошибки.
+type TeamRow struct {
+	Line     int
+	TeamName string
+}
+
+type UserRow struct {
+	Line int
+	User models.User
+}
+
+type PullRequestRow struct {
+	Line int
+	PR   models.PullRequest
+}
+
+// BulkService выгружает и загружает команды, пользователей и PR целиком.
+// Импорт сначала проверяет все строки и только потом пишет их в одной
+// транзакции: либо загружается весь файл, либо ничего.
+type BulkService struct {
+	teamRepo repository.TeamRepository
+	userRepo repository.UserRepository
+	prRepo   repository.PullRequestRepository
+	db       repository.Transactor
+	logger   *slog.Logger
+}
+
+func NewBulkService(teamRepo repository.TeamRepository, userRepo repository.UserRepository, prRepo repository.PullRequestRepository, db repository.Transactor, logger *slog.Logger) *BulkService {
+	return &BulkService{
+		teamRepo: teamRepo,
+		userRepo: userRepo,
+		prRepo:   prRepo,
+		db:       db,
+		logger:   logger,
+	}
+}
+
+// ExportTeams передаёт в fn неархивные команды по имени.
+func (s *BulkService) ExportTeams(ctx context.Context, fn func(*models.Team) error) error {
+	teams, err := s.teamRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list teams for export", "error", err)
+		return err
+	}
+	for _, team := range teams {
+		if team.ArchivedAt != nil {
+			continue
+		}
+		if err := fn(team); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportUsers передаёт в fn неархивных пользователей по user_id.
+func (s *BulkService) ExportUsers(ctx context.Context, fn func(*models.User) error) error {
+	users, err := s.userRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list users for export", "error", err)
+		return err
+	}
+	for _, user := range users {
+		if user.ArchivedAt != nil {
+			continue
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportPullRequests передаёт в fn все PR по pull_request_id, читая их
+// страницами, чтобы не держать всю выгрузку в памяти.
+func (s *BulkService) ExportPullRequests(ctx context.Context, fn func(*models.PullRequest) error) error {
+	page := repository.PullRequestPage{SortBy: repository.PRSortID, Limit: exportPageSize}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		prs, err := s.prRepo.List(repository.PullRequestFilter{}, page)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to list PRs for export", "error", err)
+			return err
+		}
+		for _, pr := range prs {
+			if err := fn(pr); err != nil {
+				return err
+			}
+		}
+		if len(prs) < page.Limit {
+			return nil
+		}
+		page.After = &repository.PullRequestCursor{PullRequestID: prs[len(prs)-1].PullRequestID}
+	}
+}
+
+// ImportTeams создаёт пустые команды. Команда, которая уже есть (в том числе
+// в архиве), — ошибка строки.
+func (s *BulkService) ImportTeams(ctx context.Context, rows []TeamRow) (int, error) {
+	s.logger.InfoContext(ctx, "importing teams", "rows", len(rows))
+
+	teams, err := s.teamRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list teams", "error", err)
+		return 0, err
+	}
+	existing := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		existing[team.TeamName] = true
+	}
+
+	rejected := &ImportError{}
+	seen := make(map[string]int, len(rows))
+	for _, row := range rows {
+		switch {
+		case row.TeamName == "":
+			rejected.Add(row.Line, "team_name", "is required")
+		case seen[row.TeamName] != 0:
+			rejected.Add(row.Line, "team_name", fmt.Sprintf("duplicates row %d", seen[row.TeamName]))
+		case existing[row.TeamName]:
+			rejected.Add(row.Line, "team_name", ErrTeamExists.Error())
+		default:
+			seen[row.TeamName] = row.Line
+		}
+	}
+	if !rejected.empty() {
+		s.logger.WarnContext(ctx, "team import rejected", "errors", rejected.Total)
+		return 0, rejected
+	}
+
+	return s.importRows(ctx, "teams", len(rows), func(tx repository.Tx) error {
+		for _, row := range rows {
+			if err := s.teamRepo.Add(tx, row.TeamName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportUsers создаёт новых пользователей. Недостающие команды создаются,
+// архивная команда или уже существующий пользователь — ошибка строки.
+func (s *BulkService) ImportUsers(ctx context.Context, rows []UserRow) (int, error) {
+	s.logger.InfoContext(ctx, "importing users", "rows", len(rows))
+
+	teams, err := s.teamRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list teams", "error", err)
+		return 0, err
+	}
+	archivedTeams := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		archivedTeams[team.TeamName] = team.ArchivedAt != nil
+	}
+
+	users, err := s.userRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list users", "error", err)
+		return 0, err
+	}
+	existing := make(map[string]bool, len(users))
+	for _, user := range users {
+		existing[user.UserID] = true
+	}
+
+	rejected := &ImportError{}
+	seen := make(map[string]int, len(rows))
+	var newTeams []string
+	for _, row := range rows {
+		user := row.User
+		switch {
+		case user.UserID == "":
+			rejected.Add(row.Line, "user_id", "is required")
+			continue
+		case seen[user.UserID] != 0:
+			rejected.Add(row.Line, "user_id", fmt.Sprintf("duplicates row %d", seen[user.UserID]))
+			continue
+		case existing[user.UserID]:
+			rejected.Add(row.Line, "user_id", ErrUserExists.Error())
+			continue
+		}
+		seen[user.UserID] = row.Line
+
+		if user.Username == "" {
+			rejected.Add(row.Line, "username", "is required")
+		}
+		if user.TeamName == "" {
+			continue
+		}
+		archived, known := archivedTeams[user.TeamName]
+		switch {
+		case archived:
+			rejected.Add(row.Line, "team_name", "team is archived")
+		case !known:
+			archivedTeams[user.TeamName] = false
+			newTeams = append(newTeams, user.TeamName)
+		}
+	}
+	if !rejected.empty() {
+		s.logger.WarnContext(ctx, "user import rejected", "errors", rejected.Total)
+		return 0, rejected
+	}
+
+	return s.importRows(ctx, "users", len(rows), func(tx repository.Tx) error {
+		for _, teamName := range newTeams {
+			if err := s.teamRepo.Add(tx, teamName); err != nil {
+				return err
+			}
+		}
+		for i := range rows {
+			user := rows[i].User
+			user.ArchivedAt = nil
+			if err := s.userRepo.Upsert(tx, &user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportPullRequests загружает PR с исходными статусом, датами и ревьюверами,
+// без автоназначения. Автор и ревьюверы должны уже существовать.
+// Уже существующие PR ищутся в транзакции импорта пачками, а PR, созданный
+// параллельно после проверки, тоже становится ошибкой строки.
+func (s *BulkService) ImportPullRequests(ctx context.Context, rows []PullRequestRow) (int, error) {
+	s.logger.InfoContext(ctx, "importing PRs", "rows", len(rows))
+
+	users, err := s.userRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list users", "error", err)
+		return 0, err
+	}
+	known := make(map[string]bool, len(users))
+	for _, user := range users {
+		known[user.UserID] = true
+	}
+
+	prIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.PR.PullRequestID != "" {
+			prIDs = append(prIDs, row.PR.PullRequestID)
+		}
+	}
+
+	return s.importRows(ctx, "PRs", len(rows), func(tx repository.Tx) error {
+		existing, err := s.prRepo.ExistingIDs(tx, prIDs)
+		if err != nil {
+			return err
+		}
+		prs, rejected := s.checkImportedPullRequests(rows, known, existing)
+		if !rejected.empty() {
+			return rejected
+		}
+		for i := range prs {
+			err := s.prRepo.Import(tx, &prs[i].PR)
+			switch {
+			case errors.Is(err, repository.ErrPRExists):
+				rejected.Add(prs[i].Line, "pull_request_id", ErrPRExists.Error())
+			case err != nil:
+				return err
+			}
+		}
+		if !rejected.empty() {
+			return rejected
+		}
+		return nil
+	})
+}
+
+// checkImportedPullRequests проверяет строки импорта PR и дополняет их
+// значениями по умолчанию; existing — уже сохранённые pull_request_id.
+func (s *BulkService) checkImportedPullRequests(rows []PullRequestRow, known, existing map[string]bool) ([]PullRequestRow, *ImportError) {
+	now := time.Now().UTC()
+	rejected := &ImportError{}
+	seen := make(map[string]int, len(rows))
+	prs := make([]PullRequestRow, 0, len(rows))
+	for _, row := range rows {
+		pr := row.PR
+		switch {
+		case pr.PullRequestID == "":
+			rejected.Add(row.Line, "pull_request_id", "is required")
+			continue
+		case seen[pr.PullRequestID] != 0:
+			rejected.Add(row.Line, "pull_request_id", fmt.Sprintf("duplicates row %d", seen[pr.PullRequestID]))
+			continue
+		case existing[pr.PullRequestID]:
+			rejected.Add(row.Line, "pull_request_id", ErrPRExists.Error())
+			continue
+		}
+		seen[pr.PullRequestID] = row.Line
+
+		if pr.PullRequestName == "" {
+			rejected.Add(row.Line, "pull_request_name", "is required")
+		}
+		switch {
+		case pr.AuthorID == "":
+			rejected.Add(row.Line, "author_id", "is required")
+		case !known[pr.AuthorID]:
+			rejected.Add(row.Line, "author_id", ErrAuthorNotFound.Error())
+		}
+		s.checkImportedReviewers(row.Line, &pr, known, rejected)
+
+		if pr.CreatedAt == nil {
+			pr.CreatedAt = &now
+		}
+		switch pr.Status {
+		case "", "OPEN":
+			pr.Status = "OPEN"
+			if pr.MergedAt != nil {
+				rejected.Add(row.Line, "mergedAt", "is allowed only for MERGED PRs")
+			}
+		case "MERGED":
+			if pr.MergedAt == nil {
+				pr.MergedAt = &now
+			}
+			if pr.MergedAt.Before(*pr.CreatedAt) {
+				rejected.Add(row.Line, "mergedAt", "is before createdAt")
+			}
+		default:
+			rejected.Add(row.Line, "status", "must be OPEN or MERGED")
+		}
+		prs = append(prs, PullRequestRow{Line: row.Line, PR: pr})
+	}
+	return prs, rejected
+}
+
+func (s *BulkService) checkImportedReviewers(line int, pr *models.PullRequest, known map[string]bool, rejected *ImportError) {
+	if len(pr.AssignedReviewers) > maxReviewers {
+		rejected.Add(line, "assigned_reviewers", fmt.Sprintf("at most %d reviewers are allowed", maxReviewers))
+		return
+	}
+	seen := make(map[string]bool, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		switch {
+		case reviewerID == pr.AuthorID:
+			rejected.Add(line, "assigned_reviewers", "author cannot review own PR")
+		case seen[reviewerID]:
+			rejected.Add(line, "assigned_reviewers", fmt.Sprintf("reviewer %s is listed twice", reviewerID))
+		case !known[reviewerID]:
+			rejected.Add(line, "assigned_reviewers", fmt.Sprintf("reviewer %s not found", reviewerID))
+		}
+		seen[reviewerID] = true
+	}
+}
+
+func (s *BulkService) importRows(ctx context.Context, entity string, n int, fn func(tx repository.Tx) error) (int, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to begin import", "error", err, "entity", entity)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		var rejected *ImportError
+		if errors.As(err, &rejected) {
+			s.logger.WarnContext(ctx, "import rejected", "entity", entity, "errors", rejected.Total)
+			return 0, err
+		}
+		s.logger.ErrorContext(ctx, "failed to import", "error", err, "entity", entity)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.ErrorContext(ctx, "failed to commit import", "error", err, "entity", entity)
+		return 0, err
+	}
+
+	s.logger.InfoContext(ctx, "import completed", "entity", entity, "imported", n)
+	return n, nil
+}
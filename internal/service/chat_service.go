@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/reviewer-service/internal/repository"
+)
+
+// ChatService хранит привязку пользователей чата к пользователям сервиса,
+// чтобы команды из чата выполнялись от имени того, кто их вызвал.
+type ChatService struct {
+	chatRepo repository.ChatRepository
+	logger   *slog.Logger
+}
+
+func NewChatService(chatRepo repository.ChatRepository, logger *slog.Logger) *ChatService {
+	return &ChatService{
+		chatRepo: chatRepo,
+		logger:   logger,
+	}
+}
+
+// LinkUser привязывает chatUserID к userID, заменяя прежние привязки обоих
+func (s *ChatService) LinkUser(ctx context.Context, chatUserID, userID string) error {
+	s.logger.InfoContext(ctx, "linking chat user", "chat_user_id", chatUserID, "user_id", userID)
+
+	if err := s.chatRepo.LinkUser(chatUserID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "user not found", "user_id", userID)
+			return ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to link chat user", "error", err, "user_id", userID)
+		return err
+	}
+	return nil
+}
+
+// ResolveUser возвращает user_id, привязанный к chatUserID
+func (s *ChatService) ResolveUser(ctx context.Context, chatUserID string) (string, error) {
+	userID, err := s.chatRepo.GetUserID(chatUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrChatUserNotLinked
+		}
+		s.logger.ErrorContext(ctx, "failed to resolve chat user", "error", err, "chat_user_id", chatUserID)
+		return "", err
+	}
+	return userID, nil
+}
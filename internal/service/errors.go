@@ -1,17 +1,34 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/reviewer-service/internal/workhours"
+)
 
 // Определяем константные ошибки для точного соответствия OpenAPI
 var (
-	ErrTeamExists        = errors.New("team already exists")
-	ErrTeamNotFound      = errors.New("team not found")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrPRExists          = errors.New("PR already exists")
-	ErrPRNotFound        = errors.New("PR not found")
-	ErrAuthorNotFound    = errors.New("author not found")
-	ErrPRMerged          = errors.New("cannot reassign on merged PR")
-	ErrNotAssigned       = errors.New("reviewer is not assigned to this PR")
-	ErrNoCandidate       = errors.New("no active replacement candidate in team")
-	ErrInvalidTeamMember = errors.New("user is not a member of the specified team")
+	ErrTeamExists          = errors.New("team already exists")
+	ErrTeamNotFound        = errors.New("team not found")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUserExists          = errors.New("user already exists")
+	ErrPRExists            = errors.New("PR already exists")
+	ErrPRNotFound          = errors.New("PR not found")
+	ErrAuthorNotFound      = errors.New("author not found")
+	ErrPRMerged            = errors.New("cannot reassign on merged PR")
+	ErrNotAssigned         = errors.New("reviewer is not assigned to this PR")
+	ErrNoCandidate         = errors.New("no active replacement candidate in team")
+	ErrInvalidTeamMember   = errors.New("user is not a member of the specified team")
+	ErrInvalidCursor       = errors.New("invalid pagination cursor")
+	ErrInvalidSort         = errors.New("unsupported sort field")
+	ErrInvalidStatus       = errors.New("unsupported status filter")
+	ErrInvalidManifest     = errors.New("invalid team manifest")
+	ErrInvalidDateRange    = errors.New("invalid date range")
+	ErrInvalidSLA          = errors.New("first_review_hours must be positive and reassign_after_hours greater than it")
+	ErrSLANotFound         = errors.New("review SLA is not configured for team")
+	ErrInvalidSchedule     = workhours.ErrInvalidSchedule
+	ErrScheduleNotFound    = errors.New("working hours are not set for user")
+	ErrInvalidEmail        = errors.New("email must be a plain address like user@example.com")
+	ErrPreferencesNotFound = errors.New("notification preferences are not set for user")
+	ErrChatUserNotLinked   = errors.New("chat user is not linked to a user")
 )
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+// StreamEventTypes — события журнала, которые отдаёт /events/stream.
+var StreamEventTypes = []string{
+	models.EventPRCreated,
+	models.EventReviewerAssigned,
+	models.EventReviewerRemoved,
+	models.EventPRMerged,
+	models.EventUserDeactivated,
+}
+
+const (
+	defaultEventPollInterval = time.Second
+	eventBatchSize           = 500
+	// subscriberBuffer — сколько событий подписчик может не забрать, прежде
+	// чем его отключат; дальше он дочитывает журнал по Last-Event-ID.
+	subscriberBuffer = 256
+	// eventGapTimeout — сколько ждать событие с пропущенным id: в PostgreSQL
+	// id выдаются до COMMIT, и транзакции фиксируются не по порядку id.
+	eventGapTimeout = 10 * time.Second
+)
+
+// EventStream раздаёт новые события журнала подписчикам /events/stream.
+// Журнал читает один опрос на процесс, пока есть хотя бы один подписчик;
+// подписчики получают события через буферизованные каналы и не задерживают
+// друг друга.
+type EventStream struct {
+	eventRepo repository.EventRepository
+	interval  time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	stop        chan struct{} // не nil, пока идёт опрос
+	closed      bool
+	// Все события с id <= cursor разосланы; разосланные после cursor
+	// ждут в sent закрытия пропусков.
+	cursor int64
+	sent   map[int64]time.Time
+}
+
+// Subscription — подписка на поток. Events закрывается, если подписчик
+// отстал или поток остановлен. Подписчик получает события, прочитанные
+// из журнала после подписки; все события с id <= Settled разосланы до неё.
+type Subscription struct {
+	Events  <-chan *models.StreamEvent
+	Settled int64
+
+	filter repository.EventFilter
+	ch     chan *models.StreamEvent
+}
+
+// NewEventStream создаёт поток; pollInterval <= 0 — раз в секунду.
+func NewEventStream(eventRepo repository.EventRepository, pollInterval time.Duration, logger *slog.Logger) *EventStream {
+	if pollInterval <= 0 {
+		pollInterval = defaultEventPollInterval
+	}
+	return &EventStream{
+		eventRepo:   eventRepo,
+		interval:    pollInterval,
+		logger:      logger,
+		now:         time.Now,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe подписывает на события потока, подходящие под filter, и при
+// первом подписчике запускает опрос журнала.
+func (s *EventStream) Subscribe(ctx context.Context, filter repository.EventFilter) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan *models.StreamEvent, subscriberBuffer)
+	sub := &Subscription{Events: ch, filter: filter, ch: ch}
+	if s.closed {
+		close(ch)
+		return sub, nil
+	}
+	if s.stop == nil {
+		last, err := s.eventRepo.LastID()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to read event log position", "error", err)
+			return nil, err
+		}
+		s.cursor = last
+		s.sent = make(map[int64]time.Time)
+		s.stop = make(chan struct{})
+		go s.run(s.stop)
+	}
+	sub.Settled = s.cursor
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe отписывает; с последним подписчиком опрос останавливается.
+func (s *EventStream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Отставшего подписчика broadcast уже отключил
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.ch)
+	}
+	if len(s.subscribers) == 0 && s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// Close отключает всех подписчиков; новые подписки сразу закрыты.
+// Вызывается при остановке сервера, чтобы потоки не держали соединения.
+func (s *EventStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.ch)
+	}
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// ListAfter читает журнал для возобновления по Last-Event-ID: до 500
+// событий после afterID, пустой ответ — журнал дочитан.
+func (s *EventStream) ListAfter(ctx context.Context, filter repository.EventFilter, afterID int64) ([]*models.StreamEvent, error) {
+	events, err := s.eventRepo.ListAfter(filter, afterID, eventBatchSize)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to read event log", "error", err, "after_id", afterID)
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *EventStream) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.poll(stop)
+		}
+	}
+}
+
+// poll читает журнал после cursor и рассылает ещё не разосланные события.
+// Журнал читается целиком, без фильтра по типам, чтобы пропуски id
+// означали только незафиксированные транзакции.
+func (s *EventStream) poll(stop <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-stop:
+		return
+	default:
+	}
+
+	now := s.now()
+	after := s.cursor
+	for {
+		events, err := s.eventRepo.ListAfter(repository.EventFilter{}, after, eventBatchSize)
+		if err != nil {
+			s.logger.Error("failed to poll event log", "error", err, "after_id", after)
+			return
+		}
+		for _, e := range events {
+			after = e.ID
+			if _, ok := s.sent[e.ID]; ok {
+				continue
+			}
+			s.sent[e.ID] = now
+			s.broadcast(e)
+		}
+		if len(events) < eventBatchSize {
+			break
+		}
+	}
+	s.advance(now)
+}
+
+// broadcast вызывается под s.mu. Подписчик с заполненным буфером
+// отключается, а не задерживает остальных.
+func (s *EventStream) broadcast(e *models.StreamEvent) {
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			s.logger.Warn("event stream subscriber is too slow, disconnecting", "event_id", e.ID)
+			delete(s.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// advance сдвигает cursor по подряд разосланным id; пропуск закрывается,
+// когда следующее за ним событие ждёт дольше eventGapTimeout.
+func (s *EventStream) advance(now time.Time) {
+	for len(s.sent) > 0 {
+		if _, ok := s.sent[s.cursor+1]; ok {
+			s.cursor++
+			delete(s.sent, s.cursor)
+			continue
+		}
+		next := int64(-1)
+		for id := range s.sent {
+			if next < 0 || id < next {
+				next = id
+			}
+		}
+		if now.Sub(s.sent[next]) < eventGapTimeout {
+			return
+		}
+		s.cursor = next - 1
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type mockEventRepository struct {
+	mu     sync.Mutex
+	events []*models.StreamEvent
+}
+
+func (m *mockEventRepository) add(id int64, eventType, teamName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, &models.StreamEvent{PREvent: models.PREvent{ID: id, Type: eventType}, TeamName: teamName})
+}
+
+func (m *mockEventRepository) ListAfter(filter repository.EventFilter, afterID int64, limit int) ([]*models.StreamEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Как и журнал, отдаёт события по возрастанию id, в каком бы порядке
+	// они ни появились
+	var events []*models.StreamEvent
+	for id := afterID + 1; len(events) < limit; id++ {
+		found := false
+		for _, e := range m.events {
+			if e.ID == id {
+				found = true
+				if filter.Matches(e) {
+					events = append(events, e)
+				}
+			}
+		}
+		if !found && id > m.maxID() {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (m *mockEventRepository) maxID() int64 {
+	var last int64
+	for _, e := range m.events {
+		last = max(last, e.ID)
+	}
+	return last
+}
+
+func (m *mockEventRepository) LastID() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.maxID(), nil
+}
+
+func newTestEventStream(repo *mockEventRepository) *EventStream {
+	// Опрос вызывается из тестов вручную
+	return NewEventStream(repo, time.Hour, setupTestLogger())
+}
+
+func (s *EventStream) pollNow() {
+	s.mu.Lock()
+	stop := s.stop
+	s.mu.Unlock()
+	s.poll(stop)
+}
+
+func received(sub *Subscription) (ids []int64, open bool) {
+	for {
+		select {
+		case e, ok := <-sub.Events:
+			if !ok {
+				return ids, false
+			}
+			ids = append(ids, e.ID)
+		default:
+			return ids, true
+		}
+	}
+}
+
+func TestEventStream_Broadcast(t *testing.T) {
+	repo := &mockEventRepository{}
+	repo.add(1, models.EventPRCreated, "backend")
+	s := newTestEventStream(repo)
+
+	backend, err := s.Subscribe(context.Background(), repository.EventFilter{TeamName: "backend", Types: StreamEventTypes})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	all, err := s.Subscribe(context.Background(), repository.EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if backend.Settled != 1 || all.Settled != 1 {
+		t.Fatalf("expected subscriptions after event 1, got %d and %d", backend.Settled, all.Settled)
+	}
+
+	repo.add(2, models.EventReviewerAssigned, "backend")
+	repo.add(3, models.EventReviewerAssigned, "frontend")
+	repo.add(4, models.EventReviewSubmitted, "backend")
+	s.pollNow()
+	s.pollNow()
+
+	if ids, open := received(backend); len(ids) != 1 || ids[0] != 2 || !open {
+		t.Errorf("expected backend subscriber to get event 2, got %v (open %v)", ids, open)
+	}
+	if ids, _ := received(all); len(ids) != 3 {
+		t.Errorf("expected unfiltered subscriber to get 3 events once, got %v", ids)
+	}
+
+	s.Unsubscribe(backend)
+	if _, open := received(backend); open {
+		t.Error("expected channel to be closed after Unsubscribe")
+	}
+	s.Unsubscribe(all)
+	if s.stop != nil {
+		t.Error("expected polling to stop without subscribers")
+	}
+}
+
+func TestEventStream_SlowSubscriber(t *testing.T) {
+	repo := &mockEventRepository{}
+	s := newTestEventStream(repo)
+
+	slow, _ := s.Subscribe(context.Background(), repository.EventFilter{TeamName: "backend"})
+	other, _ := s.Subscribe(context.Background(), repository.EventFilter{TeamName: "frontend"})
+	for id := int64(1); id <= subscriberBuffer+1; id++ {
+		repo.add(id, models.EventReviewerAssigned, "backend")
+	}
+	repo.add(subscriberBuffer+2, models.EventReviewerAssigned, "frontend")
+	s.pollNow()
+
+	if ids, open := received(slow); len(ids) != subscriberBuffer || open {
+		t.Errorf("expected slow subscriber to be disconnected after %d events, got %d (open %v)", subscriberBuffer, len(ids), open)
+	}
+	if ids, open := received(other); len(ids) != 1 || !open {
+		t.Errorf("expected other subscriber to keep streaming, got %v (open %v)", ids, open)
+	}
+	// Отключённый подписчик уходит без ошибки
+	s.Unsubscribe(slow)
+	s.Unsubscribe(other)
+}
+
+func TestEventStream_LateCommit(t *testing.T) {
+	repo := &mockEventRepository{}
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	s := newTestEventStream(repo)
+	s.now = func() time.Time { return now }
+
+	sub, _ := s.Subscribe(context.Background(), repository.EventFilter{})
+	defer s.Unsubscribe(sub)
+
+	// Транзакция с id 2 зафиксирована позже, чем с id 3
+	repo.add(1, models.EventPRCreated, "backend")
+	repo.add(3, models.EventPRCreated, "backend")
+	s.pollNow()
+	if s.cursor != 1 {
+		t.Fatalf("expected cursor to wait at the gap, got %d", s.cursor)
+	}
+	repo.add(2, models.EventPRCreated, "backend")
+	s.pollNow()
+	if ids, _ := received(sub); len(ids) != 3 || ids[2] != 2 {
+		t.Errorf("expected late event to be delivered once, got %v", ids)
+	}
+	if s.cursor != 3 {
+		t.Errorf("expected cursor to pass the filled gap, got %d", s.cursor)
+	}
+
+	// Откаченная транзакция оставляет пропуск навсегда
+	repo.add(5, models.EventPRMerged, "backend")
+	s.pollNow()
+	now = now.Add(eventGapTimeout)
+	s.pollNow()
+	if s.cursor != 5 || len(s.sent) != 0 {
+		t.Errorf("expected cursor to skip the gap after timeout, got %d with %v pending", s.cursor, s.sent)
+	}
+}
+
+func TestEventStream_Close(t *testing.T) {
+	s := newTestEventStream(&mockEventRepository{})
+	sub, _ := s.Subscribe(context.Background(), repository.EventFilter{})
+
+	s.Close()
+	if _, open := received(sub); open {
+		t.Error("expected subscription to be closed")
+	}
+	late, err := s.Subscribe(context.Background(), repository.EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, open := received(late); open {
+		t.Error("expected subscription after Close to be closed")
+	}
+	s.Unsubscribe(sub)
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"sync"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/notify"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/workhours"
+)
+
+// AssignmentNotifier узнаёт о новых назначениях ревьюверов после их
+// сохранения. previousReviewerID заполнен, если ревьювер заменил другого.
+type AssignmentNotifier interface {
+	ReviewersAssigned(ctx context.Context, pr *models.PullRequest, reviewerIDs []string, previousReviewerID string)
+}
+
+// NotificationService хранит подписки пользователей на письма и отправляет
+// утренние сводки, уведомления о назначении и напоминания о сроках.
+// Без sender подписки сохраняются, но письма не отправляются.
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	prRepo           repository.PullRequestRepository
+	userRepo         repository.UserRepository
+	calendars        *WorkCalendars
+	sender           notify.Sender
+	templates        *notify.Templates
+	digestHour       int
+	logger           *slog.Logger
+	now              func() time.Time
+
+	// pending — уведомления о назначении, которые ещё отправляются в фоне
+	pending sync.WaitGroup
+}
+
+// NewNotificationService создаёт сервис писем. Сводка уходит в первую
+// проверку после digestHour по часовому поясу пользователя из его рабочих
+// часов (без них — UTC) и пропускается в его нерабочие дни.
+func NewNotificationService(notificationRepo repository.NotificationRepository, prRepo repository.PullRequestRepository, userRepo repository.UserRepository,
+	calendars *WorkCalendars, sender notify.Sender, templates *notify.Templates, digestHour int, logger *slog.Logger) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		prRepo:           prRepo,
+		userRepo:         userRepo,
+		calendars:        calendars,
+		sender:           sender,
+		templates:        templates,
+		digestHour:       digestHour,
+		logger:           logger,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Enabled сообщает, отправляются ли письма
+func (s *NotificationService) Enabled() bool {
+	return s.sender != nil
+}
+
+// SetPreferences заменяет адрес и подписки пользователя
+func (s *NotificationService) SetPreferences(ctx context.Context, p *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	s.logger.InfoContext(ctx, "setting notification preferences", "user_id", p.UserID,
+		"daily_digest", p.DailyDigest, "assignment_notices", p.AssignmentNotices, "overdue_reminders", p.OverdueReminders)
+
+	if addr, err := mail.ParseAddress(p.Email); err != nil || addr.Address != p.Email {
+		return nil, ErrInvalidEmail
+	}
+
+	if err := s.notificationRepo.SetPreferences(p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "user not found", "user_id", p.UserID)
+			return nil, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to set notification preferences", "error", err, "user_id", p.UserID)
+		return nil, err
+	}
+	return s.GetPreferences(ctx, p.UserID)
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	p, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPreferencesNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get notification preferences", "error", err, "user_id", userID)
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReviewersAssigned отправляет уведомления в фоне, чтобы медленный
+// SMTP-сервер не задерживал ответ API; ошибки доставки только пишутся в журнал.
+func (s *NotificationService) ReviewersAssigned(ctx context.Context, pr *models.PullRequest, reviewerIDs []string, previousReviewerID string) {
+	if s.sender == nil || len(reviewerIDs) == 0 {
+		return
+	}
+	prCopy := *pr
+	reviewers := append([]string(nil), reviewerIDs...)
+	ctx = context.WithoutCancel(ctx)
+
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		s.sendAssignmentNotices(ctx, &prCopy, reviewers, previousReviewerID)
+	}()
+}
+
+// Wait дожидается фоновых уведомлений о назначении, например перед остановкой сервера
+func (s *NotificationService) Wait() {
+	s.pending.Wait()
+}
+
+func (s *NotificationService) sendAssignmentNotices(ctx context.Context, pr *models.PullRequest, reviewerIDs []string, previousReviewerID string) {
+	prefs, err := s.notificationRepo.ListPreferences(reviewerIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load notification preferences", "error", err, "pr_id", pr.PullRequestID)
+		return
+	}
+	users, err := s.usernames(reviewerIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load reviewers", "error", err, "pr_id", pr.PullRequestID)
+		return
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		p := prefs[reviewerID]
+		if p == nil || !p.AssignmentNotices {
+			continue
+		}
+		msg, err := s.templates.Render(notify.TemplateAssigned, notify.AssignmentData{
+			Username:           users[reviewerID],
+			PullRequest:        pr,
+			PreviousReviewerID: previousReviewerID,
+		})
+		if err == nil {
+			msg.To = p.Email
+			err = s.sender.Send(ctx, msg)
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to send assignment notice", "error", err, "pr_id", pr.PullRequestID, "user_id", reviewerID)
+			continue
+		}
+		s.logger.InfoContext(ctx, "assignment notice sent", "pr_id", pr.PullRequestID, "user_id", reviewerID)
+	}
+}
+
+// NotifyOverdue реализует ReviewNotifier. Ревьювер без адреса или
+// отписавшийся от напоминаний считается уведомлённым.
+func (s *NotificationService) NotifyOverdue(ctx context.Context, review models.OverdueReview) error {
+	p, err := s.notificationRepo.GetPreferences(review.ReviewerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if s.sender == nil || p == nil || !p.OverdueReminders {
+		s.logger.InfoContext(ctx, "overdue review reminder is not emailed", "pr_id", review.PullRequestID, "user_id", review.ReviewerID)
+		return nil
+	}
+
+	users, err := s.usernames([]string{review.ReviewerID})
+	if err != nil {
+		return err
+	}
+	msg, err := s.templates.Render(notify.TemplateOverdue, notify.OverdueData{Username: users[review.ReviewerID], Review: review})
+	if err != nil {
+		return err
+	}
+	msg.To = p.Email
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "overdue review reminder sent", "pr_id", review.PullRequestID, "user_id", review.ReviewerID)
+	return nil
+}
+
+// SendDigests отправляет сводки тем, у кого уже наступил digestHour, а
+// сводки за сегодня ещё не было, и возвращает число отправленных писем.
+// Без открытых ревью письмо не отправляется, но день считается закрытым.
+// Не доставленная сводка повторится при следующей проверке.
+func (s *NotificationService) SendDigests(ctx context.Context) (int, error) {
+	if s.sender == nil {
+		return 0, nil
+	}
+	recipients, err := s.notificationRepo.ListDigestRecipients()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list digest recipients", "error", err)
+		return 0, err
+	}
+	keys := make([]CalendarKey, len(recipients))
+	for i, rcpt := range recipients {
+		keys[i] = CalendarKey{UserID: rcpt.UserID, TeamName: rcpt.TeamName}
+	}
+	calendars, err := s.calendars.Load(keys)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load working hours", "error", err)
+		return 0, err
+	}
+
+	now := s.now()
+	sent := 0
+	for i, rcpt := range recipients {
+		loc := time.UTC
+		if rcpt.TimeZone != "" {
+			if l, err := time.LoadLocation(rcpt.TimeZone); err == nil {
+				loc = l
+			}
+		}
+		local := now.In(loc)
+		today := local.Format(workhours.DateLayout)
+		if local.Hour() < s.digestHour || rcpt.LastDigestOn == today {
+			continue
+		}
+
+		delivered, err := s.sendDigest(ctx, rcpt, calendars[keys[i]], local)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to send review digest", "error", err, "user_id", rcpt.UserID)
+			continue
+		}
+		if err := s.notificationRepo.MarkDigestSent(rcpt.UserID, today); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			s.logger.ErrorContext(ctx, "failed to mark review digest sent", "error", err, "user_id", rcpt.UserID)
+			return sent, err
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendDigest собирает сводку из открытых ревью пользователя, дольше ждущих первыми
+func (s *NotificationService) sendDigest(ctx context.Context, rcpt repository.DigestRecipient, cal *workhours.Calendar, local time.Time) (bool, error) {
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if cal != nil && cal.Duration(dayStart, dayStart.AddDate(0, 0, 1)) == 0 {
+		return false, nil
+	}
+
+	reviews, err := s.prRepo.GetByReviewerID(rcpt.UserID, repository.ReviewQuery{
+		Status: ReviewStatusOpen,
+		SortBy: repository.ReviewSortAssignedAt,
+		Limit:  MaxListLimit,
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(reviews) == 0 {
+		return false, nil
+	}
+	now := s.now()
+	for _, pr := range reviews {
+		pr.ReviewAgeSeconds = reviewAge(pr, now)
+	}
+
+	msg, err := s.templates.Render(notify.TemplateDigest, notify.DigestData{
+		Username: rcpt.Username,
+		Date:     local.Format(workhours.DateLayout),
+		Reviews:  reviews,
+	})
+	if err != nil {
+		return false, err
+	}
+	msg.To = rcpt.Email
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return false, err
+	}
+	s.logger.InfoContext(ctx, "review digest sent", "user_id", rcpt.UserID, "reviews", len(reviews))
+	return true, nil
+}
+
+func (s *NotificationService) usernames(userIDs []string) (map[string]string, error) {
+	users, err := s.userRepo.GetUsersByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(users))
+	for _, u := range users {
+		names[u.UserID] = u.Username
+	}
+	return names, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/notify"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type mockNotificationRepository struct {
+	prefs      map[string]*models.NotificationPreferences
+	recipients []repository.DigestRecipient
+	digestSent map[string]string
+}
+
+func (m *mockNotificationRepository) SetPreferences(p *models.NotificationPreferences) error {
+	if p.UserID == "missing" {
+		return sql.ErrNoRows
+	}
+	m.prefs[p.UserID] = p
+	return nil
+}
+
+func (m *mockNotificationRepository) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	p, ok := m.prefs[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+func (m *mockNotificationRepository) ListPreferences(userIDs []string) (map[string]*models.NotificationPreferences, error) {
+	result := make(map[string]*models.NotificationPreferences)
+	for _, id := range userIDs {
+		if p, ok := m.prefs[id]; ok {
+			result[id] = p
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepository) ListDigestRecipients() ([]repository.DigestRecipient, error) {
+	recipients := make([]repository.DigestRecipient, len(m.recipients))
+	for i, rcpt := range m.recipients {
+		rcpt.LastDigestOn = m.digestSent[rcpt.UserID]
+		recipients[i] = rcpt
+	}
+	return recipients, nil
+}
+
+func (m *mockNotificationRepository) MarkDigestSent(userID, date string) error {
+	m.digestSent[userID] = date
+	return nil
+}
+
+type mockSender struct {
+	mu   sync.Mutex
+	err  error
+	sent []notify.Message
+}
+
+func (m *mockSender) Send(ctx context.Context, msg notify.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *mockSender) recipients() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	to := make([]string, len(m.sent))
+	for i, msg := range m.sent {
+		to[i] = msg.To
+	}
+	sort.Strings(to)
+	return to
+}
+
+func newTestNotificationService(t *testing.T, repo *mockNotificationRepository, sender notify.Sender, schedules *mockScheduleRepository) *NotificationService {
+	t.Helper()
+	templates, err := notify.LoadTemplates("")
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	prRepo := &mockPRRepository{
+		prs:     make(map[string]*models.PullRequest),
+		reviews: []*models.PullRequestShort{{PullRequestID: "pr-1", PullRequestName: "Fix login", AuthorID: "u9", Status: "OPEN"}},
+	}
+	userRepo := &mockUserRepository{users: map[string]*models.User{}}
+	if schedules == nil {
+		schedules = &mockScheduleRepository{schedules: map[string]*models.WorkingHours{}, holidays: map[string][]models.Holiday{}}
+	}
+	return NewNotificationService(repo, prRepo, userRepo, NewWorkCalendars(schedules), sender, templates, 9, setupTestLogger())
+}
+
+func TestNotificationService_SetPreferences(t *testing.T) {
+	repo := &mockNotificationRepository{prefs: map[string]*models.NotificationPreferences{}}
+	s := newTestNotificationService(t, repo, nil, nil)
+
+	tests := []struct {
+		name    string
+		prefs   models.NotificationPreferences
+		wantErr error
+	}{
+		{"valid", models.NotificationPreferences{UserID: "u1", Email: "alice@example.com", DailyDigest: true}, nil},
+		{"display name", models.NotificationPreferences{UserID: "u1", Email: "Alice <alice@example.com>"}, ErrInvalidEmail},
+		{"not an address", models.NotificationPreferences{UserID: "u1", Email: "alice"}, ErrInvalidEmail},
+		{"unknown user", models.NotificationPreferences{UserID: "missing", Email: "x@example.com"}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.prefs
+			got, err := s.SetPreferences(context.Background(), &p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetPreferences() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(*got, tt.prefs) {
+				t.Errorf("SetPreferences() = %+v, want %+v", got, tt.prefs)
+			}
+		})
+	}
+
+	if _, err := s.GetPreferences(context.Background(), "u2"); !errors.Is(err, ErrPreferencesNotFound) {
+		t.Errorf("expected ErrPreferencesNotFound, got %v", err)
+	}
+}
+
+func TestNotificationService_SendDigests(t *testing.T) {
+	// Среда 2025-10-22 10:00 UTC: в Москве 13:00, в Нью-Йорке 06:00
+	now := time.Date(2025, 10, 22, 10, 0, 0, 0, time.UTC)
+	repo := &mockNotificationRepository{
+		recipients: []repository.DigestRecipient{
+			{UserID: "u1", Username: "Alice", TeamName: "backend", Email: "alice@example.com"},
+			{UserID: "u2", Username: "Bob", TeamName: "backend", Email: "bob@example.com", TimeZone: "Europe/Moscow"},
+			{UserID: "u3", Username: "Carol", TeamName: "backend", Email: "carol@example.com", TimeZone: "America/New_York"},
+			{UserID: "u4", Username: "Dave", TeamName: "frontend", Email: "dave@example.com"},
+		},
+		digestSent: map[string]string{},
+	}
+	// У u4 среда — праздник команды
+	schedules := &mockScheduleRepository{
+		schedules: map[string]*models.WorkingHours{},
+		holidays:  map[string][]models.Holiday{"frontend": {{Date: "2025-10-22"}}},
+	}
+	sender := &mockSender{}
+	s := newTestNotificationService(t, repo, sender, schedules)
+	s.now = func() time.Time { return now }
+
+	sent, err := s.SendDigests(context.Background())
+	if err != nil {
+		t.Fatalf("SendDigests() error = %v", err)
+	}
+	if sent != 2 || !reflect.DeepEqual(sender.recipients(), []string{"alice@example.com", "bob@example.com"}) {
+		t.Fatalf("expected digests to alice and bob, got %d: %v", sent, sender.recipients())
+	}
+	want := map[string]string{"u1": "2025-10-22", "u2": "2025-10-22", "u4": "2025-10-22"}
+	if !reflect.DeepEqual(repo.digestSent, want) {
+		t.Errorf("expected days %v marked sent, got %v", want, repo.digestSent)
+	}
+
+	// Повторная проверка в тот же день ничего не отправляет
+	if sent, err := s.SendDigests(context.Background()); err != nil || sent != 0 {
+		t.Errorf("expected no repeated digests, got %d, %v", sent, err)
+	}
+
+	// В Нью-Йорке наступило 09:00
+	now = now.Add(3 * time.Hour)
+	if sent, err := s.SendDigests(context.Background()); err != nil || sent != 1 {
+		t.Errorf("expected digest to carol, got %d, %v", sent, err)
+	}
+}
+
+func TestNotificationService_SendDigests_DeliveryFailure(t *testing.T) {
+	repo := &mockNotificationRepository{
+		recipients: []repository.DigestRecipient{{UserID: "u1", Username: "Alice", Email: "alice@example.com"}},
+		digestSent: map[string]string{},
+	}
+	sender := &mockSender{err: errors.New("connection refused")}
+	s := newTestNotificationService(t, repo, sender, nil)
+	s.now = func() time.Time { return time.Date(2025, 10, 22, 10, 0, 0, 0, time.UTC) }
+
+	if sent, err := s.SendDigests(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected no digests, got %d, %v", sent, err)
+	}
+	if len(repo.digestSent) != 0 {
+		t.Errorf("undelivered digest must be retried, got %v", repo.digestSent)
+	}
+}
+
+func TestNotificationService_ReviewersAssigned(t *testing.T) {
+	repo := &mockNotificationRepository{prefs: map[string]*models.NotificationPreferences{
+		"u1": {UserID: "u1", Email: "alice@example.com", AssignmentNotices: true},
+		"u2": {UserID: "u2", Email: "bob@example.com", AssignmentNotices: false},
+	}}
+	sender := &mockSender{}
+	s := newTestNotificationService(t, repo, sender, nil)
+
+	pr := &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "Fix login", AuthorID: "u9", Status: "OPEN"}
+	s.ReviewersAssigned(context.Background(), pr, []string{"u1", "u2", "u3"}, "")
+	s.Wait()
+
+	if got := sender.recipients(); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Errorf("expected notice only to alice, got %v", got)
+	}
+}
+
+func TestNotificationService_NotifyOverdue(t *testing.T) {
+	repo := &mockNotificationRepository{prefs: map[string]*models.NotificationPreferences{
+		"u1": {UserID: "u1", Email: "alice@example.com", OverdueReminders: true},
+		"u2": {UserID: "u2", Email: "bob@example.com", OverdueReminders: false},
+	}}
+	sender := &mockSender{}
+	s := newTestNotificationService(t, repo, sender, nil)
+
+	for _, reviewerID := range []string{"u1", "u2", "u3"} {
+		review := models.OverdueReview{PullRequestID: "pr-1", ReviewerID: reviewerID, OverdueSeconds: 3600}
+		if err := s.NotifyOverdue(context.Background(), review); err != nil {
+			t.Fatalf("NotifyOverdue(%s) error = %v", reviewerID, err)
+		}
+	}
+	if got := sender.recipients(); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Errorf("expected reminder only to alice, got %v", got)
+	}
+
+	// Ошибка доставки возвращается, чтобы напоминание повторилось
+	sender.err = errors.New("connection refused")
+	if err := s.NotifyOverdue(context.Background(), models.OverdueReview{PullRequestID: "pr-1", ReviewerID: "u1"}); err == nil {
+		t.Error("expected delivery error")
+	}
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"time"
@@ -12,17 +15,34 @@ import (
 	"github.com/reviewer-service/internal/repository"
 )
 
+// maxReviewers — сколько ревьюверов назначается на новый PR
+const maxReviewers = 2
+
+// Размер страницы списка PR
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 100
+)
+
 type PullRequestService struct {
-	prRepo   repository.PullRequestRepository
-	userRepo repository.UserRepository
-	logger   *slog.Logger
+	prRepo       repository.PullRequestRepository
+	userRepo     repository.UserRepository
+	availability *WorkCalendars
+	notifier     AssignmentNotifier
+	logger       *slog.Logger
 }
 
-func NewPullRequestService(prRepo repository.PullRequestRepository, userRepo repository.UserRepository, logger *slog.Logger) *PullRequestService {
+// NewPullRequestService создаёт сервис PR. С availability при выборе
+// ревьюверов сначала берутся те, у кого сейчас рабочее время; nil —
+// выбор среди всех кандидатов поровну. notifier, если задан, узнаёт
+// о назначениях при создании PR и замене ревьювера.
+func NewPullRequestService(prRepo repository.PullRequestRepository, userRepo repository.UserRepository, availability *WorkCalendars, notifier AssignmentNotifier, logger *slog.Logger) *PullRequestService {
 	return &PullRequestService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
-		logger:   logger,
+		prRepo:       prRepo,
+		userRepo:     userRepo,
+		availability: availability,
+		notifier:     notifier,
+		logger:       logger,
 	}
 }
 
@@ -48,6 +68,10 @@ func (s *PullRequestService) CreatePR(ctx context.Context, prID, prName, authorI
 		s.logger.ErrorContext(ctx, "failed to get author", "error", err, "author_id", authorID)
 		return nil, err
 	}
+	if author.ArchivedAt != nil {
+		s.logger.WarnContext(ctx, "author is archived", "author_id", authorID)
+		return nil, ErrAuthorNotFound
+	}
 
 	candidates, err := s.userRepo.GetActiveTeamMembers(author.TeamName, authorID)
 	if err != nil {
@@ -55,7 +79,7 @@ func (s *PullRequestService) CreatePR(ctx context.Context, prID, prName, authorI
 		return nil, err
 	}
 
-	reviewers := selectRandomReviewers(candidates, 2)
+	reviewers := s.pickReviewers(ctx, candidates, maxReviewers)
 	s.logger.InfoContext(ctx, "reviewers selected", "pr_id", prID, "reviewers", reviewers, "candidates_count", len(candidates))
 
 	now := time.Now()
@@ -74,6 +98,9 @@ func (s *PullRequestService) CreatePR(ctx context.Context, prID, prName, authorI
 	}
 
 	s.logger.InfoContext(ctx, "PR created successfully", "pr_id", prID, "reviewers_count", len(reviewers))
+	if s.notifier != nil {
+		s.notifier.ReviewersAssigned(ctx, pr, reviewers, "")
+	}
 	return pr, nil
 }
 
@@ -114,6 +141,40 @@ func (s *PullRequestService) MergePR(ctx context.Context, prID string) (*models.
 	return mergedPR, nil
 }
 
+// SubmitReview фиксирует, что ревьювер отправил ревью. Повторный вызов
+// возвращает время первой отправки.
+func (s *PullRequestService) SubmitReview(ctx context.Context, prID, userID string) (*models.PullRequest, time.Time, error) {
+	s.logger.InfoContext(ctx, "submitting review", "pr_id", prID, "user_id", userID)
+
+	pr, err := s.prRepo.GetByID(prID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.ErrorContext(ctx, "PR not found", "error", err, "pr_id", prID)
+			return nil, time.Time{}, ErrPRNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get PR", "error", err, "pr_id", prID)
+		return nil, time.Time{}, err
+	}
+
+	if pr.Status == "MERGED" {
+		s.logger.WarnContext(ctx, "cannot review merged PR", "pr_id", prID)
+		return nil, time.Time{}, ErrPRMerged
+	}
+
+	reviewedAt, err := s.prRepo.SubmitReview(prID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "user is not assigned to PR", "pr_id", prID, "user_id", userID)
+			return nil, time.Time{}, ErrNotAssigned
+		}
+		s.logger.ErrorContext(ctx, "failed to submit review", "error", err, "pr_id", prID)
+		return nil, time.Time{}, err
+	}
+
+	s.logger.InfoContext(ctx, "review submitted", "pr_id", prID, "user_id", userID, "reviewed_at", reviewedAt)
+	return pr, reviewedAt, nil
+}
+
 func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
 	s.logger.InfoContext(ctx, "reassigning reviewer", "pr_id", prID, "old_user_id", oldUserID)
 
@@ -186,8 +247,8 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 		}
 	}
 
-	newReviewer := filteredCandidates[rand.Intn(len(filteredCandidates))]
-	newReviewers = append(newReviewers, newReviewer.UserID)
+	newReviewerID := s.pickReviewers(ctx, filteredCandidates, 1)[0]
+	newReviewers = append(newReviewers, newReviewerID)
 
 	if err := s.prRepo.UpdateReviewers(prID, newReviewers); err != nil {
 		s.logger.ErrorContext(ctx, "failed to update reviewers", "error", err, "pr_id", prID)
@@ -204,8 +265,185 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 		return nil, "", err
 	}
 
-	s.logger.InfoContext(ctx, "reviewer reassigned successfully", "pr_id", prID, "old_user_id", oldUserID, "new_user_id", newReviewer.UserID)
-	return updatedPR, newReviewer.UserID, nil
+	s.logger.InfoContext(ctx, "reviewer reassigned successfully", "pr_id", prID, "old_user_id", oldUserID, "new_user_id", newReviewerID)
+	if s.notifier != nil {
+		s.notifier.ReviewersAssigned(ctx, updatedPR, []string{newReviewerID}, oldUserID)
+	}
+	return updatedPR, newReviewerID, nil
+}
+
+// GetHistory возвращает журнал изменений PR в порядке их применения.
+func (s *PullRequestService) GetHistory(ctx context.Context, prID string) ([]*models.PREvent, error) {
+	s.logger.DebugContext(ctx, "fetching PR history", "pr_id", prID)
+
+	if _, err := s.prRepo.GetByID(prID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "PR not found", "pr_id", prID)
+			return nil, ErrPRNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get PR", "error", err, "pr_id", prID)
+		return nil, err
+	}
+
+	events, err := s.prRepo.GetEvents(prID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to fetch PR history", "error", err, "pr_id", prID)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *PullRequestService) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	pr, err := s.prRepo.GetByID(prID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "PR not found", "pr_id", prID)
+			return nil, ErrPRNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get PR", "error", err, "pr_id", prID)
+		return nil, err
+	}
+	return pr, nil
+}
+
+// ListPullRequestsQuery — фильтры, сортировка и позиция страницы для ListPRs.
+// Cursor — непрозрачная строка из NextCursor предыдущей страницы.
+type ListPullRequestsQuery struct {
+	Filter         repository.PullRequestFilter
+	NeedsReviewers bool
+	SortBy         string
+	Desc           bool
+	Cursor         string
+	Limit          int
+}
+
+// ListPRs возвращает страницу PR и курсор следующей страницы
+// (пустой, если страница последняя).
+func (s *PullRequestService) ListPRs(ctx context.Context, q ListPullRequestsQuery) ([]*models.PullRequest, string, error) {
+	if q.SortBy == "" {
+		q.SortBy = repository.PRSortCreatedAt
+	}
+	if q.SortBy != repository.PRSortCreatedAt && q.SortBy != repository.PRSortID {
+		return nil, "", ErrInvalidSort
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultListLimit
+	}
+	if q.Limit > MaxListLimit {
+		q.Limit = MaxListLimit
+	}
+	if q.NeedsReviewers {
+		q.Filter.ReviewersBelow = maxReviewers
+	}
+
+	page := repository.PullRequestPage{SortBy: q.SortBy, Desc: q.Desc, Limit: q.Limit + 1}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor, q.SortBy, q.Desc)
+		if err != nil {
+			s.logger.WarnContext(ctx, "invalid PR list cursor", "error", err)
+			return nil, "", ErrInvalidCursor
+		}
+		page.After = after
+	}
+
+	prs, err := s.prRepo.List(q.Filter, page)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list PRs", "error", err)
+		return nil, "", err
+	}
+
+	// Лишняя запись показывает, что за страницей есть продолжение
+	var next string
+	if len(prs) > q.Limit {
+		prs = prs[:q.Limit]
+		next = encodeCursor(prs[len(prs)-1], q.SortBy, q.Desc)
+	}
+	return prs, next, nil
+}
+
+// prCursor хранит ключ последней записи вместе с сортировкой, чтобы курсор
+// нельзя было применить к выборке с другим порядком.
+type prCursor struct {
+	SortBy    string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	CreatedAt *time.Time `json:"t,omitempty"`
+	ID        string     `json:"id"`
+}
+
+func encodeCursor(pr *models.PullRequest, sortBy string, desc bool) string {
+	return encodeKey(sortBy, desc, pr.CreatedAt, pr.PullRequestID)
+}
+
+// encodeKey кодирует ключ записи; время сохраняется для всех сортировок,
+// кроме сортировки по идентификатору.
+func encodeKey(sortBy string, desc bool, at *time.Time, id string) string {
+	c := prCursor{SortBy: sortBy, Desc: desc, ID: id}
+	if sortBy != repository.PRSortID {
+		t := time.Time{}
+		if at != nil {
+			t = at.UTC()
+		}
+		c.CreatedAt = &t
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor, sortBy string, desc bool) (*repository.PullRequestCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var c prCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.SortBy != sortBy || c.Desc != desc || c.ID == "" {
+		return nil, fmt.Errorf("cursor was issued for sort %q desc=%t", c.SortBy, c.Desc)
+	}
+	after := &repository.PullRequestCursor{PullRequestID: c.ID}
+	if sortBy != repository.PRSortID {
+		if c.CreatedAt == nil {
+			return nil, errors.New("cursor has no timestamp")
+		}
+		after.At = *c.CreatedAt
+	}
+	return after, nil
+}
+
+// pickReviewers выбирает до maxCount случайных кандидатов. Если включено
+// предпочтение работающих, недостающих добирают из тех, у кого нерабочее
+// время; календарь недоступен — выбор идёт без предпочтения.
+func (s *PullRequestService) pickReviewers(ctx context.Context, candidates []*models.User, maxCount int) []string {
+	if s.availability == nil {
+		return selectRandomReviewers(candidates, maxCount)
+	}
+
+	keys := make([]CalendarKey, len(candidates))
+	for i, c := range candidates {
+		keys[i] = CalendarKey{UserID: c.UserID, TeamName: c.TeamName}
+	}
+	calendars, err := s.availability.Load(keys)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load working hours, ignoring availability", "error", err)
+		return selectRandomReviewers(candidates, maxCount)
+	}
+
+	now := time.Now()
+	var working, away []*models.User
+	for i, c := range candidates {
+		if calendars[keys[i]].IsWorking(now) {
+			working = append(working, c)
+		} else {
+			away = append(away, c)
+		}
+	}
+	reviewers := selectRandomReviewers(working, maxCount)
+	if len(reviewers) < maxCount {
+		reviewers = append(reviewers, selectRandomReviewers(away, maxCount-len(reviewers))...)
+	}
+	return reviewers
 }
 
 func selectRandomReviewers(candidates []*models.User, maxCount int) []string {
@@ -6,14 +6,24 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
 )
 
 type mockPRRepository struct {
 	prs map[string]*models.PullRequest
+
+	lastFilter repository.PullRequestFilter
+	lastPage   repository.PullRequestPage
+
+	reviews         []*models.PullRequestShort
+	lastReviewQuery repository.ReviewQuery
 }
 
 func (m *mockPRRepository) Create(pr *models.PullRequest) error {
@@ -24,6 +34,20 @@ func (m *mockPRRepository) Create(pr *models.PullRequest) error {
 	return nil
 }
 
+func (m *mockPRRepository) Import(tx repository.Tx, pr *models.PullRequest) error {
+	return m.Create(pr)
+}
+
+func (m *mockPRRepository) ExistingIDs(tx repository.Tx, prIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for _, prID := range prIDs {
+		if _, ok := m.prs[prID]; ok {
+			existing[prID] = true
+		}
+	}
+	return existing, nil
+}
+
 func (m *mockPRRepository) GetByID(prID string) (*models.PullRequest, error) {
 	pr, exists := m.prs[prID]
 	if !exists {
@@ -52,8 +76,32 @@ func (m *mockPRRepository) UpdateReviewers(prID string, reviewers []string) erro
 	return nil
 }
 
-func (m *mockPRRepository) GetByReviewerID(userID string) ([]*models.PullRequestShort, error) {
-	return nil, nil
+func (m *mockPRRepository) SubmitReview(prID, reviewerID string) (time.Time, error) {
+	pr, exists := m.prs[prID]
+	if !exists {
+		return time.Time{}, sql.ErrNoRows
+	}
+	for _, r := range pr.AssignedReviewers {
+		if r == reviewerID {
+			return time.Now().UTC(), nil
+		}
+	}
+	return time.Time{}, sql.ErrNoRows
+}
+
+func (m *mockPRRepository) GetByReviewerID(userID string, q repository.ReviewQuery) ([]*models.PullRequestShort, error) {
+	m.lastReviewQuery = q
+	reviews := make([]*models.PullRequestShort, 0)
+	for _, pr := range m.reviews {
+		if q.After != nil && pr.PullRequestID <= q.After.PullRequestID {
+			continue
+		}
+		if q.Limit > 0 && len(reviews) == q.Limit {
+			break
+		}
+		reviews = append(reviews, pr)
+	}
+	return reviews, nil
 }
 
 func (m *mockPRRepository) GetOpenPRsByAuthors(userIDs []string) ([]*models.PullRequest, error) {
@@ -64,18 +112,43 @@ func (m *mockPRRepository) GetOpenPRsByReviewers(userIDs []string) (map[string][
 	return nil, nil
 }
 
-func (m *mockPRRepository) ReassignAuthor(tx *sql.Tx, prID, newAuthorID string) error {
+func (m *mockPRRepository) ReassignAuthor(tx repository.Tx, prID, newAuthorID string) error {
 	return nil
 }
 
-func (m *mockPRRepository) RemoveReviewer(tx *sql.Tx, prID, reviewerID string) error {
+func (m *mockPRRepository) RemoveReviewer(tx repository.Tx, prID, reviewerID string) error {
 	return nil
 }
 
-func (m *mockPRRepository) AddReviewer(tx *sql.Tx, prID, reviewerID string) error {
+func (m *mockPRRepository) AddReviewer(tx repository.Tx, prID, reviewerID string) error {
 	return nil
 }
 
+func (m *mockPRRepository) GetEvents(prID string) ([]*models.PREvent, error) {
+	return []*models.PREvent{}, nil
+}
+
+// List поддерживает только сортировку по pull_request_id: сервису важно,
+// какие параметры страницы он передаёт, а не сама выборка.
+func (m *mockPRRepository) List(filter repository.PullRequestFilter, page repository.PullRequestPage) ([]*models.PullRequest, error) {
+	m.lastFilter, m.lastPage = filter, page
+	ids := make([]string, 0, len(m.prs))
+	for id := range m.prs {
+		if page.After == nil || id > page.After.PullRequestID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if page.Limit > 0 && len(ids) > page.Limit {
+		ids = ids[:page.Limit]
+	}
+	prs := make([]*models.PullRequest, 0, len(ids))
+	for _, id := range ids {
+		prs = append(prs, m.prs[id])
+	}
+	return prs, nil
+}
+
 type mockUserRepository struct {
 	users map[string]*models.User
 }
@@ -107,7 +180,7 @@ func (m *mockUserRepository) GetActiveTeamMembers(teamName string, excludeUserID
 	return members, nil
 }
 
-func (m *mockUserRepository) DeactivateUsers(tx *sql.Tx, userIDs []string) error {
+func (m *mockUserRepository) DeactivateUsers(tx repository.Tx, userIDs []string) error {
 	return nil
 }
 
@@ -115,6 +188,45 @@ func (m *mockUserRepository) GetUsersByIDs(userIDs []string) ([]*models.User, er
 	return nil, nil
 }
 
+func (m *mockUserRepository) Upsert(tx repository.Tx, user *models.User) error {
+	m.users[user.UserID] = user
+	return nil
+}
+
+func (m *mockUserRepository) Archive(tx repository.Tx, userIDs []string, at time.Time) error {
+	for _, id := range userIDs {
+		if user, exists := m.users[id]; exists {
+			user.IsActive = false
+			user.ArchivedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockUserRepository) List() ([]*models.User, error) {
+	users := make([]*models.User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users, nil
+}
+
+func (m *mockUserRepository) SetAway(userID string, until time.Time) error {
+	user, exists := m.users[userID]
+	if !exists {
+		return sql.ErrNoRows
+	}
+	user.IsActive = false
+	return nil
+}
+
+func (m *mockUserRepository) ReturnFromAway(now time.Time) (int64, error) {
+	return 0, nil
+}
+
 func setupTestLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 }
@@ -193,6 +305,24 @@ func TestPullRequestService_CreatePR(t *testing.T) {
 			},
 			expectedError: ErrAuthorNotFound,
 		},
+		{
+			name:     "archived author",
+			prID:     "pr-1",
+			prName:   "Test PR",
+			authorID: "user-1",
+			setupMocks: func() (*mockPRRepository, *mockUserRepository) {
+				archivedAt := time.Now()
+				prRepo := &mockPRRepository{prs: make(map[string]*models.PullRequest)}
+				userRepo := &mockUserRepository{
+					users: map[string]*models.User{
+						"user-1": {UserID: "user-1", Username: "author", TeamName: "team-1", ArchivedAt: &archivedAt},
+						"user-2": {UserID: "user-2", Username: "reviewer1", TeamName: "team-1", IsActive: true},
+					},
+				}
+				return prRepo, userRepo
+			},
+			expectedError: ErrAuthorNotFound,
+		},
 		{
 			name:     "no active reviewers",
 			prID:     "pr-1",
@@ -219,7 +349,7 @@ func TestPullRequestService_CreatePR(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			prRepo, userRepo := tt.setupMocks()
-			service := NewPullRequestService(prRepo, userRepo, setupTestLogger())
+			service := NewPullRequestService(prRepo, userRepo, nil, nil, setupTestLogger())
 
 			pr, err := service.CreatePR(context.Background(), tt.prID, tt.prName, tt.authorID)
 
@@ -313,7 +443,7 @@ func TestPullRequestService_MergePR(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			prRepo := tt.setupMocks()
 			userRepo := &mockUserRepository{users: make(map[string]*models.User)}
-			service := NewPullRequestService(prRepo, userRepo, setupTestLogger())
+			service := NewPullRequestService(prRepo, userRepo, nil, nil, setupTestLogger())
 
 			pr, err := service.MergePR(context.Background(), tt.prID)
 
@@ -338,6 +468,47 @@ func TestPullRequestService_MergePR(t *testing.T) {
 	}
 }
 
+func TestPullRequestService_SubmitReview(t *testing.T) {
+	prRepo := &mockPRRepository{
+		prs: map[string]*models.PullRequest{
+			"pr-open":   {PullRequestID: "pr-open", Status: "OPEN", AssignedReviewers: []string{"u2"}},
+			"pr-merged": {PullRequestID: "pr-merged", Status: "MERGED", AssignedReviewers: []string{"u2"}},
+		},
+	}
+	userRepo := &mockUserRepository{users: make(map[string]*models.User)}
+	service := NewPullRequestService(prRepo, userRepo, nil, nil, setupTestLogger())
+
+	tests := []struct {
+		name          string
+		prID          string
+		userID        string
+		expectedError error
+	}{
+		{name: "assigned reviewer", prID: "pr-open", userID: "u2"},
+		{name: "PR not found", prID: "pr-missing", userID: "u2", expectedError: ErrPRNotFound},
+		{name: "merged PR", prID: "pr-merged", userID: "u2", expectedError: ErrPRMerged},
+		{name: "not assigned", prID: "pr-open", userID: "u3", expectedError: ErrNotAssigned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, reviewedAt, err := service.SubmitReview(context.Background(), tt.prID, tt.userID)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pr.PullRequestID != tt.prID || reviewedAt.IsZero() {
+				t.Errorf("unexpected result: %+v, %v", pr, reviewedAt)
+			}
+		})
+	}
+}
+
 func TestPullRequestService_ReassignReviewer(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -473,7 +644,7 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			prRepo, userRepo := tt.setupMocks()
-			service := NewPullRequestService(prRepo, userRepo, setupTestLogger())
+			service := NewPullRequestService(prRepo, userRepo, nil, nil, setupTestLogger())
 
 			pr, newUserID, err := service.ReassignReviewer(context.Background(), tt.prID, tt.oldUserID)
 
@@ -498,3 +669,126 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 	}
 }
 
+func TestPullRequestService_ListPRs(t *testing.T) {
+	ctx := context.Background()
+	prRepo := &mockPRRepository{prs: map[string]*models.PullRequest{
+		"pr-1": {PullRequestID: "pr-1"},
+		"pr-2": {PullRequestID: "pr-2"},
+		"pr-3": {PullRequestID: "pr-3"},
+	}}
+	svc := NewPullRequestService(prRepo, &mockUserRepository{}, nil, nil, setupTestLogger())
+
+	query := ListPullRequestsQuery{SortBy: repository.PRSortID, Limit: 2}
+	first, next, err := svc.ListPRs(ctx, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 2 || first[1].PullRequestID != "pr-2" || next == "" {
+		t.Fatalf("expected [pr-1 pr-2] and next cursor, got %d PRs, cursor %q", len(first), next)
+	}
+	if prRepo.lastPage.Limit != 3 {
+		t.Errorf("expected one extra row to be requested, got limit %d", prRepo.lastPage.Limit)
+	}
+
+	query.Cursor = next
+	second, next, err := svc.ListPRs(ctx, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second) != 1 || second[0].PullRequestID != "pr-3" || next != "" {
+		t.Errorf("expected last page [pr-3] without cursor, got %d PRs, cursor %q", len(second), next)
+	}
+	if prRepo.lastPage.After == nil || prRepo.lastPage.After.PullRequestID != "pr-2" {
+		t.Errorf("expected page after pr-2, got %+v", prRepo.lastPage.After)
+	}
+
+	invalid := []ListPullRequestsQuery{
+		{SortBy: repository.PRSortID, Desc: true, Cursor: query.Cursor},
+		{SortBy: repository.PRSortCreatedAt, Cursor: query.Cursor},
+		{Cursor: "not-a-cursor"},
+	}
+	for _, q := range invalid {
+		if _, _, err := svc.ListPRs(ctx, q); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor for %+v, got %v", q, err)
+		}
+	}
+	if _, _, err := svc.ListPRs(ctx, ListPullRequestsQuery{SortBy: "author_id"}); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("expected ErrInvalidSort, got %v", err)
+	}
+
+	if _, _, err := svc.ListPRs(ctx, ListPullRequestsQuery{NeedsReviewers: true, Limit: 1000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prRepo.lastFilter.ReviewersBelow != maxReviewers || prRepo.lastPage.Limit != MaxListLimit+1 {
+		t.Errorf("unexpected defaults: filter %+v, page %+v", prRepo.lastFilter, prRepo.lastPage)
+	}
+	if prRepo.lastPage.SortBy != repository.PRSortCreatedAt || prRepo.lastPage.Desc {
+		t.Errorf("expected created_at sort by default, got %+v", prRepo.lastPage)
+	}
+}
+
+type mockScheduleRepository struct {
+	schedules map[string]*models.WorkingHours
+	holidays  map[string][]models.Holiday
+}
+
+func (m *mockScheduleRepository) SetWorkingHours(userID string, wh *models.WorkingHours) error {
+	m.schedules[userID] = wh
+	return nil
+}
+
+func (m *mockScheduleRepository) GetWorkingHours(userID string) (*models.WorkingHours, error) {
+	wh, ok := m.schedules[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return wh, nil
+}
+
+func (m *mockScheduleRepository) ListWorkingHours(userIDs []string) (map[string]*models.WorkingHours, error) {
+	return m.schedules, nil
+}
+
+func (m *mockScheduleRepository) SetHolidays(teamName string, holidays []models.Holiday) error {
+	m.holidays[teamName] = holidays
+	return nil
+}
+
+func (m *mockScheduleRepository) GetHolidays(teamName string) ([]models.Holiday, error) {
+	return m.holidays[teamName], nil
+}
+
+func (m *mockScheduleRepository) ListHolidays(teamNames []string) (map[string][]models.Holiday, error) {
+	return m.holidays, nil
+}
+
+func TestPullRequestService_CreatePR_PrefersWorkingReviewers(t *testing.T) {
+	// user-4 работает только в день, до которого ещё трое суток
+	offDay := strings.ToLower(time.Now().UTC().Add(72 * time.Hour).Weekday().String())
+	schedules := &mockScheduleRepository{schedules: map[string]*models.WorkingHours{
+		"user-4": {TimeZone: "UTC", Periods: []models.WorkingPeriod{{Weekday: offDay, Start: "00:00", End: "24:00"}}},
+	}}
+
+	for i := 0; i < 20; i++ {
+		prRepo := &mockPRRepository{prs: make(map[string]*models.PullRequest)}
+		userRepo := &mockUserRepository{
+			users: map[string]*models.User{
+				"user-1": {UserID: "user-1", TeamName: "team-1", IsActive: true},
+				"user-2": {UserID: "user-2", TeamName: "team-1", IsActive: true},
+				"user-3": {UserID: "user-3", TeamName: "team-1", IsActive: true},
+				"user-4": {UserID: "user-4", TeamName: "team-1", IsActive: true},
+			},
+		}
+		s := NewPullRequestService(prRepo, userRepo, NewWorkCalendars(schedules), nil, setupTestLogger())
+
+		pr, err := s.CreatePR(context.Background(), "pr-1", "Test PR", "user-1")
+		if err != nil {
+			t.Fatalf("CreatePR() error = %v", err)
+		}
+		got := append([]string(nil), pr.AssignedReviewers...)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, []string{"user-2", "user-3"}) {
+			t.Fatalf("expected working reviewers user-2 and user-3, got %v", got)
+		}
+	}
+}
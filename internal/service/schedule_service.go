@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/workhours"
+)
+
+type ScheduleService struct {
+	scheduleRepo repository.ScheduleRepository
+	logger       *slog.Logger
+}
+
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, logger *slog.Logger) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo: scheduleRepo,
+		logger:       logger,
+	}
+}
+
+// SetWorkingHours заменяет недельное расписание пользователя
+func (s *ScheduleService) SetWorkingHours(ctx context.Context, userID string, wh *models.WorkingHours) (*models.WorkingHours, error) {
+	s.logger.InfoContext(ctx, "setting working hours", "user_id", userID, "time_zone", wh.TimeZone)
+
+	if err := workhours.Validate(wh); err != nil {
+		return nil, err
+	}
+	for i := range wh.Periods {
+		wh.Periods[i].Weekday = strings.ToLower(wh.Periods[i].Weekday)
+	}
+
+	if err := s.scheduleRepo.SetWorkingHours(userID, wh); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "user not found", "user_id", userID)
+			return nil, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to set working hours", "error", err, "user_id", userID)
+		return nil, err
+	}
+	return s.GetWorkingHours(ctx, userID)
+}
+
+func (s *ScheduleService) GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error) {
+	wh, err := s.scheduleRepo.GetWorkingHours(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get working hours", "error", err, "user_id", userID)
+		return nil, err
+	}
+	return wh, nil
+}
+
+// SetHolidays заменяет календарь праздников команды
+func (s *ScheduleService) SetHolidays(ctx context.Context, teamName string, holidays []models.Holiday) ([]models.Holiday, error) {
+	s.logger.InfoContext(ctx, "setting team holidays", "team_name", teamName, "count", len(holidays))
+
+	if _, err := workhours.New(nil, holidays); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		if seen[h.Date] {
+			return nil, fmt.Errorf("%w: holiday %s is listed twice", ErrInvalidSchedule, h.Date)
+		}
+		seen[h.Date] = true
+	}
+
+	if err := s.scheduleRepo.SetHolidays(teamName, holidays); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "team not found", "team_name", teamName)
+			return nil, ErrTeamNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to set team holidays", "error", err, "team_name", teamName)
+		return nil, err
+	}
+	return s.GetHolidays(ctx, teamName)
+}
+
+func (s *ScheduleService) GetHolidays(ctx context.Context, teamName string) ([]models.Holiday, error) {
+	holidays, err := s.scheduleRepo.GetHolidays(teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get team holidays", "error", err, "team_name", teamName)
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// CalendarKey — пользователь и команда, чьи праздники к нему применяются
+type CalendarKey struct {
+	UserID   string
+	TeamName string
+}
+
+// WorkCalendars собирает рабочие календари для расчёта сроков, возраста
+// ревью и аналитики. Nil *WorkCalendars и пользователь без расписания
+// и праздников дают nil-календарь — время считается по часам на стене.
+type WorkCalendars struct {
+	scheduleRepo repository.ScheduleRepository
+}
+
+func NewWorkCalendars(scheduleRepo repository.ScheduleRepository) *WorkCalendars {
+	return &WorkCalendars{scheduleRepo: scheduleRepo}
+}
+
+// Load строит календари для keys двумя запросами: за расписаниями и праздниками
+func (c *WorkCalendars) Load(keys []CalendarKey) (map[CalendarKey]*workhours.Calendar, error) {
+	calendars := make(map[CalendarKey]*workhours.Calendar, len(keys))
+	if c == nil || len(keys) == 0 {
+		return calendars, nil
+	}
+
+	userIDs := make([]string, 0, len(keys))
+	teamNames := make([]string, 0, len(keys))
+	seenUsers := make(map[string]bool)
+	seenTeams := make(map[string]bool)
+	for _, k := range keys {
+		if !seenUsers[k.UserID] {
+			seenUsers[k.UserID] = true
+			userIDs = append(userIDs, k.UserID)
+		}
+		if k.TeamName != "" && !seenTeams[k.TeamName] {
+			seenTeams[k.TeamName] = true
+			teamNames = append(teamNames, k.TeamName)
+		}
+	}
+
+	schedules, err := c.scheduleRepo.ListWorkingHours(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := c.scheduleRepo.ListHolidays(teamNames)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if _, done := calendars[k]; done {
+			continue
+		}
+		cal, err := workhours.New(schedules[k.UserID], holidays[k.TeamName])
+		if err != nil {
+			return nil, fmt.Errorf("calendar of user %s: %w", k.UserID, err)
+		}
+		calendars[k] = cal
+	}
+	return calendars, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+// ReviewNotifier доставляет напоминания о просроченных ревью. Если доставка
+// не удалась, назначение не отмечается напомненным и попытка повторится
+// при следующей проверке.
+type ReviewNotifier interface {
+	NotifyOverdue(ctx context.Context, review models.OverdueReview) error
+}
+
+// LogNotifier пишет напоминания в журнал сервиса; используется, пока не
+// подключён другой канал доставки.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) NotifyOverdue(ctx context.Context, review models.OverdueReview) error {
+	n.logger.WarnContext(ctx, "review is overdue",
+		"pr_id", review.PullRequestID,
+		"reviewer_id", review.ReviewerID,
+		"team_name", review.TeamName,
+		"due_at", review.DueAt,
+		"overdue_seconds", review.OverdueSeconds)
+	return nil
+}
+
+// ReviewerReassigner — часть PullRequestService, через которую SLAService
+// заменяет ревьювера после второго срока.
+type ReviewerReassigner interface {
+	ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error)
+}
+
+// SLACheckResult — итог одной проверки сроков ревью
+type SLACheckResult struct {
+	Reminded   int
+	Reassigned int
+}
+
+type SLAService struct {
+	slaRepo    repository.SLARepository
+	calendars  *WorkCalendars
+	reassigner ReviewerReassigner
+	notifier   ReviewNotifier
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// NewSLAService создаёт сервис сроков ревью. Сроки считаются в рабочем
+// времени ревьювера по calendars; nil — в календарных часах.
+func NewSLAService(slaRepo repository.SLARepository, calendars *WorkCalendars, reassigner ReviewerReassigner, notifier ReviewNotifier, logger *slog.Logger) *SLAService {
+	return &SLAService{
+		slaRepo:    slaRepo,
+		calendars:  calendars,
+		reassigner: reassigner,
+		notifier:   notifier,
+		logger:     logger,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetTeamSLA задаёт срок первого ревью команды. Срок автоматической замены,
+// если указан, должен быть больше срока напоминания.
+func (s *SLAService) SetTeamSLA(ctx context.Context, sla *models.TeamSLA) (*models.TeamSLA, error) {
+	s.logger.InfoContext(ctx, "setting review SLA", "team_name", sla.TeamName, "first_review_hours", sla.FirstReviewHours)
+
+	if sla.FirstReviewHours <= 0 {
+		return nil, ErrInvalidSLA
+	}
+	if sla.ReassignAfterHours != nil && *sla.ReassignAfterHours <= sla.FirstReviewHours {
+		return nil, ErrInvalidSLA
+	}
+
+	if err := s.slaRepo.Set(sla); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "team not found", "team_name", sla.TeamName)
+			return nil, ErrTeamNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to set review SLA", "error", err, "team_name", sla.TeamName)
+		return nil, err
+	}
+	return s.GetTeamSLA(ctx, sla.TeamName)
+}
+
+func (s *SLAService) GetTeamSLA(ctx context.Context, teamName string) (*models.TeamSLA, error) {
+	sla, err := s.slaRepo.Get(teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSLANotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get review SLA", "error", err, "team_name", teamName)
+		return nil, err
+	}
+	return sla, nil
+}
+
+// ListOverdue возвращает просроченные назначения, самые старые первыми;
+// пустой teamName — по всем командам со сроком.
+func (s *SLAService) ListOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	pending, err := s.slaRepo.ListPending(teamName)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list pending reviews", "error", err, "team_name", teamName)
+		return nil, err
+	}
+	overdue, err := s.overdue(pending, s.now())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to compute review deadlines", "error", err, "team_name", teamName)
+		return nil, err
+	}
+	return overdue, nil
+}
+
+// CheckOverdue напоминает о каждом просроченном назначении один раз,
+// а после срока ReassignAfterHours заменяет ревьювера через ReassignReviewer.
+// Если заменить некем, напоминание всё равно отправляется.
+func (s *SLAService) CheckOverdue(ctx context.Context) (SLACheckResult, error) {
+	var result SLACheckResult
+
+	pending, err := s.slaRepo.ListPending("")
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list pending reviews", "error", err)
+		return result, err
+	}
+	now := s.now()
+	overdue, err := s.overdue(pending, now)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to compute review deadlines", "error", err)
+		return result, err
+	}
+
+	for _, review := range overdue {
+		if review.ReassignAt != nil && !now.Before(*review.ReassignAt) {
+			_, newReviewerID, err := s.reassigner.ReassignReviewer(ctx, review.PullRequestID, review.ReviewerID)
+			switch {
+			case err == nil:
+				s.logger.InfoContext(ctx, "overdue reviewer reassigned",
+					"pr_id", review.PullRequestID, "old_user_id", review.ReviewerID, "new_user_id", newReviewerID)
+				result.Reassigned++
+				continue
+			case errors.Is(err, ErrPRMerged), errors.Is(err, ErrNotAssigned), errors.Is(err, ErrPRNotFound):
+				// PR изменился после выборки — напоминать больше не о чем
+				continue
+			case errors.Is(err, ErrNoCandidate):
+				s.logger.WarnContext(ctx, "no candidate to replace overdue reviewer", "pr_id", review.PullRequestID, "user_id", review.ReviewerID)
+			default:
+				return result, err
+			}
+		}
+
+		if review.RemindedAt != nil {
+			continue
+		}
+		if err := s.notifier.NotifyOverdue(ctx, review); err != nil {
+			s.logger.ErrorContext(ctx, "failed to send overdue review reminder", "error", err, "pr_id", review.PullRequestID, "user_id", review.ReviewerID)
+			continue
+		}
+		if err := s.slaRepo.MarkReminded(review.PullRequestID, review.ReviewerID, now); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			s.logger.ErrorContext(ctx, "failed to mark review reminded", "error", err, "pr_id", review.PullRequestID, "user_id", review.ReviewerID)
+			return result, err
+		}
+		result.Reminded++
+	}
+	return result, nil
+}
+
+// overdue отбирает назначения с истёкшим сроком. Сроки и просрочка
+// считаются в рабочем времени ревьювера с праздниками команды автора.
+func (s *SLAService) overdue(pending []repository.PendingReview, now time.Time) ([]models.OverdueReview, error) {
+	keys := make([]CalendarKey, 0, len(pending))
+	for _, p := range pending {
+		keys = append(keys, CalendarKey{UserID: p.ReviewerID, TeamName: p.SLA.TeamName})
+	}
+	calendars, err := s.calendars.Load(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := make([]models.OverdueReview, 0)
+	for _, p := range pending {
+		cal := calendars[CalendarKey{UserID: p.ReviewerID, TeamName: p.SLA.TeamName}]
+		dueAt := cal.Add(p.AssignedAt, time.Duration(p.SLA.FirstReviewHours)*time.Hour)
+		if now.Before(dueAt) {
+			continue
+		}
+		review := models.OverdueReview{
+			PullRequestID:   p.PullRequestID,
+			PullRequestName: p.PullRequestName,
+			AuthorID:        p.AuthorID,
+			TeamName:        p.SLA.TeamName,
+			ReviewerID:      p.ReviewerID,
+			AssignedAt:      p.AssignedAt,
+			DueAt:           dueAt,
+			OverdueSeconds:  int64(cal.Duration(dueAt, now) / time.Second),
+			RemindedAt:      p.RemindedAt,
+		}
+		if p.SLA.ReassignAfterHours != nil {
+			reassignAt := cal.Add(p.AssignedAt, time.Duration(*p.SLA.ReassignAfterHours)*time.Hour)
+			review.ReassignAt = &reassignAt
+		}
+		overdue = append(overdue, review)
+	}
+	return overdue, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type mockSLARepository struct {
+	slas     map[string]*models.TeamSLA
+	pending  []repository.PendingReview
+	reminded []string
+}
+
+func (m *mockSLARepository) Set(sla *models.TeamSLA) error {
+	m.slas[sla.TeamName] = sla
+	return nil
+}
+
+func (m *mockSLARepository) Get(teamName string) (*models.TeamSLA, error) {
+	sla, ok := m.slas[teamName]
+	if !ok {
+		return nil, errors.New("unexpected team " + teamName)
+	}
+	return sla, nil
+}
+
+func (m *mockSLARepository) ListPending(teamName string) ([]repository.PendingReview, error) {
+	return m.pending, nil
+}
+
+func (m *mockSLARepository) MarkReminded(prID, reviewerID string, at time.Time) error {
+	m.reminded = append(m.reminded, prID+"/"+reviewerID)
+	return nil
+}
+
+type mockReassigner struct {
+	err        error
+	reassigned []string
+}
+
+func (m *mockReassigner) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
+	if m.err != nil {
+		return nil, "", m.err
+	}
+	m.reassigned = append(m.reassigned, prID+"/"+oldUserID)
+	return &models.PullRequest{PullRequestID: prID}, "u9", nil
+}
+
+type mockNotifier struct {
+	notified []string
+}
+
+func (m *mockNotifier) NotifyOverdue(ctx context.Context, review models.OverdueReview) error {
+	m.notified = append(m.notified, review.PullRequestID+"/"+review.ReviewerID)
+	return nil
+}
+
+func TestSLAService_CheckOverdue(t *testing.T) {
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	reassignAfter := 48
+	sla := models.TeamSLA{TeamName: "backend", FirstReviewHours: 24, ReassignAfterHours: &reassignAfter}
+	reminded := now.Add(-time.Hour)
+	pending := []repository.PendingReview{
+		// Срок ещё не истёк
+		{PullRequestID: "pr-1", ReviewerID: "u1", AssignedAt: now.Add(-time.Hour), SLA: sla},
+		// Просрочено, напоминания не было
+		{PullRequestID: "pr-2", ReviewerID: "u2", AssignedAt: now.Add(-30 * time.Hour), SLA: sla},
+		// Просрочено, напоминание уже отправлено
+		{PullRequestID: "pr-3", ReviewerID: "u3", AssignedAt: now.Add(-30 * time.Hour), RemindedAt: &reminded, SLA: sla},
+		// Истёк срок замены
+		{PullRequestID: "pr-4", ReviewerID: "u4", AssignedAt: now.Add(-50 * time.Hour), RemindedAt: &reminded, SLA: sla},
+	}
+
+	tests := []struct {
+		name           string
+		reassignErr    error
+		wantResult     SLACheckResult
+		wantNotified   []string
+		wantReassigned []string
+	}{
+		{
+			name:           "reminds once and reassigns after second threshold",
+			wantResult:     SLACheckResult{Reminded: 1, Reassigned: 1},
+			wantNotified:   []string{"pr-2/u2"},
+			wantReassigned: []string{"pr-4/u4"},
+		},
+		{
+			name:         "keeps reviewer when no candidate",
+			reassignErr:  ErrNoCandidate,
+			wantResult:   SLACheckResult{Reminded: 1},
+			wantNotified: []string{"pr-2/u2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSLARepository{pending: pending}
+			reassigner := &mockReassigner{err: tt.reassignErr}
+			notifier := &mockNotifier{}
+			s := NewSLAService(repo, nil, reassigner, notifier, setupTestLogger())
+			s.now = func() time.Time { return now }
+
+			result, err := s.CheckOverdue(context.Background())
+			if err != nil {
+				t.Fatalf("CheckOverdue() error = %v", err)
+			}
+			if result != tt.wantResult {
+				t.Errorf("result = %+v, want %+v", result, tt.wantResult)
+			}
+			if !reflect.DeepEqual(notifier.notified, tt.wantNotified) {
+				t.Errorf("notified = %v, want %v", notifier.notified, tt.wantNotified)
+			}
+			if !reflect.DeepEqual(repo.reminded, tt.wantNotified) {
+				t.Errorf("marked reminded = %v, want %v", repo.reminded, tt.wantNotified)
+			}
+			if !reflect.DeepEqual(reassigner.reassigned, tt.wantReassigned) {
+				t.Errorf("reassigned = %v, want %v", reassigner.reassigned, tt.wantReassigned)
+			}
+		})
+	}
+}
+
+func TestSLAService_ListOverdue(t *testing.T) {
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	sla := models.TeamSLA{TeamName: "backend", FirstReviewHours: 24}
+	repo := &mockSLARepository{pending: []repository.PendingReview{
+		{PullRequestID: "pr-1", ReviewerID: "u1", AssignedAt: now.Add(-23 * time.Hour), SLA: sla},
+		{PullRequestID: "pr-2", ReviewerID: "u2", AssignedAt: now.Add(-26 * time.Hour), SLA: sla},
+	}}
+	s := NewSLAService(repo, nil, &mockReassigner{}, &mockNotifier{}, setupTestLogger())
+	s.now = func() time.Time { return now }
+
+	overdue, err := s.ListOverdue(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListOverdue() error = %v", err)
+	}
+	if len(overdue) != 1 {
+		t.Fatalf("expected one overdue review, got %+v", overdue)
+	}
+	got := overdue[0]
+	if got.PullRequestID != "pr-2" || !got.DueAt.Equal(now.Add(-2*time.Hour)) || got.OverdueSeconds != 7200 || got.ReassignAt != nil {
+		t.Errorf("unexpected overdue review: %+v", got)
+	}
+}
+
+func TestSLAService_ListOverdue_WorkingHours(t *testing.T) {
+	// Среда 12:00; ревьювер работает по будням 09:00–17:00 UTC
+	now := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	var periods []models.WorkingPeriod
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday"} {
+		periods = append(periods, models.WorkingPeriod{Weekday: day, Start: "09:00", End: "17:00"})
+	}
+	schedules := &mockScheduleRepository{
+		schedules: map[string]*models.WorkingHours{"u1": {TimeZone: "UTC", Periods: periods}},
+		holidays:  map[string][]models.Holiday{},
+	}
+	repo := &mockSLARepository{pending: []repository.PendingReview{
+		{PullRequestID: "pr-1", ReviewerID: "u1", AssignedAt: time.Date(2025, 10, 20, 16, 0, 0, 0, time.UTC),
+			SLA: models.TeamSLA{TeamName: "backend", FirstReviewHours: 8}},
+	}}
+	s := NewSLAService(repo, NewWorkCalendars(schedules), &mockReassigner{}, &mockNotifier{}, setupTestLogger())
+	s.now = func() time.Time { return now }
+
+	// Час в понедельник и семь во вторник: срок — вторник 16:00
+	overdue, err := s.ListOverdue(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListOverdue() error = %v", err)
+	}
+	if len(overdue) != 1 {
+		t.Fatalf("expected one overdue review, got %+v", overdue)
+	}
+	wantDue := time.Date(2025, 10, 21, 16, 0, 0, 0, time.UTC)
+	if got := overdue[0]; !got.DueAt.Equal(wantDue) || got.OverdueSeconds != 4*3600 {
+		t.Errorf("expected due %v and 4h overdue, got %+v", wantDue, got)
+	}
+
+	// Вторник — праздник команды: срок сдвигается на среду 16:00
+	schedules.holidays["backend"] = []models.Holiday{{Date: "2025-10-21"}}
+	overdue, err = s.ListOverdue(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListOverdue() error = %v", err)
+	}
+	if len(overdue) != 0 {
+		t.Errorf("expected no overdue reviews over the holiday, got %+v", overdue)
+	}
+}
+
+func TestSLAService_SetTeamSLA(t *testing.T) {
+	s := NewSLAService(&mockSLARepository{slas: map[string]*models.TeamSLA{}}, nil, &mockReassigner{}, &mockNotifier{}, setupTestLogger())
+	early := 12
+
+	for _, sla := range []models.TeamSLA{
+		{TeamName: "backend", FirstReviewHours: 0},
+		{TeamName: "backend", FirstReviewHours: 24, ReassignAfterHours: &early},
+	} {
+		if _, err := s.SetTeamSLA(context.Background(), &sla); !errors.Is(err, ErrInvalidSLA) {
+			t.Errorf("SetTeamSLA(%+v) error = %v, want ErrInvalidSLA", sla, err)
+		}
+	}
+
+	got, err := s.SetTeamSLA(context.Background(), &models.TeamSLA{TeamName: "backend", FirstReviewHours: 8})
+	if err != nil || got.FirstReviewHours != 8 {
+		t.Errorf("SetTeamSLA() = %+v, %v", got, err)
+	}
+}
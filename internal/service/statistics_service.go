@@ -2,7 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
 	"github.com/reviewer-service/internal/repository"
@@ -10,24 +16,113 @@ import (
 
 type StatisticsService struct {
 	statsRepo repository.StatisticsRepository
+	cache     *StatisticsCache
+	calendars *WorkCalendars
 	logger    *slog.Logger
 }
 
-func NewStatisticsService(statsRepo repository.StatisticsRepository, logger *slog.Logger) *StatisticsService {
+// NewStatisticsService создаёт сервис статистики; cache может быть nil —
+// тогда /statistics считается на каждый запрос. calendars нужны для
+// /statistics/timings в рабочих часах.
+func NewStatisticsService(statsRepo repository.StatisticsRepository, cache *StatisticsCache, calendars *WorkCalendars, logger *slog.Logger) *StatisticsService {
 	return &StatisticsService{
 		statsRepo: statsRepo,
+		cache:     cache,
+		calendars: calendars,
 		logger:    logger,
 	}
 }
 
-func (s *StatisticsService) GetStatistics(ctx context.Context) (*models.Statistics, error) {
-	s.logger.DebugContext(ctx, "fetching statistics")
+// StatisticsCache хранит последний снимок /statistics не дольше TTL.
+// Снимок, посчитанный до Invalidate, не сохраняется: иначе запрос,
+// начатый до изменения, вернул бы в кэш устаревшие данные.
+// Кэш живёт в памяти процесса и сбрасывается только его собственными
+// изменениями: записи других экземпляров в ту же базу станут видны
+// лишь по истечении TTL, поэтому TTL стоит держать коротким.
+type StatisticsCache struct {
+	ttl        time.Duration
+	mu         sync.Mutex
+	stats      *models.Statistics
+	expiresAt  time.Time
+	generation uint64
+}
+
+// NewStatisticsCache возвращает nil при ttl <= 0, то есть кэш выключен
+func NewStatisticsCache(ttl time.Duration) *StatisticsCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &StatisticsCache{ttl: ttl}
+}
+
+// Invalidate сбрасывает снимок после изменения команд, пользователей или PR
+func (c *StatisticsCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = nil
+	c.generation++
+}
+
+func (c *StatisticsCache) get(now time.Time) (*models.Statistics, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats != nil && now.Before(c.expiresAt) {
+		return c.stats, c.generation
+	}
+	return nil, c.generation
+}
+
+func (c *StatisticsCache) put(stats *models.Statistics, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.stats = stats
+	c.expiresAt = now.Add(c.ttl)
+}
+
+// StatisticsQuery ограничивает раздел load PR, созданными в [From, To)
+type StatisticsQuery struct {
+	From *time.Time
+	To   *time.Time
+}
 
-	stats, err := s.statsRepo.GetStatistics()
+// GetStatistics возвращает снимок статистики вместе с нагрузкой по командам
+// и ревьюверам. Из кэша отдаётся общий экземпляр, изменять его нельзя;
+// запросы с границами периода кэш не используют.
+func (s *StatisticsService) GetStatistics(ctx context.Context, q StatisticsQuery) (*models.Statistics, error) {
+	s.logger.DebugContext(ctx, "fetching statistics", "from", q.From, "to", q.To)
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidDateRange)
+	}
+	cacheable := s.cache != nil && q.From == nil && q.To == nil
+
+	var generation uint64
+	if cacheable {
+		var cached *models.Statistics
+		cached, generation = s.cache.get(time.Now())
+		if cached != nil {
+			s.logger.DebugContext(ctx, "statistics served from cache", "generated_at", cached.GeneratedAt)
+			return cached, nil
+		}
+	}
+
+	generatedAt := time.Now().UTC()
+	stats, loads, err := s.statsRepo.GetStatistics(q.From, q.To)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to get statistics", "error", err)
 		return nil, err
 	}
+	stats.GeneratedAt = generatedAt
+	stats.Load = reviewLoad(loads, q)
+	if cacheable {
+		s.cache.put(stats, generation, time.Now())
+	}
 
 	s.logger.DebugContext(ctx, "statistics fetched successfully",
 		"teams", stats.Teams.Total,
@@ -38,3 +133,384 @@ func (s *StatisticsService) GetStatistics(ctx context.Context) (*models.Statisti
 
 	return stats, nil
 }
+
+// reviewLoad суммирует нагрузку ревьюверов по их командам
+func reviewLoad(loads []models.ReviewerLoad, q StatisticsQuery) *models.ReviewLoad {
+	load := &models.ReviewLoad{
+		ByTeam:     make([]models.TeamLoad, 0),
+		ByReviewer: loads,
+	}
+	if q.From != nil {
+		from := q.From.UTC()
+		load.From = &from
+	}
+	if q.To != nil {
+		to := q.To.UTC()
+		load.To = &to
+	}
+
+	byTeam := make(map[string]*models.TeamLoad)
+	for _, l := range loads {
+		if l.TeamName == "" {
+			continue
+		}
+		team, ok := byTeam[l.TeamName]
+		if !ok {
+			team = &models.TeamLoad{TeamName: l.TeamName}
+			byTeam[l.TeamName] = team
+		}
+		team.OpenAssignments += l.OpenAssignments
+		team.TotalAssignments += l.TotalAssignments
+	}
+	for _, team := range byTeam {
+		load.ByTeam = append(load.ByTeam, *team)
+	}
+	sort.Slice(load.ByTeam, func(i, j int) bool { return load.ByTeam[i].TeamName < load.ByTeam[j].TeamName })
+	return load
+}
+
+// MaxTimingBuckets ограничивает число интервалов в /statistics/timings
+const MaxTimingBuckets = 366
+
+type ReviewTimingsQuery struct {
+	From   time.Time
+	To     time.Time
+	Bucket string
+	// WorkingHours считает длительности в рабочем времени: до merge и до
+	// первого ревью — автора PR, от назначения до ревью — ревьювера
+	WorkingHours bool
+}
+
+// GetReviewTimings считает перцентили времени до merge и до первого ревью
+// за [From, To). По команде замеры группируются по команде автора PR;
+// по ревьюверу time_to_merge — PR, где он назначен, а time_to_first_review —
+// время от его назначения до его ревью.
+func (s *StatisticsService) GetReviewTimings(ctx context.Context, q ReviewTimingsQuery) (*models.ReviewTimings, error) {
+	s.logger.DebugContext(ctx, "fetching review timings", "from", q.From, "to", q.To, "bucket", q.Bucket)
+
+	from, to := q.From.UTC(), q.To.UTC()
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidDateRange)
+	}
+	if q.Bucket == "" {
+		q.Bucket = models.TimingBucketDay
+	}
+	if q.Bucket != models.TimingBucketDay && q.Bucket != models.TimingBucketWeek {
+		return nil, fmt.Errorf("%w: bucket must be day or week", ErrInvalidDateRange)
+	}
+
+	starts := timingBucketStarts(from, to, q.Bucket)
+	if len(starts) > MaxTimingBuckets {
+		return nil, fmt.Errorf("%w: range spans more than %d buckets", ErrInvalidDateRange, MaxTimingBuckets)
+	}
+
+	samples, err := s.statsRepo.GetTimingSamples(from, to)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get timing samples", "error", err)
+		return nil, err
+	}
+	if q.WorkingHours {
+		if err := s.toWorkingTime(samples); err != nil {
+			s.logger.ErrorContext(ctx, "failed to convert timings to working hours", "error", err)
+			return nil, err
+		}
+	}
+
+	result := &models.ReviewTimings{
+		From:         from,
+		To:           to,
+		Bucket:       q.Bucket,
+		WorkingHours: q.WorkingHours,
+		Total:        timingBreakdown(samples),
+		Buckets:      make([]models.TimingBucket, 0, len(starts)),
+	}
+
+	bucketed := make(map[time.Time]*repository.TimingSamples, len(starts))
+	for _, start := range starts {
+		bucketed[start] = &repository.TimingSamples{}
+	}
+	bucketOf := func(sample repository.TimingSample) *repository.TimingSamples {
+		return bucketed[timingBucketStart(sample.At, q.Bucket)]
+	}
+	for _, sample := range samples.Merge {
+		b := bucketOf(sample)
+		b.Merge = append(b.Merge, sample)
+	}
+	for _, sample := range samples.FirstReview {
+		b := bucketOf(sample)
+		b.FirstReview = append(b.FirstReview, sample)
+	}
+	for _, sample := range samples.ReviewerResponse {
+		b := bucketOf(sample)
+		b.ReviewerResponse = append(b.ReviewerResponse, sample)
+	}
+	for _, start := range starts {
+		result.Buckets = append(result.Buckets, models.TimingBucket{
+			Start:           start,
+			TimingBreakdown: timingBreakdown(bucketed[start]),
+		})
+	}
+
+	s.logger.DebugContext(ctx, "review timings fetched successfully",
+		"merged", len(samples.Merge),
+		"first_reviews", len(samples.FirstReview),
+		"buckets", len(result.Buckets),
+	)
+
+	return result, nil
+}
+
+// timingBucketStart возвращает начало интервала, в который попадает t:
+// полночь UTC или полночь UTC ближайшего понедельника не позже t
+func timingBucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == models.TimingBucketWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// toWorkingTime пересчитывает длительности замеров в рабочее время
+// с праздниками команды автора PR
+func (s *StatisticsService) toWorkingTime(samples *repository.TimingSamples) error {
+	key := func(sample repository.TimingSample, perReviewer bool) CalendarKey {
+		if perReviewer {
+			return CalendarKey{UserID: sample.ReviewerIDs[0], TeamName: sample.TeamName}
+		}
+		return CalendarKey{UserID: sample.AuthorID, TeamName: sample.TeamName}
+	}
+	groups := []struct {
+		samples     []repository.TimingSample
+		perReviewer bool
+	}{
+		{samples.Merge, false},
+		{samples.FirstReview, false},
+		{samples.ReviewerResponse, true},
+	}
+
+	var keys []CalendarKey
+	for _, g := range groups {
+		for _, sample := range g.samples {
+			keys = append(keys, key(sample, g.perReviewer))
+		}
+	}
+	calendars, err := s.calendars.Load(keys)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		for i := range g.samples {
+			sample := &g.samples[i]
+			cal := calendars[key(*sample, g.perReviewer)]
+			sample.Duration = cal.Duration(sample.At.Add(-sample.Duration), sample.At)
+		}
+	}
+	return nil
+}
+
+func timingBucketStarts(from, to time.Time, bucket string) []time.Time {
+	step := 1
+	if bucket == models.TimingBucketWeek {
+		step = 7
+	}
+	starts := make([]time.Time, 0)
+	for start := timingBucketStart(from, bucket); start.Before(to); start = start.AddDate(0, 0, step) {
+		starts = append(starts, start)
+		if len(starts) > MaxTimingBuckets {
+			break
+		}
+	}
+	return starts
+}
+
+func timingBreakdown(samples *repository.TimingSamples) models.TimingBreakdown {
+	type durations struct {
+		merge, firstReview []time.Duration
+	}
+	byTeam := make(map[string]*durations)
+	byReviewer := make(map[string]*durations)
+	get := func(m map[string]*durations, key string) *durations {
+		d, ok := m[key]
+		if !ok {
+			d = &durations{}
+			m[key] = d
+		}
+		return d
+	}
+
+	var overall durations
+	for _, sample := range samples.Merge {
+		overall.merge = append(overall.merge, sample.Duration)
+		if sample.TeamName != "" {
+			d := get(byTeam, sample.TeamName)
+			d.merge = append(d.merge, sample.Duration)
+		}
+		for _, reviewerID := range sample.ReviewerIDs {
+			d := get(byReviewer, reviewerID)
+			d.merge = append(d.merge, sample.Duration)
+		}
+	}
+	for _, sample := range samples.FirstReview {
+		overall.firstReview = append(overall.firstReview, sample.Duration)
+		if sample.TeamName != "" {
+			d := get(byTeam, sample.TeamName)
+			d.firstReview = append(d.firstReview, sample.Duration)
+		}
+	}
+	for _, sample := range samples.ReviewerResponse {
+		for _, reviewerID := range sample.ReviewerIDs {
+			d := get(byReviewer, reviewerID)
+			d.firstReview = append(d.firstReview, sample.Duration)
+		}
+	}
+
+	summary := func(d *durations) models.TimingSummary {
+		return models.TimingSummary{
+			TimeToMerge:       percentiles(d.merge),
+			TimeToFirstReview: percentiles(d.firstReview),
+		}
+	}
+
+	breakdown := models.TimingBreakdown{
+		Overall:    summary(&overall),
+		ByTeam:     make([]models.TeamTimings, 0, len(byTeam)),
+		ByReviewer: make([]models.ReviewerTimings, 0, len(byReviewer)),
+	}
+	for teamName, d := range byTeam {
+		breakdown.ByTeam = append(breakdown.ByTeam, models.TeamTimings{TeamName: teamName, TimingSummary: summary(d)})
+	}
+	for userID, d := range byReviewer {
+		breakdown.ByReviewer = append(breakdown.ByReviewer, models.ReviewerTimings{UserID: userID, TimingSummary: summary(d)})
+	}
+	sort.Slice(breakdown.ByTeam, func(i, j int) bool {
+		return breakdown.ByTeam[i].TeamName < breakdown.ByTeam[j].TeamName
+	})
+	sort.Slice(breakdown.ByReviewer, func(i, j int) bool {
+		return breakdown.ByReviewer[i].UserID < breakdown.ByReviewer[j].UserID
+	})
+	return breakdown
+}
+
+// percentiles считает p50/p90/p99 методом ближайшего ранга
+func percentiles(values []time.Duration) models.Percentiles {
+	p := models.Percentiles{Count: len(values)}
+	if len(values) == 0 {
+		return p
+	}
+
+	seconds := make([]int64, len(values))
+	for i, v := range values {
+		seconds[i] = int64(v / time.Second)
+	}
+	sort.Slice(seconds, func(i, j int) bool { return seconds[i] < seconds[j] })
+
+	rank := func(pct int) *int64 {
+		idx := (pct*len(seconds)+99)/100 - 1
+		if idx < 0 {
+			idx = 0
+		}
+		v := seconds[idx]
+		return &v
+	}
+	p.P50, p.P90, p.P99 = rank(50), rank(90), rank(99)
+	return p
+}
+
+// LoadTolerance — допустимое отклонение открытых назначений от среднего по
+// команде: больше mean*(1+LoadTolerance) — перегружен, меньше mean*(1-LoadTolerance) — недогружен
+const LoadTolerance = 0.5
+
+// GetTeamStatistics возвращает нагрузку участников команды и индекс
+// равномерности открытых назначений. Неактивные участники в индекс и
+// среднее не входят: ревью им не назначаются.
+func (s *StatisticsService) GetTeamStatistics(ctx context.Context, teamName string) (*models.TeamStatistics, error) {
+	s.logger.DebugContext(ctx, "fetching team statistics", "team_name", teamName)
+
+	members, err := s.statsRepo.GetTeamLoad(teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "team not found", "team_name", teamName)
+			return nil, ErrTeamNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get team load", "error", err, "team_name", teamName)
+		return nil, err
+	}
+
+	stats := &models.TeamStatistics{TeamName: teamName, Members: members}
+	open := make([]int, 0, len(members))
+	for _, m := range members {
+		stats.OpenAssignments += m.OpenAssignments
+		stats.TotalAssignments += m.TotalAssignments
+		if m.IsActive {
+			open = append(open, m.OpenAssignments)
+		}
+	}
+	stats.Fairness = fairness(open)
+
+	mean := stats.Fairness.MeanOpenAssignments
+	for i := range stats.Members {
+		m := &stats.Members[i]
+		switch {
+		case !m.IsActive:
+			m.Load = models.LoadInactive
+		case float64(m.OpenAssignments) > mean*(1+LoadTolerance):
+			m.Load = models.LoadOverloaded
+		case float64(m.OpenAssignments) < mean*(1-LoadTolerance):
+			m.Load = models.LoadUnderloaded
+		default:
+			m.Load = models.LoadBalanced
+		}
+	}
+
+	s.logger.DebugContext(ctx, "team statistics fetched successfully",
+		"team_name", teamName,
+		"members", len(members),
+		"gini", stats.Fairness.Gini,
+	)
+
+	return stats, nil
+}
+
+// fairness считает среднее, коэффициент Джини и отношение максимума к минимуму
+func fairness(values []int) models.TeamFairness {
+	var f models.TeamFairness
+	if len(values) == 0 {
+		return f
+	}
+
+	sum, minV, maxV := 0, values[0], values[0]
+	for _, v := range values {
+		sum += v
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+	}
+	n := float64(len(values))
+	f.MeanOpenAssignments = float64(sum) / n
+	if minV > 0 {
+		ratio := float64(maxV) / float64(minV)
+		f.MaxMinRatio = &ratio
+	}
+	if sum == 0 {
+		return f
+	}
+
+	var diff int
+	for _, a := range values {
+		for _, b := range values {
+			if a > b {
+				diff += a - b
+			} else {
+				diff += b - a
+			}
+		}
+	}
+	f.Gini = float64(diff) / (2 * n * float64(sum))
+	return f
+}
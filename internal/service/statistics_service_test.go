@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type mockStatisticsRepository struct {
+	samples  *repository.TimingSamples
+	from, to time.Time
+	teams    map[string][]models.MemberLoad
+	calls    int
+
+	reviewerLoad     []models.ReviewerLoad
+	loadFrom, loadTo *time.Time
+	// beforeReturn вызывается внутри GetStatistics, имитируя изменение во время подсчёта
+	beforeReturn func()
+}
+
+func (m *mockStatisticsRepository) GetStatistics(loadFrom, loadTo *time.Time) (*models.Statistics, []models.ReviewerLoad, error) {
+	m.calls++
+	m.loadFrom, m.loadTo = loadFrom, loadTo
+	if m.beforeReturn != nil {
+		m.beforeReturn()
+	}
+	stats := &models.Statistics{}
+	stats.PullRequests.Total = m.calls
+	return stats, m.reviewerLoad, nil
+}
+
+func (m *mockStatisticsRepository) GetTimingSamples(from, to time.Time) (*repository.TimingSamples, error) {
+	m.from, m.to = from, to
+	return m.samples, nil
+}
+
+func (m *mockStatisticsRepository) GetReviewerLoad(from, to *time.Time) ([]models.ReviewerLoad, error) {
+	m.loadFrom, m.loadTo = from, to
+	return m.reviewerLoad, nil
+}
+
+func (m *mockStatisticsRepository) GetTeamLoad(teamName string) ([]models.MemberLoad, error) {
+	members, ok := m.teams[teamName]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return members, nil
+}
+
+func int64Value(p *int64) int64 {
+	if p == nil {
+		return -1
+	}
+	return *p
+}
+
+func TestPercentiles(t *testing.T) {
+	if p := percentiles(nil); p.Count != 0 || p.P50 != nil || p.P90 != nil || p.P99 != nil {
+		t.Errorf("expected empty percentiles, got %+v", p)
+	}
+
+	values := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, time.Duration(i)*time.Second)
+	}
+	p := percentiles(values)
+	if p.Count != 100 || int64Value(p.P50) != 50 || int64Value(p.P90) != 90 || int64Value(p.P99) != 99 {
+		t.Errorf("unexpected percentiles: count=%d p50=%d p90=%d p99=%d",
+			p.Count, int64Value(p.P50), int64Value(p.P90), int64Value(p.P99))
+	}
+
+	p = percentiles([]time.Duration{3 * time.Second})
+	if int64Value(p.P50) != 3 || int64Value(p.P99) != 3 {
+		t.Errorf("expected single value for all percentiles, got p50=%d p99=%d", int64Value(p.P50), int64Value(p.P99))
+	}
+}
+
+func TestTimingBucketStart(t *testing.T) {
+	// 2025-10-23 — четверг
+	at := time.Date(2025, 10, 23, 15, 4, 5, 0, time.UTC)
+	if got := timingBucketStart(at, models.TimingBucketDay); !got.Equal(time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected day bucket: %v", got)
+	}
+	if got := timingBucketStart(at, models.TimingBucketWeek); !got.Equal(time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected week bucket: %v", got)
+	}
+	sunday := time.Date(2025, 10, 26, 23, 0, 0, 0, time.UTC)
+	if got := timingBucketStart(sunday, models.TimingBucketWeek); !got.Equal(time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected Sunday to belong to the week from Monday, got %v", got)
+	}
+}
+
+func TestStatisticsService_GetReviewTimings(t *testing.T) {
+	from := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	day1, day3 := from.Add(10*time.Hour), from.AddDate(0, 0, 2).Add(10*time.Hour)
+
+	repo := &mockStatisticsRepository{samples: &repository.TimingSamples{
+		Merge: []repository.TimingSample{
+			{PullRequestID: "pr-1", TeamName: "backend", ReviewerIDs: []string{"u2", "u3"}, At: day1, Duration: 4 * time.Hour},
+			{PullRequestID: "pr-2", TeamName: "frontend", ReviewerIDs: []string{"u2"}, At: day3, Duration: 8 * time.Hour},
+		},
+		FirstReview: []repository.TimingSample{
+			{PullRequestID: "pr-1", TeamName: "backend", At: day1, Duration: time.Hour},
+		},
+		ReviewerResponse: []repository.TimingSample{
+			{PullRequestID: "pr-1", TeamName: "backend", ReviewerIDs: []string{"u2"}, At: day1, Duration: 30 * time.Minute},
+		},
+	}}
+	service := NewStatisticsService(repo, nil, nil, setupTestLogger())
+
+	timings, err := service.GetReviewTimings(context.Background(), ReviewTimingsQuery{From: from, To: to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.from.Equal(from) || !repo.to.Equal(to) {
+		t.Errorf("expected repository range [%v, %v), got [%v, %v)", from, to, repo.from, repo.to)
+	}
+	if timings.Bucket != models.TimingBucketDay || len(timings.Buckets) != 3 {
+		t.Fatalf("expected 3 day buckets, got %q with %d", timings.Bucket, len(timings.Buckets))
+	}
+
+	total := timings.Total
+	if total.Overall.TimeToMerge.Count != 2 || int64Value(total.Overall.TimeToMerge.P50) != 4*3600 ||
+		int64Value(total.Overall.TimeToMerge.P99) != 8*3600 {
+		t.Errorf("unexpected overall time to merge: %+v", total.Overall.TimeToMerge)
+	}
+	if len(total.ByTeam) != 2 || total.ByTeam[0].TeamName != "backend" || total.ByTeam[0].TimeToFirstReview.Count != 1 ||
+		total.ByTeam[1].TeamName != "frontend" || total.ByTeam[1].TimeToFirstReview.Count != 0 {
+		t.Errorf("unexpected team timings: %+v", total.ByTeam)
+	}
+	if len(total.ByReviewer) != 2 || total.ByReviewer[0].UserID != "u2" || total.ByReviewer[0].TimeToMerge.Count != 2 ||
+		int64Value(total.ByReviewer[0].TimeToFirstReview.P50) != 1800 || total.ByReviewer[1].TimeToFirstReview.Count != 0 {
+		t.Errorf("unexpected reviewer timings: %+v", total.ByReviewer)
+	}
+
+	if got := timings.Buckets[1]; !got.Start.Equal(from.AddDate(0, 0, 1)) || got.Overall.TimeToMerge.Count != 0 || len(got.ByTeam) != 0 {
+		t.Errorf("expected empty second bucket, got %+v", got)
+	}
+	if got := timings.Buckets[2]; got.Overall.TimeToMerge.Count != 1 || len(got.ByReviewer) != 1 {
+		t.Errorf("unexpected third bucket: %+v", got)
+	}
+
+	weekly, err := service.GetReviewTimings(context.Background(), ReviewTimingsQuery{From: from, To: to, Bucket: models.TimingBucketWeek})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(weekly.Buckets) != 1 || weekly.Buckets[0].Overall.TimeToMerge.Count != 2 {
+		t.Errorf("expected a single week bucket with both merges, got %+v", weekly.Buckets)
+	}
+}
+
+func TestStatisticsService_GetReviewTimings_WorkingHours(t *testing.T) {
+	from := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	// PR создан в пятницу 17:00 и влит в понедельник 11:00
+	mergedAt := from.Add(11 * time.Hour)
+	repo := &mockStatisticsRepository{samples: &repository.TimingSamples{
+		Merge: []repository.TimingSample{
+			{PullRequestID: "pr-1", TeamName: "backend", AuthorID: "u1", ReviewerIDs: []string{"u2"}, At: mergedAt, Duration: 66 * time.Hour},
+		},
+		ReviewerResponse: []repository.TimingSample{
+			{PullRequestID: "pr-1", TeamName: "backend", ReviewerIDs: []string{"u2"}, At: mergedAt, Duration: 66 * time.Hour},
+		},
+	}}
+	schedules := &mockScheduleRepository{schedules: map[string]*models.WorkingHours{
+		"u1": {TimeZone: "UTC", Periods: []models.WorkingPeriod{
+			{Weekday: "friday", Start: "09:00", End: "18:00"},
+			{Weekday: "monday", Start: "09:00", End: "18:00"},
+		}},
+	}}
+	service := NewStatisticsService(repo, nil, NewWorkCalendars(schedules), setupTestLogger())
+
+	timings, err := service.GetReviewTimings(context.Background(), ReviewTimingsQuery{From: from, To: from.AddDate(0, 0, 1), WorkingHours: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !timings.WorkingHours {
+		t.Error("expected working_hours to be reported")
+	}
+	// Час в пятницу и два в понедельник
+	if got := int64Value(timings.Total.Overall.TimeToMerge.P50); got != 3*3600 {
+		t.Errorf("expected 3h working time to merge, got %d", got)
+	}
+	// У ревьювера без расписания время не меняется
+	if got := int64Value(timings.Total.ByReviewer[0].TimeToFirstReview.P50); got != 66*3600 {
+		t.Errorf("expected 66h reviewer response, got %d", got)
+	}
+}
+
+func TestStatisticsService_GetReviewTimings_InvalidRange(t *testing.T) {
+	service := NewStatisticsService(&mockStatisticsRepository{samples: &repository.TimingSamples{}}, nil, nil, setupTestLogger())
+	from := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	queries := []ReviewTimingsQuery{
+		{From: from, To: from},
+		{From: from, To: from.Add(-time.Hour)},
+		{From: from, To: from.Add(time.Hour), Bucket: "month"},
+		{From: from, To: from.AddDate(2, 0, 0)},
+	}
+	for _, q := range queries {
+		if _, err := service.GetReviewTimings(context.Background(), q); !errors.Is(err, ErrInvalidDateRange) {
+			t.Errorf("expected ErrInvalidDateRange for %+v, got %v", q, err)
+		}
+	}
+}
+
+func TestFairness(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		mean   float64
+		gini   float64
+		maxMin float64 // 0 — отношение не определено
+	}{
+		{name: "no members", values: nil},
+		{name: "nothing assigned", values: []int{0, 0, 0}},
+		{name: "equal load", values: []int{2, 2, 2}, mean: 2, gini: 0, maxMin: 1},
+		{name: "all on one member", values: []int{0, 0, 0, 4}, mean: 1, gini: 0.75},
+		{name: "uneven load", values: []int{1, 3}, mean: 2, gini: 0.25, maxMin: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fairness(tt.values)
+			if math.Abs(f.MeanOpenAssignments-tt.mean) > 1e-9 || math.Abs(f.Gini-tt.gini) > 1e-9 {
+				t.Errorf("expected mean %v and gini %v, got %+v", tt.mean, tt.gini, f)
+			}
+			if tt.maxMin == 0 && f.MaxMinRatio != nil {
+				t.Errorf("expected no max/min ratio, got %v", *f.MaxMinRatio)
+			}
+			if tt.maxMin != 0 && (f.MaxMinRatio == nil || math.Abs(*f.MaxMinRatio-tt.maxMin) > 1e-9) {
+				t.Errorf("expected max/min ratio %v, got %v", tt.maxMin, f.MaxMinRatio)
+			}
+		})
+	}
+}
+
+func TestStatisticsService_GetTeamStatistics(t *testing.T) {
+	repo := &mockStatisticsRepository{teams: map[string][]models.MemberLoad{
+		"backend": {
+			{UserID: "u1", IsActive: true, OpenAssignments: 6, TotalAssignments: 9, OpenAuthored: 1, TotalAuthored: 3},
+			{UserID: "u2", IsActive: true, OpenAssignments: 2, TotalAssignments: 4},
+			{UserID: "u3", IsActive: true, OpenAssignments: 1, TotalAssignments: 5},
+			{UserID: "u4", IsActive: false, OpenAssignments: 0, TotalAssignments: 2},
+		},
+	}}
+	service := NewStatisticsService(repo, nil, nil, setupTestLogger())
+
+	stats, err := service.GetTeamStatistics(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.OpenAssignments != 9 || stats.TotalAssignments != 20 {
+		t.Errorf("unexpected team totals: open=%d total=%d", stats.OpenAssignments, stats.TotalAssignments)
+	}
+	if stats.Fairness.MeanOpenAssignments != 3 || stats.Fairness.MaxMinRatio == nil || *stats.Fairness.MaxMinRatio != 6 {
+		t.Errorf("expected inactive member to be excluded from fairness, got %+v", stats.Fairness)
+	}
+
+	want := map[string]string{
+		"u1": models.LoadOverloaded,
+		"u2": models.LoadBalanced,
+		"u3": models.LoadUnderloaded,
+		"u4": models.LoadInactive,
+	}
+	for _, m := range stats.Members {
+		if m.Load != want[m.UserID] {
+			t.Errorf("expected %s to be %s, got %s", m.UserID, want[m.UserID], m.Load)
+		}
+	}
+
+	if _, err := service.GetTeamStatistics(context.Background(), "missing"); !errors.Is(err, ErrTeamNotFound) {
+		t.Errorf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestStatisticsService_GetStatisticsCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := &mockStatisticsRepository{}
+		service := NewStatisticsService(repo, NewStatisticsCache(0), nil, setupTestLogger())
+		for i := 0; i < 2; i++ {
+			stats, err := service.GetStatistics(ctx, StatisticsQuery{})
+			if err != nil || stats.GeneratedAt.IsZero() {
+				t.Fatalf("unexpected result: %+v, %v", stats, err)
+			}
+		}
+		if repo.calls != 2 {
+			t.Errorf("expected 2 repository calls without cache, got %d", repo.calls)
+		}
+	})
+
+	t.Run("hit and invalidate", func(t *testing.T) {
+		repo := &mockStatisticsRepository{}
+		cache := NewStatisticsCache(time.Hour)
+		service := NewStatisticsService(repo, cache, nil, setupTestLogger())
+
+		first, _ := service.GetStatistics(ctx, StatisticsQuery{})
+		second, _ := service.GetStatistics(ctx, StatisticsQuery{})
+		if repo.calls != 1 || second != first {
+			t.Fatalf("expected cached snapshot, got %d repository calls", repo.calls)
+		}
+
+		cache.Invalidate()
+		third, _ := service.GetStatistics(ctx, StatisticsQuery{})
+		if repo.calls != 2 || third.PullRequests.Total != 2 {
+			t.Errorf("expected recomputation after invalidation, got %d calls", repo.calls)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		repo := &mockStatisticsRepository{}
+		service := NewStatisticsService(repo, NewStatisticsCache(time.Nanosecond), nil, setupTestLogger())
+		service.GetStatistics(ctx, StatisticsQuery{})
+		time.Sleep(time.Millisecond)
+		service.GetStatistics(ctx, StatisticsQuery{})
+		if repo.calls != 2 {
+			t.Errorf("expected expired snapshot to be recomputed, got %d calls", repo.calls)
+		}
+	})
+
+	t.Run("invalidated while computing", func(t *testing.T) {
+		repo := &mockStatisticsRepository{}
+		cache := NewStatisticsCache(time.Hour)
+		repo.beforeReturn = func() {
+			repo.beforeReturn = nil
+			cache.Invalidate()
+		}
+		service := NewStatisticsService(repo, cache, nil, setupTestLogger())
+
+		service.GetStatistics(ctx, StatisticsQuery{})
+		service.GetStatistics(ctx, StatisticsQuery{})
+		if repo.calls != 2 {
+			t.Errorf("expected snapshot computed before invalidation not to be cached, got %d calls", repo.calls)
+		}
+	})
+}
+
+func TestStatisticsService_GetStatisticsLoad(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockStatisticsRepository{reviewerLoad: []models.ReviewerLoad{
+		{UserID: "f1", TeamName: "frontend", OpenAssignments: 1, TotalAssignments: 1},
+		{UserID: "u1", TeamName: "backend", OpenAssignments: 2, TotalAssignments: 5},
+		{UserID: "u2", TeamName: "backend", OpenAssignments: 1, TotalAssignments: 2},
+		{UserID: "x1", OpenAssignments: 0, TotalAssignments: 3},
+	}}
+	cache := NewStatisticsCache(time.Hour)
+	service := NewStatisticsService(repo, cache, nil, setupTestLogger())
+
+	stats, err := service.GetStatistics(ctx, StatisticsQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []models.TeamLoad{
+		{TeamName: "backend", OpenAssignments: 3, TotalAssignments: 7},
+		{TeamName: "frontend", OpenAssignments: 1, TotalAssignments: 1},
+	}
+	if stats.Load == nil || !reflect.DeepEqual(stats.Load.ByTeam, want) || len(stats.Load.ByReviewer) != 4 {
+		t.Fatalf("unexpected load: %+v", stats.Load)
+	}
+	if stats.Load.From != nil || stats.Load.To != nil {
+		t.Errorf("expected unbounded load, got %+v", stats.Load)
+	}
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	ranged, err := service.GetStatistics(ctx, StatisticsQuery{From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.calls != 2 || ranged == stats {
+		t.Errorf("expected ranged statistics to bypass the cache, got %d repository calls", repo.calls)
+	}
+	if !repo.loadFrom.Equal(from) || !repo.loadTo.Equal(to) || !ranged.Load.From.Equal(from) || !ranged.Load.To.Equal(to) {
+		t.Errorf("expected range to reach the repository and the response, got %+v", ranged.Load)
+	}
+
+	if _, err := service.GetStatistics(ctx, StatisticsQuery{From: &to, To: &from}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("expected ErrInvalidDateRange, got %v", err)
+	}
+}
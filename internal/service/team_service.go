@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
 	"github.com/reviewer-service/internal/repository"
@@ -14,18 +17,20 @@ type TeamService struct {
 	teamRepo repository.TeamRepository
 	userRepo repository.UserRepository
 	prRepo   repository.PullRequestRepository
-	db       interface {
-		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
-	}
-	logger *slog.Logger
+	db       repository.Transactor
+	notifier AssignmentNotifier
+	logger   *slog.Logger
 }
 
-func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.UserRepository, prRepo repository.PullRequestRepository, db *sql.DB, logger *slog.Logger) *TeamService {
+// NewTeamService создаёт сервис команд. notifier, если задан, узнаёт о
+// ревьюверах, назначенных взамен уходящих, после фиксации транзакции.
+func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.UserRepository, prRepo repository.PullRequestRepository, db repository.Transactor, notifier AssignmentNotifier, logger *slog.Logger) *TeamService {
 	return &TeamService{
 		teamRepo: teamRepo,
 		userRepo: userRepo,
 		prRepo:   prRepo,
 		db:       db,
+		notifier: notifier,
 		logger:   logger,
 	}
 }
@@ -43,16 +48,310 @@ func (s *TeamService) CreateTeam(ctx context.Context, team *models.Team) error {
 		return ErrTeamExists
 	}
 
-	if err := s.teamRepo.Create(team); err != nil {
+	ids := make([]string, 0, len(team.Members))
+	for _, member := range team.Members {
+		ids = append(ids, member.UserID)
+	}
+	existingUsers, err := s.userRepo.GetUsersByIDs(ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get team members", "error", err, "team_name", team.TeamName)
+		return err
+	}
+	previousTeam := make(map[string]string, len(existingUsers))
+	for _, u := range existingUsers {
+		// Архивный пользователь восстанавливается и ни из какой команды не уходит
+		if u.ArchivedAt == nil {
+			previousTeam[u.UserID] = u.TeamName
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.teamRepo.Add(tx, team.TeamName); err != nil {
 		s.logger.ErrorContext(ctx, "failed to create team", "error", err, "team_name", team.TeamName)
 		return err
 	}
 
-	s.logger.InfoContext(ctx, "team created successfully", "team_name", team.TeamName)
+	// Участники других команд переходят в новую с теми же последствиями, что и /users/moveTeam
+	changes := newReviewChanges(ids...)
+	reassigned := 0
+	for _, member := range team.Members {
+		user := &models.User{UserID: member.UserID, Username: member.Username, TeamName: team.TeamName, IsActive: member.IsActive}
+		n, err := s.changeTeam(ctx, tx, user, previousTeam[member.UserID], changes)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to add team member", "error", err, "team_name", team.TeamName, "user_id", member.UserID)
+			return err
+		}
+		reassigned += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	s.logger.InfoContext(ctx, "team created successfully", "team_name", team.TeamName, "reassigned", reassigned)
+	return nil
+}
+
+// AddMember добавляет пользователя в команду или обновляет его данные.
+// isActive == nil оставляет активность существующего пользователя без изменений
+// (новый или архивный пользователь становится активным). Если пользователь состоял в другой
+// команде, он переходит в эту, как при MoveUser.
+func (s *TeamService) AddMember(ctx context.Context, teamName, userID, username string, isActive *bool) (*models.Team, int, error) {
+	s.logger.InfoContext(ctx, "adding team member", "team_name", teamName, "user_id", userID)
+
+	if err := s.ensureTeam(teamName); err != nil {
+		return nil, 0, err
+	}
+
+	user := &models.User{UserID: userID, Username: username, TeamName: teamName, IsActive: true}
+	previous := ""
+	existing, err := s.userRepo.GetByID(userID)
+	switch {
+	case err == nil && existing.ArchivedAt == nil:
+		previous = existing.TeamName
+		user.IsActive = existing.IsActive
+	case err == nil:
+		// Архивный пользователь восстанавливается как новый
+	case !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
+		return nil, 0, err
+	}
+	if isActive != nil {
+		user.IsActive = *isActive
+	}
+
+	changes := newReviewChanges(user.UserID)
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return s.changeTeam(ctx, tx, user, previous, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to add team member", "error", err, "team_name", teamName, "user_id", userID)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	team, err := s.GetTeam(ctx, teamName, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.logger.InfoContext(ctx, "team member added", "team_name", teamName, "user_id", userID, "previous_team", previous, "reassigned", reassigned)
+	return team, reassigned, nil
+}
+
+// RemoveMember выводит пользователя из команды: он остаётся в системе без команды
+// (история PR сохраняется), а его открытые ревью переназначаются внутри команды.
+func (s *TeamService) RemoveMember(ctx context.Context, teamName, userID string) (*models.Team, int, error) {
+	s.logger.InfoContext(ctx, "removing team member", "team_name", teamName, "user_id", userID)
+
+	if err := s.ensureTeam(teamName); err != nil {
+		return nil, 0, err
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user.TeamName != teamName {
+		return nil, 0, ErrInvalidTeamMember
+	}
+
+	user.TeamName = ""
+	changes := newReviewChanges(user.UserID)
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return s.changeTeam(ctx, tx, user, teamName, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to remove team member", "error", err, "team_name", teamName, "user_id", userID)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	team, err := s.GetTeam(ctx, teamName, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.logger.InfoContext(ctx, "team member removed", "team_name", teamName, "user_id", userID, "reassigned", reassigned)
+	return team, reassigned, nil
+}
+
+// MoveUser переводит пользователя в другую команду. Его открытые ревью
+// переназначаются внутри прежней команды; авторство открытых PR не меняется.
+func (s *TeamService) MoveUser(ctx context.Context, userID, teamName string) (*models.User, int, error) {
+	s.logger.InfoContext(ctx, "moving user", "user_id", userID, "team_name", teamName)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.ensureTeam(teamName); err != nil {
+		return nil, 0, err
+	}
+	if user.TeamName == teamName {
+		return user, 0, nil
+	}
+
+	previous := user.TeamName
+	user.TeamName = teamName
+	changes := newReviewChanges(user.UserID)
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return s.changeTeam(ctx, tx, user, previous, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to move user", "error", err, "user_id", userID, "team_name", teamName)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	s.logger.InfoContext(ctx, "user moved", "user_id", userID, "from", previous, "to", teamName, "reassigned", reassigned)
+	return user, reassigned, nil
+}
+
+// ensureTeam проверяет, что команда существует и не архивирована.
+func (s *TeamService) ensureTeam(teamName string) error {
+	team, err := s.teamRepo.GetByName(teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+	if team.ArchivedAt != nil {
+		return ErrTeamNotFound
+	}
 	return nil
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+// getUser возвращает неархивного пользователя; архивного вернуть в работу
+// можно только через /team/add или /team/addMember.
+func (s *TeamService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if user.ArchivedAt != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *TeamService) inTx(ctx context.Context, fn func(tx repository.Tx) (int, error)) (int, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	n, err := fn(tx)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// reviewChanges — состояние пакета переходов в одной транзакции: чтения идут
+// мимо транзакции, поэтому уходящие пользователи и уже назначенные замены
+// учитываются здесь, а не в результатах запросов.
+type reviewChanges struct {
+	leaving map[string]bool
+	added   map[string][]string
+}
+
+func newReviewChanges(leaving ...string) *reviewChanges {
+	c := &reviewChanges{leaving: make(map[string]bool, len(leaving)), added: make(map[string][]string)}
+	for _, id := range leaving {
+		c.leaving[id] = true
+	}
+	return c
+}
+
+// notifyAssigned сообщает notifier о заменах из changes; вызывается после
+// фиксации транзакции, чтобы письма не уходили об откаченных назначениях.
+func (s *TeamService) notifyAssigned(ctx context.Context, changes *reviewChanges) {
+	if s.notifier == nil {
+		return
+	}
+	prIDs := make([]string, 0, len(changes.added))
+	for prID := range changes.added {
+		prIDs = append(prIDs, prID)
+	}
+	sort.Strings(prIDs)
+	for _, prID := range prIDs {
+		pr, err := s.prRepo.GetByID(prID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to load PR for assignment notice", "error", err, "pr_id", prID)
+			continue
+		}
+		s.notifier.ReviewersAssigned(ctx, pr, changes.added[prID], "")
+	}
+}
+
+// changeTeam сохраняет пользователя в команде user.TeamName (пустая — без команды).
+// Если он покидает команду previous, его открытые ревью переходят к случайным
+// активным участникам previous, кроме автора PR, уже назначенных ревьюверов и
+// уходящих пользователей; если кандидатов нет, ревьювер просто снимается.
+// Возвращает число PR, получивших замену.
+func (s *TeamService) changeTeam(ctx context.Context, tx repository.Tx, user *models.User, previous string, changes *reviewChanges) (int, error) {
+	reassigned := 0
+	if previous != "" && previous != user.TeamName {
+		reviewerPRs, err := s.prRepo.GetOpenPRsByReviewers([]string{user.UserID})
+		if err != nil {
+			return 0, err
+		}
+		candidates, err := s.userRepo.GetActiveTeamMembers(previous, user.UserID)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, pr := range reviewerPRs[user.UserID] {
+			if err := s.prRepo.RemoveReviewer(tx, pr.PullRequestID, user.UserID); err != nil {
+				return 0, err
+			}
+
+			taken := map[string]bool{pr.AuthorID: true}
+			for _, r := range pr.AssignedReviewers {
+				taken[r] = true
+			}
+			for _, r := range changes.added[pr.PullRequestID] {
+				taken[r] = true
+			}
+			available := make([]*models.User, 0, len(candidates))
+			for _, c := range candidates {
+				if !taken[c.UserID] && !changes.leaving[c.UserID] {
+					available = append(available, c)
+				}
+			}
+			replacement := selectRandomReviewers(available, 1)
+			if len(replacement) == 0 {
+				s.logger.WarnContext(ctx, "no replacement reviewer in team", "pr_id", pr.PullRequestID, "team_name", previous)
+				continue
+			}
+			if err := s.prRepo.AddReviewer(tx, pr.PullRequestID, replacement[0]); err != nil {
+				return 0, err
+			}
+			changes.added[pr.PullRequestID] = append(changes.added[pr.PullRequestID], replacement[0])
+			reassigned++
+		}
+	}
+
+	if err := s.userRepo.Upsert(tx, user); err != nil {
+		return 0, err
+	}
+	return reassigned, nil
+}
+
+// GetTeam возвращает команду с участниками. По умолчанию архивная команда
+// не находится, а архивные участники не показываются; includeArchived
+// снимает оба ограничения.
+func (s *TeamService) GetTeam(ctx context.Context, teamName string, includeArchived bool) (*models.Team, error) {
 	s.logger.DebugContext(ctx, "fetching team", "team_name", teamName)
 
 	team, err := s.teamRepo.GetByName(teamName)
@@ -64,7 +363,20 @@ func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Tea
 		s.logger.ErrorContext(ctx, "failed to get team", "error", err, "team_name", teamName)
 		return nil, err
 	}
+	if includeArchived {
+		return team, nil
+	}
+	if team.ArchivedAt != nil {
+		return nil, ErrTeamNotFound
+	}
 
+	members := make([]models.TeamMember, 0, len(team.Members))
+	for _, m := range team.Members {
+		if m.ArchivedAt == nil {
+			members = append(members, m)
+		}
+	}
+	team.Members = members
 	return team, nil
 }
 
@@ -85,6 +397,9 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 		}
 		return nil, err
 	}
+	if team.ArchivedAt != nil {
+		return nil, ErrTeamNotFound
+	}
 
 	users, err := s.userRepo.GetUsersByIDs(userIDs)
 	if err != nil {
@@ -96,7 +411,7 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 	}
 
 	for _, u := range users {
-		if u.TeamName != team.TeamName {
+		if u.TeamName != team.TeamName || u.ArchivedAt != nil {
 			return nil, ErrInvalidTeamMember
 		}
 	}
@@ -107,19 +422,52 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 	}
 	defer tx.Rollback()
 
-	authorPRs, err := s.prRepo.GetOpenPRsByAuthors(userIDs)
+	changes := newReviewChanges(userIDs...)
+	reassignedCount, err := s.releaseUsers(tx, teamName, userIDs, changes)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.userRepo.DeactivateUsers(tx, userIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	s.logger.InfoContext(ctx, "team members deactivated", "team_name", teamName, "count", len(userIDs), "reassigned", reassignedCount)
+
+	return map[string]interface{}{
+		"deactivated_users": userIDs,
+		"reassigned_prs":    reassignedCount,
+	}, nil
+}
+
+// releaseUsers освобождает пользователей userIDs от открытой работы в команде
+// teamName: авторство их открытых PR и места ревьюверов переходят к оставшимся
+// активным участникам по кругу. Если таких нет, авторство не меняется, а
+// ревьювер просто снимается. Кандидаты перебираются по user_id, уходящие
+// ревьюверы — по user_id, их PR — по pull_request_id, поэтому результат не
+// зависит от порядка выборки; автор PR его ревьювером не становится — если
+// очередь дошла до него, место остаётся свободным.
+// Уходящие из changes кандидатами не становятся, а назначенные замены
+// записываются в changes. Возвращает число переназначений.
+func (s *TeamService) releaseUsers(tx repository.Tx, teamName string, userIDs []string, changes *reviewChanges) (int, error) {
+	authorPRs, err := s.prRepo.GetOpenPRsByAuthors(userIDs)
+	if err != nil {
+		return 0, err
+	}
+
 	reviewerPRs, err := s.prRepo.GetOpenPRsByReviewers(userIDs)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	activeMembers, err := s.userRepo.GetActiveTeamMembers(teamName, "")
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	activeMap := make(map[string]bool)
@@ -129,11 +477,23 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 	for _, uid := range userIDs {
 		delete(activeMap, uid)
 	}
+	for uid := range changes.leaving {
+		delete(activeMap, uid)
+	}
 
 	var activeList []string
 	for uid := range activeMap {
 		activeList = append(activeList, uid)
 	}
+	sort.Strings(activeList)
+
+	sortPRsByID(authorPRs)
+	reviewerIDs := make([]string, 0, len(reviewerPRs))
+	for reviewerID, prs := range reviewerPRs {
+		reviewerIDs = append(reviewerIDs, reviewerID)
+		sortPRsByID(prs)
+	}
+	sort.Strings(reviewerIDs)
 
 	reassignedCount := 0
 
@@ -141,29 +501,29 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 		if len(activeList) > 0 {
 			newAuthor := activeList[reassignedCount%len(activeList)]
 			if err := s.prRepo.ReassignAuthor(tx, pr.PullRequestID, newAuthor); err != nil {
-				return nil, err
+				return 0, err
 			}
 			reassignedCount++
 		}
 	}
 
-	for reviewerID, prs := range reviewerPRs {
-		for _, pr := range prs {
+	for _, reviewerID := range reviewerIDs {
+		for _, pr := range reviewerPRs[reviewerID] {
 			if err := s.prRepo.RemoveReviewer(tx, pr.PullRequestID, reviewerID); err != nil {
-				return nil, err
+				return 0, err
 			}
 
 			updatedReviewers := make([]string, 0, len(pr.AssignedReviewers))
 			for _, r := range pr.AssignedReviewers {
-				if r != reviewerID {
+				if r != reviewerID && !changes.leaving[r] {
 					updatedReviewers = append(updatedReviewers, r)
 				}
 			}
-			pr.AssignedReviewers = updatedReviewers
+			pr.AssignedReviewers = append(updatedReviewers, changes.added[pr.PullRequestID]...)
 
 			if len(pr.AssignedReviewers) < 2 && len(activeList) > 0 {
 				newReviewer := activeList[reassignedCount%len(activeList)]
-				alreadyReviewer := false
+				alreadyReviewer := newReviewer == pr.AuthorID
 				for _, r := range pr.AssignedReviewers {
 					if r == newReviewer {
 						alreadyReviewer = true
@@ -172,27 +532,513 @@ func (s *TeamService) DeactivateTeamMembers(ctx context.Context, teamName string
 				}
 				if !alreadyReviewer {
 					if err := s.prRepo.AddReviewer(tx, pr.PullRequestID, newReviewer); err != nil {
-						return nil, err
+						return 0, err
 					}
-					pr.AssignedReviewers = append(pr.AssignedReviewers, newReviewer)
+					changes.added[pr.PullRequestID] = append(changes.added[pr.PullRequestID], newReviewer)
 					reassignedCount++
 				}
 			}
 		}
 	}
 
-	if err := s.userRepo.DeactivateUsers(tx, userIDs); err != nil {
-		return nil, err
+	return reassignedCount, nil
+}
+
+func sortPRsByID(prs []*models.PullRequest) {
+	sort.Slice(prs, func(i, j int) bool {
+		return prs[i].PullRequestID < prs[j].PullRequestID
+	})
+}
+
+// ArchiveUser архивирует пользователя: его открытая работа передаётся коллегам
+// по команде, как в DeactivateTeamMembers, а сам он деактивируется и пропадает
+// из состава команды. Запись остаётся, поэтому история PR по-прежнему на него
+// ссылается. Повторный вызов ничего не меняет.
+func (s *TeamService) ArchiveUser(ctx context.Context, userID string) (*models.User, int, error) {
+	s.logger.InfoContext(ctx, "archiving user", "user_id", userID)
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
+		return nil, 0, err
+	}
+	if user.ArchivedAt != nil {
+		return user, 0, nil
 	}
 
+	at := time.Now().UTC()
+	changes := newReviewChanges(userID)
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		n, err := s.releaseUsers(tx, user.TeamName, []string{userID}, changes)
+		if err != nil {
+			return 0, err
+		}
+		return n, s.userRepo.Archive(tx, []string{userID}, at)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to archive user", "error", err, "user_id", userID)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	user.IsActive = false
+	user.ArchivedAt = &at
+	s.logger.InfoContext(ctx, "user archived", "user_id", userID, "reassigned", reassigned)
+	return user, reassigned, nil
+}
+
+// ArchiveTeam архивирует команду вместе с текущими участниками. Передать их
+// работу внутри команды некому, поэтому ревьюверы снимаются с открытых PR,
+// а авторство сохраняется; участников, которых нужно оставить, сначала
+// переводят в другую команду. Повторный вызов ничего не меняет.
+func (s *TeamService) ArchiveTeam(ctx context.Context, teamName string) (*models.Team, []string, int, error) {
+	s.logger.InfoContext(ctx, "archiving team", "team_name", teamName)
+
+	team, err := s.teamRepo.GetByName(teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, 0, ErrTeamNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get team", "error", err, "team_name", teamName)
+		return nil, nil, 0, err
+	}
+	if team.ArchivedAt != nil {
+		return team, []string{}, 0, nil
+	}
+
+	members := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		if m.ArchivedAt == nil {
+			members = append(members, m.UserID)
+		}
+	}
+
+	at := time.Now().UTC()
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		n := 0
+		if len(members) > 0 {
+			var err error
+			if n, err = s.releaseUsers(tx, teamName, members, newReviewChanges(members...)); err != nil {
+				return 0, err
+			}
+			if err := s.userRepo.Archive(tx, members, at); err != nil {
+				return 0, err
+			}
+		}
+		return n, s.teamRepo.Archive(tx, teamName, at)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to archive team", "error", err, "team_name", teamName)
+		return nil, nil, 0, err
+	}
+
+	archived, err := s.teamRepo.GetByName(teamName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	s.logger.InfoContext(ctx, "team archived", "team_name", teamName, "members", len(members), "reassigned", reassigned)
+	return archived, members, reassigned, nil
+}
+
+// SyncTeams приводит команды и их состав к манифесту teams: создаёт
+// недостающие команды и пользователей (архивные восстанавливаются),
+// обновляет имя и активность, переводит пользователей между командами, как
+// MoveUser, и деактивирует неактивных в манифесте и пропавших из команд
+// манифеста, как DeactivateTeamMembers. Команды, которых нет в манифесте, и
+// их участники не меняются. Кто переходит в другую команду неактивным,
+// передаёт работу в прежней команде как при деактивации.
+// Всё выполняется в одной транзакции; при dryRun она откатывается, и вызов
+// только возвращает план изменений.
+func (s *TeamService) SyncTeams(ctx context.Context, teams []models.Team, dryRun bool) ([]models.SyncChange, int, error) {
+	s.logger.InfoContext(ctx, "syncing teams", "teams", len(teams), "dry_run", dryRun)
+
+	manifestTeam := make(map[string]string)
+	teamSeen := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		if teamSeen[team.TeamName] {
+			return nil, 0, fmt.Errorf("%w: team %s listed twice", ErrInvalidManifest, team.TeamName)
+		}
+		teamSeen[team.TeamName] = true
+		for _, m := range team.Members {
+			if other, ok := manifestTeam[m.UserID]; ok {
+				return nil, 0, fmt.Errorf("%w: user %s listed in teams %s and %s", ErrInvalidManifest, m.UserID, other, team.TeamName)
+			}
+			manifestTeam[m.UserID] = team.TeamName
+		}
+	}
+
+	var changes []models.SyncChange
+	for _, team := range teams {
+		existing, err := s.teamRepo.GetByName(team.TeamName)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			changes = append(changes, models.SyncChange{Action: models.SyncCreateTeam, TeamName: team.TeamName})
+		case err != nil:
+			s.logger.ErrorContext(ctx, "failed to get team", "error", err, "team_name", team.TeamName)
+			return nil, 0, err
+		case existing.ArchivedAt != nil:
+			return nil, 0, fmt.Errorf("%w: team %s is archived", ErrInvalidManifest, team.TeamName)
+		}
+	}
+
+	current, err := s.userRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list users", "error", err)
+		return nil, 0, err
+	}
+	users := make(map[string]*models.User, len(current))
+	for _, u := range current {
+		if u.ArchivedAt == nil {
+			users[u.UserID] = u
+		}
+	}
+
+	// Планируем изменения пользователей: сначала по манифесту, затем те,
+	// кого в манифесте нет
+	type userChange struct {
+		user     *models.User
+		previous string
+	}
+	var upserts []userChange
+	deactivate := make(map[string][]string)
+	var leaving []string
+	for _, team := range teams {
+		for _, m := range team.Members {
+			user := &models.User{UserID: m.UserID, Username: m.Username, TeamName: team.TeamName, IsActive: m.IsActive}
+			old, ok := users[m.UserID]
+			switch {
+			case !ok:
+				changes = append(changes, models.SyncChange{Action: models.SyncCreateUser, TeamName: team.TeamName, UserID: m.UserID})
+				upserts = append(upserts, userChange{user: user})
+			case old.TeamName != team.TeamName && old.IsActive && !m.IsActive:
+				// Уходит из прежней команды неактивным: работу в ней передаём
+				// как при деактивации, вместе с авторством открытых PR
+				changes = append(changes,
+					models.SyncChange{Action: models.SyncMoveUser, TeamName: team.TeamName, UserID: m.UserID, PreviousTeam: old.TeamName},
+					models.SyncChange{Action: models.SyncDeactivateUser, TeamName: team.TeamName, UserID: m.UserID})
+				upserts = append(upserts, userChange{user: user, previous: team.TeamName})
+				if old.TeamName != "" {
+					deactivate[old.TeamName] = append(deactivate[old.TeamName], m.UserID)
+				}
+				leaving = append(leaving, m.UserID)
+			case old.TeamName != team.TeamName:
+				changes = append(changes, models.SyncChange{Action: models.SyncMoveUser, TeamName: team.TeamName, UserID: m.UserID, PreviousTeam: old.TeamName})
+				upserts = append(upserts, userChange{user: user, previous: old.TeamName})
+				leaving = append(leaving, m.UserID)
+			case old.IsActive && !m.IsActive:
+				changes = append(changes, models.SyncChange{Action: models.SyncDeactivateUser, TeamName: team.TeamName, UserID: m.UserID})
+				if old.Username != m.Username {
+					upserts = append(upserts, userChange{user: user, previous: team.TeamName})
+				}
+				deactivate[team.TeamName] = append(deactivate[team.TeamName], m.UserID)
+				leaving = append(leaving, m.UserID)
+			case old.Username != m.Username || old.IsActive != m.IsActive:
+				changes = append(changes, models.SyncChange{Action: models.SyncUpdateUser, TeamName: team.TeamName, UserID: m.UserID})
+				upserts = append(upserts, userChange{user: user, previous: team.TeamName})
+			}
+		}
+	}
+	for _, u := range current {
+		if _, listed := manifestTeam[u.UserID]; listed || !teamSeen[u.TeamName] || u.ArchivedAt != nil || !u.IsActive {
+			continue
+		}
+		changes = append(changes, models.SyncChange{Action: models.SyncDeactivateUser, TeamName: u.TeamName, UserID: u.UserID})
+		deactivate[u.TeamName] = append(deactivate[u.TeamName], u.UserID)
+		leaving = append(leaving, u.UserID)
+	}
+	if changes == nil {
+		changes = []models.SyncChange{}
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	for _, c := range changes {
+		if c.Action == models.SyncCreateTeam {
+			if err := s.teamRepo.Add(tx, c.TeamName); err != nil {
+				s.logger.ErrorContext(ctx, "failed to create team", "error", err, "team_name", c.TeamName)
+				return nil, 0, err
+			}
+		}
+	}
+
+	// Общее состояние пакета: уходящие не получают чужие ревью, а замены
+	// не назначаются на один PR дважды
+	batch := newReviewChanges(leaving...)
+	reassigned := 0
+	for _, uc := range upserts {
+		n, err := s.changeTeam(ctx, tx, uc.user, uc.previous, batch)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to sync user", "error", err, "user_id", uc.user.UserID)
+			return nil, 0, err
+		}
+		reassigned += n
+	}
+
+	teamNames := make([]string, 0, len(deactivate))
+	for teamName := range deactivate {
+		teamNames = append(teamNames, teamName)
+	}
+	sort.Strings(teamNames)
+	for _, teamName := range teamNames {
+		userIDs := deactivate[teamName]
+		n, err := s.releaseUsers(tx, teamName, userIDs, batch)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to release users", "error", err, "team_name", teamName)
+			return nil, 0, err
+		}
+		if err := s.userRepo.DeactivateUsers(tx, userIDs); err != nil {
+			return nil, 0, err
+		}
+		reassigned += n
+	}
+
+	if dryRun {
+		s.logger.InfoContext(ctx, "teams sync planned", "changes", len(changes), "reassigned", reassigned)
+		return changes, reassigned, nil
+	}
 	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, batch)
+
+	s.logger.InfoContext(ctx, "teams synced", "changes", len(changes), "reassigned", reassigned)
+	return changes, reassigned, nil
+}
+
+// ListTeams возвращает неархивные команды без архивных участников.
+func (s *TeamService) ListTeams(ctx context.Context) ([]*models.Team, error) {
+	all, err := s.teamRepo.List()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list teams", "error", err)
+		return nil, err
+	}
+	teams := make([]*models.Team, 0, len(all))
+	for _, team := range all {
+		if team.ArchivedAt != nil {
+			continue
+		}
+		members := make([]models.TeamMember, 0, len(team.Members))
+		for _, m := range team.Members {
+			if m.ArchivedAt == nil {
+				members = append(members, m)
+			}
+		}
+		team.Members = members
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// CreateUser создаёт пользователя в команде user.TeamName или без команды,
+// если она пустая. Архивный пользователь с тем же идентификатором
+// восстанавливается, живой — ErrUserExists.
+func (s *TeamService) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	s.logger.InfoContext(ctx, "creating user", "user_id", user.UserID, "team_name", user.TeamName)
+
+	existing, err := s.userRepo.GetByID(user.UserID)
+	switch {
+	case err == nil && existing.ArchivedAt == nil:
+		return nil, ErrUserExists
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", user.UserID)
 		return nil, err
 	}
+	if user.TeamName != "" {
+		if err := s.ensureTeam(user.TeamName); err != nil {
+			return nil, err
+		}
+	}
 
-	s.logger.InfoContext(ctx, "team members deactivated", "team_name", teamName, "count", len(userIDs), "reassigned", reassignedCount)
+	if _, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return 0, s.userRepo.Upsert(tx, user)
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "failed to create user", "error", err, "user_id", user.UserID)
+		return nil, err
+	}
 
-	return map[string]interface{}{
-		"deactivated_users": userIDs,
-		"reassigned_prs":    reassignedCount,
-	}, nil
+	created, err := s.getUser(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "user created", "user_id", user.UserID)
+	return created, nil
+}
+
+// UpdateUser меняет имя и/или активность пользователя (nil — без изменений).
+// Деактивация передаёт открытую работу коллегам по команде так же, как
+// DeactivateTeamMembers. Возвращает пользователя и число переназначений.
+func (s *TeamService) UpdateUser(ctx context.Context, userID string, username *string, isActive *bool) (*models.User, int, error) {
+	s.logger.InfoContext(ctx, "updating user", "user_id", userID)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deactivate := isActive != nil && !*isActive && user.IsActive
+	updated := *user
+	if username != nil {
+		updated.Username = *username
+	}
+	if isActive != nil {
+		updated.IsActive = *isActive
+	}
+	if updated == *user {
+		return user, 0, nil
+	}
+
+	changes := newReviewChanges(userID)
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		if err := s.userRepo.Upsert(tx, &updated); err != nil {
+			return 0, err
+		}
+		if !deactivate {
+			return 0, nil
+		}
+		return s.releaseUsers(tx, user.TeamName, []string{userID}, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to update user", "error", err, "user_id", userID)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	s.logger.InfoContext(ctx, "user updated", "user_id", userID, "is_active", updated.IsActive, "reassigned", reassigned)
+	return &updated, reassigned, nil
+}
+
+// ProvisionTeam создаёт команду из существующих пользователей: они переходят
+// в неё с прежними именем и активностью, как при MoveUser.
+func (s *TeamService) ProvisionTeam(ctx context.Context, teamName string, userIDs []string) (*models.Team, int, error) {
+	s.logger.InfoContext(ctx, "provisioning team", "team_name", teamName, "user_ids", userIDs)
+
+	if _, err := s.teamRepo.GetByName(teamName); err == nil {
+		return nil, 0, ErrTeamExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "failed to check team existence", "error", err, "team_name", teamName)
+		return nil, 0, err
+	}
+	users, err := s.liveUsers(userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes := newReviewChanges()
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		if err := s.teamRepo.Add(tx, teamName); err != nil {
+			return 0, err
+		}
+		return s.moveUsers(ctx, tx, users, teamName, nil, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to provision team", "error", err, "team_name", teamName)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	team, err := s.GetTeam(ctx, teamName, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.logger.InfoContext(ctx, "team provisioned", "team_name", teamName, "reassigned", reassigned)
+	return team, reassigned, nil
+}
+
+// UpdateMembers добавляет пользователей add в команду (как AddMember, без
+// смены имени и активности) и выводит из неё пользователей remove (как
+// RemoveMember) в одной транзакции. Уже состоящие в команде в add и не
+// состоящие в remove пропускаются.
+func (s *TeamService) UpdateMembers(ctx context.Context, teamName string, add, remove []string) (*models.Team, int, error) {
+	s.logger.InfoContext(ctx, "updating team members", "team_name", teamName, "add", add, "remove", remove)
+
+	if err := s.ensureTeam(teamName); err != nil {
+		return nil, 0, err
+	}
+	adding, err := s.liveUsers(add)
+	if err != nil {
+		return nil, 0, err
+	}
+	removing, err := s.liveUsers(remove)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	changes := newReviewChanges()
+	reassigned, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return s.moveUsers(ctx, tx, adding, teamName, removing, changes)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to update team members", "error", err, "team_name", teamName)
+		return nil, 0, err
+	}
+	s.notifyAssigned(ctx, changes)
+
+	team, err := s.GetTeam(ctx, teamName, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.logger.InfoContext(ctx, "team members updated", "team_name", teamName, "reassigned", reassigned)
+	return team, reassigned, nil
+}
+
+// liveUsers возвращает неархивных пользователей userIDs без повторов или
+// ErrUserNotFound, если кого-то из них нет.
+func (s *TeamService) liveUsers(userIDs []string) ([]*models.User, error) {
+	users, err := s.userRepo.GetUsersByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	unique := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		unique[id] = true
+	}
+	if len(users) != len(unique) {
+		return nil, ErrUserNotFound
+	}
+	for _, u := range users {
+		if u.ArchivedAt != nil {
+			return nil, ErrUserNotFound
+		}
+	}
+	return users, nil
+}
+
+// moveUsers переводит adding в команду teamName, а состоящих в ней removing
+// оставляет без команды; все они уходят из прежних команд одним пакетом
+// changes.
+func (s *TeamService) moveUsers(ctx context.Context, tx repository.Tx, adding []*models.User, teamName string, removing []*models.User, changes *reviewChanges) (int, error) {
+	var moves []*models.User
+	var previous []string
+	for _, u := range adding {
+		if u.TeamName != teamName {
+			moves = append(moves, &models.User{UserID: u.UserID, Username: u.Username, TeamName: teamName, IsActive: u.IsActive})
+			previous = append(previous, u.TeamName)
+		}
+	}
+	for _, u := range removing {
+		if u.TeamName == teamName {
+			moves = append(moves, &models.User{UserID: u.UserID, Username: u.Username, IsActive: u.IsActive})
+			previous = append(previous, u.TeamName)
+		}
+	}
+
+	for _, u := range moves {
+		changes.leaving[u.UserID] = true
+	}
+	reassigned := 0
+	for i, u := range moves {
+		n, err := s.changeTeam(ctx, tx, u, previous[i], changes)
+		if err != nil {
+			return 0, err
+		}
+		reassigned += n
+	}
+	return reassigned, nil
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/storage"
+)
+
+type recordingAssignmentNotifier struct {
+	assigned map[string][]string
+}
+
+func (n *recordingAssignmentNotifier) ReviewersAssigned(ctx context.Context, pr *models.PullRequest, reviewerIDs []string, previousReviewerID string) {
+	n.assigned[pr.PullRequestID] = append(n.assigned[pr.PullRequestID], reviewerIDs...)
+}
+
+// newTestTeamService собирает сервис поверх in-memory хранилища с командами
+// team (r1, x и автор y) и other и PR pr-1 автора y с ревьювером r1.
+func newTestTeamService(t *testing.T) (*TeamService, *recordingAssignmentNotifier) {
+	t.Helper()
+	st := storage.NewMemory()
+	notifier := &recordingAssignmentNotifier{assigned: make(map[string][]string)}
+	s := NewTeamService(st.Teams, st.Users, st.PullRequests, st.Transactor, notifier, setupTestLogger())
+
+	ctx := context.Background()
+	teams := []*models.Team{
+		{TeamName: "team", Members: []models.TeamMember{
+			{UserID: "y", Username: "Author", IsActive: true},
+			{UserID: "r1", Username: "R1", IsActive: true},
+			{UserID: "x", Username: "X", IsActive: true},
+		}},
+		{TeamName: "other", Members: []models.TeamMember{}},
+	}
+	for _, team := range teams {
+		if err := s.CreateTeam(ctx, team); err != nil {
+			t.Fatalf("CreateTeam(%s) error = %v", team.TeamName, err)
+		}
+	}
+	if _, err := s.inTx(ctx, func(tx repository.Tx) (int, error) {
+		return 0, st.PullRequests.Import(tx, &models.PullRequest{
+			PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "y", Status: "OPEN", AssignedReviewers: []string{"r1"},
+		})
+	}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	return s, notifier
+}
+
+func TestTeamService_NotifiesReplacementReviewers(t *testing.T) {
+	want := map[string][]string{"pr-1": {"x"}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		change func(s *TeamService) error
+	}{
+		{
+			name: "deactivate members",
+			change: func(s *TeamService) error {
+				_, err := s.DeactivateTeamMembers(ctx, "team", []string{"r1"})
+				return err
+			},
+		},
+		{
+			name: "move user",
+			change: func(s *TeamService) error {
+				_, _, err := s.MoveUser(ctx, "r1", "other")
+				return err
+			},
+		},
+		{
+			name: "sync teams",
+			change: func(s *TeamService) error {
+				_, _, err := s.SyncTeams(ctx, []models.Team{{TeamName: "team", Members: []models.TeamMember{
+					{UserID: "y", Username: "Author", IsActive: true},
+					{UserID: "r1", Username: "R1", IsActive: false},
+					{UserID: "x", Username: "X", IsActive: true},
+				}}}, false)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, notifier := newTestTeamService(t)
+			if err := tt.change(s); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+			if !reflect.DeepEqual(notifier.assigned, want) {
+				t.Errorf("expected notices %v, got %v", want, notifier.assigned)
+			}
+		})
+	}
+
+	t.Run("dry run sync", func(t *testing.T) {
+		s, notifier := newTestTeamService(t)
+		if _, _, err := s.SyncTeams(ctx, []models.Team{{TeamName: "team", Members: []models.TeamMember{
+			{UserID: "y", Username: "Author", IsActive: true},
+			{UserID: "x", Username: "X", IsActive: true},
+		}}}, true); err != nil {
+			t.Fatalf("SyncTeams() error = %v", err)
+		}
+		if len(notifier.assigned) != 0 {
+			t.Errorf("dry run must not send notices, got %v", notifier.assigned)
+		}
+	})
+}
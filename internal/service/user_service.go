@@ -5,29 +5,34 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/reviewer-service/internal/models"
 	"github.com/reviewer-service/internal/repository"
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
-	prRepo   repository.PullRequestRepository
-	logger   *slog.Logger
+	userRepo  repository.UserRepository
+	prRepo    repository.PullRequestRepository
+	calendars *WorkCalendars
+	logger    *slog.Logger
 }
 
-func NewUserService(userRepo repository.UserRepository, prRepo repository.PullRequestRepository, logger *slog.Logger) *UserService {
+// NewUserService создаёт сервис пользователей; calendars (может быть nil)
+// нужны для возраста ревью в рабочем времени.
+func NewUserService(userRepo repository.UserRepository, prRepo repository.PullRequestRepository, calendars *WorkCalendars, logger *slog.Logger) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		prRepo:   prRepo,
-		logger:   logger,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		calendars: calendars,
+		logger:    logger,
 	}
 }
 
 func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
 	s.logger.InfoContext(ctx, "updating user activity", "user_id", userID, "is_active", isActive)
 
-	_, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			s.logger.ErrorContext(ctx, "user not found", "error", err, "user_id", userID)
@@ -36,6 +41,10 @@ func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive
 		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
 		return nil, err
 	}
+	// Архивного пользователя нельзя вернуть в ротацию в обход /team/addMember
+	if user.ArchivedAt != nil {
+		return nil, ErrUserNotFound
+	}
 
 	updatedUser, err := s.userRepo.UpdateActivity(userID, isActive)
 	if err != nil {
@@ -47,25 +56,168 @@ func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive
 	return updatedUser, nil
 }
 
-func (s *UserService) GetUserReviews(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
-	s.logger.DebugContext(ctx, "fetching user reviews", "user_id", userID)
+// SetAway выводит пользователя из ротации до until; после этого срока
+// фоновая задача вернёт его, если активность не меняли вручную.
+func (s *UserService) SetAway(ctx context.Context, userID string, until time.Time) error {
+	s.logger.InfoContext(ctx, "setting user away", "user_id", userID, "until", until)
+
+	if err := s.userRepo.SetAway(userID, until); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "user not found", "user_id", userID)
+			return ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to set user away", "error", err, "user_id", userID)
+		return err
+	}
+	return nil
+}
 
+// GetUser возвращает неархивного пользователя.
+func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			s.logger.ErrorContext(ctx, "user not found", "error", err, "user_id", userID)
 			return nil, ErrUserNotFound
 		}
 		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
 		return nil, err
 	}
+	if user.ArchivedAt != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
 
-	reviews, err := s.prRepo.GetByReviewerID(user.UserID)
+// ListUsers возвращает всех неархивных пользователей в порядке user_id.
+func (s *UserService) ListUsers(ctx context.Context) ([]*models.User, error) {
+	all, err := s.userRepo.List()
 	if err != nil {
-		s.logger.ErrorContext(ctx, "failed to fetch reviews", "error", err, "user_id", userID)
+		s.logger.ErrorContext(ctx, "failed to list users", "error", err)
 		return nil, err
 	}
+	users := make([]*models.User, 0, len(all))
+	for _, u := range all {
+		if u.ArchivedAt == nil {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// Значения фильтра статуса во входящих ревью
+const (
+	ReviewStatusOpen   = "OPEN"
+	ReviewStatusMerged = "MERGED"
+	ReviewStatusAll    = "ALL"
+)
+
+// UserReviewsQuery — параметры GetUserReviews. Пустой Status означает OPEN,
+// ALL снимает фильтр. Cursor — NextCursor предыдущей страницы.
+type UserReviewsQuery struct {
+	Status          string
+	IncludeAuthored bool
+	SortBy          string
+	Cursor          string
+	Limit           int
+}
+
+// GetUserReviews возвращает страницу входящих ревью пользователя и курсор
+// следующей страницы. Возраст ревью считается до текущего момента для открытых
+// PR и до мержа для смерженных.
+func (s *UserService) GetUserReviews(ctx context.Context, userID string, q UserReviewsQuery) ([]*models.PullRequestShort, string, error) {
+	s.logger.DebugContext(ctx, "fetching user reviews", "user_id", userID)
+
+	rq := repository.ReviewQuery{IncludeAuthored: q.IncludeAuthored, SortBy: q.SortBy}
+	switch q.Status {
+	case "", ReviewStatusOpen:
+		rq.Status = ReviewStatusOpen
+	case ReviewStatusMerged:
+		rq.Status = ReviewStatusMerged
+	case ReviewStatusAll:
+	default:
+		return nil, "", ErrInvalidStatus
+	}
+	if rq.SortBy == "" {
+		rq.SortBy = repository.PRSortID
+	}
+	if rq.SortBy != repository.PRSortID && rq.SortBy != repository.ReviewSortAssignedAt {
+		return nil, "", ErrInvalidSort
+	}
+	rq.Limit = q.Limit
+	if rq.Limit <= 0 {
+		rq.Limit = DefaultListLimit
+	}
+	if rq.Limit > MaxListLimit {
+		rq.Limit = MaxListLimit
+	}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor, rq.SortBy, false)
+		if err != nil {
+			s.logger.WarnContext(ctx, "invalid reviews cursor", "error", err, "user_id", userID)
+			return nil, "", ErrInvalidCursor
+		}
+		rq.After = after
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.ErrorContext(ctx, "user not found", "error", err, "user_id", userID)
+			return nil, "", ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get user", "error", err, "user_id", userID)
+		return nil, "", err
+	}
+
+	limit := rq.Limit
+	rq.Limit++
+	reviews, err := s.prRepo.GetByReviewerID(user.UserID, rq)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to fetch reviews", "error", err, "user_id", userID)
+		return nil, "", err
+	}
+
+	var next string
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+		last := reviews[len(reviews)-1]
+		next = encodeKey(rq.SortBy, false, last.AssignedAt, last.PullRequestID)
+	}
+
+	key := CalendarKey{UserID: user.UserID, TeamName: user.TeamName}
+	calendars, err := s.calendars.Load([]CalendarKey{key})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load working hours", "error", err, "user_id", userID)
+		return nil, "", err
+	}
+	cal := calendars[key]
+
+	now := time.Now().UTC()
+	for _, pr := range reviews {
+		pr.ReviewAgeSeconds = reviewAge(pr, now)
+		if pr.AssignedAt != nil {
+			until := now
+			if pr.MergedAt != nil {
+				until = *pr.MergedAt
+			}
+			pr.ReviewWorkingAgeSeconds = int64(cal.Duration(*pr.AssignedAt, until) / time.Second)
+		}
+	}
 
 	s.logger.DebugContext(ctx, "reviews fetched", "user_id", userID, "count", len(reviews))
-	return reviews, nil
+	return reviews, next, nil
+}
+
+func reviewAge(pr *models.PullRequestShort, now time.Time) int64 {
+	if pr.AssignedAt == nil {
+		return 0
+	}
+	until := now
+	if pr.MergedAt != nil {
+		until = *pr.MergedAt
+	}
+	if age := until.Sub(*pr.AssignedAt); age > 0 {
+		return int64(age / time.Second)
+	}
+	return 0
 }
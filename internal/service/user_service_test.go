@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+func TestUserService_GetUserReviews(t *testing.T) {
+	ctx := context.Background()
+	assignedAt := time.Now().UTC().Add(-2 * time.Hour)
+	mergedAt := assignedAt.Add(30 * time.Minute)
+	prRepo := &mockPRRepository{reviews: []*models.PullRequestShort{
+		{PullRequestID: "pr-1", Status: "OPEN", AssignedAt: &assignedAt},
+		{PullRequestID: "pr-2", Status: "MERGED", AssignedAt: &assignedAt, MergedAt: &mergedAt},
+		{PullRequestID: "pr-3", Status: "OPEN"},
+	}}
+	userRepo := &mockUserRepository{users: map[string]*models.User{
+		"u1": {UserID: "u1", TeamName: "backend", IsActive: true},
+	}}
+	svc := NewUserService(userRepo, prRepo, nil, setupTestLogger())
+
+	first, next, err := svc.GetUserReviews(ctx, "u1", UserReviewsQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 2 || next == "" {
+		t.Fatalf("expected 2 reviews and next cursor, got %d, cursor %q", len(first), next)
+	}
+	if q := prRepo.lastReviewQuery; q.Status != "OPEN" || q.SortBy != repository.PRSortID || q.Limit != 3 {
+		t.Errorf("unexpected defaults: %+v", q)
+	}
+	if age := first[0].ReviewAgeSeconds; age < 7200 || age > 7260 {
+		t.Errorf("expected open review age about 2h, got %ds", age)
+	}
+	if age := first[1].ReviewAgeSeconds; age != 1800 {
+		t.Errorf("expected merged review age 30m, got %ds", age)
+	}
+
+	last, next, err := svc.GetUserReviews(ctx, "u1", UserReviewsQuery{Status: "ALL", Limit: 2, Cursor: next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(last) != 1 || last[0].PullRequestID != "pr-3" || next != "" || last[0].ReviewAgeSeconds != 0 {
+		t.Errorf("expected last page [pr-3] without cursor, got %+v, cursor %q", last, next)
+	}
+	if q := prRepo.lastReviewQuery; q.Status != "" || q.After == nil || q.After.PullRequestID != "pr-2" {
+		t.Errorf("expected unfiltered page after pr-2, got %+v", q)
+	}
+
+	tests := []struct {
+		name string
+		q    UserReviewsQuery
+		user string
+		want error
+	}{
+		{"unknown status", UserReviewsQuery{Status: "CLOSED"}, "u1", ErrInvalidStatus},
+		{"unknown sort", UserReviewsQuery{SortBy: "created_at"}, "u1", ErrInvalidSort},
+		{"cursor for other sort", UserReviewsQuery{SortBy: repository.ReviewSortAssignedAt, Cursor: encodeKey(repository.PRSortID, false, nil, "pr-1")}, "u1", ErrInvalidCursor},
+		{"unknown user", UserReviewsQuery{}, "missing", ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.GetUserReviews(ctx, tt.user, tt.q); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Package static содержит встроенные в бинарник ресурсы страницы документации.
+package static
+
+import "embed"
+
+//go:embed docs/*.html docs/*.js docs/*.css docs/*.png
+var Docs embed.FS
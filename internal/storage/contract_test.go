@@ -0,0 +1,1525 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+	"github.com/reviewer-service/internal/repository"
+)
+
+type backend struct {
+	name string
+	open func(t *testing.T) *Storage
+}
+
+// backends — реализации, которые обязаны проходить общий контракт репозиториев.
+var backends = []backend{
+	{name: DriverMemory, open: func(t *testing.T) *Storage { return NewMemory() }},
+	{name: DriverSQLite, open: openTestSQLite},
+	{name: DriverPostgres, open: openTestPostgres},
+}
+
+func openTestSQLite(t *testing.T) *Storage {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "reviewers.db"))
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	return migrated(t, NewSQL(db, DriverSQLite))
+}
+
+func openTestPostgres(t *testing.T) *Storage {
+	if testing.Short() {
+		t.Skip("Skipping postgres contract tests in short mode")
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		getEnv("DB_HOST", "localhost"), getEnv("DB_PORT", "5432"), getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"), getEnv("DB_NAME", "reviewers"))
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Skipf("Database not available: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		t.Skipf("Database not reachable: %v", err)
+	}
+
+	st := migrated(t, NewSQL(db, DriverPostgres))
+	for _, table := range []string{"idempotency_keys", "pr_events", "pr_reviewers", "pull_requests", "users", "teams"} {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			db.Close()
+			t.Fatalf("Failed to cleanup %s: %v", table, err)
+		}
+	}
+	return st
+}
+
+func migrated(t *testing.T, st *Storage) *Storage {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := st.Migrate(context.Background(), logger); err != nil {
+		st.Close()
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	return st
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func runContract(t *testing.T, test func(t *testing.T, st *Storage)) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			st := b.open(t)
+			defer st.Close()
+			test(t, st)
+		})
+	}
+}
+
+func seedTeam(t *testing.T, st *Storage, teamName string, members ...models.TeamMember) {
+	t.Helper()
+	if err := st.Teams.Create(&models.Team{TeamName: teamName, Members: members}); err != nil {
+		t.Fatalf("failed to create team %s: %v", teamName, err)
+	}
+}
+
+func seedPR(t *testing.T, st *Storage, prID, authorID string, reviewers ...string) {
+	t.Helper()
+	now := time.Now().UTC()
+	pr := &models.PullRequest{
+		PullRequestID:     prID,
+		PullRequestName:   "PR " + prID,
+		AuthorID:          authorID,
+		Status:            "OPEN",
+		AssignedReviewers: reviewers,
+		CreatedAt:         &now,
+	}
+	if err := st.PullRequests.Create(pr); err != nil {
+		t.Fatalf("failed to create PR %s: %v", prID, err)
+	}
+}
+
+func member(id string, active bool) models.TeamMember {
+	return models.TeamMember{UserID: id, Username: "name-" + id, IsActive: active}
+}
+
+func sorted(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	return out
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestContract_Teams(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u2", true), member("u1", false))
+
+		team, err := st.Teams.GetByName("backend")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(team.Members) != 2 || team.Members[0].UserID != "u1" || team.Members[1].UserID != "u2" {
+			t.Errorf("expected members sorted by user_id, got %+v", team.Members)
+		}
+		if team.Members[0].IsActive {
+			t.Error("expected u1 to be inactive")
+		}
+
+		if _, err := st.Teams.GetByName("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows, got %v", err)
+		}
+
+		if err := st.Teams.Create(&models.Team{TeamName: "backend"}); err == nil {
+			t.Error("expected error on duplicate team")
+		}
+		// Существующий пользователь переходит в новую команду, а не ломает её создание
+		if err := st.Teams.Create(&models.Team{TeamName: "other", Members: []models.TeamMember{member("u1", true)}}); err != nil {
+			t.Fatalf("expected existing user to be upserted, got %v", err)
+		}
+		user, err := st.Users.GetByID("u1")
+		if err != nil || user.TeamName != "other" || !user.IsActive {
+			t.Errorf("expected u1 to move to other, got %+v, %v", user, err)
+		}
+	})
+}
+
+func TestContract_TeamMembership(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		ctx := context.Background()
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true))
+
+		tx, err := st.Transactor.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		steps := []error{
+			st.Teams.Add(tx, "payments"),
+			st.Users.Upsert(tx, &models.User{UserID: "u2", Username: "Bob", TeamName: "payments", IsActive: false}),
+			st.Users.Upsert(tx, &models.User{UserID: "u3", Username: "Carol", TeamName: "payments", IsActive: true}),
+			st.Users.Upsert(tx, &models.User{UserID: "u1", Username: "Alice"}),
+		}
+		for i, err := range steps {
+			if err != nil {
+				t.Fatalf("step %d failed: %v", i, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+
+		team, err := st.Teams.GetByName("payments")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(team.Members) != 2 || team.Members[0].UserID != "u2" || team.Members[0].IsActive || team.Members[1].UserID != "u3" {
+			t.Errorf("expected payments members [u2 u3], got %+v", team.Members)
+		}
+		user, err := st.Users.GetByID("u2")
+		if err != nil || user.Username != "Bob" {
+			t.Errorf("expected u2 to be renamed, got %+v, %v", user, err)
+		}
+
+		// Пользователь без команды остаётся в системе, но ни в одну команду не попадает
+		user, err = st.Users.GetByID("u1")
+		if err != nil || user.TeamName != "" {
+			t.Errorf("expected u1 without team, got %+v, %v", user, err)
+		}
+		backend, err := st.Teams.GetByName("backend")
+		if err != nil || len(backend.Members) != 0 {
+			t.Errorf("expected backend without members, got %+v, %v", backend, err)
+		}
+		active, err := st.Users.GetActiveTeamMembers("", "")
+		if err != nil || len(active) != 0 {
+			t.Errorf("teamless users must not be candidates, got %+v, %v", active, err)
+		}
+
+		tx, err = st.Transactor.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		if err := st.Teams.Add(tx, "backend"); err == nil {
+			t.Error("expected error on duplicate team")
+		}
+		tx.Rollback()
+	})
+}
+
+func TestContract_Users(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", false))
+
+		user, err := st.Users.GetByID("u1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.TeamName != "backend" || user.Username != "name-u1" || !user.IsActive {
+			t.Errorf("unexpected user: %+v", user)
+		}
+		if _, err := st.Users.GetByID("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows, got %v", err)
+		}
+
+		updated, err := st.Users.UpdateActivity("u3", true)
+		if err != nil || !updated.IsActive {
+			t.Fatalf("expected u3 to be activated, got %+v, %v", updated, err)
+		}
+		if _, err := st.Users.UpdateActivity("missing", true); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows, got %v", err)
+		}
+
+		active, err := st.Users.GetActiveTeamMembers("backend", "u2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(active) != 2 || active[0].UserID != "u1" || active[1].UserID != "u3" {
+			t.Errorf("expected [u1 u3], got %+v", active)
+		}
+
+		users, err := st.Users.GetUsersByIDs([]string{"u1", "u2", "missing"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(users) != 2 {
+			t.Errorf("expected 2 users, got %d", len(users))
+		}
+
+		all, err := st.Users.List()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := make([]string, 0, len(all))
+		for _, u := range all {
+			ids = append(ids, u.UserID)
+		}
+		if !equalStrings(ids, []string{"u1", "u2", "u3"}) {
+			t.Errorf("expected all users ordered by id, got %v", ids)
+		}
+	})
+}
+
+func TestContract_TransactionCommitAndRollback(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		ctx := context.Background()
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedPR(t, st, "pr-1", "u1", "u2")
+
+		tx, err := st.Transactor.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		if err := st.Users.DeactivateUsers(tx, []string{"u2"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.RemoveReviewer(tx, "pr-1", "u2"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("failed to rollback: %v", err)
+		}
+
+		user, _ := st.Users.GetByID("u2")
+		pr, _ := st.PullRequests.GetByID("pr-1")
+		if !user.IsActive || !equalStrings(pr.AssignedReviewers, []string{"u2"}) {
+			t.Fatalf("rollback must discard changes, got user %+v, reviewers %v", user, pr.AssignedReviewers)
+		}
+
+		tx, err = st.Transactor.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		steps := []error{
+			st.Users.DeactivateUsers(tx, []string{"u1", "u2"}),
+			st.PullRequests.ReassignAuthor(tx, "pr-1", "u3"),
+			st.PullRequests.RemoveReviewer(tx, "pr-1", "u2"),
+			st.PullRequests.AddReviewer(tx, "pr-1", "u1"),
+			st.PullRequests.AddReviewer(tx, "pr-1", "u1"),
+		}
+		for i, err := range steps {
+			if err != nil {
+				t.Fatalf("step %d failed: %v", i, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+
+		pr, err = st.PullRequests.GetByID("pr-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pr.AuthorID != "u3" || !equalStrings(pr.AssignedReviewers, []string{"u1"}) {
+			t.Errorf("unexpected PR after commit: %+v", pr)
+		}
+		active, _ := st.Users.GetActiveTeamMembers("backend", "")
+		if len(active) != 1 || active[0].UserID != "u3" {
+			t.Errorf("expected only u3 to stay active, got %+v", active)
+		}
+	})
+}
+
+func TestContract_PullRequests(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedPR(t, st, "pr-2", "u1", "u2", "u3")
+		seedPR(t, st, "pr-1", "u2", "u3")
+		seedPR(t, st, "pr-3", "u3")
+
+		pr, err := st.PullRequests.GetByID("pr-2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pr.Status != "OPEN" || pr.CreatedAt == nil || pr.MergedAt != nil {
+			t.Errorf("unexpected PR: %+v", pr)
+		}
+		if !equalStrings(sorted(pr.AssignedReviewers), []string{"u2", "u3"}) {
+			t.Errorf("expected reviewers [u2 u3], got %v", pr.AssignedReviewers)
+		}
+
+		noReviewers, err := st.PullRequests.GetByID("pr-3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if noReviewers.AssignedReviewers == nil || len(noReviewers.AssignedReviewers) != 0 {
+			t.Errorf("expected empty non-nil reviewers, got %#v", noReviewers.AssignedReviewers)
+		}
+
+		if _, err := st.PullRequests.GetByID("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows, got %v", err)
+		}
+		if err := st.PullRequests.Create(&models.PullRequest{PullRequestID: "pr-1", PullRequestName: "dup", AuthorID: "u1", Status: "OPEN"}); err == nil {
+			t.Error("expected error on duplicate PR")
+		}
+		if err := st.PullRequests.Create(&models.PullRequest{PullRequestID: "pr-x", PullRequestName: "x", AuthorID: "missing", Status: "OPEN"}); err == nil {
+			t.Error("expected error on unknown author")
+		}
+
+		reviews, err := st.PullRequests.GetByReviewerID("u3", repository.ReviewQuery{SortBy: repository.PRSortID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(reviews) != 2 || reviews[0].PullRequestID != "pr-1" || reviews[1].PullRequestID != "pr-2" {
+			t.Errorf("expected [pr-1 pr-2], got %+v", reviews)
+		}
+
+		if err := st.PullRequests.UpdateReviewers("pr-2", []string{"u3"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.UpdateStatus("pr-1", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		merged, _ := st.PullRequests.GetByID("pr-1")
+		if merged.Status != "MERGED" || merged.MergedAt == nil {
+			t.Errorf("expected merged PR with merged_at, got %+v", merged)
+		}
+
+		byAuthors, err := st.PullRequests.GetOpenPRsByAuthors([]string{"u1", "u2"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(byAuthors) != 1 || byAuthors[0].PullRequestID != "pr-2" {
+			t.Errorf("expected only open pr-2, got %+v", byAuthors)
+		}
+
+		byReviewers, err := st.PullRequests.GetOpenPRsByReviewers([]string{"u2", "u3"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(byReviewers["u2"]) != 0 || len(byReviewers["u3"]) != 1 || byReviewers["u3"][0].PullRequestID != "pr-2" {
+			t.Errorf("unexpected open PRs by reviewers: %+v", byReviewers)
+		}
+	})
+}
+
+func TestContract_PullRequestEvents(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true), member("u4", true))
+		seedPR(t, st, "pr-1", "u1", "u2", "u3")
+
+		if err := st.PullRequests.UpdateReviewers("pr-1", []string{"u3", "u4"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.ReassignAuthor(tx, "pr-1", "u2"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.RemoveReviewer(tx, "pr-1", "u4"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.RemoveReviewer(tx, "pr-1", "u1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := st.PullRequests.UpdateStatus("pr-1", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		pr, err := st.PullRequests.GetByID("pr-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalStrings(pr.AssignedReviewers, []string{"u3"}) {
+			t.Errorf("expected reviewers [u3], got %v", pr.AssignedReviewers)
+		}
+
+		events, err := st.PullRequests.GetEvents("pr-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []models.PREvent{
+			{Type: models.EventPRCreated, UserID: "u1"},
+			{Type: models.EventReviewerAssigned, UserID: "u2"},
+			{Type: models.EventReviewerAssigned, UserID: "u3"},
+			{Type: models.EventReviewerRemoved, UserID: "u2"},
+			{Type: models.EventReviewerAssigned, UserID: "u4"},
+			{Type: models.EventAuthorChanged, UserID: "u2", PreviousUserID: "u1"},
+			{Type: models.EventReviewerRemoved, UserID: "u4"},
+			{Type: models.EventPRMerged},
+		}
+		if len(events) != len(want) {
+			t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+		}
+		for i, e := range events {
+			if e.PullRequestID != "pr-1" || e.Type != want[i].Type || e.UserID != want[i].UserID || e.PreviousUserID != want[i].PreviousUserID {
+				t.Errorf("event %d: expected %+v, got %+v", i, want[i], *e)
+			}
+			if i > 0 && e.ID <= events[i-1].ID {
+				t.Errorf("event ids must increase, got %d after %d", e.ID, events[i-1].ID)
+			}
+			if e.CreatedAt.IsZero() {
+				t.Errorf("event %d has no created_at", i)
+			}
+		}
+
+		empty, err := st.PullRequests.GetEvents("missing")
+		if err != nil || empty == nil || len(empty) != 0 {
+			t.Errorf("expected empty non-nil slice, got %v, %v", empty, err)
+		}
+	})
+}
+
+func TestContract_ImportPullRequests(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", false))
+
+		createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		mergedAt := createdAt.Add(26 * time.Hour)
+		imported := &models.PullRequest{
+			PullRequestID:     "pr-old",
+			PullRequestName:   "Old",
+			AuthorID:          "u1",
+			Status:            "MERGED",
+			AssignedReviewers: []string{"u3", "u2"},
+			CreatedAt:         &createdAt,
+			MergedAt:          &mergedAt,
+		}
+
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.Import(tx, imported); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := st.PullRequests.GetByID("pr-old"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected rolled back import to leave no PR, got %v", err)
+		}
+
+		tx, err = st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.Import(tx, imported); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		pr, err := st.PullRequests.GetByID("pr-old")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pr.Status != "MERGED" || !equalStrings(sorted(pr.AssignedReviewers), []string{"u2", "u3"}) {
+			t.Errorf("unexpected imported PR: %+v", pr)
+		}
+		if pr.CreatedAt == nil || !pr.CreatedAt.Equal(createdAt) || pr.MergedAt == nil || !pr.MergedAt.Equal(mergedAt) {
+			t.Errorf("expected original timestamps, got %v / %v", pr.CreatedAt, pr.MergedAt)
+		}
+
+		events, err := st.PullRequests.GetEvents("pr-old")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) != 4 {
+			t.Fatalf("expected 4 events, got %+v", events)
+		}
+		if events[0].Type != models.EventPRCreated || !events[0].CreatedAt.Equal(createdAt) {
+			t.Errorf("unexpected first event: %+v", events[0])
+		}
+		if last := events[3]; last.Type != models.EventPRMerged || !last.CreatedAt.Equal(mergedAt) {
+			t.Errorf("unexpected last event: %+v", last)
+		}
+
+		tx, err = st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer tx.Rollback()
+		if err := st.PullRequests.Import(tx, imported); !errors.Is(err, repository.ErrPRExists) {
+			t.Errorf("expected ErrPRExists for duplicate import, got %v", err)
+		}
+		// Конфликт не должен прерывать транзакцию
+		next := &models.PullRequest{PullRequestID: "pr-next", PullRequestName: "Next", AuthorID: "u1", Status: "OPEN", CreatedAt: &createdAt}
+		if err := st.PullRequests.Import(tx, next); err != nil {
+			t.Fatalf("unexpected error after conflict: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := st.PullRequests.GetByID("pr-next"); err != nil {
+			t.Errorf("expected PR imported after conflict, got %v", err)
+		}
+	})
+}
+
+func TestContract_ExistingPullRequestIDs(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true))
+		seedPR(t, st, "pr-1", "u1")
+		seedPR(t, st, "pr-2", "u1")
+
+		// Больше одной пачки запроса
+		ids := []string{"pr-1"}
+		for i := 0; i < 1200; i++ {
+			ids = append(ids, fmt.Sprintf("pr-missing-%d", i))
+		}
+		ids = append(ids, "pr-2")
+
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer tx.Rollback()
+
+		existing, err := st.PullRequests.ExistingIDs(tx, ids)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(existing) != 2 || !existing["pr-1"] || !existing["pr-2"] {
+			t.Errorf("expected pr-1 and pr-2, got %v", existing)
+		}
+
+		empty, err := st.PullRequests.ExistingIDs(tx, nil)
+		if err != nil || len(empty) != 0 {
+			t.Errorf("expected no ids, got %v, %v", empty, err)
+		}
+	})
+}
+
+func TestContract_ListPullRequests(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedTeam(t, st, "frontend", member("f1", true))
+
+		base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		create := func(prID, authorID string, hoursAfter int, reviewers ...string) {
+			createdAt := base.Add(time.Duration(hoursAfter) * time.Hour)
+			pr := &models.PullRequest{
+				PullRequestID:     prID,
+				PullRequestName:   "PR " + prID,
+				AuthorID:          authorID,
+				Status:            "OPEN",
+				AssignedReviewers: reviewers,
+				CreatedAt:         &createdAt,
+			}
+			if err := st.PullRequests.Create(pr); err != nil {
+				t.Fatalf("failed to create PR %s: %v", prID, err)
+			}
+		}
+		// pr-a и pr-b созданы одновременно: порядок между ними задаёт pull_request_id
+		create("pr-c", "u1", 0, "u2", "u3")
+		create("pr-b", "u2", 1, "u1")
+		create("pr-a", "u3", 1)
+		create("pr-d", "f1", 2)
+		create("pr-e", "u1", 3, "u2")
+		if err := st.PullRequests.UpdateStatus("pr-e", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ids := func(prs []*models.PullRequest) []string {
+			out := make([]string, 0, len(prs))
+			for _, pr := range prs {
+				out = append(out, pr.PullRequestID)
+			}
+			return out
+		}
+		at := func(hoursAfter int) *time.Time {
+			v := base.Add(time.Duration(hoursAfter) * time.Hour)
+			return &v
+		}
+		byCreated := repository.PullRequestPage{SortBy: repository.PRSortCreatedAt}
+
+		tests := []struct {
+			name   string
+			filter repository.PullRequestFilter
+			page   repository.PullRequestPage
+			want   []string
+		}{
+			{"all by created_at", repository.PullRequestFilter{}, byCreated, []string{"pr-c", "pr-a", "pr-b", "pr-d", "pr-e"}},
+			{"all by created_at desc", repository.PullRequestFilter{}, repository.PullRequestPage{SortBy: repository.PRSortCreatedAt, Desc: true}, []string{"pr-e", "pr-d", "pr-b", "pr-a", "pr-c"}},
+			{"all by id", repository.PullRequestFilter{}, repository.PullRequestPage{SortBy: repository.PRSortID}, []string{"pr-a", "pr-b", "pr-c", "pr-d", "pr-e"}},
+			{"status", repository.PullRequestFilter{Status: "MERGED"}, byCreated, []string{"pr-e"}},
+			{"author", repository.PullRequestFilter{AuthorID: "u1"}, byCreated, []string{"pr-c", "pr-e"}},
+			{"reviewer", repository.PullRequestFilter{ReviewerID: "u2"}, byCreated, []string{"pr-c", "pr-e"}},
+			{"author team", repository.PullRequestFilter{TeamName: "frontend"}, byCreated, []string{"pr-d"}},
+			{"created range", repository.PullRequestFilter{CreatedFrom: at(1), CreatedTo: at(3)}, byCreated, []string{"pr-a", "pr-b", "pr-d"}},
+			{"merged range", repository.PullRequestFilter{MergedFrom: at(-1)}, byCreated, []string{"pr-e"}},
+			{"merged before range", repository.PullRequestFilter{MergedTo: at(0)}, byCreated, []string{}},
+			{"needs reviewers", repository.PullRequestFilter{ReviewersBelow: 2}, byCreated, []string{"pr-a", "pr-b", "pr-d"}},
+			{"combined", repository.PullRequestFilter{TeamName: "backend", Status: "OPEN", ReviewersBelow: 2}, byCreated, []string{"pr-a", "pr-b"}},
+			{"limit", repository.PullRequestFilter{}, repository.PullRequestPage{SortBy: repository.PRSortCreatedAt, Limit: 2}, []string{"pr-c", "pr-a"}},
+			{
+				"after cursor with equal created_at",
+				repository.PullRequestFilter{},
+				repository.PullRequestPage{SortBy: repository.PRSortCreatedAt, After: &repository.PullRequestCursor{At: *at(1), PullRequestID: "pr-a"}},
+				[]string{"pr-b", "pr-d", "pr-e"},
+			},
+			{
+				"after cursor desc",
+				repository.PullRequestFilter{},
+				repository.PullRequestPage{SortBy: repository.PRSortCreatedAt, Desc: true, After: &repository.PullRequestCursor{At: *at(1), PullRequestID: "pr-b"}},
+				[]string{"pr-a", "pr-c"},
+			},
+			{
+				"after cursor by id",
+				repository.PullRequestFilter{},
+				repository.PullRequestPage{SortBy: repository.PRSortID, Desc: true, After: &repository.PullRequestCursor{PullRequestID: "pr-c"}},
+				[]string{"pr-b", "pr-a"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				prs, err := st.PullRequests.List(tt.filter, tt.page)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := ids(prs); !equalStrings(got, tt.want) {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			})
+		}
+
+		prs, err := st.PullRequests.List(repository.PullRequestFilter{AuthorID: "u1"}, byCreated)
+		if err != nil || len(prs) != 2 {
+			t.Fatalf("unexpected result: %v, %v", prs, err)
+		}
+		if !equalStrings(sorted(prs[0].AssignedReviewers), []string{"u2", "u3"}) || !prs[0].CreatedAt.Equal(base) {
+			t.Errorf("unexpected pr-c: %+v", prs[0])
+		}
+		if prs[1].Status != "MERGED" || prs[1].MergedAt == nil {
+			t.Errorf("expected merged pr-e with merged_at, got %+v", prs[1])
+		}
+	})
+}
+
+func TestContract_ReviewInbox(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+
+		// Назначения идут по очереди, чтобы assigned_at различались
+		createdAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		if err := st.PullRequests.Create(&models.PullRequest{PullRequestID: "pr-x", PullRequestName: "own", AuthorID: "u1", Status: "OPEN", AssignedReviewers: []string{"u2"}, CreatedAt: &createdAt}); err != nil {
+			t.Fatalf("failed to create PR: %v", err)
+		}
+		for _, prID := range []string{"pr-w", "pr-z", "pr-y"} {
+			time.Sleep(2 * time.Millisecond)
+			seedPR(t, st, prID, "u2", "u1")
+		}
+		if err := st.PullRequests.UpdateStatus("pr-w", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		all, err := st.PullRequests.GetByReviewerID("u1", repository.ReviewQuery{SortBy: repository.PRSortID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assigned := make(map[string]time.Time)
+		for _, pr := range all {
+			if pr.Role != models.ReviewRoleReviewer || pr.AssignedAt == nil {
+				t.Fatalf("expected reviewer entry with assigned_at, got %+v", pr)
+			}
+			assigned[pr.PullRequestID] = *pr.AssignedAt
+		}
+		if !assigned["pr-w"].Before(assigned["pr-z"]) || !assigned["pr-z"].Before(assigned["pr-y"]) {
+			t.Fatalf("expected increasing assigned_at, got %v", assigned)
+		}
+
+		ids := func(prs []*models.PullRequestShort) []string {
+			out := make([]string, 0, len(prs))
+			for _, pr := range prs {
+				out = append(out, pr.PullRequestID)
+			}
+			return out
+		}
+		open := repository.ReviewQuery{Status: "OPEN", SortBy: repository.ReviewSortAssignedAt}
+		withAuthored := open
+		withAuthored.IncludeAuthored = true
+		afterZ := open
+		afterZ.After = &repository.PullRequestCursor{At: assigned["pr-z"], PullRequestID: "pr-z"}
+		limited := withAuthored
+		limited.Limit = 2
+
+		tests := []struct {
+			name string
+			q    repository.ReviewQuery
+			want []string
+		}{
+			{"any status by id", repository.ReviewQuery{SortBy: repository.PRSortID}, []string{"pr-w", "pr-y", "pr-z"}},
+			{"merged", repository.ReviewQuery{Status: "MERGED", SortBy: repository.PRSortID}, []string{"pr-w"}},
+			{"oldest waiting", open, []string{"pr-z", "pr-y"}},
+			{"include authored", withAuthored, []string{"pr-x", "pr-z", "pr-y"}},
+			{"after cursor", afterZ, []string{"pr-y"}},
+			{"after cursor by id", repository.ReviewQuery{SortBy: repository.PRSortID, After: &repository.PullRequestCursor{PullRequestID: "pr-w"}}, []string{"pr-y", "pr-z"}},
+			{"limit", limited, []string{"pr-x", "pr-z"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				prs, err := st.PullRequests.GetByReviewerID("u1", tt.q)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := ids(prs); !equalStrings(got, tt.want) {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			})
+		}
+
+		prs, err := st.PullRequests.GetByReviewerID("u1", withAuthored)
+		if err != nil || len(prs) != 3 {
+			t.Fatalf("unexpected result: %v, %v", prs, err)
+		}
+		if prs[0].Role != models.ReviewRoleAuthor || prs[0].AssignedAt == nil || !prs[0].AssignedAt.Equal(createdAt) {
+			t.Errorf("expected authored pr-x waiting since creation, got %+v", prs[0])
+		}
+		if merged := all[0]; merged.Status != "MERGED" || merged.MergedAt == nil {
+			t.Errorf("expected merged pr-w with merged_at, got %+v", merged)
+		}
+	})
+}
+
+func TestContract_Statistics(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", false))
+		seedTeam(t, st, "frontend", member("f1", true))
+		seedPR(t, st, "pr-1", "u1", "u2")
+		seedPR(t, st, "pr-2", "u2", "u1")
+		seedPR(t, st, "pr-3", "f1", "u1")
+		if err := st.PullRequests.UpdateStatus("pr-3", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		stats, _, err := st.Statistics.GetStatistics(nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.Teams.Total != 2 || stats.Users.Total != 4 || stats.Users.Active != 3 || stats.Users.Inactive != 1 {
+			t.Errorf("unexpected team/user stats: %+v", stats)
+		}
+		if stats.PullRequests.Total != 3 || stats.PullRequests.Open != 2 || stats.PullRequests.Merged != 1 {
+			t.Errorf("unexpected PR stats: %+v", stats.PullRequests)
+		}
+		if stats.ReviewAssignments.Total != 3 || len(stats.ReviewAssignments.ByReviewer) != 2 {
+			t.Fatalf("unexpected assignment stats: %+v", stats.ReviewAssignments)
+		}
+		if top := stats.ReviewAssignments.ByReviewer[0]; top.UserID != "u1" || top.Count != 2 {
+			t.Errorf("expected u1 with 2 assignments first, got %+v", top)
+		}
+	})
+}
+
+func TestContract_TeamLoad(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", false))
+		seedTeam(t, st, "frontend", member("f1", true))
+		seedPR(t, st, "pr-1", "u1", "u2")
+		seedPR(t, st, "pr-2", "u2", "u1")
+		seedPR(t, st, "pr-3", "f1", "u1", "u2")
+		if err := st.PullRequests.UpdateStatus("pr-3", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		members, err := st.Statistics.GetTeamLoad("backend")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []models.MemberLoad{
+			{UserID: "u1", Username: "name-u1", IsActive: true, OpenAssignments: 1, TotalAssignments: 2, OpenAuthored: 1, TotalAuthored: 1},
+			{UserID: "u2", Username: "name-u2", IsActive: true, OpenAssignments: 1, TotalAssignments: 2, OpenAuthored: 1, TotalAuthored: 1},
+			{UserID: "u3", Username: "name-u3", IsActive: false},
+		}
+		if len(members) != len(want) {
+			t.Fatalf("expected %d members, got %+v", len(want), members)
+		}
+		for i := range want {
+			if members[i] != want[i] {
+				t.Errorf("member %d: expected %+v, got %+v", i, want[i], members[i])
+			}
+		}
+
+		if _, err := st.Statistics.GetTeamLoad("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing team, got %v", err)
+		}
+	})
+}
+
+func TestContract_ReviewerLoad(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true))
+		seedTeam(t, st, "frontend", member("f1", true))
+		seedPR(t, st, "pr-1", "u1", "u2", "f1")
+		seedPR(t, st, "pr-2", "f1", "u1")
+		if err := st.PullRequests.UpdateStatus("pr-2", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loads, err := st.Statistics.GetReviewerLoad(nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []models.ReviewerLoad{
+			{UserID: "f1", TeamName: "frontend", OpenAssignments: 1, TotalAssignments: 1},
+			{UserID: "u1", TeamName: "backend", OpenAssignments: 0, TotalAssignments: 1},
+			{UserID: "u2", TeamName: "backend", OpenAssignments: 1, TotalAssignments: 1},
+		}
+		if len(loads) != len(want) {
+			t.Fatalf("expected %d reviewers, got %+v", len(want), loads)
+		}
+		for i := range want {
+			if loads[i] != want[i] {
+				t.Errorf("reviewer %d: expected %+v, got %+v", i, want[i], loads[i])
+			}
+		}
+
+		// Снимок статистики отдаёт ту же нагрузку
+		if _, snapshot, err := st.Statistics.GetStatistics(nil, nil); err != nil || !reflect.DeepEqual(snapshot, loads) {
+			t.Errorf("expected statistics snapshot load %+v, got %+v, %v", loads, snapshot, err)
+		}
+
+		future := time.Now().Add(time.Hour)
+		if loads, err := st.Statistics.GetReviewerLoad(&future, nil); err != nil || len(loads) != 0 {
+			t.Errorf("expected no load after %v, got %+v, %v", future, loads, err)
+		}
+		past := time.Now().Add(-time.Hour)
+		if loads, err := st.Statistics.GetReviewerLoad(&past, &future); err != nil || len(loads) != len(want) {
+			t.Errorf("expected %d reviewers within range, got %+v, %v", len(want), loads, err)
+		}
+	})
+}
+
+func TestContract_ReviewSLA(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedTeam(t, st, "frontend", member("f1", true), member("f2", true))
+		seedPR(t, st, "pr-1", "u1", "u2", "u3")
+		seedPR(t, st, "pr-merged", "u1", "u2")
+		seedPR(t, st, "pr-front", "f1", "f2")
+		if err := st.PullRequests.UpdateStatus("pr-merged", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := st.PullRequests.SubmitReview("pr-1", "u3"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := st.SLAs.Set(&models.TeamSLA{TeamName: "missing", FirstReviewHours: 24}); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing team, got %v", err)
+		}
+		if _, err := st.SLAs.Get("backend"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows before SLA is set, got %v", err)
+		}
+		reassignAfter := 48
+		if err := st.SLAs.Set(&models.TeamSLA{TeamName: "backend", FirstReviewHours: 12}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.SLAs.Set(&models.TeamSLA{TeamName: "backend", FirstReviewHours: 24, ReassignAfterHours: &reassignAfter}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sla, err := st.SLAs.Get("backend")
+		if err != nil || sla.FirstReviewHours != 24 || sla.ReassignAfterHours == nil || *sla.ReassignAfterHours != 48 {
+			t.Fatalf("expected replaced SLA, got %+v, %v", sla, err)
+		}
+
+		// Только открытые PR команды со сроком и без отправленного ревью
+		pending, err := st.SLAs.ListPending("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(pending) != 1 {
+			t.Fatalf("expected one pending review, got %+v", pending)
+		}
+		p := pending[0]
+		if p.PullRequestID != "pr-1" || p.ReviewerID != "u2" || p.AuthorID != "u1" || p.AssignedAt.IsZero() ||
+			p.RemindedAt != nil || p.SLA.TeamName != "backend" || p.SLA.FirstReviewHours != 24 {
+			t.Errorf("unexpected pending review: %+v", p)
+		}
+		if other, err := st.SLAs.ListPending("frontend"); err != nil || len(other) != 0 {
+			t.Errorf("expected no pending reviews for frontend, got %+v, %v", other, err)
+		}
+
+		at := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+		if err := st.SLAs.MarkReminded("pr-1", "u2", at); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.SLAs.MarkReminded("pr-1", "f1", at); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing assignment, got %v", err)
+		}
+		pending, err = st.SLAs.ListPending("backend")
+		if err != nil || len(pending) != 1 || pending[0].RemindedAt == nil || !pending[0].RemindedAt.Equal(at) {
+			t.Errorf("expected reminded_at %v, got %+v, %v", at, pending, err)
+		}
+	})
+}
+
+func TestContract_Schedules(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true))
+
+		wh := &models.WorkingHours{TimeZone: "Europe/Moscow", Periods: []models.WorkingPeriod{
+			{Weekday: "monday", Start: "09:00", End: "13:00"},
+			{Weekday: "monday", Start: "14:00", End: "18:00"},
+		}}
+		if err := st.Schedules.SetWorkingHours("missing", wh); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing user, got %v", err)
+		}
+		if _, err := st.Schedules.GetWorkingHours("u1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows before working hours are set, got %v", err)
+		}
+		if err := st.Schedules.SetWorkingHours("u1", &models.WorkingHours{TimeZone: "UTC", Periods: wh.Periods[:1]}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Schedules.SetWorkingHours("u1", wh); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := st.Schedules.GetWorkingHours("u1")
+		if err != nil || got.TimeZone != "Europe/Moscow" || len(got.Periods) != 2 || got.Periods[1] != wh.Periods[1] {
+			t.Fatalf("expected replaced working hours, got %+v, %v", got, err)
+		}
+		all, err := st.Schedules.ListWorkingHours([]string{"u1", "u2", "missing"})
+		if err != nil || len(all) != 1 || all["u1"] == nil {
+			t.Errorf("expected working hours only for u1, got %+v, %v", all, err)
+		}
+
+		if err := st.Schedules.SetHolidays("missing", nil); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing team, got %v", err)
+		}
+		if _, err := st.Schedules.GetHolidays("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing team, got %v", err)
+		}
+		if err := st.Schedules.SetHolidays("backend", []models.Holiday{{Date: "2025-05-01"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		holidays := []models.Holiday{{Date: "2026-01-02"}, {Date: "2026-01-01", Name: "New Year"}}
+		if err := st.Schedules.SetHolidays("backend", holidays); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		stored, err := st.Schedules.GetHolidays("backend")
+		if err != nil || len(stored) != 2 || stored[0] != holidays[1] || stored[1] != holidays[0] {
+			t.Errorf("expected replaced holidays sorted by date, got %+v, %v", stored, err)
+		}
+		byTeam, err := st.Schedules.ListHolidays([]string{"backend", "missing"})
+		if err != nil || len(byTeam) != 1 || len(byTeam["backend"]) != 2 {
+			t.Errorf("expected holidays only for backend, got %+v, %v", byTeam, err)
+		}
+		if err := st.Schedules.SetHolidays("backend", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cleared, err := st.Schedules.GetHolidays("backend"); err != nil || len(cleared) != 0 {
+			t.Errorf("expected no holidays after reset, got %+v, %v", cleared, err)
+		}
+	})
+}
+
+func TestContract_NotificationPreferences(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", false), member("u4", true))
+
+		prefs := func(userID string, digest bool) *models.NotificationPreferences {
+			return &models.NotificationPreferences{UserID: userID, Email: userID + "@example.com",
+				DailyDigest: digest, AssignmentNotices: true, OverdueReminders: !digest}
+		}
+		if err := st.Notifications.SetPreferences(prefs("missing", true)); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing user, got %v", err)
+		}
+		if _, err := st.Notifications.GetPreferences("u1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows before preferences are set, got %v", err)
+		}
+		if err := st.Notifications.SetPreferences(prefs("u1", false)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, p := range []*models.NotificationPreferences{prefs("u1", true), prefs("u2", false), prefs("u3", true), prefs("u4", true)} {
+			if err := st.Notifications.SetPreferences(p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		got, err := st.Notifications.GetPreferences("u1")
+		if err != nil || *got != *prefs("u1", true) {
+			t.Fatalf("expected replaced preferences, got %+v, %v", got, err)
+		}
+		byUser, err := st.Notifications.ListPreferences([]string{"u1", "u2", "missing"})
+		if err != nil || len(byUser) != 2 || byUser["u2"] == nil || byUser["u2"].DailyDigest {
+			t.Errorf("expected preferences for u1 and u2, got %+v, %v", byUser, err)
+		}
+
+		if err := st.Schedules.SetWorkingHours("u4", &models.WorkingHours{TimeZone: "Europe/Moscow",
+			Periods: []models.WorkingPeriod{{Weekday: "monday", Start: "09:00", End: "18:00"}}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// u2 отписан от сводки, u3 неактивен
+		recipients, err := st.Notifications.ListDigestRecipients()
+		if err != nil || len(recipients) != 2 {
+			t.Fatalf("expected digest recipients u1 and u4, got %+v, %v", recipients, err)
+		}
+		want := repository.DigestRecipient{UserID: "u1", Username: "name-u1", TeamName: "backend", Email: "u1@example.com"}
+		if recipients[0] != want || recipients[1].UserID != "u4" || recipients[1].TimeZone != "Europe/Moscow" {
+			t.Errorf("unexpected digest recipients %+v", recipients)
+		}
+
+		if err := st.Notifications.MarkDigestSent("u1", "2025-10-22"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recipients, err = st.Notifications.ListDigestRecipients()
+		if err != nil || recipients[0].LastDigestOn != "2025-10-22" || recipients[1].LastDigestOn != "" {
+			t.Errorf("expected digest marked sent for u1 only, got %+v, %v", recipients, err)
+		}
+		// Новые подписки не сбрасывают отметку о сводке
+		if err := st.Notifications.SetPreferences(prefs("u1", true)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if recipients, err = st.Notifications.ListDigestRecipients(); err != nil || recipients[0].LastDigestOn != "2025-10-22" {
+			t.Errorf("expected digest mark to survive preference update, got %+v, %v", recipients, err)
+		}
+
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Users.Archive(tx, []string{"u4"}, time.Now().UTC()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Notifications.SetPreferences(prefs("u4", true)); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for archived user, got %v", err)
+		}
+		if recipients, err = st.Notifications.ListDigestRecipients(); err != nil || len(recipients) != 1 {
+			t.Errorf("expected archived user to get no digest, got %+v, %v", recipients, err)
+		}
+	})
+}
+
+func TestContract_ReviewTimings(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+
+		createdAt := time.Now().UTC().Add(-2 * time.Hour)
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.Import(tx, &models.PullRequest{
+			PullRequestID: "pr-1", PullRequestName: "PR", AuthorID: "u1", Status: "OPEN",
+			AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &createdAt,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		first, err := st.PullRequests.SubmitReview("pr-1", "u2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		again, err := st.PullRequests.SubmitReview("pr-1", "u2")
+		if err != nil || !again.Equal(first) {
+			t.Errorf("expected repeated review to keep %v, got %v, %v", first, again, err)
+		}
+		if _, err := st.PullRequests.SubmitReview("pr-1", "u3"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := st.PullRequests.SubmitReview("pr-1", "u1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for non-reviewer, got %v", err)
+		}
+		if err := st.PullRequests.UpdateStatus("pr-1", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		now := time.Now().UTC()
+		samples, err := st.Statistics.GetTimingSamples(now.Add(-time.Hour), now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		about2h := func(d time.Duration) bool {
+			return d >= 2*time.Hour && d < 2*time.Hour+time.Minute
+		}
+		if len(samples.Merge) != 1 || samples.Merge[0].TeamName != "backend" || samples.Merge[0].AuthorID != "u1" || !about2h(samples.Merge[0].Duration) ||
+			!equalStrings(samples.Merge[0].ReviewerIDs, []string{"u2", "u3"}) {
+			t.Errorf("unexpected merge samples: %+v", samples.Merge)
+		}
+		if len(samples.FirstReview) != 1 || !samples.FirstReview[0].At.Equal(first) || !about2h(samples.FirstReview[0].Duration) {
+			t.Errorf("unexpected first review samples: %+v", samples.FirstReview)
+		}
+		if len(samples.ReviewerResponse) != 2 {
+			t.Fatalf("expected 2 reviewer samples, got %+v", samples.ReviewerResponse)
+		}
+		for i, want := range []string{"u2", "u3"} {
+			got := samples.ReviewerResponse[i]
+			if !equalStrings(got.ReviewerIDs, []string{want}) || !about2h(got.Duration) {
+				t.Errorf("unexpected reviewer sample %d: %+v", i, got)
+			}
+		}
+
+		later, err := st.Statistics.GetTimingSamples(now.Add(time.Hour), now.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(later.Merge)+len(later.FirstReview)+len(later.ReviewerResponse) != 0 {
+			t.Errorf("expected no samples outside the range, got %+v", later)
+		}
+	})
+}
+
+func TestContract_Archive(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		ctx := context.Background()
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedTeam(t, st, "legacy", member("l1", true))
+		seedPR(t, st, "pr-1", "u1", "u2")
+
+		at := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+		tx, err := st.Transactor.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		steps := []error{
+			st.Users.Archive(tx, []string{"u2"}, at),
+			st.Users.Archive(tx, []string{"l1"}, at),
+			st.Teams.Archive(tx, "legacy", at),
+		}
+		for i, err := range steps {
+			if err != nil {
+				t.Fatalf("step %d failed: %v", i, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+
+		// Архивный пользователь остаётся доступным по идентификатору и в истории PR
+		user, err := st.Users.GetByID("u2")
+		if err != nil || user.IsActive || user.ArchivedAt == nil || !user.ArchivedAt.Equal(at) {
+			t.Fatalf("expected archived inactive u2, got %+v, %v", user, err)
+		}
+		pr, err := st.PullRequests.GetByID("pr-1")
+		if err != nil || !equalStrings(pr.AssignedReviewers, []string{"u2"}) {
+			t.Errorf("archiving must not rewrite PR history, got %+v, %v", pr, err)
+		}
+
+		active, err := st.Users.GetActiveTeamMembers("backend", "")
+		if err != nil || len(active) != 2 {
+			t.Errorf("expected archived user to be excluded from candidates, got %+v, %v", active, err)
+		}
+
+		all, err := st.Users.List()
+		if err != nil || len(all) != 4 || all[1].UserID != "u1" || all[2].ArchivedAt == nil {
+			t.Errorf("expected List to include archived users, got %+v, %v", all, err)
+		}
+
+		team, err := st.Teams.GetByName("legacy")
+		if err != nil || team.ArchivedAt == nil || len(team.Members) != 1 || team.Members[0].ArchivedAt == nil {
+			t.Errorf("expected archived legacy team with archived member, got %+v, %v", team, err)
+		}
+
+		teams, err := st.Teams.List()
+		if err != nil || len(teams) != 2 || teams[0].TeamName != "backend" || teams[1].ArchivedAt == nil {
+			t.Fatalf("expected [backend legacy] with archived legacy, got %+v, %v", teams, err)
+		}
+		if len(teams[0].Members) != 3 || teams[0].Members[1].ArchivedAt == nil || len(teams[1].Members) != 1 {
+			t.Errorf("expected List to return members like GetByName, got %+v", teams)
+		}
+
+		stats, _, err := st.Statistics.GetStatistics(nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.Teams.Total != 1 || stats.Users.Total != 2 || stats.Users.Active != 2 {
+			t.Errorf("archived entities must not be counted, got %+v", stats)
+		}
+
+		// Upsert восстанавливает архивного пользователя
+		tx, err = st.Transactor.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin tx: %v", err)
+		}
+		if err := st.Users.Upsert(tx, &models.User{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		user, err = st.Users.GetByID("u2")
+		if err != nil || user.ArchivedAt != nil || !user.IsActive {
+			t.Errorf("expected u2 to be restored, got %+v, %v", user, err)
+		}
+	})
+}
+
+func TestContract_Idempotency(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		expiresAt := time.Now().UTC().Add(time.Hour)
+
+		existing, err := st.Idempotency.Reserve("key-1", "hash-1", expiresAt)
+		if err != nil || existing != nil {
+			t.Fatalf("expected fresh reservation, got %+v, %v", existing, err)
+		}
+
+		existing, err = st.Idempotency.Reserve("key-1", "hash-1", expiresAt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if existing == nil || existing.Completed || existing.RequestHash != "hash-1" {
+			t.Fatalf("expected pending record, got %+v", existing)
+		}
+
+		body := []byte(`{"pr":{"pull_request_id":"pr-1"}}` + "\n")
+		if err := st.Idempotency.Save("key-1", 201, "application/json", body); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		existing, err = st.Idempotency.Reserve("key-1", "hash-1", expiresAt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !existing.Completed || existing.StatusCode != 201 || existing.ContentType != "application/json" || string(existing.Body) != string(body) {
+			t.Errorf("unexpected completed record: %+v", existing)
+		}
+
+		if err := st.Idempotency.Delete("key-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if existing, _ := st.Idempotency.Reserve("key-1", "hash-2", expiresAt); existing != nil {
+			t.Errorf("expected key to be free after delete, got %+v", existing)
+		}
+
+		if _, err := st.Idempotency.Reserve("key-old", "hash", time.Now().UTC().Add(-time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		deleted, err := st.Idempotency.DeleteExpired(time.Now().UTC())
+		if err != nil || deleted != 1 {
+			t.Errorf("expected 1 expired key deleted, got %d, %v", deleted, err)
+		}
+	})
+}
+
+func TestContract_ChatUsersAndAway(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+
+		if err := st.Chat.LinkUser("U1", "missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing user, got %v", err)
+		}
+		if _, err := st.Chat.GetUserID("U1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for unlinked chat user, got %v", err)
+		}
+		for chatUserID, userID := range map[string]string{"U1": "u1", "U2": "u2"} {
+			if err := st.Chat.LinkUser(chatUserID, userID); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		// Повторная привязка заменяет обе старые связи
+		if err := st.Chat.LinkUser("U1", "u2"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if userID, err := st.Chat.GetUserID("U1"); err != nil || userID != "u2" {
+			t.Errorf("expected U1 linked to u2, got %q, %v", userID, err)
+		}
+		if _, err := st.Chat.GetUserID("U2"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected old link of u2 to be removed, got %v", err)
+		}
+
+		now := time.Now().UTC()
+		if err := st.Users.SetAway("missing", now); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for missing user, got %v", err)
+		}
+		for _, userID := range []string{"u1", "u2", "u3"} {
+			if err := st.Users.SetAway(userID, now.Add(time.Hour)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := st.Users.SetAway("u2", now.Add(-time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		users, err := st.Users.GetUsersByIDs([]string{"u1", "u2", "u3"})
+		if err != nil || len(users) != 3 {
+			t.Fatalf("unexpected users %+v, %v", users, err)
+		}
+		for _, u := range users {
+			if u.IsActive {
+				t.Errorf("expected %s to be inactive while away", u.UserID)
+			}
+		}
+		// Явное изменение активности отменяет отсутствие: u3 не вернётся сам
+		if _, err := st.Users.UpdateActivity("u3", false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		returned, err := st.Users.ReturnFromAway(now)
+		if err != nil || returned != 1 {
+			t.Fatalf("expected only u2 to return, got %d, %v", returned, err)
+		}
+		for userID, wantActive := range map[string]bool{"u1": false, "u2": true, "u3": false} {
+			u, err := st.Users.GetByID(userID)
+			if err != nil || u.IsActive != wantActive {
+				t.Errorf("expected %s active=%v, got %+v, %v", userID, wantActive, u, err)
+			}
+		}
+		if returned, err := st.Users.ReturnFromAway(now.Add(2 * time.Hour)); err != nil || returned != 1 {
+			t.Errorf("expected only u1 to return later, got %d, %v", returned, err)
+		}
+
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Users.Archive(tx, []string{"u2"}, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := st.Chat.GetUserID("U1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected archived user to be unresolvable, got %v", err)
+		}
+		if err := st.Chat.LinkUser("U9", "u2"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows linking archived user, got %v", err)
+		}
+		if err := st.Users.SetAway("u2", now.Add(time.Hour)); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows for archived user, got %v", err)
+		}
+	})
+}
+
+func TestContract_EventLog(t *testing.T) {
+	runContract(t, func(t *testing.T, st *Storage) {
+		seedTeam(t, st, "backend", member("u1", true), member("u2", true), member("u3", true))
+		seedTeam(t, st, "frontend", member("f1", true))
+		start, err := st.Events.LastID()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		seedPR(t, st, "pr-1", "u1", "u2")
+		seedPR(t, st, "pr-2", "f1")
+		if _, err := st.PullRequests.SubmitReview("pr-1", "u2"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Повторная деактивация в журнал не пишется
+		for i := 0; i < 2; i++ {
+			if _, err := st.Users.UpdateActivity("u3", false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := st.Users.SetAway("u2", time.Now().UTC().Add(time.Hour)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.PullRequests.UpdateStatus("pr-1", "MERGED"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tx, err := st.Transactor.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Users.DeactivateUsers(tx, []string{"u3", "u1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := st.Users.Archive(tx, []string{"f1"}, time.Now().UTC()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		streamed := repository.EventFilter{Types: []string{models.EventPRCreated, models.EventReviewerAssigned,
+			models.EventReviewerRemoved, models.EventPRMerged, models.EventUserDeactivated}}
+		events, err := st.Events.ListAfter(streamed, start, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []models.StreamEvent{
+			{PREvent: models.PREvent{PullRequestID: "pr-1", Type: models.EventPRCreated, UserID: "u1"}, AuthorID: "u1", TeamName: "backend"},
+			{PREvent: models.PREvent{PullRequestID: "pr-1", Type: models.EventReviewerAssigned, UserID: "u2"}, AuthorID: "u1", TeamName: "backend"},
+			{PREvent: models.PREvent{PullRequestID: "pr-2", Type: models.EventPRCreated, UserID: "f1"}, AuthorID: "f1", TeamName: "frontend"},
+			{PREvent: models.PREvent{Type: models.EventUserDeactivated, UserID: "u3"}, TeamName: "backend"},
+			{PREvent: models.PREvent{Type: models.EventUserDeactivated, UserID: "u2"}, TeamName: "backend"},
+			{PREvent: models.PREvent{PullRequestID: "pr-1", Type: models.EventPRMerged}, AuthorID: "u1", TeamName: "backend"},
+			{PREvent: models.PREvent{Type: models.EventUserDeactivated, UserID: "u1"}, TeamName: "backend"},
+			{PREvent: models.PREvent{Type: models.EventUserDeactivated, UserID: "f1"}, TeamName: "frontend"},
+		}
+		if len(events) != len(want) {
+			t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+		}
+		for i, e := range events {
+			got := *e
+			got.ID, got.CreatedAt = 0, time.Time{}
+			if got != want[i] {
+				t.Errorf("event %d: expected %+v, got %+v", i, want[i], got)
+			}
+			if i > 0 && e.ID <= events[i-1].ID {
+				t.Errorf("event ids must increase, got %d after %d", e.ID, events[i-1].ID)
+			}
+		}
+
+		last, err := st.Events.LastID()
+		if err != nil || last != events[len(events)-1].ID {
+			t.Errorf("expected last id %d, got %d, %v", events[len(events)-1].ID, last, err)
+		}
+		// Без фильтра по типам журнал отдаётся целиком, включая REVIEW_SUBMITTED
+		if all, err := st.Events.ListAfter(repository.EventFilter{}, start, 100); err != nil || len(all) != len(want)+1 {
+			t.Errorf("expected %d events of all types, got %d, %v", len(want)+1, len(all), err)
+		}
+		if page, err := st.Events.ListAfter(streamed, events[0].ID, 2); err != nil || len(page) != 2 || page[0].ID != events[1].ID {
+			t.Errorf("expected page after first event, got %+v, %v", page, err)
+		}
+
+		ids := func(filter repository.EventFilter) []int {
+			t.Helper()
+			filter.Types = streamed.Types
+			got, err := st.Events.ListAfter(filter, start, 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			positions := make([]int, 0, len(got))
+			for _, e := range got {
+				for i := range events {
+					if events[i].ID == e.ID {
+						positions = append(positions, i)
+					}
+				}
+			}
+			return positions
+		}
+		for name, tt := range map[string]struct {
+			filter repository.EventFilter
+			want   []int
+		}{
+			"team":         {repository.EventFilter{TeamName: "frontend"}, []int{2, 7}},
+			"user":         {repository.EventFilter{UserID: "u1"}, []int{0, 1, 5, 6}},
+			"pull request": {repository.EventFilter{PullRequestID: "pr-2"}, []int{2}},
+			"combined":     {repository.EventFilter{TeamName: "backend", UserID: "u2"}, []int{1, 4}},
+		} {
+			if got := ids(tt.filter); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("%s filter: expected events %v, got %v", name, tt.want, got)
+			}
+		}
+	})
+}
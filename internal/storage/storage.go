@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	_ "github.com/lib/pq"
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/migrate"
+	"github.com/reviewer-service/internal/repository"
+	"github.com/reviewer-service/internal/repository/memory"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+	DriverSQLite   = "sqlite"
+)
+
+// Storage объединяет репозитории одного бэкенда хранилища.
+type Storage struct {
+	Teams         repository.TeamRepository
+	Users         repository.UserRepository
+	PullRequests  repository.PullRequestRepository
+	Statistics    repository.StatisticsRepository
+	SLAs          repository.SLARepository
+	Schedules     repository.ScheduleRepository
+	Notifications repository.NotificationRepository
+	Chat          repository.ChatRepository
+	Events        repository.EventRepository
+	Idempotency   repository.IdempotencyRepository
+	Transactor    repository.Transactor
+
+	// Driver и DB нужны для миграций; у in-memory бэкенда DB == nil.
+	Driver string
+	DB     *sql.DB
+
+	close func() error
+}
+
+func Open(cfg *config.Config, logger *slog.Logger) (*Storage, error) {
+	switch cfg.Storage.Driver {
+	case DriverPostgres:
+		db, err := connectPostgres(cfg.Database, logger)
+		if err != nil {
+			return nil, err
+		}
+		return NewSQL(db, DriverPostgres), nil
+	case DriverSQLite:
+		db, err := OpenSQLite(cfg.Storage.SQLitePath)
+		if err != nil {
+			logger.Error("failed to open sqlite database", "error", err, "path", cfg.Storage.SQLitePath)
+			return nil, err
+		}
+		logger.Info("sqlite database opened", "path", cfg.Storage.SQLitePath)
+		return NewSQL(db, DriverSQLite), nil
+	case DriverMemory:
+		logger.Warn("using in-memory storage, data will be lost on restart")
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// NewSQL собирает репозитории поверх *sql.DB. Запросы переносимы между
+// PostgreSQL и SQLite, поэтому реализация у обоих драйверов общая.
+func NewSQL(db *sql.DB, driver string) *Storage {
+	return &Storage{
+		Teams:         repository.NewTeamRepository(db),
+		Users:         repository.NewUserRepository(db),
+		PullRequests:  repository.NewPullRequestRepository(db),
+		Statistics:    repository.NewStatisticsRepository(db),
+		SLAs:          repository.NewSLARepository(db),
+		Schedules:     repository.NewScheduleRepository(db),
+		Notifications: repository.NewNotificationRepository(db),
+		Chat:          repository.NewChatRepository(db),
+		Events:        repository.NewEventRepository(db),
+		Idempotency:   repository.NewIdempotencyRepository(db),
+		Transactor:    repository.NewTransactor(db),
+		Driver:        driver,
+		DB:            db,
+		close:         db.Close,
+	}
+}
+
+func NewMemory() *Storage {
+	store := memory.NewStore()
+	return &Storage{
+		Teams:         memory.NewTeamRepository(store),
+		Users:         memory.NewUserRepository(store),
+		PullRequests:  memory.NewPullRequestRepository(store),
+		Statistics:    memory.NewStatisticsRepository(store),
+		SLAs:          memory.NewSLARepository(store),
+		Schedules:     memory.NewScheduleRepository(store),
+		Notifications: memory.NewNotificationRepository(store),
+		Chat:          memory.NewChatRepository(store),
+		Events:        memory.NewEventRepository(store),
+		Idempotency:   memory.NewIdempotencyRepository(store),
+		Transactor:    store,
+		Driver:        DriverMemory,
+		close:         func() error { return nil },
+	}
+}
+
+// Migrate применяет недостающие миграции; для in-memory бэкенда ничего не делает.
+func (s *Storage) Migrate(ctx context.Context, logger *slog.Logger) error {
+	if s.DB == nil {
+		return nil
+	}
+
+	migrator, err := migrate.New(s.DB, s.Driver, logger)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, "database schema is up to date", "applied", len(applied))
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return s.close()
+}
+
+func connectPostgres(cfg config.DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		logger.Error("failed to connect to database", "error", err)
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		logger.Error("failed to ping database", "error", err)
+		db.Close()
+		return nil, err
+	}
+
+	logger.Info("database connection established")
+	return db, nil
+}
@@ -0,0 +1,228 @@
+// Package workhours считает длительности в рабочем времени пользователя:
+// по недельному расписанию в его часовом поясе без праздников команды.
+package workhours
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	// Часовые пояса встроены в бинарник: в контейнере может не быть tzdata
+	_ "time/tzdata"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// DateLayout — формат дат праздников
+const DateLayout = "2006-01-02"
+
+// maxSearchDays ограничивает поиск рабочего времени в Add, чтобы календарь
+// из одних праздников не зациклил расчёт
+const maxSearchDays = 3660
+
+var ErrInvalidSchedule = errors.New("invalid working hours")
+
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+}
+
+// span — рабочий промежуток дня в минутах от полуночи, [start, end)
+type span struct {
+	start, end int
+}
+
+// Calendar — рабочее время одного пользователя. Nil-календарь означает
+// круглосуточную доступность: длительности считаются по часам на стене.
+type Calendar struct {
+	loc      *time.Location
+	week     [7][]span
+	holidays map[string]bool
+}
+
+// New строит календарь. Без расписания считается, что пользователь работает
+// круглосуточно в UTC, кроме праздников; без расписания и праздников
+// возвращается nil.
+func New(wh *models.WorkingHours, holidays []models.Holiday) (*Calendar, error) {
+	if wh == nil && len(holidays) == 0 {
+		return nil, nil
+	}
+
+	c := &Calendar{loc: time.UTC, holidays: make(map[string]bool, len(holidays))}
+	if wh == nil {
+		for d := range c.week {
+			c.week[d] = []span{{0, 24 * 60}}
+		}
+	} else if err := c.setSchedule(wh); err != nil {
+		return nil, err
+	}
+
+	for _, h := range holidays {
+		if _, err := time.Parse(DateLayout, h.Date); err != nil {
+			return nil, fmt.Errorf("%w: holiday date %q must be YYYY-MM-DD", ErrInvalidSchedule, h.Date)
+		}
+		c.holidays[h.Date] = true
+	}
+	return c, nil
+}
+
+// Validate проверяет расписание так же, как New
+func Validate(wh *models.WorkingHours) error {
+	return (&Calendar{}).setSchedule(wh)
+}
+
+func (c *Calendar) setSchedule(wh *models.WorkingHours) error {
+	loc, err := time.LoadLocation(wh.TimeZone)
+	if err != nil || wh.TimeZone == "" {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, wh.TimeZone)
+	}
+	if len(wh.Periods) == 0 {
+		return fmt.Errorf("%w: at least one period is required", ErrInvalidSchedule)
+	}
+	c.loc = loc
+
+	for _, p := range wh.Periods {
+		day, ok := weekdays[strings.ToLower(p.Weekday)]
+		if !ok {
+			return fmt.Errorf("%w: unknown weekday %q", ErrInvalidSchedule, p.Weekday)
+		}
+		start, err := parseClock(p.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(p.End)
+		if err != nil {
+			return err
+		}
+		if start >= end {
+			return fmt.Errorf("%w: period %s %s-%s ends before it starts", ErrInvalidSchedule, p.Weekday, p.Start, p.End)
+		}
+		c.week[day] = append(c.week[day], span{start, end})
+	}
+
+	for d := range c.week {
+		spans := c.week[d]
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		for i := 1; i < len(spans); i++ {
+			if spans[i].start < spans[i-1].end {
+				return fmt.Errorf("%w: periods on %s overlap", ErrInvalidSchedule, strings.ToLower(time.Weekday(d).String()))
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock разбирает "HH:MM"; "24:00" — конец суток
+func parseClock(v string) (int, error) {
+	var h, m int
+	if len(v) != 5 || v[2] != ':' {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidSchedule, v)
+	}
+	if _, err := fmt.Sscanf(v, "%02d:%02d", &h, &m); err != nil || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidSchedule, v)
+	}
+	return h*60 + m, nil
+}
+
+// IsWorking сообщает, рабочее ли время в момент t
+func (c *Calendar) IsWorking(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	local := t.In(c.loc)
+	for _, iv := range c.intervals(local) {
+		if !t.Before(iv[0]) && t.Before(iv[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Duration — рабочее время в [from, to); 0, если to не позже from
+func (c *Calendar) Duration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if c == nil {
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := startOfDay(from.In(c.loc)); day.Before(to); day = nextDay(day) {
+		for _, iv := range c.intervals(day) {
+			start, end := iv[0], iv[1]
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+	return total
+}
+
+// Add возвращает момент, когда с from наберётся d рабочего времени
+func (c *Calendar) Add(from time.Time, d time.Duration) time.Time {
+	if c == nil || d <= 0 {
+		return from.Add(d)
+	}
+
+	remaining := d
+	day := startOfDay(from.In(c.loc))
+	for i := 0; i < maxSearchDays; i++ {
+		for _, iv := range c.intervals(day) {
+			start, end := iv[0], iv[1]
+			if start.Before(from) {
+				start = from
+			}
+			if !end.After(start) {
+				continue
+			}
+			available := end.Sub(start)
+			if available >= remaining {
+				return start.Add(remaining)
+			}
+			remaining -= available
+		}
+		day = nextDay(day)
+	}
+	return from.Add(d)
+}
+
+// intervals возвращает рабочие промежутки местных суток, которым принадлежит day
+func (c *Calendar) intervals(day time.Time) [][2]time.Time {
+	if c.holidays[day.Format(DateLayout)] {
+		return nil
+	}
+	y, m, d := day.Date()
+	spans := c.week[day.Weekday()]
+	out := make([][2]time.Time, 0, len(spans))
+	for _, s := range spans {
+		// time.Date сам учитывает переход на летнее время
+		out = append(out, [2]time.Time{
+			time.Date(y, m, d, 0, s.start, 0, 0, c.loc),
+			time.Date(y, m, d, 0, s.end, 0, 0, c.loc),
+		})
+	}
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func nextDay(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, day.Location())
+}
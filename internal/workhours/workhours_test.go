@@ -0,0 +1,137 @@
+package workhours
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/models"
+)
+
+// officeHours — будни 09:00–13:00 и 14:00–18:00 по Москве (UTC+3)
+func officeHours() *models.WorkingHours {
+	wh := &models.WorkingHours{TimeZone: "Europe/Moscow"}
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday"} {
+		wh.Periods = append(wh.Periods,
+			models.WorkingPeriod{Weekday: day, Start: "09:00", End: "13:00"},
+			models.WorkingPeriod{Weekday: day, Start: "14:00", End: "18:00"})
+	}
+	return wh
+}
+
+func TestCalendar_Duration(t *testing.T) {
+	cal, err := New(officeHours(), []models.Holiday{{Date: "2025-10-22", Name: "Team day"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{
+			name: "within a morning",
+			from: time.Date(2025, 10, 20, 10, 0, 0, 0, msk),
+			to:   time.Date(2025, 10, 20, 12, 30, 0, 0, msk),
+			want: 150 * time.Minute,
+		},
+		{
+			name: "skips lunch and night",
+			from: time.Date(2025, 10, 20, 17, 0, 0, 0, msk),
+			to:   time.Date(2025, 10, 21, 10, 0, 0, 0, msk),
+			want: 2 * time.Hour,
+		},
+		{
+			name: "skips weekend",
+			from: time.Date(2025, 10, 17, 17, 0, 0, 0, msk),
+			to:   time.Date(2025, 10, 20, 10, 0, 0, 0, msk),
+			want: 2 * time.Hour,
+		},
+		{
+			name: "skips team holiday",
+			from: time.Date(2025, 10, 21, 17, 0, 0, 0, msk),
+			to:   time.Date(2025, 10, 23, 10, 0, 0, 0, msk),
+			want: 2 * time.Hour,
+		},
+		{
+			name: "reversed range",
+			from: time.Date(2025, 10, 21, 17, 0, 0, 0, msk),
+			to:   time.Date(2025, 10, 21, 10, 0, 0, 0, msk),
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.Duration(tt.from, tt.to); got != tt.want {
+				t.Errorf("Duration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendar_Add(t *testing.T) {
+	cal, err := New(officeHours(), []models.Holiday{{Date: "2025-10-20"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	// Пятница 16:00 + 8 рабочих часов: 2 часа в пятницу, понедельник — праздник,
+	// затем 4 + 2 часа во вторник
+	from := time.Date(2025, 10, 17, 16, 0, 0, 0, msk)
+	want := time.Date(2025, 10, 21, 16, 0, 0, 0, msk)
+	if got := cal.Add(from, 8*time.Hour); !got.Equal(want) {
+		t.Errorf("Add() = %v, want %v", got, want)
+	}
+	if got := cal.Duration(from, want); got != 8*time.Hour {
+		t.Errorf("Duration() of Add() result = %v, want 8h", got)
+	}
+
+	if !cal.IsWorking(time.Date(2025, 10, 21, 9, 0, 0, 0, msk)) || cal.IsWorking(time.Date(2025, 10, 21, 13, 30, 0, 0, msk)) {
+		t.Errorf("unexpected IsWorking around lunch")
+	}
+}
+
+func TestCalendar_NilIsAlwaysWorking(t *testing.T) {
+	cal, err := New(nil, nil)
+	if err != nil || cal != nil {
+		t.Fatalf("New(nil, nil) = %v, %v; want nil calendar", cal, err)
+	}
+	from := time.Date(2025, 10, 18, 23, 0, 0, 0, time.UTC)
+	if got := cal.Duration(from, from.Add(5*time.Hour)); got != 5*time.Hour {
+		t.Errorf("Duration() = %v, want 5h", got)
+	}
+	if got := cal.Add(from, time.Hour); !got.Equal(from.Add(time.Hour)) || !cal.IsWorking(from) {
+		t.Errorf("Add() = %v, want wall clock", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []*models.WorkingHours{
+		{TimeZone: "Mars/Olympus", Periods: []models.WorkingPeriod{{Weekday: "monday", Start: "09:00", End: "18:00"}}},
+		{TimeZone: "UTC"},
+		{TimeZone: "UTC", Periods: []models.WorkingPeriod{{Weekday: "someday", Start: "09:00", End: "18:00"}}},
+		{TimeZone: "UTC", Periods: []models.WorkingPeriod{{Weekday: "monday", Start: "18:00", End: "09:00"}}},
+		{TimeZone: "UTC", Periods: []models.WorkingPeriod{{Weekday: "monday", Start: "9:00", End: "18:00"}}},
+		{TimeZone: "UTC", Periods: []models.WorkingPeriod{
+			{Weekday: "monday", Start: "09:00", End: "13:00"},
+			{Weekday: "monday", Start: "12:00", End: "18:00"},
+		}},
+	}
+	for _, wh := range invalid {
+		if err := Validate(wh); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidSchedule", wh, err)
+		}
+	}
+
+	night := &models.WorkingHours{TimeZone: "UTC", Periods: []models.WorkingPeriod{{Weekday: "sunday", Start: "20:00", End: "24:00"}}}
+	if err := Validate(night); err != nil {
+		t.Errorf("Validate() = %v for a period until midnight", err)
+	}
+	if _, err := New(nil, []models.Holiday{{Date: "22.10.2025"}}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("New() with malformed holiday = %v, want ErrInvalidSchedule", err)
+	}
+}
@@ -0,0 +1,11 @@
+// Package migrations встраивает версионированные SQL-миграции в бинарник.
+// Файлы именуются NNN_name.up.sql / NNN_name.down.sql, по каталогу на СУБД.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql
+var Postgres embed.FS
+
+//go:embed sqlite/*.sql
+var SQLite embed.FS
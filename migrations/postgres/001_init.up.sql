@@ -12,8 +12,8 @@ CREATE TABLE IF NOT EXISTS users (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE INDEX idx_users_team ON users(team_name);
-CREATE INDEX idx_users_active ON users(is_active);
+CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(is_active);
 
 CREATE TABLE IF NOT EXISTS pull_requests (
     pull_request_id VARCHAR(255) PRIMARY KEY,
@@ -24,8 +24,8 @@ CREATE TABLE IF NOT EXISTS pull_requests (
     merged_at TIMESTAMP
 );
 
-CREATE INDEX idx_pr_status ON pull_requests(status);
-CREATE INDEX idx_pr_author ON pull_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status ON pull_requests(status);
+CREATE INDEX IF NOT EXISTS idx_pr_author ON pull_requests(author_id);
 
 CREATE TABLE IF NOT EXISTS pr_reviewers (
     id SERIAL PRIMARY KEY,
@@ -35,6 +35,6 @@ CREATE TABLE IF NOT EXISTS pr_reviewers (
     UNIQUE(pull_request_id, reviewer_id)
 );
 
-CREATE INDEX idx_pr_reviewers_pr ON pr_reviewers(pull_request_id);
-CREATE INDEX idx_pr_reviewers_reviewer ON pr_reviewers(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_pr ON pr_reviewers(pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewer ON pr_reviewers(reviewer_id);
 
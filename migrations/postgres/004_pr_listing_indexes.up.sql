@@ -0,0 +1,4 @@
+-- Индексы для keyset-пагинации и фильтров /pullRequest/list.
+CREATE INDEX IF NOT EXISTS idx_pr_created ON pull_requests(created_at, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status_created ON pull_requests(status, created_at, pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_merged ON pull_requests(merged_at);
@@ -0,0 +1,2 @@
+-- Пользователь может не состоять в команде (/team/removeMember).
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
@@ -0,0 +1,3 @@
+-- Мягкое удаление (/team/archive, /users/archive): строки остаются ради истории PR.
+ALTER TABLE teams ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN archived_at TIMESTAMP;
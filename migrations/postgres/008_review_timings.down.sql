@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pr_events_type_created;
+ALTER TABLE pr_reviewers DROP COLUMN reviewed_at;
@@ -0,0 +1,3 @@
+-- Время отправки ревью (/pullRequest/submitReview) и выборки /statistics/timings по журналу.
+ALTER TABLE pr_reviewers ADD COLUMN reviewed_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_pr_events_type_created ON pr_events(event_type, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE pr_reviewers DROP COLUMN reminded_at;
+DROP TABLE IF EXISTS team_review_slas;
@@ -0,0 +1,13 @@
+-- Рабочие часы пользователей (periods — JSON-массив промежутков по дням недели) и праздники команд.
+CREATE TABLE IF NOT EXISTS user_working_hours (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    time_zone VARCHAR(64) NOT NULL,
+    periods TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS team_holidays (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    holiday_date VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (team_name, holiday_date)
+);
@@ -0,0 +1,11 @@
+-- Адрес и подписки пользователя на письма; last_digest_on — дата последней сводки
+-- в его часовом поясе, чтобы не отправить её дважды за день.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    daily_digest BOOLEAN NOT NULL DEFAULT TRUE,
+    assignment_notices BOOLEAN NOT NULL DEFAULT TRUE,
+    overdue_reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    last_digest_on VARCHAR(10),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,11 @@
+-- Привязка пользователей чата (Slack) к users.user_id; у пользователя не
+-- больше одной учётной записи чата.
+CREATE TABLE IF NOT EXISTS chat_users (
+    chat_user_id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL UNIQUE REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Отсутствие (/review ooo): до away_until пользователь неактивен, затем
+-- возвращается в ротацию.
+ALTER TABLE users ADD COLUMN away_until TIMESTAMP;
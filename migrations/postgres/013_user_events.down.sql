@@ -0,0 +1,2 @@
+DELETE FROM pr_events WHERE pull_request_id IS NULL;
+ALTER TABLE pr_events ALTER COLUMN pull_request_id SET NOT NULL;
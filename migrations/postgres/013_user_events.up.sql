@@ -0,0 +1,3 @@
+-- Журнал pr_events хранит и события пользователей без PR (USER_DEACTIVATED);
+-- его читает /events/stream.
+ALTER TABLE pr_events ALTER COLUMN pull_request_id DROP NOT NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS pr_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    event_type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255),
+    previous_user_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pull_request_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pr_merged;
+DROP INDEX IF EXISTS idx_pr_status_created;
+DROP INDEX IF EXISTS idx_pr_created;
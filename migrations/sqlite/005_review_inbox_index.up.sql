@@ -0,0 +1,2 @@
+-- Индекс для входящих ревью /users/getReview с сортировкой по assigned_at.
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewer_assigned ON pr_reviewers(reviewer_id, assigned_at, pull_request_id);
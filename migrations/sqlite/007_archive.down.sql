@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN archived_at;
+ALTER TABLE teams DROP COLUMN archived_at;
@@ -0,0 +1,8 @@
+-- Сроки ревью по командам и отметка о напоминании по каждому назначению.
+CREATE TABLE IF NOT EXISTS team_review_slas (
+    team_name VARCHAR(255) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    first_review_hours INTEGER NOT NULL,
+    reassign_after_hours INTEGER,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+ALTER TABLE pr_reviewers ADD COLUMN reminded_at TIMESTAMP;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_holidays;
+DROP TABLE IF EXISTS user_working_hours;
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN away_until;
+DROP TABLE IF EXISTS chat_users;
@@ -0,0 +1,19 @@
+-- События пользователей без PR при откате удаляются.
+CREATE TABLE pr_events_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id),
+    event_type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255),
+    previous_user_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO pr_events_old (id, pull_request_id, event_type, user_id, previous_user_id, created_at)
+SELECT id, pull_request_id, event_type, user_id, previous_user_id, created_at FROM pr_events
+WHERE pull_request_id IS NOT NULL;
+
+DROP TABLE pr_events;
+ALTER TABLE pr_events_old RENAME TO pr_events;
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_type_created ON pr_events(event_type, created_at);
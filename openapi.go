@@ -0,0 +1,8 @@
+// Package reviewerservice встраивает спецификацию API в бинарник, чтобы
+// сервер не зависел от рабочего каталога.
+package reviewerservice
+
+import _ "embed"
+
+//go:embed openapi.yaml
+var OpenAPISpec []byte
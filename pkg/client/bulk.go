@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Форматы массовой выгрузки и загрузки
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+var importContentTypes = map[string]string{
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv",
+}
+
+// ExportTeams — GET /team/export: неархивные команды в формате FormatNDJSON
+// или FormatCSV. Выгрузка читается потоком; закрывает её вызывающий.
+func (c *Client) ExportTeams(ctx context.Context, format string) (io.ReadCloser, error) {
+	return c.export(ctx, "/team/export", format)
+}
+
+// ExportUsers — GET /users/export: неархивные пользователи.
+func (c *Client) ExportUsers(ctx context.Context, format string) (io.ReadCloser, error) {
+	return c.export(ctx, "/users/export", format)
+}
+
+// ExportPullRequests — GET /pullRequest/export: все PR с ревьюверами и датами.
+func (c *Client) ExportPullRequests(ctx context.Context, format string) (io.ReadCloser, error) {
+	return c.export(ctx, "/pullRequest/export", format)
+}
+
+func (c *Client) export(ctx context.Context, path, format string) (io.ReadCloser, error) {
+	var query url.Values
+	if format == FormatCSV {
+		query = url.Values{"format": {FormatCSV}}
+	}
+	var body io.ReadCloser
+	if err := c.get(ctx, path, query, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ImportTeams — POST /team/import: файл data загружается целиком или не
+// загружается вовсе. Ошибки строк возвращаются как ErrInvalidRequest,
+// поля вида rows.<строка>.<поле> — в APIError.Details.
+func (c *Client) ImportTeams(ctx context.Context, format string, data []byte) (*ImportResult, error) {
+	return c.importRows(ctx, "/team/import", format, data)
+}
+
+// ImportUsers — POST /users/import: недостающие команды создаются.
+func (c *Client) ImportUsers(ctx context.Context, format string, data []byte) (*ImportResult, error) {
+	return c.importRows(ctx, "/users/import", format, data)
+}
+
+// ImportPullRequests — POST /pullRequest/import: PR загружаются со статусом,
+// датами и ревьюверами из файла, без автоназначения.
+func (c *Client) ImportPullRequests(ctx context.Context, format string, data []byte) (*ImportResult, error) {
+	return c.importRows(ctx, "/pullRequest/import", format, data)
+}
+
+func (c *Client) importRows(ctx context.Context, path, format string, data []byte) (*ImportResult, error) {
+	contentType, ok := importContentTypes[format]
+	if !ok {
+		contentType = importContentTypes[FormatNDJSON]
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	var result ImportResult
+	if err := c.doRaw(ctx, http.MethodPost, path, nil, data, contentType, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,191 @@
+// Package client — типизированный Go-клиент HTTP API сервиса назначения ревьюверов.
+//
+// Клиент не покрывает /scim/v2/* и /slack/*: первые вызывает поставщик
+// учётных записей по протоколу SCIM 2.0 со своим токеном, вторые — Slack
+// с подписью запроса секретом приложения.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+type Client struct {
+	baseURL         *url.URL
+	httpClient      *http.Client
+	token           string
+	userAgent       string
+	retry           retryPolicy
+	idempotencyKeys bool
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken передаёт токен в заголовке Authorization: Bearer.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: host is empty", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  "reviewer-service-client",
+		retry: retryPolicy{
+			maxAttempts: DefaultMaxAttempts,
+			baseDelay:   DefaultRetryDelay,
+			maxDelay:    DefaultMaxDelay,
+		},
+		idempotencyKeys: true,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, out)
+}
+
+// do выполняет запрос с повторами. POST получает один Idempotency-Key на все
+// попытки, поэтому повтор после обрыва связи не создаст PR или команду дважды.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		payload = raw
+	}
+	return c.doRaw(ctx, method, path, query, payload, "application/json", out)
+}
+
+// doRaw отправляет готовое тело payload с типом contentType. out может быть
+// *[]byte для тела целиком и *io.ReadCloser для потока, который закрывает
+// вызывающий; иначе ответ разбирается как JSON.
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, payload []byte, contentType string, out interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var idempotencyKey string
+	if method == http.MethodPost && c.idempotencyKeys {
+		idempotencyKey = newIdempotencyKey()
+	}
+	attempts := 1
+	if method == http.MethodGet || idempotencyKey != "" {
+		attempts = c.retry.maxAttempts
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleep(ctx, c.retry.delay(attempt-1, err)); sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		var resp *http.Response
+		resp, err = c.send(ctx, method, u.String(), payload, contentType, idempotencyKey)
+		if err == nil {
+			if dst, ok := out.(*io.ReadCloser); ok {
+				*dst = resp.Body
+				return nil
+			}
+			defer resp.Body.Close()
+			switch dst := out.(type) {
+			case nil:
+				return nil
+			case *[]byte:
+				*dst, err = io.ReadAll(resp.Body)
+				return err
+			}
+			if decodeErr := json.NewDecoder(resp.Body).Decode(out); decodeErr != nil && !errors.Is(decodeErr, io.EOF) {
+				return fmt.Errorf("decode %s %s response: %w", method, path, decodeErr)
+			}
+			return nil
+		}
+		if !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// send возвращает *APIError для ответов 4xx/5xx; тело успешного ответа
+// закрывает вызывающий.
+func (c *Client) send(ctx context.Context, method, target string, payload []byte, contentType, idempotencyKey string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,728 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reviewer-service/internal/config"
+	"github.com/reviewer-service/internal/server"
+	"github.com/reviewer-service/internal/storage"
+	"github.com/reviewer-service/pkg/client"
+)
+
+func newRouter(t *testing.T) http.Handler {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		Idempotency: config.IdempotencyConfig{TTL: time.Hour},
+		Statistics:  config.StatisticsConfig{CacheTTL: time.Hour},
+	}
+	router, err := server.NewRouter(storage.NewMemory(), cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	return router
+}
+
+func setup(t *testing.T, handler http.Handler, opts ...client.Option) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]client.Option{client.WithRetry(3, time.Millisecond)}, opts...)
+	c, err := client.New(srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+	return c
+}
+
+func seedTeam(t *testing.T, ctx context.Context, c *client.Client) {
+	t.Helper()
+	_, err := c.CreateTeam(ctx, client.Team{
+		TeamName: "backend",
+		Members: []client.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: true},
+			{UserID: "u4", Username: "Dave", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTeam() error = %v", err)
+	}
+}
+
+func TestClient_AllRoutes(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+	seedTeam(t, ctx, c)
+
+	team, err := c.GetTeam(ctx, "backend")
+	if err != nil || len(team.Members) != 4 {
+		t.Fatalf("GetTeam() = %+v, %v", team, err)
+	}
+
+	pr, err := c.CreatePullRequest(ctx, "pr-1", "Add search", "u1")
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if pr.Status != client.StatusOpen || len(pr.AssignedReviewers) != 2 || pr.CreatedAt == nil {
+		t.Fatalf("unexpected PR: %+v", pr)
+	}
+
+	reviews, err := c.GetUserReviews(ctx, pr.AssignedReviewers[0])
+	if err != nil || len(reviews) != 1 || reviews[0].PullRequestID != "pr-1" {
+		t.Fatalf("GetUserReviews() = %+v, %v", reviews, err)
+	}
+	if reviews[0].Role != "REVIEWER" || reviews[0].AssignedAt == nil {
+		t.Errorf("unexpected review entry: %+v", reviews[0])
+	}
+
+	inbox, next, err := c.ListUserReviews(ctx, "u1", client.ReviewsOptions{Status: "ALL", IncludeAuthored: true, Sort: "assigned_at", Limit: 1})
+	if err != nil || len(inbox) != 1 || inbox[0].PullRequestID != "pr-1" || inbox[0].Role != "AUTHOR" || next != "" {
+		t.Fatalf("ListUserReviews() = %+v, %q, %v", inbox, next, err)
+	}
+
+	reviewer := pr.AssignedReviewers[1]
+	_, reviewedAt, err := c.SubmitReview(ctx, "pr-1", reviewer)
+	if err != nil || reviewedAt.IsZero() {
+		t.Fatalf("SubmitReview() = %v, %v", reviewedAt, err)
+	}
+	if _, again, err := c.SubmitReview(ctx, "pr-1", reviewer); err != nil || !again.Equal(reviewedAt) {
+		t.Errorf("repeated SubmitReview() = %v, %v; want %v", again, err, reviewedAt)
+	}
+
+	old := pr.AssignedReviewers[0]
+	reassigned, replacedBy, err := c.ReassignReviewer(ctx, "pr-1", old)
+	if err != nil {
+		t.Fatalf("ReassignReviewer() error = %v", err)
+	}
+	if replacedBy == "" || replacedBy == old {
+		t.Errorf("unexpected replaced_by %q", replacedBy)
+	}
+	for _, r := range reassigned.AssignedReviewers {
+		if r == old {
+			t.Errorf("old reviewer %s is still assigned", old)
+		}
+	}
+
+	user, err := c.SetUserActive(ctx, "u4", false)
+	if err != nil || user.IsActive || user.TeamName != "backend" {
+		t.Fatalf("SetUserActive() = %+v, %v", user, err)
+	}
+
+	result, err := c.DeactivateTeamMembers(ctx, "backend", []string{replacedBy})
+	if err != nil || len(result.DeactivatedUsers) != 1 {
+		t.Fatalf("DeactivateTeamMembers() = %+v, %v", result, err)
+	}
+
+	merged, err := c.MergePullRequest(ctx, "pr-1")
+	if err != nil || merged.Status != client.StatusMerged || merged.MergedAt == nil {
+		t.Fatalf("MergePullRequest() = %+v, %v", merged, err)
+	}
+	if _, err := c.MergePullRequest(ctx, "pr-1"); err != nil {
+		t.Errorf("repeated merge must be idempotent, got %v", err)
+	}
+
+	events, err := c.GetPullRequestHistory(ctx, "pr-1")
+	if err != nil || len(events) == 0 {
+		t.Fatalf("GetPullRequestHistory() = %+v, %v", events, err)
+	}
+	if events[0].Type != "PR_CREATED" || events[len(events)-1].Type != "PR_MERGED" {
+		t.Errorf("unexpected history: %+v", events)
+	}
+
+	got, err := c.GetPullRequest(ctx, "pr-1")
+	if err != nil || got.Status != client.StatusMerged {
+		t.Fatalf("GetPullRequest() = %+v, %v", got, err)
+	}
+
+	for _, id := range []string{"pr-2", "pr-3"} {
+		if _, err := c.CreatePullRequest(ctx, id, "Follow-up", "u2"); err != nil {
+			t.Fatalf("CreatePullRequest(%s) error = %v", id, err)
+		}
+	}
+	var listed []string
+	opts := client.ListOptions{TeamName: "backend", Sort: "pull_request_id", Order: "asc", Limit: 2}
+	for {
+		page, next, err := c.ListPullRequests(ctx, opts)
+		if err != nil {
+			t.Fatalf("ListPullRequests() error = %v", err)
+		}
+		for _, p := range page {
+			listed = append(listed, p.PullRequestID)
+		}
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if strings.Join(listed, ",") != "pr-1,pr-2,pr-3" {
+		t.Errorf("expected pr-1,pr-2,pr-3 across pages, got %v", listed)
+	}
+	open, _, err := c.ListPullRequests(ctx, client.ListOptions{Status: client.StatusOpen, AuthorID: "u2", CreatedFrom: time.Now().Add(-time.Hour)})
+	if err != nil || len(open) != 2 {
+		t.Errorf("ListPullRequests(open by u2) = %d PRs, %v", len(open), err)
+	}
+
+	stats, err := c.GetStatistics(ctx)
+	if err != nil {
+		t.Fatalf("GetStatistics() error = %v", err)
+	}
+	if stats.Teams.Total != 1 || stats.PullRequests.Merged != 1 || stats.Users.Inactive == 0 || stats.Users.Active+stats.Users.Inactive != 4 {
+		t.Errorf("unexpected statistics: %+v", stats)
+	}
+	if cached, err := c.GetStatistics(ctx); err != nil || !cached.GeneratedAt.Equal(stats.GeneratedAt) {
+		t.Errorf("expected cached statistics from %v, got %+v, %v", stats.GeneratedAt, cached, err)
+	}
+	if _, err := c.CreatePullRequest(ctx, "pr-4", "After snapshot", "u1"); err != nil {
+		t.Fatalf("CreatePullRequest(pr-4) error = %v", err)
+	}
+	if fresh, err := c.GetStatistics(ctx); err != nil || fresh.PullRequests.Total != stats.PullRequests.Total+1 {
+		t.Errorf("expected statistics to be recomputed after a write, got %+v, %v", fresh, err)
+	}
+
+	if stats.Load == nil || len(stats.Load.ByTeam) != 1 || stats.Load.ByTeam[0].TeamName != "backend" {
+		t.Errorf("unexpected statistics load: %+v", stats.Load)
+	}
+	ranged, err := c.GetStatisticsRange(ctx, time.Now().Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("GetStatisticsRange() error = %v", err)
+	}
+	if ranged.Load == nil || ranged.Load.From == nil || len(ranged.Load.ByReviewer) != 0 {
+		t.Errorf("expected empty load for a future range, got %+v", ranged.Load)
+	}
+
+	teamStats, err := c.GetTeamStatistics(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeamStatistics() error = %v", err)
+	}
+	if teamStats.TeamName != "backend" || len(teamStats.Members) != 4 || teamStats.OpenAssignments > teamStats.TotalAssignments || teamStats.TotalAssignments == 0 {
+		t.Errorf("unexpected team statistics: %+v", teamStats)
+	}
+
+	reassignAfter := 48
+	sla, err := c.SetTeamSLA(ctx, client.TeamSLA{TeamName: "backend", FirstReviewHours: 24, ReassignAfterHours: &reassignAfter})
+	if err != nil || sla.FirstReviewHours != 24 || sla.ReassignAfterHours == nil || *sla.ReassignAfterHours != 48 {
+		t.Errorf("SetTeamSLA() = %+v, %v", sla, err)
+	}
+	if got, err := c.GetTeamSLA(ctx, "backend"); err != nil || got.FirstReviewHours != 24 {
+		t.Errorf("GetTeamSLA() = %+v, %v", got, err)
+	}
+	// Все назначения только что созданы, поэтому срок ещё не истёк
+	if overdue, err := c.ListOverdueReviews(ctx, "backend"); err != nil || len(overdue) != 0 {
+		t.Errorf("ListOverdueReviews() = %+v, %v", overdue, err)
+	}
+
+	wh, err := c.SetWorkingHours(ctx, "u2", client.WorkingHours{TimeZone: "Europe/Berlin", Periods: []client.WorkingPeriod{
+		{Weekday: "monday", Start: "09:00", End: "17:00"},
+	}})
+	if err != nil || wh.TimeZone != "Europe/Berlin" || len(wh.Periods) != 1 {
+		t.Errorf("SetWorkingHours() = %+v, %v", wh, err)
+	}
+	if got, err := c.GetWorkingHours(ctx, "u2"); err != nil || got.Periods[0].End != "17:00" {
+		t.Errorf("GetWorkingHours() = %+v, %v", got, err)
+	}
+	holidays, err := c.SetTeamHolidays(ctx, "backend", []client.Holiday{{Date: "2030-12-25", Name: "Christmas"}, {Date: "2030-01-01"}})
+	if err != nil || len(holidays) != 2 || holidays[0].Date != "2030-01-01" {
+		t.Errorf("SetTeamHolidays() = %+v, %v", holidays, err)
+	}
+	if got, err := c.GetTeamHolidays(ctx, "backend"); err != nil || len(got) != 2 || got[1].Name != "Christmas" {
+		t.Errorf("GetTeamHolidays() = %+v, %v", got, err)
+	}
+	prefs, err := c.SetNotificationPreferences(ctx, client.NotificationPreferences{UserID: "u2", Email: "bob@example.com", DailyDigest: true})
+	if err != nil || prefs.Email != "bob@example.com" || !prefs.DailyDigest || prefs.AssignmentNotices {
+		t.Errorf("SetNotificationPreferences() = %+v, %v", prefs, err)
+	}
+	if got, err := c.GetNotificationPreferences(ctx, "u2"); err != nil || got.UserID != "u2" || got.OverdueReminders {
+		t.Errorf("GetNotificationPreferences() = %+v, %v", got, err)
+	}
+	if err := c.SetChatUser(ctx, "u2", "U2147483697"); err != nil {
+		t.Errorf("SetChatUser() error = %v", err)
+	}
+	if working, err := c.GetReviewTimings(ctx, client.TimingsOptions{WorkingHours: true}); err != nil || !working.WorkingHours {
+		t.Errorf("GetReviewTimings(working hours) = %+v, %v", working, err)
+	}
+
+	timings, err := c.GetReviewTimings(ctx, client.TimingsOptions{Bucket: client.BucketWeek})
+	if err != nil {
+		t.Fatalf("GetReviewTimings() error = %v", err)
+	}
+	if timings.Bucket != client.BucketWeek || timings.Total.Overall.TimeToMerge.Count != 1 ||
+		timings.Total.Overall.TimeToFirstReview.Count != 1 || len(timings.Buckets) == 0 {
+		t.Errorf("unexpected review timings: %+v", timings)
+	}
+
+	spec, err := c.GetOpenAPISpec(ctx)
+	if err != nil || !strings.Contains(string(spec), "openapi:") {
+		t.Errorf("GetOpenAPISpec() returned %d bytes, %v", len(spec), err)
+	}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+	seedTeam(t, ctx, c)
+
+	if _, err := c.CreatePullRequest(ctx, "pr-1", "Add search", "u1"); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if _, err := c.CreatePullRequest(ctx, "pr-merged", "Old", "u1"); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if _, err := c.MergePullRequest(ctx, "pr-merged"); err != nil {
+		t.Fatalf("MergePullRequest() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:       "team exists",
+			call:       func() error { _, err := c.CreateTeam(ctx, client.Team{TeamName: "backend"}); return err },
+			wantErr:    client.ErrTeamExists,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "team not found",
+			call:       func() error { _, err := c.GetTeam(ctx, "missing"); return err },
+			wantErr:    client.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "pr exists",
+			call:       func() error { _, err := c.CreatePullRequest(ctx, "pr-1", "Again", "u1"); return err },
+			wantErr:    client.ErrPRExists,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "pr merged",
+			call:       func() error { _, _, err := c.ReassignReviewer(ctx, "pr-merged", "u2"); return err },
+			wantErr:    client.ErrPRMerged,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "not assigned",
+			call:       func() error { _, _, err := c.ReassignReviewer(ctx, "pr-1", "u1"); return err },
+			wantErr:    client.ErrNotAssigned,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "invalid team member",
+			call: func() error {
+				_, err := c.CreateTeam(ctx, client.Team{TeamName: "frontend", Members: []client.TeamMember{{UserID: "f1", Username: "Eve", IsActive: true}}})
+				if err != nil {
+					return err
+				}
+				_, err = c.DeactivateTeamMembers(ctx, "frontend", []string{"u1"})
+				return err
+			},
+			wantErr:    client.ErrInvalidTeamMember,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing query parameter",
+			call:       func() error { _, err := c.GetTeam(ctx, ""); return err },
+			wantErr:    client.ErrInvalidRequest,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, apiErr.StatusCode)
+			}
+			if errors.Is(err, client.ErrInvalidRequest) && (len(apiErr.Details) == 0 || apiErr.Details[0].Field != "team_name") {
+				t.Errorf("expected field violation for team_name, got %+v", apiErr.Details)
+			}
+		})
+	}
+}
+
+func TestClient_NoCandidate(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+
+	_, err := c.CreateTeam(ctx, client.Team{TeamName: "duo", Members: []client.TeamMember{
+		{UserID: "a", Username: "A", IsActive: true},
+		{UserID: "b", Username: "B", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("CreateTeam() error = %v", err)
+	}
+	if _, err := c.CreatePullRequest(ctx, "pr-1", "Small", "a"); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if _, err := c.SetUserActive(ctx, "a", false); err != nil {
+		t.Fatalf("SetUserActive() error = %v", err)
+	}
+
+	_, _, err = c.ReassignReviewer(ctx, "pr-1", "b")
+	if !errors.Is(err, client.ErrNoCandidate) {
+		t.Errorf("expected ErrNoCandidate, got %v", err)
+	}
+}
+
+func TestClient_TeamMembership(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+	seedTeam(t, ctx, c)
+	if _, err := c.CreateTeam(ctx, client.Team{TeamName: "frontend"}); err != nil {
+		t.Fatalf("CreateTeam() error = %v", err)
+	}
+
+	added, err := c.AddTeamMember(ctx, "frontend", "u5", "Eve", nil)
+	if err != nil || len(added.Team.Members) != 1 || !added.Team.Members[0].IsActive {
+		t.Fatalf("AddTeamMember() = %+v, %v", added, err)
+	}
+
+	inactive := false
+	added, err = c.AddTeamMember(ctx, "frontend", "u4", "Dave", &inactive)
+	if err != nil || len(added.Team.Members) != 2 {
+		t.Fatalf("AddTeamMember(existing) = %+v, %v", added, err)
+	}
+	backend, err := c.GetTeam(ctx, "backend")
+	if err != nil || len(backend.Members) != 3 {
+		t.Errorf("expected u4 to leave backend, got %+v, %v", backend, err)
+	}
+
+	moved, err := c.MoveUser(ctx, "u5", "backend")
+	if err != nil || moved.User.TeamName != "backend" || moved.ReassignedPRs != 0 {
+		t.Fatalf("MoveUser() = %+v, %v", moved, err)
+	}
+
+	removed, err := c.RemoveTeamMember(ctx, "backend", "u5")
+	if err != nil || len(removed.Team.Members) != 3 {
+		t.Fatalf("RemoveTeamMember() = %+v, %v", removed, err)
+	}
+	if _, err := c.RemoveTeamMember(ctx, "backend", "u5"); !errors.Is(err, client.ErrInvalidTeamMember) {
+		t.Errorf("expected ErrInvalidTeamMember, got %v", err)
+	}
+
+	archived, err := c.ArchiveUser(ctx, "u3")
+	if err != nil || archived.User.ArchivedAt == nil || archived.User.IsActive {
+		t.Fatalf("ArchiveUser() = %+v, %v", archived, err)
+	}
+	backend, err = c.GetTeamWithArchived(ctx, "backend")
+	if err != nil || len(backend.Members) != 3 {
+		t.Errorf("GetTeamWithArchived() = %+v, %v", backend, err)
+	}
+
+	result, err := c.ArchiveTeam(ctx, "frontend")
+	if err != nil || result.Team.ArchivedAt == nil || len(result.ArchivedUsers) != 1 {
+		t.Fatalf("ArchiveTeam() = %+v, %v", result, err)
+	}
+	if _, err := c.GetTeam(ctx, "frontend"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected archived team to be hidden, got %v", err)
+	}
+}
+
+func TestClient_SyncTeams(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+	seedTeam(t, ctx, c)
+
+	teams := []client.Team{
+		{TeamName: "backend", Members: []client.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: false},
+		}},
+		{TeamName: "frontend", Members: []client.TeamMember{
+			{UserID: "u4", Username: "Dave", IsActive: true},
+		}},
+	}
+	want := []client.SyncChange{
+		{Action: client.SyncCreateTeam, TeamName: "frontend"},
+		{Action: client.SyncDeactivateUser, TeamName: "backend", UserID: "u3"},
+		{Action: client.SyncMoveUser, TeamName: "frontend", UserID: "u4", PreviousTeam: "backend"},
+	}
+
+	plan, err := c.SyncTeams(ctx, teams, true)
+	if err != nil || !plan.DryRun || !reflect.DeepEqual(plan.Changes, want) {
+		t.Fatalf("SyncTeams(dry run) = %+v, %v", plan, err)
+	}
+	if _, err := c.GetTeam(ctx, "frontend"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("dry run must not create teams, got %v", err)
+	}
+
+	applied, err := c.SyncTeams(ctx, teams, false)
+	if err != nil || applied.DryRun || !reflect.DeepEqual(applied.Changes, want) {
+		t.Fatalf("SyncTeams() = %+v, %v", applied, err)
+	}
+	frontend, err := c.GetTeam(ctx, "frontend")
+	if err != nil || len(frontend.Members) != 1 {
+		t.Errorf("GetTeam(frontend) = %+v, %v", frontend, err)
+	}
+
+	teams = append(teams, client.Team{TeamName: "backend"})
+	if _, err := c.SyncTeams(ctx, teams, false); !errors.Is(err, client.ErrInvalidRequest) {
+		t.Errorf("expected ErrInvalidRequest for duplicate team, got %v", err)
+	}
+}
+
+// flaky отвечает 503 на первые failures запросов, затем проксирует в реальный роутер.
+type flaky struct {
+	mu       sync.Mutex
+	next     http.Handler
+	failures int
+	requests []*http.Request
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Clone(context.Background()))
+	fail := len(f.requests) <= f.failures
+	f.mu.Unlock()
+
+	if fail {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, `{"error":{"code":"INTERNAL_ERROR","message":"unavailable"}}`)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func TestClient_RetriesIdempotentCalls(t *testing.T) {
+	ctx := context.Background()
+	router := newRouter(t)
+	f := &flaky{next: router}
+	c := setup(t, f)
+	seedTeam(t, ctx, c)
+
+	f.mu.Lock()
+	f.failures, f.requests = 2, nil
+	f.mu.Unlock()
+
+	pr, err := c.CreatePullRequest(ctx, "pr-1", "Add search", "u1")
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if pr.PullRequestID != "pr-1" {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+	if len(f.requests) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(f.requests))
+	}
+	key := f.requests[0].Header.Get("Idempotency-Key")
+	if key == "" {
+		t.Fatal("POST must carry Idempotency-Key")
+	}
+	for i, r := range f.requests {
+		if got := r.Header.Get("Idempotency-Key"); got != key {
+			t.Errorf("attempt %d: Idempotency-Key %q, want %q", i, got, key)
+		}
+	}
+
+	f.mu.Lock()
+	f.failures, f.requests = 1, nil
+	f.mu.Unlock()
+	if _, err := c.GetTeam(ctx, "backend"); err != nil {
+		t.Fatalf("GetTeam() error = %v", err)
+	}
+	if len(f.requests) != 2 {
+		t.Errorf("expected GET to be retried once, got %d attempts", len(f.requests))
+	}
+
+	f.mu.Lock()
+	f.failures, f.requests = 10, nil
+	f.mu.Unlock()
+	_, err = c.GetTeam(ctx, "backend")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after exhausting retries, got %v", err)
+	}
+	if len(f.requests) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(f.requests))
+	}
+}
+
+func TestClient_DoesNotRetryWithoutIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	f := &flaky{next: newRouter(t), failures: 1}
+	c := setup(t, f, client.WithoutIdempotencyKeys())
+
+	_, err := c.CreateTeam(ctx, client.Team{TeamName: "backend"})
+	if !errors.Is(err, client.ErrInternal) {
+		t.Fatalf("expected ErrInternal, got %v", err)
+	}
+	if len(f.requests) != 1 {
+		t.Errorf("POST without Idempotency-Key must not be retried, got %d attempts", len(f.requests))
+	}
+	if f.requests[0].Header.Get("Idempotency-Key") != "" {
+		t.Error("unexpected Idempotency-Key header")
+	}
+}
+
+func TestClient_ContextCancellation(t *testing.T) {
+	block := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	})
+	c := setup(t, handler)
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetStatistics(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled request took %v", elapsed)
+	}
+}
+
+func TestClient_TokenAndUnknownErrors(t *testing.T) {
+	var auth string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, `{"error":{"code":"SOMETHING_NEW","message":"future error"}}`)
+	})
+	c := setup(t, handler, client.WithToken("secret"))
+
+	_, err := c.GetStatistics(context.Background())
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "SOMETHING_NEW" || apiErr.Message != "future error" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errors.Is(err, client.ErrNotFound) || errors.Is(err, client.ErrInternal) {
+		t.Error("unknown code must not match known sentinel errors")
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", auth)
+	}
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, raw := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		if _, err := client.New(raw); err == nil {
+			t.Errorf("New(%q) error = nil, want error", raw)
+		}
+	}
+}
+
+func TestClient_BulkImportExport(t *testing.T) {
+	ctx := context.Background()
+	c := setup(t, newRouter(t))
+
+	teams, err := c.ImportTeams(ctx, client.FormatNDJSON, []byte(`{"team_name":"backend"}`+"\n"))
+	if err != nil || teams.Entity != "teams" || teams.Imported != 1 {
+		t.Fatalf("ImportTeams() = %+v, %v", teams, err)
+	}
+	users, err := c.ImportUsers(ctx, client.FormatCSV, []byte("user_id,username,team_name\nu1,Alice,backend\nu2,Bob,backend\n"))
+	if err != nil || users.Imported != 2 {
+		t.Fatalf("ImportUsers() = %+v, %v", users, err)
+	}
+	prs, err := c.ImportPullRequests(ctx, client.FormatNDJSON, []byte(`{"pull_request_id":"pr-1","pull_request_name":"Old","author_id":"u1","status":"MERGED","assigned_reviewers":["u2"],"createdAt":"2024-03-01T10:00:00Z","mergedAt":"2024-03-02T10:00:00Z"}`+"\n"))
+	if err != nil || prs.Imported != 1 {
+		t.Fatalf("ImportPullRequests() = %+v, %v", prs, err)
+	}
+
+	_, err = c.ImportPullRequests(ctx, client.FormatNDJSON, []byte(`{"pull_request_id":"pr-1","pull_request_name":"Again","author_id":"u1"}`+"\n"))
+	var apiErr *client.APIError
+	if !errors.Is(err, client.ErrInvalidRequest) || !errors.As(err, &apiErr) || len(apiErr.Details) != 1 || apiErr.Details[0].Field != "rows.1.pull_request_id" {
+		t.Fatalf("expected row-level error for existing PR, got %v", err)
+	}
+
+	export, err := c.ExportUsers(ctx, client.FormatCSV)
+	if err != nil {
+		t.Fatalf("ExportUsers() error = %v", err)
+	}
+	body, err := io.ReadAll(export)
+	export.Close()
+	if err != nil || string(body) != "user_id,username,team_name,is_active\nu1,Alice,backend,true\nu2,Bob,backend,true\n" {
+		t.Errorf("unexpected users export %q, %v", body, err)
+	}
+
+	export, err = c.ExportPullRequests(ctx, client.FormatNDJSON)
+	if err != nil {
+		t.Fatalf("ExportPullRequests() error = %v", err)
+	}
+	body, err = io.ReadAll(export)
+	export.Close()
+	if err != nil || strings.Count(string(body), "\n") != 1 || !strings.Contains(string(body), `"pull_request_id":"pr-1"`) {
+		t.Errorf("unexpected PR export %q, %v", body, err)
+	}
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		Idempotency: config.IdempotencyConfig{TTL: time.Hour},
+		Events:      config.EventsConfig{PollInterval: 10 * time.Millisecond},
+	}
+	router, err := server.NewRouter(storage.NewMemory(), cfg, logger)
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	// Короткий таймаут клиента не должен обрывать поток
+	c := setup(t, router, client.WithHTTPClient(&http.Client{Timeout: 200 * time.Millisecond}))
+	seedTeam(t, ctx, c)
+	if _, err := c.CreatePullRequest(ctx, "pr-1", "Add search", "u1"); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+
+	// С начала журнала: PR_CREATED и два назначения
+	stream, err := c.StreamEvents(ctx, client.EventsOptions{PullRequestID: "pr-1", LastEventID: "0"})
+	if err != nil {
+		t.Fatalf("StreamEvents() error = %v", err)
+	}
+	defer stream.Close()
+	for i, want := range []string{"PR_CREATED", "REVIEWER_ASSIGNED", "REVIEWER_ASSIGNED"} {
+		event, err := stream.Next()
+		if err != nil || event.Type != want || event.PullRequestID != "pr-1" || event.TeamName != "backend" {
+			t.Fatalf("event %d = %+v, %v; want %s", i, event, err, want)
+		}
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if _, err := c.MergePullRequest(ctx, "pr-1"); err != nil {
+		t.Fatalf("MergePullRequest() error = %v", err)
+	}
+	event, err := stream.Next()
+	if err != nil || event.Type != "PR_MERGED" {
+		t.Fatalf("live event = %+v, %v; want PR_MERGED", event, err)
+	}
+	if stream.LastEventID() != strconv.FormatInt(event.ID, 10) {
+		t.Errorf("LastEventID() = %q, want %d", stream.LastEventID(), event.ID)
+	}
+
+	if _, err := c.StreamEvents(ctx, client.EventsOptions{LastEventID: "-1"}); !errors.Is(err, client.ErrInvalidRequest) {
+		t.Errorf("expected ErrInvalidRequest for invalid Last-Event-ID, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package client
+
+import "context"
+
+// GetOpenAPISpec — GET /api/openapi.yaml: спецификация API в исходном виде.
+func (c *Client) GetOpenAPISpec(ctx context.Context) ([]byte, error) {
+	var spec []byte
+	if err := c.get(ctx, "/api/openapi.yaml", nil, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ошибки, соответствующие значениям error.code из ErrorResponse.
+// Проверяются через errors.Is; подробности — через errors.As(err, *APIError).
+var (
+	ErrTeamExists            = errors.New("team already exists")
+	ErrPRExists              = errors.New("pull request already exists")
+	ErrPRMerged              = errors.New("pull request is merged")
+	ErrNotAssigned           = errors.New("reviewer is not assigned")
+	ErrNoCandidate           = errors.New("no replacement candidate")
+	ErrNotFound              = errors.New("resource not found")
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrInvalidTeamMember     = errors.New("user is not a member of the team")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused with different request")
+	ErrIdempotencyInProgress = errors.New("request with the same idempotency key is in progress")
+	ErrInternal              = errors.New("internal server error")
+)
+
+var errorsByCode = map[string]error{
+	"TEAM_EXISTS":             ErrTeamExists,
+	"PR_EXISTS":               ErrPRExists,
+	"PR_MERGED":               ErrPRMerged,
+	"NOT_ASSIGNED":            ErrNotAssigned,
+	"NO_CANDIDATE":            ErrNoCandidate,
+	"NOT_FOUND":               ErrNotFound,
+	"INVALID_REQUEST":         ErrInvalidRequest,
+	"INVALID_TEAM_MEMBER":     ErrInvalidTeamMember,
+	"IDEMPOTENCY_KEY_REUSED":  ErrIdempotencyKeyReused,
+	"IDEMPOTENCY_IN_PROGRESS": ErrIdempotencyInProgress,
+	"INTERNAL_ERROR":          ErrInternal,
+}
+
+// APIError — ответ сервиса со статусом 4xx/5xx и телом ErrorResponse.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Details перечисляет нарушения по полям для INVALID_REQUEST.
+	Details []FieldViolation
+
+	retryAfter time.Duration
+}
+
+// FieldViolation — нарушение схемы запроса в конкретном поле.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("reviewer-service: HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("reviewer-service: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap возвращает sentinel-ошибку для известного кода; неизвестные коды
+// (например, из более новой версии сервиса) не сопоставляются ни с чем.
+func (e *APIError) Unwrap() error {
+	return errorsByCode[e.Code]
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return apiErr
+	}
+
+	var envelope struct {
+		Error struct {
+			Code    string           `json:"code"`
+			Message string           `json:"message"`
+			Details []FieldViolation `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error.Code == "" {
+		return apiErr
+	}
+	apiErr.Code = envelope.Error.Code
+	apiErr.Message = envelope.Error.Message
+	apiErr.Details = envelope.Error.Details
+	return apiErr
+}
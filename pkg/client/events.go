@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// EventsOptions — фильтры GET /events/stream; пустые поля не ограничивают
+// поток. LastEventID продолжает поток после этого события, как при
+// переподключении EventSource.
+type EventsOptions struct {
+	TeamName      string
+	UserID        string
+	PullRequestID string
+	LastEventID   string
+}
+
+func (o EventsOptions) values() url.Values {
+	q := url.Values{}
+	if o.TeamName != "" {
+		q.Set("team_name", o.TeamName)
+	}
+	if o.UserID != "" {
+		q.Set("user_id", o.UserID)
+	}
+	if o.PullRequestID != "" {
+		q.Set("pull_request_id", o.PullRequestID)
+	}
+	return q
+}
+
+// EventStream — открытый поток Server-Sent Events. Не потокобезопасен.
+type EventStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+	lastID string
+}
+
+// StreamEvents — GET /events/stream. Поток не ограничен таймаутом
+// HTTP-клиента и не повторяется: после обрыва откройте его заново с
+// EventsOptions.LastEventID = stream.LastEventID(). Событие может прийти
+// повторно, поэтому его стоит сверять по ID.
+func (c *Client) StreamEvents(ctx context.Context, opts EventsOptions) (*EventStream, error) {
+	u := *c.baseURL
+	u.Path += "/events/stream"
+	u.RawQuery = opts.values().Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if opts.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", opts.LastEventID)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	// Timeout http.Client ограничивает и чтение тела, а поток бесконечен
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return &EventStream{body: resp.Body, reader: bufio.NewReader(resp.Body), lastID: opts.LastEventID}, nil
+}
+
+// Next ждёт следующее событие. Комментарии keep-alive пропускаются;
+// io.EOF — сервер закрыл поток.
+func (s *EventStream) Next() (*StreamEvent, error) {
+	var id, data string
+	hasData := false
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return nil, io.EOF
+			}
+			if err != io.EOF {
+				return nil, err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if !hasData {
+				continue
+			}
+			var event StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return nil, fmt.Errorf("decode event %s: %w", id, err)
+			}
+			if id != "" {
+				s.lastID = id
+			}
+			return &event, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			if hasData {
+				data += "\n"
+			}
+			data += value
+			hasData = true
+		}
+	}
+}
+
+// LastEventID — id последнего полученного события для переподключения.
+func (s *EventStream) LastEventID() string {
+	return s.lastID
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
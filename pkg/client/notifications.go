@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// SetNotificationPreferences — POST /users/setNotificationPreferences:
+// заменяет адрес и подписки пользователя на письма.
+func (c *Client) SetNotificationPreferences(ctx context.Context, prefs NotificationPreferences) (*NotificationPreferences, error) {
+	var resp struct {
+		Preferences *NotificationPreferences `json:"preferences"`
+	}
+	if err := c.post(ctx, "/users/setNotificationPreferences", prefs, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Preferences, nil
+}
+
+// GetNotificationPreferences — GET /users/getNotificationPreferences.
+func (c *Client) GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	var prefs NotificationPreferences
+	if err := c.get(ctx, "/users/getNotificationPreferences", url.Values{"user_id": {userID}}, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CreatePullRequest — POST /pullRequest/create; ревьюверы назначаются сервером.
+func (c *Client) CreatePullRequest(ctx context.Context, pullRequestID, pullRequestName, authorID string) (*PullRequest, error) {
+	req := struct {
+		PullRequestID   string `json:"pull_request_id"`
+		PullRequestName string `json:"pull_request_name"`
+		AuthorID        string `json:"author_id"`
+	}{PullRequestID: pullRequestID, PullRequestName: pullRequestName, AuthorID: authorID}
+
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.post(ctx, "/pullRequest/create", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// MergePullRequest — POST /pullRequest/merge. Повторный merge не считается ошибкой.
+func (c *Client) MergePullRequest(ctx context.Context, pullRequestID string) (*PullRequest, error) {
+	req := struct {
+		PullRequestID string `json:"pull_request_id"`
+	}{PullRequestID: pullRequestID}
+
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.post(ctx, "/pullRequest/merge", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// ReassignReviewer — POST /pullRequest/reassign. Возвращает обновлённый PR
+// и идентификатор нового ревьювера (replaced_by).
+func (c *Client) ReassignReviewer(ctx context.Context, pullRequestID, oldUserID string) (*PullRequest, string, error) {
+	req := struct {
+		PullRequestID string `json:"pull_request_id"`
+		OldUserID     string `json:"old_user_id"`
+	}{PullRequestID: pullRequestID, OldUserID: oldUserID}
+
+	var resp struct {
+		PR         *PullRequest `json:"pr"`
+		ReplacedBy string       `json:"replaced_by"`
+	}
+	if err := c.post(ctx, "/pullRequest/reassign", req, &resp); err != nil {
+		return nil, "", err
+	}
+	return resp.PR, resp.ReplacedBy, nil
+}
+
+// SubmitReview — POST /pullRequest/submitReview. Повторный вызов возвращает
+// время первой отправки ревью.
+func (c *Client) SubmitReview(ctx context.Context, pullRequestID, userID string) (*PullRequest, time.Time, error) {
+	req := struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}{PullRequestID: pullRequestID, UserID: userID}
+
+	var resp struct {
+		PR         *PullRequest `json:"pr"`
+		ReviewedAt time.Time    `json:"reviewed_at"`
+	}
+	if err := c.post(ctx, "/pullRequest/submitReview", req, &resp); err != nil {
+		return nil, time.Time{}, err
+	}
+	return resp.PR, resp.ReviewedAt, nil
+}
+
+// GetPullRequestHistory — GET /pullRequest/history.
+func (c *Client) GetPullRequestHistory(ctx context.Context, pullRequestID string) ([]PullRequestEvent, error) {
+	var resp struct {
+		Events []PullRequestEvent `json:"events"`
+	}
+	if err := c.get(ctx, "/pullRequest/history", url.Values{"pull_request_id": {pullRequestID}}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// GetPullRequest — GET /pullRequest/get.
+func (c *Client) GetPullRequest(ctx context.Context, pullRequestID string) (*PullRequest, error) {
+	var resp struct {
+		PR *PullRequest `json:"pr"`
+	}
+	if err := c.get(ctx, "/pullRequest/get", url.Values{"pull_request_id": {pullRequestID}}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// ListOptions — фильтры и сортировка GET /pullRequest/list. Пустые поля
+// не передаются, и сервер применяет значения по умолчанию
+// (created_at по убыванию, 50 записей).
+type ListOptions struct {
+	Status         string
+	AuthorID       string
+	ReviewerID     string
+	TeamName       string
+	CreatedFrom    time.Time
+	CreatedTo      time.Time
+	MergedFrom     time.Time
+	MergedTo       time.Time
+	NeedsReviewers bool
+	Sort           string // created_at или pull_request_id
+	Order          string // asc или desc
+	Limit          int
+	Cursor         string
+}
+
+func (o ListOptions) values() url.Values {
+	q := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	setTime := func(key string, t time.Time) {
+		if !t.IsZero() {
+			q.Set(key, t.UTC().Format(time.RFC3339))
+		}
+	}
+	set("status", o.Status)
+	set("author_id", o.AuthorID)
+	set("reviewer_id", o.ReviewerID)
+	set("team_name", o.TeamName)
+	setTime("created_from", o.CreatedFrom)
+	setTime("created_to", o.CreatedTo)
+	setTime("merged_from", o.MergedFrom)
+	setTime("merged_to", o.MergedTo)
+	if o.NeedsReviewers {
+		q.Set("needs_reviewers", "true")
+	}
+	set("sort", o.Sort)
+	set("order", o.Order)
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	set("cursor", o.Cursor)
+	return q
+}
+
+// ListPullRequests — одна страница GET /pullRequest/list. Пустой nextCursor
+// означает последнюю страницу; для продолжения передайте его в opts.Cursor.
+func (c *Client) ListPullRequests(ctx context.Context, opts ListOptions) (prs []PullRequest, nextCursor string, err error) {
+	var resp struct {
+		PullRequests []PullRequest `json:"pull_requests"`
+		NextCursor   *string       `json:"next_cursor"`
+	}
+	if err := c.get(ctx, "/pullRequest/list", opts.values(), &resp); err != nil {
+		return nil, "", err
+	}
+	if resp.NextCursor != nil {
+		nextCursor = *resp.NextCursor
+	}
+	return resp.PullRequests, nextCursor, nil
+}
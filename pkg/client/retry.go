@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 3
+	DefaultRetryDelay  = 100 * time.Millisecond
+	DefaultMaxDelay    = 2 * time.Second
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// retryPolicy повторяет только идемпотентные запросы: GET и POST с
+// Idempotency-Key, который сервис использует для дедупликации повторов.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// WithRetry задаёт число попыток (1 — без повторов) и базовую задержку
+// экспоненциального backoff.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		c.retry.maxAttempts = maxAttempts
+		c.retry.baseDelay = baseDelay
+	}
+}
+
+// WithoutIdempotencyKeys отключает автоматический Idempotency-Key, и POST-запросы
+// перестают повторяться.
+func WithoutIdempotencyKeys() Option {
+	return func(c *Client) {
+		c.idempotencyKeys = false
+	}
+}
+
+// retryable: сетевые сбои, перегрузка/недоступность и 409 IDEMPOTENCY_IN_PROGRESS
+// (первый запрос с тем же ключом ещё выполняется). Отмена контекста не повторяется.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return errors.Is(apiErr, ErrIdempotencyInProgress)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (p retryPolicy) delay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		return minDuration(apiErr.retryAfter, p.maxDelay)
+	}
+	backoff := float64(p.baseDelay) * math.Pow(2, float64(attempt))
+	jitter := mrand.Float64()*0.5 + 0.75
+	return minDuration(time.Duration(backoff*jitter), p.maxDelay)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func newIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// SetWorkingHours — POST /users/setWorkingHours: заменяет рабочие часы пользователя.
+func (c *Client) SetWorkingHours(ctx context.Context, userID string, wh WorkingHours) (*WorkingHours, error) {
+	req := struct {
+		UserID string `json:"user_id"`
+		WorkingHours
+	}{UserID: userID, WorkingHours: wh}
+	var resp struct {
+		WorkingHours *WorkingHours `json:"working_hours"`
+	}
+	if err := c.post(ctx, "/users/setWorkingHours", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WorkingHours, nil
+}
+
+// GetWorkingHours — GET /users/getWorkingHours.
+func (c *Client) GetWorkingHours(ctx context.Context, userID string) (*WorkingHours, error) {
+	var resp struct {
+		WorkingHours *WorkingHours `json:"working_hours"`
+	}
+	if err := c.get(ctx, "/users/getWorkingHours", url.Values{"user_id": {userID}}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WorkingHours, nil
+}
+
+// SetTeamHolidays — POST /team/setHolidays: заменяет весь календарь праздников команды.
+func (c *Client) SetTeamHolidays(ctx context.Context, teamName string, holidays []Holiday) ([]Holiday, error) {
+	if holidays == nil {
+		holidays = []Holiday{}
+	}
+	req := struct {
+		TeamName string    `json:"team_name"`
+		Holidays []Holiday `json:"holidays"`
+	}{TeamName: teamName, Holidays: holidays}
+	var resp struct {
+		Holidays []Holiday `json:"holidays"`
+	}
+	if err := c.post(ctx, "/team/setHolidays", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Holidays, nil
+}
+
+// GetTeamHolidays — GET /team/getHolidays.
+func (c *Client) GetTeamHolidays(ctx context.Context, teamName string) ([]Holiday, error) {
+	var resp struct {
+		Holidays []Holiday `json:"holidays"`
+	}
+	if err := c.get(ctx, "/team/getHolidays", url.Values{"team_name": {teamName}}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Holidays, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// SetTeamSLA — POST /team/setReviewSLA: задаёт или заменяет срок первого
+// ревью для PR авторов команды.
+func (c *Client) SetTeamSLA(ctx context.Context, sla TeamSLA) (*TeamSLA, error) {
+	var resp struct {
+		SLA *TeamSLA `json:"sla"`
+	}
+	if err := c.post(ctx, "/team/setReviewSLA", sla, &resp); err != nil {
+		return nil, err
+	}
+	return resp.SLA, nil
+}
+
+// GetTeamSLA — GET /team/getReviewSLA.
+func (c *Client) GetTeamSLA(ctx context.Context, teamName string) (*TeamSLA, error) {
+	var sla TeamSLA
+	if err := c.get(ctx, "/team/getReviewSLA", url.Values{"team_name": {teamName}}, &sla); err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+// ListOverdueReviews — GET /pullRequest/overdue; пустой teamName — по всем командам.
+func (c *Client) ListOverdueReviews(ctx context.Context, teamName string) ([]OverdueReview, error) {
+	q := url.Values{}
+	if teamName != "" {
+		q.Set("team_name", teamName)
+	}
+	var resp struct {
+		Overdue []OverdueReview `json:"overdue"`
+	}
+	if err := c.get(ctx, "/pullRequest/overdue", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Overdue, nil
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// GetStatistics — GET /statistics.
+func (c *Client) GetStatistics(ctx context.Context) (*Statistics, error) {
+	var stats Statistics
+	if err := c.get(ctx, "/statistics", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetStatisticsRange — GET /statistics?from=&to=: нагрузка в Load считается
+// только по PR, созданным в [from, to). Нулевая граница не передаётся.
+func (c *Client) GetStatisticsRange(ctx context.Context, from, to time.Time) (*Statistics, error) {
+	q := url.Values{}
+	if !from.IsZero() {
+		q.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		q.Set("to", to.UTC().Format(time.RFC3339))
+	}
+	var stats Statistics
+	if err := c.get(ctx, "/statistics", q, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetTeamStatistics — GET /statistics/team.
+func (c *Client) GetTeamStatistics(ctx context.Context, teamName string) (*TeamStatistics, error) {
+	var stats TeamStatistics
+	if err := c.get(ctx, "/statistics/team", url.Values{"team_name": {teamName}}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// TimingsOptions — период и интервал GET /statistics/timings. Пустые поля
+// не передаются: сервер берёт последние 30 дней по суткам.
+// WorkingHours — считать длительности в рабочем времени.
+type TimingsOptions struct {
+	From         time.Time
+	To           time.Time
+	Bucket       string // BucketDay или BucketWeek
+	WorkingHours bool
+}
+
+func (o TimingsOptions) values() url.Values {
+	q := url.Values{}
+	if !o.From.IsZero() {
+		q.Set("from", o.From.UTC().Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		q.Set("to", o.To.UTC().Format(time.RFC3339))
+	}
+	if o.Bucket != "" {
+		q.Set("bucket", o.Bucket)
+	}
+	if o.WorkingHours {
+		q.Set("working_hours", "true")
+	}
+	return q
+}
+
+// GetReviewTimings — GET /statistics/timings.
+func (c *Client) GetReviewTimings(ctx context.Context, opts TimingsOptions) (*ReviewTimings, error) {
+	var timings ReviewTimings
+	if err := c.get(ctx, "/statistics/timings", opts.values(), &timings); err != nil {
+		return nil, err
+	}
+	return &timings, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateTeam — POST /team/add.
+func (c *Client) CreateTeam(ctx context.Context, team Team) (*Team, error) {
+	if team.Members == nil {
+		team.Members = []TeamMember{}
+	}
+	var resp struct {
+		Team *Team `json:"team"`
+	}
+	if err := c.post(ctx, "/team/add", team, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Team, nil
+}
+
+// GetTeam — GET /team/get: архивная команда не находится, архивные
+// участники не возвращаются.
+func (c *Client) GetTeam(ctx context.Context, teamName string) (*Team, error) {
+	return c.getTeam(ctx, url.Values{"team_name": {teamName}})
+}
+
+// GetTeamWithArchived — GET /team/get?include_archived=true: команда вместе
+// с архивными участниками, в том числе архивная.
+func (c *Client) GetTeamWithArchived(ctx context.Context, teamName string) (*Team, error) {
+	return c.getTeam(ctx, url.Values{"team_name": {teamName}, "include_archived": {"true"}})
+}
+
+func (c *Client) getTeam(ctx context.Context, query url.Values) (*Team, error) {
+	var team Team
+	if err := c.get(ctx, "/team/get", query, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// DeactivateTeamMembers — POST /team/deactivateMembers: деактивирует участников
+// и переназначает их открытые PR на оставшихся активных коллег.
+func (c *Client) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string) (*DeactivationResult, error) {
+	req := struct {
+		TeamName string   `json:"team_name"`
+		UserIDs  []string `json:"user_ids"`
+	}{TeamName: teamName, UserIDs: userIDs}
+
+	var result DeactivationResult
+	if err := c.post(ctx, "/team/deactivateMembers", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddTeamMember — POST /team/addMember: создаёт пользователя или обновляет
+// существующего и переводит его в команду. Nil isActive сохраняет текущую
+// активность (новые пользователи активны).
+func (c *Client) AddTeamMember(ctx context.Context, teamName, userID, username string, isActive *bool) (*MembershipResult, error) {
+	req := struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		IsActive *bool  `json:"is_active,omitempty"`
+	}{TeamName: teamName, UserID: userID, Username: username, IsActive: isActive}
+
+	var result MembershipResult
+	if err := c.post(ctx, "/team/addMember", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveTeamMember — POST /team/removeMember: оставляет пользователя без
+// команды и переназначает его открытые ревью внутри неё.
+func (c *Client) RemoveTeamMember(ctx context.Context, teamName, userID string) (*MembershipResult, error) {
+	req := struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+	}{TeamName: teamName, UserID: userID}
+
+	var result MembershipResult
+	if err := c.post(ctx, "/team/removeMember", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ArchiveTeam — POST /team/archive: архивирует команду вместе с участниками.
+func (c *Client) ArchiveTeam(ctx context.Context, teamName string) (*TeamArchiveResult, error) {
+	req := struct {
+		TeamName string `json:"team_name"`
+	}{TeamName: teamName}
+
+	var result TeamArchiveResult
+	if err := c.post(ctx, "/team/archive", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SyncTeams — POST /team/sync: приводит команды и их состав к манифесту teams.
+// С dryRun сервер только возвращает план изменений.
+func (c *Client) SyncTeams(ctx context.Context, teams []Team, dryRun bool) (*SyncResult, error) {
+	req := struct {
+		Teams []Team `json:"teams"`
+	}{Teams: teams}
+	if req.Teams == nil {
+		req.Teams = []Team{}
+	}
+	for i := range req.Teams {
+		if req.Teams[i].Members == nil {
+			req.Teams[i].Members = []TeamMember{}
+		}
+	}
+
+	var query url.Values
+	if dryRun {
+		query = url.Values{"dry_run": {"true"}}
+	}
+	var result SyncResult
+	if err := c.do(ctx, http.MethodPost, "/team/sync", query, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,292 @@
+package client
+
+import "time"
+
+const (
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+)
+
+type TeamMember struct {
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	IsActive   bool       `json:"is_active"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+type Team struct {
+	TeamName   string       `json:"team_name"`
+	Members    []TeamMember `json:"members"`
+	ArchivedAt *time.Time   `json:"archived_at,omitempty"`
+}
+
+type User struct {
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	TeamName   string     `json:"team_name"`
+	IsActive   bool       `json:"is_active"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+type PullRequest struct {
+	PullRequestID     string     `json:"pull_request_id"`
+	PullRequestName   string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         *time.Time `json:"createdAt,omitempty"`
+	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+}
+
+type PullRequestShort struct {
+	PullRequestID           string     `json:"pull_request_id"`
+	PullRequestName         string     `json:"pull_request_name"`
+	AuthorID                string     `json:"author_id"`
+	Status                  string     `json:"status"`
+	Role                    string     `json:"role"`
+	AssignedAt              *time.Time `json:"assigned_at,omitempty"`
+	MergedAt                *time.Time `json:"mergedAt,omitempty"`
+	ReviewAgeSeconds        int64      `json:"review_age_seconds"`
+	ReviewWorkingAgeSeconds int64      `json:"review_working_age_seconds"`
+}
+
+type PullRequestEvent struct {
+	ID             int64     `json:"id"`
+	PullRequestID  string    `json:"pull_request_id"`
+	Type           string    `json:"type"`
+	UserID         string    `json:"user_id,omitempty"`
+	PreviousUserID string    `json:"previous_user_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// StreamEvent — событие /events/stream с командой и автором PR на момент чтения.
+type StreamEvent struct {
+	PullRequestEvent
+	AuthorID string `json:"author_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+}
+
+type DeactivationResult struct {
+	DeactivatedUsers []string `json:"deactivated_users"`
+	ReassignedPRs    int      `json:"reassigned_prs"`
+}
+
+type MembershipResult struct {
+	Team          *Team `json:"team"`
+	ReassignedPRs int   `json:"reassigned_prs"`
+}
+
+type UserChangeResult struct {
+	User          *User `json:"user"`
+	ReassignedPRs int   `json:"reassigned_prs"`
+}
+
+type TeamArchiveResult struct {
+	Team          *Team    `json:"team"`
+	ArchivedUsers []string `json:"archived_users"`
+	ReassignedPRs int      `json:"reassigned_prs"`
+}
+
+// SyncChange — изменение из ответа /team/sync; Action — одна из констант Sync*.
+type SyncChange struct {
+	Action       string `json:"action"`
+	TeamName     string `json:"team_name"`
+	UserID       string `json:"user_id,omitempty"`
+	PreviousTeam string `json:"previous_team,omitempty"`
+}
+
+const (
+	SyncCreateTeam     = "CREATE_TEAM"
+	SyncCreateUser     = "CREATE_USER"
+	SyncUpdateUser     = "UPDATE_USER"
+	SyncMoveUser       = "MOVE_USER"
+	SyncDeactivateUser = "DEACTIVATE_USER"
+)
+
+type SyncResult struct {
+	DryRun        bool         `json:"dry_run"`
+	Changes       []SyncChange `json:"changes"`
+	ReassignedPRs int          `json:"reassigned_prs"`
+}
+
+// ImportResult — ответ импорта: Entity — teams, users или pull_requests.
+type ImportResult struct {
+	Entity   string `json:"entity"`
+	Imported int    `json:"imported"`
+}
+
+// WorkingHours — недельное расписание пользователя в часовом поясе IANA.
+type WorkingHours struct {
+	TimeZone string          `json:"time_zone"`
+	Periods  []WorkingPeriod `json:"periods"`
+}
+
+// WorkingPeriod — промежуток "HH:MM"–"HH:MM" дня Weekday (monday ... sunday).
+type WorkingPeriod struct {
+	Weekday string `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// Holiday — нерабочий день команды в формате YYYY-MM-DD.
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+// NotificationPreferences — адрес пользователя и его подписки на письма.
+type NotificationPreferences struct {
+	UserID            string `json:"user_id"`
+	Email             string `json:"email"`
+	DailyDigest       bool   `json:"daily_digest"`
+	AssignmentNotices bool   `json:"assignment_notices"`
+	OverdueReminders  bool   `json:"overdue_reminders"`
+}
+
+// TeamSLA — срок первого ревью команды; ReassignAfterHours включает
+// автоматическую замену ревьювера.
+type TeamSLA struct {
+	TeamName           string `json:"team_name"`
+	FirstReviewHours   int    `json:"first_review_hours"`
+	ReassignAfterHours *int   `json:"reassign_after_hours,omitempty"`
+}
+
+type OverdueReview struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	TeamName        string     `json:"team_name"`
+	ReviewerID      string     `json:"reviewer_id"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	DueAt           time.Time  `json:"due_at"`
+	OverdueSeconds  int64      `json:"overdue_seconds"`
+	RemindedAt      *time.Time `json:"reminded_at,omitempty"`
+	ReassignAt      *time.Time `json:"reassign_at,omitempty"`
+}
+
+type ReviewerAssignment struct {
+	UserID string `json:"user_id"`
+	Count  int    `json:"count"`
+}
+
+type Statistics struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Teams       struct {
+		Total int `json:"total"`
+	} `json:"teams"`
+	Users struct {
+		Total    int `json:"total"`
+		Active   int `json:"active"`
+		Inactive int `json:"inactive"`
+	} `json:"users"`
+	PullRequests struct {
+		Total  int `json:"total"`
+		Open   int `json:"open"`
+		Merged int `json:"merged"`
+	} `json:"pull_requests"`
+	ReviewAssignments struct {
+		Total      int                  `json:"total"`
+		ByReviewer []ReviewerAssignment `json:"by_reviewer"`
+	} `json:"review_assignments"`
+	Load *ReviewLoad `json:"load,omitempty"`
+}
+
+// ReviewLoad — открытые и все назначения ревьюверов по командам и по ревьюверам.
+type ReviewLoad struct {
+	From       *time.Time     `json:"from,omitempty"`
+	To         *time.Time     `json:"to,omitempty"`
+	ByTeam     []TeamLoad     `json:"by_team"`
+	ByReviewer []ReviewerLoad `json:"by_reviewer"`
+}
+
+type TeamLoad struct {
+	TeamName         string `json:"team_name"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+type ReviewerLoad struct {
+	UserID           string `json:"user_id"`
+	TeamName         string `json:"team_name,omitempty"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+const (
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+// Percentiles — перцентили в секундах; без замеров (Count = 0) поля P* равны nil.
+type Percentiles struct {
+	Count int    `json:"count"`
+	P50   *int64 `json:"p50_seconds,omitempty"`
+	P90   *int64 `json:"p90_seconds,omitempty"`
+	P99   *int64 `json:"p99_seconds,omitempty"`
+}
+
+type TimingSummary struct {
+	TimeToMerge       Percentiles `json:"time_to_merge"`
+	TimeToFirstReview Percentiles `json:"time_to_first_review"`
+}
+
+type TeamTimings struct {
+	TeamName string `json:"team_name"`
+	TimingSummary
+}
+
+type ReviewerTimings struct {
+	UserID string `json:"user_id"`
+	TimingSummary
+}
+
+type TimingBreakdown struct {
+	Overall    TimingSummary     `json:"overall"`
+	ByTeam     []TeamTimings     `json:"by_team"`
+	ByReviewer []ReviewerTimings `json:"by_reviewer"`
+}
+
+type TimingBucket struct {
+	Start time.Time `json:"start"`
+	TimingBreakdown
+}
+
+type ReviewTimings struct {
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Bucket       string          `json:"bucket"`
+	WorkingHours bool            `json:"working_hours"`
+	Total        TimingBreakdown `json:"total"`
+	Buckets      []TimingBucket  `json:"buckets"`
+}
+
+// Значения MemberLoad.Load
+const (
+	LoadOverloaded  = "OVERLOADED"
+	LoadUnderloaded = "UNDERLOADED"
+	LoadBalanced    = "BALANCED"
+	LoadInactive    = "INACTIVE"
+)
+
+type MemberLoad struct {
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	IsActive         bool   `json:"is_active"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+	OpenAuthored     int    `json:"open_authored"`
+	TotalAuthored    int    `json:"total_authored"`
+	Load             string `json:"load"`
+}
+
+type TeamStatistics struct {
+	TeamName         string `json:"team_name"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+	Fairness         struct {
+		MeanOpenAssignments float64  `json:"mean_open_assignments"`
+		Gini                float64  `json:"gini"`
+		MaxMinRatio         *float64 `json:"max_min_ratio,omitempty"`
+	} `json:"fairness"`
+	Members []MemberLoad `json:"members"`
+}
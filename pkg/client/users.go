@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// SetUserActive — POST /users/setIsActive.
+func (c *Client) SetUserActive(ctx context.Context, userID string, isActive bool) (*User, error) {
+	req := struct {
+		UserID   string `json:"user_id"`
+		IsActive bool   `json:"is_active"`
+	}{UserID: userID, IsActive: isActive}
+
+	var resp struct {
+		User *User `json:"user"`
+	}
+	if err := c.post(ctx, "/users/setIsActive", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// GetUserReviews — GET /users/getReview: первая страница открытых PR,
+// где пользователь назначен ревьювером.
+func (c *Client) GetUserReviews(ctx context.Context, userID string) ([]PullRequestShort, error) {
+	prs, _, err := c.ListUserReviews(ctx, userID, ReviewsOptions{})
+	return prs, err
+}
+
+// ReviewsOptions — параметры GET /users/getReview. Пустые поля не передаются:
+// сервер отдаёт открытые PR по pull_request_id, 50 записей.
+type ReviewsOptions struct {
+	Status          string // OPEN, MERGED или ALL
+	IncludeAuthored bool
+	Sort            string // pull_request_id или assigned_at
+	Limit           int
+	Cursor          string
+}
+
+func (o ReviewsOptions) values(userID string) url.Values {
+	q := url.Values{"user_id": {userID}}
+	if o.Status != "" {
+		q.Set("status", o.Status)
+	}
+	if o.IncludeAuthored {
+		q.Set("include_authored", "true")
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	return q
+}
+
+// ListUserReviews — одна страница входящих ревью пользователя. Пустой
+// nextCursor означает последнюю страницу.
+func (c *Client) ListUserReviews(ctx context.Context, userID string, opts ReviewsOptions) (prs []PullRequestShort, nextCursor string, err error) {
+	var resp struct {
+		PullRequests []PullRequestShort `json:"pull_requests"`
+		NextCursor   *string            `json:"next_cursor"`
+	}
+	if err := c.get(ctx, "/users/getReview", opts.values(userID), &resp); err != nil {
+		return nil, "", err
+	}
+	if resp.NextCursor != nil {
+		nextCursor = *resp.NextCursor
+	}
+	return resp.PullRequests, nextCursor, nil
+}
+
+// MoveUser — POST /users/moveTeam: переводит пользователя в другую команду,
+// его открытые ревью переназначаются внутри прежней.
+func (c *Client) MoveUser(ctx context.Context, userID, teamName string) (*UserChangeResult, error) {
+	req := struct {
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+	}{UserID: userID, TeamName: teamName}
+
+	var result UserChangeResult
+	if err := c.post(ctx, "/users/moveTeam", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ArchiveUser — POST /users/archive: передаёт открытую работу пользователя
+// коллегам и архивирует его; история PR сохраняется.
+func (c *Client) ArchiveUser(ctx context.Context, userID string) (*UserChangeResult, error) {
+	req := struct {
+		UserID string `json:"user_id"`
+	}{UserID: userID}
+
+	var result UserChangeResult
+	if err := c.post(ctx, "/users/archive", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetChatUser — POST /users/setChatUser: привязывает идентификатор
+// пользователя Slack к пользователю сервиса.
+func (c *Client) SetChatUser(ctx context.Context, userID, chatUserID string) error {
+	req := struct {
+		UserID     string `json:"user_id"`
+		ChatUserID string `json:"chat_user_id"`
+	}{UserID: userID, ChatUserID: chatUserID}
+	return c.post(ctx, "/users/setChatUser", req, nil)
+}